@@ -0,0 +1,122 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoMetrics gathers the repo-level aggregates of a 'summary' report,
+// leaving out the rows of the authors, for tracking the repo as a whole
+// over time.
+type RepoMetrics struct {
+	Authors     int       `json:"authors" yaml:"authors"`
+	Commits     int       `json:"commits" yaml:"commits"`
+	Additions   int       `json:"additions" yaml:"additions"`
+	Deletions   int       `json:"deletions" yaml:"deletions"`
+	Granularity float64   `json:"granularity" yaml:"granularity"`
+	Gini        float64   `json:"gini" yaml:"gini"`
+	BusFactor   int       `json:"bus_factor" yaml:"bus_factor"`
+	First       time.Time `json:"first_commit" yaml:"first_commit"`
+	Last        time.Time `json:"last_commit" yaml:"last_commit"`
+	Days        int       `json:"days" yaml:"days"`
+}
+
+// Aggregate returns the repo-level metrics of the summaries and totals of
+// a report.
+func Aggregate(summaries []AuthorSummary, totals Totals) RepoMetrics {
+	m := RepoMetrics{
+		Authors:     len(summaries),
+		Commits:     totals.Commits,
+		Additions:   totals.Additions,
+		Deletions:   totals.Deletions,
+		Granularity: totals.Granularity,
+		Gini:        totals.Gini,
+		BusFactor:   busFactor(summaries),
+		First:       totals.First,
+		Last:        totals.Last,
+	}
+	if !totals.First.IsZero() {
+		m.Days = totals.Days()
+	}
+
+	return m
+}
+
+// busFactor returns the smallest number of authors who together made at
+// least half of the line changes of the repo, taking the authors with the
+// most line changes first. It is 0 when the authors made less than half
+// of them altogether, as when others were left out of the report.
+func busFactor(summaries []AuthorSummary) int {
+	ratios := make([]float64, len(summaries))
+	for i, s := range summaries {
+		ratios[i] = s.LineRatio
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(ratios)))
+
+	var sum float64
+	for i, r := range ratios {
+		sum += r
+		if sum >= 0.5 {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// writeRepoMetrics writes the repo-level metrics to w in one of the table,
+// json, pretty-json, ndjson or yaml formats.
+func writeRepoMetrics(w io.Writer, m RepoMetrics, format string) error {
+	switch format {
+	case "json", "ndjson":
+		if err := json.NewEncoder(w).Encode(m); err != nil {
+			return fmt.Errorf("error encoding json: %w", err)
+		}
+		return nil
+
+	case "pretty-json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(m); err != nil {
+			return fmt.Errorf("error encoding json: %w", err)
+		}
+		return nil
+
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(m); err != nil {
+			return fmt.Errorf("error encoding yaml: %w", err)
+		}
+		return enc.Close()
+
+	case "table":
+		fmt.Fprintf(w, " Authors: %d\n", m.Authors)
+		fmt.Fprintf(w, " Commits: %d\n", m.Commits)
+		fmt.Fprintf(w, " Additions: %d\n", m.Additions)
+		fmt.Fprintf(w, " Deletions: %d\n", m.Deletions)
+		fmt.Fprintf(w, " Overall repo commit granularity: %.3f\n", m.Granularity)
+		fmt.Fprintf(w, " Gini coefficient of line changes: %.3f\n", m.Gini)
+		fmt.Fprintf(w, " Bus factor: %d\n", m.BusFactor)
+		if !m.First.IsZero() {
+			fmt.Fprintf(w,
+				" Commits from %s to %s (%d days)\n",
+				m.First.Format("2006-01-02"),
+				m.Last.Format("2006-01-02"),
+				m.Days,
+			)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("--aggregate-only can't be used with the %s format", format)
+	}
+}
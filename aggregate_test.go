@@ -0,0 +1,38 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_Aggregate(t *testing.T) {
+	summaries, totals, _ := Summarize(
+		map[string]int{"A": 5, "B": 3, "C": 1, "D": 1},
+		map[string]LineChanges{"A": {Additions: 30}, "B": {Additions: 30}, "C": {Additions: 25}, "D": {Additions: 15}},
+	)
+	totals.First = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	totals.Last = time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	m := Aggregate(summaries, totals)
+	if m.Authors != 4 || m.Commits != 10 || m.Additions != 100 || m.BusFactor != 2 || m.Days != 10 {
+		t.Errorf("unexpected metrics: %+v", m)
+	}
+
+	if f := busFactor(summaries[:1]); f != 0 {
+		t.Errorf("Expected no bus factor for authors making less than half, got %d", f)
+	}
+
+	var buf bytes.Buffer
+	if err := writeRepoMetrics(&buf, m, "json"); err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"authors":4,"commits":10,"additions":100,"deletions":0,"granularity":0.1,"gini":0.125,"bus_factor":2,"first_commit":"2023-01-01T00:00:00Z","last_commit":"2023-01-11T00:00:00Z","days":10}` + "\n"
+	if buf.String() != exp {
+		t.Errorf("Expected %s, got %s", exp, buf.String())
+	}
+
+	if err := writeRepoMetrics(&buf, m, "svg"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "strings"
+
+// anomaly holds the commits with one kind of data-quality issue skewing
+// the metrics.
+type anomaly struct {
+	Kind    string
+	Commits []string
+}
+
+// findAnomalies returns the commits without an author name, also when
+// labelled as unknown already, the non-merge commits without any file
+// changes and the commits with numstat lines that couldn't be parsed, in
+// that order. Each kind is listed, also when no commit has it.
+func findAnomalies(commits []commit) []anomaly {
+	anomalies := []anomaly{
+		{Kind: "empty author"},
+		{Kind: "empty commit"},
+		{Kind: "malformed numstat"},
+	}
+
+	for _, c := range commits {
+		if c.NoAuthor || strings.TrimSpace(c.Author) == "" {
+			anomalies[0].Commits = append(anomalies[0].Commits, c.Hash)
+		}
+		if !c.isMerge() && len(c.Files) == 0 && len(c.Malformed) == 0 {
+			anomalies[1].Commits = append(anomalies[1].Commits, c.Hash)
+		}
+		if len(c.Malformed) > 0 {
+			anomalies[2].Commits = append(anomalies[2].Commits, c.Hash)
+		}
+	}
+
+	return anomalies
+}
+
+// examples returns up to n of the commits of the anomaly, abbreviated and
+// separated by commas.
+func (a anomaly) examples(n int) string {
+	var short []string
+	for i, h := range a.Commits {
+		if i == n {
+			break
+		}
+		if len(h) > 12 {
+			h = h[:12]
+		}
+		short = append(short, h)
+	}
+	return strings.Join(short, ", ")
+}
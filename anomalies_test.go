@@ -0,0 +1,44 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_FindAnomalies(t *testing.T) {
+	commits := []commit{
+		{Hash: "aaa", Author: "Author One", Parents: []string{"p"}, Files: []fileChange{{Path: "a.go"}}},
+		{Hash: "bbb", Author: " ", Parents: []string{"p"}, Files: []fileChange{{Path: "b.go"}}},
+		{Hash: "ccc", Author: "Author One", Parents: []string{"p"}},
+		{Hash: "ddd", Author: "Author Two", Parents: []string{"p", "q"}},
+		{Hash: "eee", Author: "Author Two", Malformed: []string{"x\ty\tz"}},
+		{Hash: "fff", Author: defaultUnknownAuthor, Parents: []string{"p"}, Files: []fileChange{{Path: "f.go"}}},
+		{Hash: "ggg", Author: defaultUnknownAuthor, NoAuthor: true, Parents: []string{"p"}, Files: []fileChange{{Path: "g.go"}}},
+	}
+
+	got := findAnomalies(commits)
+	exp := []anomaly{
+		{"empty author", []string{"bbb", "ggg"}},
+		{"empty commit", []string{"ccc"}},
+		{"malformed numstat", []string{"eee"}},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %+v, got: %+v", exp, got)
+	}
+}
+
+func Test_AnomaliesEmptyCommit(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.git("commit", "-q", "--allow-empty", "-m", "empty")
+
+	commits, err := logCommits(r.options())
+	if err != nil {
+		t.Fatalf("error reading commits: %s", err)
+	}
+
+	a := findAnomalies(commits)
+	if len(a[1].Commits) != 1 || a[1].examples(3) != a[1].Commits[0][:12] {
+		t.Errorf("Expected the empty commit, got: %+v", a[1])
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"sort"
+)
+
+// anonymizeFlags are the flags replacing the author names of a report with
+// pseudonyms, shared by all commands naming authors.
+type anonymizeFlags struct {
+	anonymize bool
+	key       string
+}
+
+func (af *anonymizeFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&af.anonymize, "anonymize", false, "replace author names with pseudonyms")
+	fs.StringVar(&af.key, "key", "", "secret key making the --anonymize pseudonyms stable across runs")
+}
+
+// pseudonyms returns the pseudonyms of the authors of a report with
+// --anonymize, or nil leaving their names as they are.
+func (af anonymizeFlags) pseudonyms() *pseudonyms {
+	if !af.anonymize {
+		return nil
+	}
+	return &pseudonyms{key: af.key, names: make(map[string]string)}
+}
+
+// pseudonyms replaces author names with pseudonyms, the same name always
+// getting the same one, for the reports naming authors in several places.
+// Without a key, the authors are numbered "Author 1" to "Author N" in the
+// order they are first named, which for most reports is by descending
+// contributions. With a key, each name is replaced by its pseudonym for
+// that key, like anonymize does.
+type pseudonyms struct {
+	key   string
+	names map[string]string
+}
+
+// name returns the pseudonym of the author, or the name itself when p is
+// nil, without --anonymize.
+func (p *pseudonyms) name(author string) string {
+	if p == nil {
+		return author
+	}
+	if p.key != "" {
+		return pseudonym(author, p.key)
+	}
+
+	n, ok := p.names[author]
+	if !ok {
+		n = fmt.Sprintf("Author %d", len(p.names)+1)
+		p.names[author] = n
+	}
+	return n
+}
+
+// rename replaces the author names of the summaries with their
+// pseudonyms. The authors not named before are numbered by descending
+// commits, line changes and then name, like anonymize does, rather than in
+// the order of the summaries, so that sorting them by author tells nothing
+// of their names.
+func (p *pseudonyms) rename(summaries []AuthorSummary) {
+	if p == nil {
+		return
+	}
+
+	order := append([]AuthorSummary(nil), summaries...)
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		if a.Additions+a.Deletions != b.Additions+b.Deletions {
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		}
+		return a.Author < b.Author
+	})
+	for _, s := range order {
+		p.name(s.Author)
+	}
+
+	for i, s := range summaries {
+		summaries[i].Author = p.name(s.Author)
+	}
+}
+
+// anonymize replaces the author names of the summaries with pseudonyms.
+// Without a key, the authors are numbered "Author 1" to "Author N" by
+// descending commits and line changes, which reveals nothing about their
+// names. With a key, each name is replaced by its pseudonym for that key,
+// which stays the same across runs and repos.
+func anonymize(summaries []AuthorSummary, key string) {
+	if key != "" {
+		for i, s := range summaries {
+			summaries[i].Author = pseudonym(s.Author, key)
+		}
+		return
+	}
+
+	order := make([]int, len(summaries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := summaries[order[i]], summaries[order[j]]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Additions+a.Deletions > b.Additions+b.Deletions
+	})
+
+	for n, i := range order {
+		summaries[i].Author = fmt.Sprintf("Author %d", n+1)
+	}
+}
+
+// pseudonym returns the pseudonym of an author name for the given key,
+// made from the start of the HMAC-SHA256 of the name, so that it can't be
+// traced back to the name without the key.
+func pseudonym(name, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(name))
+	return "author-" + hex.EncodeToString(mac.Sum(nil))[:8]
+}
@@ -0,0 +1,72 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func Test_Pseudonym(t *testing.T) {
+	a := pseudonym("Svein-Kåre Bjørnsen", "secret")
+	if b := pseudonym("Svein-Kåre Bjørnsen", "secret"); a != b {
+		t.Errorf("Expected identical names to get identical pseudonyms, got %q and %q", a, b)
+	}
+	if b := pseudonym("Ørjan Ås", "secret"); a == b {
+		t.Errorf("Expected different names to get different pseudonyms, got %q", a)
+	}
+	if b := pseudonym("Svein-Kåre Bjørnsen", "other"); a == b {
+		t.Errorf("Expected the pseudonym to depend on the key, got %q", a)
+	}
+	if len(a) != len("author-")+8 {
+		t.Errorf("Expected author- and 8 hex digits, got %q", a)
+	}
+}
+
+func Test_Anonymize(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Few", Commits: 1, Additions: 1},
+		{Author: "Most", Commits: 5},
+		{Author: "Tied", Commits: 1, Additions: 10},
+	}
+
+	anonymize(summaries, "")
+	for i, exp := range []string{"Author 3", "Author 1", "Author 2"} {
+		if summaries[i].Author != exp {
+			t.Errorf("Expected %q, got: %q", exp, summaries[i].Author)
+		}
+	}
+
+	summaries = []AuthorSummary{{Author: "Most"}, {Author: "Few"}}
+	anonymize(summaries, "secret")
+	if summaries[0].Author != pseudonym("Most", "secret") {
+		t.Errorf("Expected the keyed pseudonym, got: %q", summaries[0].Author)
+	}
+}
+
+func Test_AnonymizeCommands(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Svein-Kåre Bjørnsen", "one.txt", "1\n")
+	r.commit("Ørjan Ås", "two.txt", "1\n2\n")
+	r.commit("Ørjan Ås", "two.txt", "1\n2\n3\n")
+
+	for _, cmd := range []*Z.Cmd{AuthorCommitsCmd, AllBranchesCmd, HotspotsCmd, CsvAuthorCommitsCmd, CsvContributionSummaryCmd} {
+		out := filepath.Join(t.TempDir(), "out")
+		if err := cmd.Call(cmd, "--repo", r.dir, "--anonymize", "--output", out); err != nil {
+			t.Fatalf("error running %s: %s", cmd.Name, err)
+		}
+		buf, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(buf)
+		if strings.Contains(got, "Bjørnsen") || strings.Contains(got, "Ås") {
+			t.Errorf("Expected no author names in the %s output, got:\n%s", cmd.Name, got)
+		}
+		if !strings.Contains(got, "Author 1") {
+			t.Errorf("Expected the authors numbered in the %s output, got:\n%s", cmd.Name, got)
+		}
+	}
+}
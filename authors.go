@@ -0,0 +1,138 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// authorsFormat is the git log format of the 'authorsfile' command,
+// giving the author name, email and date of each commit separated by NUL
+// bytes.
+const authorsFormat = "--format=%aN%x00%aE%x00%aI"
+
+// authorEntry is a line of an AUTHORS file, being an author along with
+// the email they last committed with, their number of commits and the
+// date of their first one.
+type authorEntry struct {
+	Name    string
+	Email   string
+	Commits int
+	First   time.Time
+}
+
+// String returns the entry as written in an AUTHORS file, like
+// "Name <email>", or only the name when the email is empty.
+func (e authorEntry) String() string {
+	if e.Email == "" {
+		return e.Name
+	}
+	return fmt.Sprintf("%s <%s>", e.Name, e.Email)
+}
+
+// authorEntries returns an entry of each author of a non-merge commit in
+// the history of o, sorted by the date of their first commit, or by name
+// when byName is set.
+func authorEntries(o options, byName bool) ([]authorEntry, error) {
+	out, err := o.walk("log", "--no-merges", o.logFormat(authorsFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseAuthorEntries(out)
+	if err != nil {
+		return nil, err
+	}
+	sortAuthorEntries(entries, byName)
+
+	return entries, nil
+}
+
+// parseAuthorEntries returns an entry of each author in the output of git
+// log with authorsFormat, newest first. An author is one name, being
+// consolidated from their identities by the mailmap, and their email is
+// the one of their newest commit. Commits without an author name are left
+// out, as they credit no one.
+func parseAuthorEntries(gitOutput string) ([]authorEntry, error) {
+	byName := make(map[string]*authorEntry)
+	var entries []*authorEntry
+
+	scanner := bufio.NewScanner(strings.NewReader(gitOutput))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed log line: %q", line)
+		}
+		name := strings.TrimSpace(fields[0])
+		if name == "" {
+			continue
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date of commit by %s: %w", name, err)
+		}
+
+		e, ok := byName[name]
+		if !ok {
+			e = &authorEntry{Name: name, Email: strings.TrimSpace(fields[1])}
+			byName[name] = e
+			entries = append(entries, e)
+		}
+		e.Commits++
+		if e.First.IsZero() || !date.After(e.First) {
+			e.First = date
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	list := make([]authorEntry, len(entries))
+	for i, e := range entries {
+		list[i] = *e
+	}
+	return list, nil
+}
+
+// sortAuthorEntries sorts the entries by the date of the first commit of
+// their author, then by name, or only by name when byName is set, ignoring
+// case.
+func sortAuthorEntries(entries []authorEntry, byName bool) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !byName && !a.First.Equal(b.First) {
+			return a.First.Before(b.First)
+		}
+		if x, y := strings.ToLower(a.Name), strings.ToLower(b.Name); x != y {
+			return x < y
+		}
+		return a.Name < b.Name
+	})
+}
+
+// writeAuthorsFile writes the entries of the authors of at least
+// minCommits commits to w, one per line.
+func writeAuthorsFile(w io.Writer, entries []authorEntry, minCommits int) error {
+	for _, e := range entries {
+		if e.Commits < minCommits {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
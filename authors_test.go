@@ -0,0 +1,69 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_ParseAuthorEntries(t *testing.T) {
+	out := "Bravo\x00bravo@new.example\x002024-03-01T10:00:00Z\n" +
+		"alpha\x00alpha@example.com\x002024-02-01T10:00:00Z\n" +
+		"Bravo\x00bravo@old.example\x002024-01-01T10:00:00Z\n" +
+		" \x00nobody@example.com\x002023-12-01T10:00:00Z\n" +
+		"Charlie\x00\x002024-02-01T10:00:00Z\n"
+
+	entries, err := parseAuthorEntries(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	sortAuthorEntries(entries, false)
+	if err := writeAuthorsFile(buf, entries, 0); err != nil {
+		t.Fatal(err)
+	}
+	exp := "Bravo <bravo@new.example>\nalpha <alpha@example.com>\nCharlie\n"
+	if buf.String() != exp {
+		t.Errorf("Expected the authors by first commit:\n%s\ngot:\n%s", exp, buf)
+	}
+
+	buf.Reset()
+	sortAuthorEntries(entries, true)
+	if err := writeAuthorsFile(buf, entries, 2); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "Bravo <bravo@new.example>\n"; buf.String() != exp {
+		t.Errorf("Expected only the authors of 2 commits:\n%s\ngot:\n%s", exp, buf)
+	}
+
+	buf.Reset()
+	if err := writeAuthorsFile(buf, entries, 0); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "alpha <alpha@example.com>\nBravo <bravo@new.example>\nCharlie\n"; buf.String() != exp {
+		t.Errorf("Expected the authors by name:\n%s\ngot:\n%s", exp, buf)
+	}
+}
+
+func Test_AuthorEntriesMailmap(t *testing.T) {
+	r := newTestRepo(t)
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Ann", "GIT_AUTHOR_EMAIL=ann@old.example"}, "commit", "-q", "--allow-empty", "-m", "one")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Bob", "GIT_AUTHOR_EMAIL=bob@example.com"}, "commit", "-q", "--allow-empty", "-m", "two")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=ann", "GIT_AUTHOR_EMAIL=ann@new.example"}, "commit", "-q", "--allow-empty", "-m", "three")
+	r.write(".mailmap", "Ann <ann@new.example> <ann@old.example>\nAnn <ann@new.example> ann <ann@new.example>\n")
+	r.git("add", ".mailmap")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Bob", "GIT_AUTHOR_EMAIL=bob@example.com"}, "commit", "-q", "-m", "mailmap")
+
+	entries, err := authorEntries(r.options(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeAuthorsFile(buf, entries, 0); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "Ann <ann@new.example>\nBob <bob@example.com>\n"; buf.String() != exp {
+		t.Errorf("Expected the identities of Ann consolidated:\n%s\ngot:\n%s", exp, buf)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// badgeMetrics are the metrics a badge can show, by the name given to
+// --metric, along with the label of the badge.
+var badgeMetrics = []struct {
+	name  string
+	label string
+	value func(m RepoMetrics) int
+}{
+	{"contributors", "contributors", func(m RepoMetrics) int { return m.Authors }},
+	{"commits", "commits", func(m RepoMetrics) int { return m.Commits }},
+	{"bus-factor", "bus factor", func(m RepoMetrics) int { return m.BusFactor }},
+}
+
+// badge is the JSON read by the shields.io endpoint badge, as given by
+// https://shields.io/badges/endpoint-badge.
+type badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// checkBadgeMetric returns an error for a --metric not in badgeMetrics.
+func checkBadgeMetric(metric string) error {
+	names := make([]string, len(badgeMetrics))
+	for i, b := range badgeMetrics {
+		if b.name == metric {
+			return nil
+		}
+		names[i] = b.name
+	}
+	return fmt.Errorf("unknown --metric %q, must be one of: %s", metric, strings.Join(names, ", "))
+}
+
+// makeBadge returns the badge showing the metric of m. The badges are
+// blue, except for the bus factor, which is red when 1 or less, yellow
+// when 2 and green above.
+func makeBadge(metric string, m RepoMetrics) badge {
+	b := badge{SchemaVersion: 1, Color: "blue"}
+	for _, bm := range badgeMetrics {
+		if bm.name != metric {
+			continue
+		}
+
+		v := bm.value(m)
+		b.Label, b.Message = bm.label, strconv.Itoa(v)
+		if metric == "bus-factor" {
+			switch {
+			case v <= 1:
+				b.Color = "red"
+			case v == 2:
+				b.Color = "yellow"
+			default:
+				b.Color = "green"
+			}
+		}
+	}
+	return b
+}
+
+// writeBadge writes the badge to w as a single line of JSON.
+func writeBadge(w io.Writer, b badge) error {
+	return json.NewEncoder(w).Encode(b)
+}
@@ -0,0 +1,39 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_MakeBadge(t *testing.T) {
+	m := RepoMetrics{Authors: 42, Commits: 340, BusFactor: 2}
+
+	var buf bytes.Buffer
+	if err := writeBadge(&buf, makeBadge("contributors", m)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := `{"schemaVersion":1,"label":"contributors","message":"42","color":"blue"}` + "\n"
+	if buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+
+	cases := []struct {
+		busFactor int
+		color     string
+	}{{0, "red"}, {1, "red"}, {2, "yellow"}, {3, "green"}}
+	for _, c := range cases {
+		m.BusFactor = c.busFactor
+		b := makeBadge("bus-factor", m)
+		if b.Label != "bus factor" || b.Color != c.color {
+			t.Errorf("Expected a %s bus factor badge for %d, got %+v", c.color, c.busFactor, b)
+		}
+	}
+
+	if b := makeBadge("commits", m); b.Message != "340" {
+		t.Errorf("Expected 340 commits, got %+v", b)
+	}
+
+	if err := checkBadgeMetric("lines"); err == nil {
+		t.Error("Expected an error for an unknown metric")
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// baselineVersion is stored in every baseline, and is increased whenever
+// their meaning changes, so that older ones are not merged into.
+const baselineVersion = 1
+
+// baseline is the state stored by --baseline, being the commit counts and
+// line changes of each author, as parsed before any filtering, up to the
+// analysed commit Head.
+type baseline struct {
+	Version     int                    `json:"version"`
+	Head        string                 `json:"head"`
+	Params      baselineParams         `json:"params"`
+	Commits     map[string]int         `json:"commits"`
+	LineChanges map[string]LineChanges `json:"line_changes"`
+	First       time.Time              `json:"first_commit"`
+	Last        time.Time              `json:"last_commit"`
+}
+
+// baselineParams are the flags changing the parsed numbers, along with
+// the hash of the mailmap mapping the authors, which must match for a
+// baseline to be merged into.
+type baselineParams struct {
+	GitDir          string   `json:"git_dir"`
+	FirstParent     bool     `json:"first_parent,omitempty"`
+	NoMailmap       bool     `json:"no_mailmap,omitempty"`
+	Mailmap         string   `json:"mailmap,omitempty"`
+	DateType        string   `json:"date_type,omitempty"`
+	TZ              string   `json:"tz,omitempty"`
+	MaxCommitLines  int      `json:"max_commit_lines,omitempty"`
+	ExcludeCommit   bool     `json:"exclude_commit,omitempty"`
+	CapLinesPerFile int      `json:"cap_lines_per_file,omitempty"`
+	DiffFilter      string   `json:"diff_filter,omitempty"`
+	CodeOnly        bool     `json:"code_only,omitempty"`
+	CodeExts        []string `json:"code_exts,omitempty"`
+	SquashAuthor    string   `json:"squash_author,omitempty"`
+	ExcludeReverts  bool     `json:"exclude_reverts,omitempty"`
+	ExcludeReverted bool     `json:"exclude_reverted,omitempty"`
+	Gitattributes   []string `json:"gitattributes,omitempty"`
+	Roots           []string `json:"roots,omitempty"`
+	UnknownAuthor   string   `json:"unknown_author,omitempty"`
+	Paths           []string `json:"paths,omitempty"`
+	Follow          bool     `json:"follow,omitempty"`
+}
+
+// baselineParams returns the parameters of the baseline of the history of
+// o.
+func (o options) baselineParams() (baselineParams, error) {
+	gitDir, err := o.git("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return baselineParams{}, err
+	}
+
+	mailmap, err := mailmapHash(o)
+	if err != nil {
+		return baselineParams{}, err
+	}
+
+	return baselineParams{
+		GitDir:          strings.TrimSpace(gitDir),
+		FirstParent:     o.firstParent,
+		NoMailmap:       o.noMailmap,
+		Mailmap:         mailmap,
+		DateType:        o.dateType,
+		TZ:              o.tz,
+		MaxCommitLines:  o.maxCommitLines,
+		ExcludeCommit:   o.excludeCommit,
+		CapLinesPerFile: o.capLinesPerFile,
+		DiffFilter:      o.diffFilter,
+		CodeOnly:        o.codeOnly,
+		CodeExts:        o.codeExts,
+		SquashAuthor:    o.squashAuthor,
+		ExcludeReverts:  o.excludeReverts,
+		ExcludeReverted: o.excludeReverted,
+		Gitattributes:   o.attributes.lines(),
+		Roots:           o.roots,
+		UnknownAuthor:   o.unknownAuthor,
+		Paths:           o.paths,
+		Follow:          o.follow,
+	}, nil
+}
+
+// checkBaseline returns an error when the history of o can't be analysed
+// incrementally, as when it is limited in time or to a range.
+func (o options) checkBaseline() error {
+	switch {
+	case o.since != "" || o.until != "":
+		return errors.New("--baseline can't be combined with --since or --until")
+	case o.sinceTag.set || o.commitsGiven():
+		return errors.New("--baseline can't be combined with --since-tag, --commits or --commits-file")
+	case o.allBranches:
+		return errors.New("--baseline can't be combined with --all-branches")
+	case strings.Contains(o.rev, ".."):
+		return errors.New("--baseline can't be used with a range")
+	}
+	return nil
+}
+
+// readBaseline returns the baseline stored in the file, and whether there
+// was one. A missing file is no error, as the first run creates it.
+func readBaseline(path string) (baseline, bool, error) {
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return baseline{}, false, nil
+	}
+	if err != nil {
+		return baseline{}, false, fmt.Errorf("error reading baseline: %w", err)
+	}
+
+	var b baseline
+	if err := json.Unmarshal(buf, &b); err != nil {
+		return baseline{}, false, fmt.Errorf("error decoding baseline %s: %w", path, err)
+	}
+	if b.Commits == nil {
+		b.Commits = make(map[string]int)
+	}
+	if b.LineChanges == nil {
+		b.LineChanges = make(map[string]LineChanges)
+	}
+
+	return b, true, nil
+}
+
+// writeBaseline writes the baseline to the file, replacing any previous
+// one only once it is complete.
+func writeBaseline(path string, b baseline) error {
+	buf, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(path, append(buf, '\n')); err != nil {
+		return fmt.Errorf("error writing baseline: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes the file through a temporary file in the same
+// directory, renamed to its name once written, so that concurrent readers
+// never see a partial one.
+func writeFileAtomic(path string, buf []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(buf)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+// incremental returns the baseline of the history of o, holding the
+// commit counts and line changes of each author along with the dates of
+// its first and last commits, merging those of the commits added since
+// the baseline stored in the file into it. The whole history is analysed
+// instead when there is no baseline yet, when it was made with other
+// flags, or when its commit is no longer part of the history, as after a
+// force-push. The file is then updated to the analysed commit.
+func incremental(o options, path string) (baseline, error) {
+	if o.rev == "" && !hasCommits(o) {
+		return baseline{}, ErrNoCommits
+	}
+
+	head, err := resolveRev(o)
+	if err != nil {
+		return baseline{}, fmt.Errorf("error resolving %s: %w", o.rev, err)
+	}
+	o.rev = head
+
+	params, err := o.baselineParams()
+	if err != nil {
+		return baseline{}, err
+	}
+
+	b, ok, err := readBaseline(path)
+	if err != nil {
+		return baseline{}, err
+	}
+
+	switch {
+	case !ok:
+		logf(slog.LevelInfo, "no baseline in %s, analysing the whole history", path)
+	case b.Version != baselineVersion || !reflect.DeepEqual(b.Params, params):
+		logf(slog.LevelInfo, "baseline made with other flags, analysing the whole history")
+		ok = false
+	case b.Head != head && !isAncestor(o, b.Head, head):
+		logf(slog.LevelInfo, "baseline commit %s no longer in the history, analysing the whole history", b.Head)
+		ok = false
+	}
+
+	if !ok {
+		b = baseline{Commits: map[string]int{}, LineChanges: map[string]LineChanges{}}
+	} else if b.Head != head {
+		o.rev = b.Head + ".." + head
+	}
+
+	if !ok || b.Head != head {
+		commits, err := authorCommits(o)
+		if err != nil {
+			return baseline{}, fmt.Errorf("error extracting commit counts: %w", err)
+		}
+		lineChanges, err := mapLineChanges(o)
+		if err != nil {
+			return baseline{}, fmt.Errorf("error extracting line changes: %w", err)
+		}
+
+		// the new commits may all be left out, like by --path
+		first, last, err := dateSpan(o)
+		if err != nil && !(ok && errors.Is(err, ErrNoCommits)) {
+			return baseline{}, fmt.Errorf("error getting date span: %w", err)
+		}
+
+		debugf("merging %d new commits into the baseline", sumCounts(commits))
+		for k, v := range commits {
+			b.Commits[k] += v
+		}
+		for k, v := range lineChanges {
+			b.LineChanges[k] = b.LineChanges[k].Merge(v)
+		}
+		if !first.IsZero() && (b.First.IsZero() || first.Before(b.First)) {
+			b.First = first
+		}
+		if last.After(b.Last) {
+			b.Last = last
+		}
+	}
+
+	b.Version, b.Head, b.Params = baselineVersion, head, params
+	if dryRun {
+		return b, nil
+	}
+
+	return b, writeBaseline(path, b)
+}
+
+// isAncestor reports whether the commit is part of the history of head,
+// being false for a commit that no longer exists.
+func isAncestor(o options, commit, head string) bool {
+	_, err := o.git("merge-base", "--is-ancestor", commit, head)
+	return err == nil
+}
+
+// sumCounts returns the sum of the counts.
+func sumCounts(counts map[string]int) int {
+	var n int
+	for _, v := range counts {
+		n += v
+	}
+	return n
+}
@@ -0,0 +1,86 @@
+package gitcontrib
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func Test_Incremental(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.commit("Author Two", "two.txt", "1\n")
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	b, err := incremental(r.options(), path)
+	if err != nil {
+		t.Fatalf("error analysing history: %s", err)
+	}
+	if b.Commits["Author One"] != 1 || b.Commits["Author Two"] != 1 {
+		t.Fatalf("Expected the whole history, got %v", b.Commits)
+	}
+
+	// tampering with the stored counts shows they are merged into
+	stored, _, err := readBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.Commits["Author One"] = 10
+	if err := writeBaseline(path, stored); err != nil {
+		t.Fatal(err)
+	}
+
+	r.commit("Author One", "three.txt", "1\n2\n3\n")
+	b, err = incremental(r.options(), path)
+	if err != nil {
+		t.Fatalf("error analysing new commits: %s", err)
+	}
+	if b.Commits["Author One"] != 11 || b.LineChanges["Author One"] != (LineChanges{Additions: 5}) {
+		t.Errorf("Expected the new commit merged into the baseline, got %v, %v", b.Commits, b.LineChanges)
+	}
+	if head := r.git("rev-parse", "HEAD"); b.Head+"\n" != head {
+		t.Errorf("Expected the baseline at %s, got %s", head, b.Head)
+	}
+
+	// without new commits, the baseline is reused as is
+	b, err = incremental(r.options(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Commits["Author One"] != 11 {
+		t.Errorf("Expected the baseline reused, got %v", b.Commits)
+	}
+
+	// rewriting the history analyses it all again
+	r.git("reset", "-q", "--hard", "HEAD~2")
+	r.commit("Author Two", "four.txt", "1\n")
+	b, err = incremental(r.options(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Commits["Author One"] != 1 || b.Commits["Author Two"] != 1 {
+		t.Errorf("Expected the rewritten history analysed again, got %v", b.Commits)
+	}
+
+	// other flags analyse it all again too
+	o := r.options()
+	o.paths = stringList{"one.txt"}
+	b, err = incremental(o, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Commits) != 1 || b.Commits["Author One"] != 1 {
+		t.Errorf("Expected only the history of --path, got %v", b.Commits)
+	}
+}
+
+func Test_CheckBaseline(t *testing.T) {
+	for _, o := range []options{
+		{since: "1 week ago"},
+		{rev: "v1..HEAD"},
+		{commitList: stringList{"abc"}},
+	} {
+		if err := o.checkBaseline(); err == nil {
+			t.Errorf("Expected %+v to be rejected", o)
+		}
+	}
+}
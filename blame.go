@@ -0,0 +1,127 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// survivingLines returns the number of lines of each author surviving in
+// the files at the end of the revision or range of o, as attributed by git
+// blame. Binary files have no lines, so they are skipped. As git blame is
+// run once for every file, this is much slower than walking the history.
+func survivingLines(o options) (map[string]int, error) {
+	rev := blameRev(o.rev)
+
+	files, err := textFiles(o, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		printCommand(commandLine([]string{"blame", "--line-porcelain", rev, "--", "FILE"}))
+		return make(map[string]int), nil
+	}
+
+	counts := make([]map[string]int, len(files))
+	err = parallel(len(files), runtime.NumCPU(), func(i int) error {
+		out, err := o.git("blame", "--line-porcelain", rev, "--", files[i])
+		if err != nil {
+			return fmt.Errorf("error blaming %s: %w", files[i], err)
+		}
+		counts[i] = parseBlame(out)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]int)
+	for _, c := range counts {
+		for author, n := range c {
+			lines[author] += n
+		}
+	}
+
+	return lines, nil
+}
+
+// blameRev returns the revision whose files are blamed for the revision
+// or range rev, being the end of a range and HEAD when none is given.
+func blameRev(rev string) string {
+	if i := strings.LastIndex(rev, ".."); i >= 0 {
+		rev = strings.TrimPrefix(rev[i+2:], ".")
+	}
+	if rev == "" {
+		return "HEAD"
+	}
+	return rev
+}
+
+// textFiles returns the paths of the files at rev that aren't binary,
+// limited to the paths of o.
+func textFiles(o options, rev string) ([]string, error) {
+	lines, err := textFileLines(o, rev, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, len(lines))
+	for i, f := range lines {
+		files[i] = f.Path
+	}
+
+	return files, nil
+}
+
+// textFileLines returns the files at rev that aren't binary nor empty,
+// limited to the paths of o and leaving out those matching the exclude
+// pathspecs, with their number of lines as additions. They are found by
+// diffing rev against the empty tree, which lists binary files without
+// line counts.
+func textFileLines(o options, rev string, exclude []string) ([]fileChange, error) {
+	empty, err := o.git("hash-object", "-t", "tree", "--stdin")
+	if err != nil {
+		return nil, fmt.Errorf("error finding the empty tree: %w", err)
+	}
+
+	args := []string{"diff-tree", "-r", "--numstat", "--no-renames", strings.TrimSpace(empty), rev}
+	if len(o.paths) > 0 || len(exclude) > 0 {
+		args = append(append(args, "--"), o.paths...)
+	}
+	for _, e := range exclude {
+		args = append(args, ":(exclude)"+e)
+	}
+	out, err := o.git(args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing files: %w", err)
+	}
+
+	var files []fileChange
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		f, err := parseNumstat(scanner.Text())
+		if err != nil || f.Additions == 0 {
+			continue // binary or empty
+		}
+		files = append(files, f)
+	}
+
+	return files, scanner.Err()
+}
+
+// parseBlame returns the number of lines of each author in the output of
+// git blame --line-porcelain, which has an "author" header for every line.
+func parseBlame(gitOutput string) map[string]int {
+	lines := make(map[string]int)
+	for _, line := range strings.Split(gitOutput, "\n") {
+		if author, ok := strings.CutPrefix(line, "author "); ok {
+			lines[author]++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,53 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_SurvivingLines(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n3\n")
+	r.commit("Author One", "image.bin", "\x00\x01\x02\n")
+	r.commit("Author Two", "one.txt", "1\ntwo\nthree\n")
+	r.commit("Author Two", "sub/two.txt", "1\n")
+
+	lines, err := survivingLines(r.options())
+	if err != nil {
+		t.Fatalf("error counting surviving lines: %s", err)
+	}
+
+	exp := map[string]int{"Author One": 1, "Author Two": 3}
+	if !reflect.DeepEqual(lines, exp) {
+		t.Errorf("Expected %v, got %v", exp, lines)
+	}
+
+	o := r.options()
+	o.paths = stringList{"sub"}
+	lines, err = survivingLines(o)
+	if err != nil {
+		t.Fatalf("error counting surviving lines: %s", err)
+	}
+	if !reflect.DeepEqual(lines, map[string]int{"Author Two": 1}) {
+		t.Errorf("Expected only the lines under the path, got %v", lines)
+	}
+
+	sf := summaryFlags{options: r.options()}
+	sf.noCache = true
+	sf.survive = true
+	summaries, _, err := sf.analyse()
+	if err != nil {
+		t.Fatalf("error analysing: %s", err)
+	}
+	for _, s := range summaries {
+		if s.Author == "Author One" && (s.Additions != 3 || s.Surviving != 1 || s.Footprint != 0.25) {
+			t.Errorf("unexpected summary: %+v", s)
+		}
+	}
+
+	for rev, exp := range map[string]string{"": "HEAD", "v1..main": "main", "v1...main": "main", "v1": "v1", "v1..": "HEAD"} {
+		if got := blameRev(rev); got != exp {
+			t.Errorf("Expected blamed revision of %q to be %q, got %q", rev, exp, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// defaultBotPattern matches the names of common bots, like those of the
+// GitHub apps ending in "[bot]", Dependabot, GitHub Actions and Renovate.
+const defaultBotPattern = `(?i)\[bot\]$|dependabot|github-actions|renovate`
+
+// botsAuthor is the name of the single row the bots are collapsed into by
+// --collapse-bots.
+const botsAuthor = "bots"
+
+// resolveBots compiles the --bot-pattern expression matching the bots
+// collapsed by --collapse-bots, which are matched by defaultBotPattern
+// unless another is given.
+func (ff *filterFlags) resolveBots() error {
+	if !ff.collapseBots || ff.bots != nil {
+		return nil
+	}
+
+	pattern := ff.botPattern
+	if pattern == "" {
+		pattern = defaultBotPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid --bot-pattern expression: %w", err)
+	}
+	ff.bots = re
+	return nil
+}
+
+// rowName returns the name of the row the author is reported in, being
+// the name of their team, or that of the bots with --collapse-bots, or
+// their own.
+func (ff *filterFlags) rowName(author string) string {
+	if team, ok := ff.teams[author]; ok {
+		return team
+	}
+	if ff.bots != nil && ff.bots.MatchString(author) {
+		return botsAuthor
+	}
+	return author
+}
+
+// mergeBots merges the authors matching --bot-pattern into a single bots
+// entry of the given maps with --collapse-bots, summing their commits and
+// line changes like those of a team. Either map may be nil. The teams are
+// merged first, so that a bot in a team is reported with the team.
+func (ff *filterFlags) mergeBots(
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) {
+	if ff.bots == nil {
+		return
+	}
+
+	bots := make(teams)
+	add := func(author string) {
+		if !ff.teams.has(author) && ff.bots.MatchString(author) {
+			bots[author] = botsAuthor
+		}
+	}
+	for author := range commitMap {
+		add(author)
+	}
+	for author := range lineChangesMap {
+		add(author)
+	}
+	bots.merge(commitMap, lineChangesMap)
+}
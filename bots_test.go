@@ -0,0 +1,51 @@
+package gitcontrib
+
+import (
+	"testing"
+)
+
+func Test_CollapseBots(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.commit("dependabot[bot]", "go.mod", "1\n2\n3\n")
+	r.commit("Renovate Bot", "package.json", "1\n")
+	r.commit("Author Two", "two.txt", "1\n")
+	r.commit("dependabot[bot]", "go.sum", "1\n")
+
+	ff := filterFlags{collapseBots: true}
+	summaries, totals, err := ff.collect(r.options())
+	if err != nil {
+		t.Fatalf("error collecting summaries: %s", err)
+	}
+
+	rows := make(map[string]AuthorSummary)
+	for _, s := range summaries {
+		rows[s.Author] = s
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected the bots in one row and the humans apart, got %+v", summaries)
+	}
+	if bots := rows[botsAuthor]; bots.Commits != 3 || bots.Additions != 5 {
+		t.Errorf("Expected 3 commits and 5 additions of the bots, got %+v", bots)
+	}
+	if rows["Author One"].Commits != 1 || rows["Author Two"].Commits != 1 {
+		t.Errorf("Expected the humans to keep their rows, got %+v", summaries)
+	}
+	if totals.Commits != 5 {
+		t.Errorf("Expected the bots to count in the totals, got %+v", totals)
+	}
+
+	ff = filterFlags{collapseBots: true, botPattern: "^Renovate"}
+	summaries, _, err = ff.collect(r.options())
+	if err != nil {
+		t.Fatalf("error collecting summaries: %s", err)
+	}
+	if len(summaries) != 4 || ff.rowName("Renovate Bot") != botsAuthor || ff.rowName("dependabot[bot]") != "dependabot[bot]" {
+		t.Errorf("Expected only the authors matching --bot-pattern to be collapsed, got %+v", summaries)
+	}
+
+	ff = filterFlags{collapseBots: true, botPattern: "["}
+	if _, _, err := ff.collect(r.options()); err == nil {
+		t.Error("Expected an invalid --bot-pattern to be rejected")
+	}
+}
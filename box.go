@@ -0,0 +1,100 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// boxStyle holds the characters drawing the borders of a boxed table. The
+// corners and junctions are given top to bottom, left to right.
+type boxStyle struct {
+	horizontal string
+	vertical   string
+	top        [3]string
+	middle     [3]string
+	bottom     [3]string
+}
+
+// unicodeBox draws the borders with box-drawing characters.
+var unicodeBox = boxStyle{
+	horizontal: "─",
+	vertical:   "│",
+	top:        [3]string{"┌", "┬", "┐"},
+	middle:     [3]string{"├", "┼", "┤"},
+	bottom:     [3]string{"└", "┴", "┘"},
+}
+
+// asciiBox draws the borders with ASCII characters, for terminals without
+// box-drawing characters.
+var asciiBox = boxStyle{
+	horizontal: "-",
+	vertical:   "|",
+	top:        [3]string{"+", "+", "+"},
+	middle:     [3]string{"+", "+", "+"},
+	bottom:     [3]string{"+", "+", "+"},
+}
+
+// write writes rows to w as a table with borders around every column, the
+// first row being the header, separated from the rest by a line. The cells
+// of the leading text columns are left-aligned, truncated to the maximum
+// name width, and those of the following numeric columns right-aligned.
+// Widths count runes, so that names with accented letters line up.
+func (b boxStyle) write(w io.Writer, text int, rows [][]string) error {
+	var widths []int
+	for _, r := range rows {
+		for i := range r {
+			if i < text {
+				r[i] = truncate(r[i], tableLayout.maxNameWidth)
+			}
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(r[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	line := func(corners [3]string) string {
+		parts := make([]string, len(widths))
+		for i, n := range widths {
+			parts[i] = strings.Repeat(b.horizontal, n+2)
+		}
+		return corners[0] + strings.Join(parts, corners[1]) + corners[2]
+	}
+
+	var out strings.Builder
+	out.WriteString(line(b.top) + "\n")
+	for i, r := range rows {
+		for j, n := range widths {
+			var c string
+			if j < len(r) {
+				c = r[j]
+			}
+			pad := strings.Repeat(" ", n-utf8.RuneCountInString(c))
+			if j < text || i == 0 {
+				c += pad
+			} else {
+				c = pad + c
+			}
+			out.WriteString(b.vertical + " " + c + " ")
+		}
+		out.WriteString(b.vertical + "\n")
+
+		if i == 0 && len(rows) > 1 {
+			out.WriteString(line(b.middle) + "\n")
+		}
+	}
+	out.WriteString(line(b.bottom) + "\n")
+
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return fmt.Errorf("error writing table: %w", err)
+	}
+
+	return nil
+}
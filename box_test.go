@@ -0,0 +1,38 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func Test_WriteSummaryBox(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Ólafur Þórðarson", Commits: 12, Additions: 100, Deletions: 20, LineRatio: 0.75, CommitRatio: 0.8, Granularity: 0.1},
+		{Author: "Two", Commits: 3, Additions: 30, Deletions: 10, LineRatio: 0.25, CommitRatio: 0.2, Granularity: 0.075},
+	}
+
+	tests := []struct {
+		golden string
+		opts   renderOptions
+	}{
+		{"testdata/summary-box.golden", renderOptions{box: true, noFooter: true}},
+		{"testdata/summary-box-ascii.golden", renderOptions{box: true, ascii: true, noFooter: true}},
+	}
+
+	for _, test := range tests {
+		exp, err := os.ReadFile(test.golden)
+		if err != nil {
+			t.Fatalf("error reading golden file: %s", err)
+		}
+
+		buf := new(bytes.Buffer)
+		if err := writeSummaryTable(buf, summaries, Totals{}, test.opts); err != nil {
+			t.Fatalf("error rendering table: %s", err)
+		}
+
+		if buf.String() != string(exp) {
+			t.Errorf("Expected %s:\n%s\ngot:\n%s", test.golden, exp, buf)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"strings"
+	"sync"
+)
+
+// branchSummary holds the 'summary' report of a single branch.
+type branchSummary struct {
+	Branch    string
+	Summaries []AuthorSummary
+	Totals    Totals
+}
+
+// listBranches returns the names of the local branches of the repo of o,
+// along with the remote-tracking ones when remote is set.
+func listBranches(o options, remote bool) ([]string, error) {
+	args := []string{"branch"}
+	if remote {
+		args = append(args, "--all")
+	}
+
+	out, err := o.git(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBranches(out), nil
+}
+
+// parseBranches returns the branch names listed in git branch output, in
+// the listed order. Symbolic refs like "origin/HEAD -> origin/main" and
+// detached HEADs are left out, as they are not branches of their own.
+func parseBranches(gitBranchOutput string) []string {
+	var branches []string
+
+	scanner := bufio.NewScanner(strings.NewReader(gitBranchOutput))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+
+		if line == "" || strings.HasPrefix(line, "(") ||
+			strings.Contains(line, " -> ") {
+			continue
+		}
+
+		branches = append(branches, line)
+	}
+
+	return branches
+}
+
+// parallel calls fn with each index from 0 to n-1, running at most jobs
+// calls at the same time, and returns the error of the lowest index
+// failing, if any.
+func parallel(n, jobs int, fn func(i int) error) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, jobs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
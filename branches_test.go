@@ -0,0 +1,61 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ParseBranches(t *testing.T) {
+	gbOutput := `  feature/x
+* main
+  remotes/origin/HEAD -> origin/main
+  remotes/origin/main
+`
+	exp := []string{"feature/x", "main", "remotes/origin/main"}
+	if got := parseBranches(gbOutput); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %q, got %q", exp, got)
+	}
+}
+
+func Test_AllBranchesUnion(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.git("branch", "feature")
+	r.git("checkout", "-q", "feature")
+	r.commit("Author Two", "two.txt", "1\n2\n")
+	r.git("checkout", "-q", "main")
+	r.commit("Author One", "one.txt", "1\n2\n")
+
+	o := r.options()
+	o.allBranches = true
+	if err := o.resolve(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(o.tips) != 2 {
+		t.Errorf("Expected the tips of both branches, got %v", o.tips)
+	}
+
+	// the first commit is on both branches, and counts once
+	commits, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := map[string]int{"Author One": 2, "Author Two": 1}; !reflect.DeepEqual(commits, exp) {
+		t.Errorf("Expected commits %v, got %v", exp, commits)
+	}
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := map[string]LineChanges{"Author One": {Additions: 2}, "Author Two": {Additions: 2}}
+	if !reflect.DeepEqual(lineChanges, exp) {
+		t.Errorf("Expected line changes %v, got %v", exp, lineChanges)
+	}
+
+	o = r.options()
+	o.allBranches, o.rev = true, "main"
+	if err := o.resolve(); err == nil {
+		t.Error("Expected an error combining --all-branches with --branch")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AuthorStats holds the counts of an author combined by the breadth
+// score, along with those of the whole history, by which they are
+// normalized.
+type AuthorStats struct {
+	Commits int // non-merge commits
+	Files   int // distinct files touched
+	Churn   int // added and deleted lines
+
+	TotalCommits int
+	TotalFiles   int
+	TotalChurn   int
+}
+
+// Weights are the weights of the commits, the files touched and the line
+// churn in the breadth score, set by --breadth-weights.
+type Weights struct {
+	Commits float64
+	Files   float64
+	Churn   float64
+}
+
+// defaultWeights weigh the files touched as much as the commits and the
+// churn together, breadth being about how much of the code an author
+// reaches rather than how much they change.
+var defaultWeights = Weights{Commits: 1, Files: 2, Churn: 1}
+
+func (w *Weights) String() string {
+	return fmt.Sprintf("commits=%g,files=%g,churn=%g", w.Commits, w.Files, w.Churn)
+}
+
+// Set sets the weights given as a comma-separated list of NAME=WEIGHT,
+// like "files=3,churn=0.5", the weights not listed being kept. The weights
+// can't be negative, and at least one must be positive.
+func (w *Weights) Set(list string) error {
+	set := *w
+	for _, def := range strings.Split(list, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(def), "=")
+		if !ok {
+			return fmt.Errorf("invalid weight %q, expected NAME=WEIGHT", def)
+		}
+
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || f < 0 {
+			return fmt.Errorf("invalid weight %q, must be a number of at least 0", value)
+		}
+
+		switch strings.TrimSpace(name) {
+		case "commits":
+			set.Commits = f
+		case "files":
+			set.Files = f
+		case "churn":
+			set.Churn = f
+		default:
+			return fmt.Errorf("unknown weight %q, must be one of: commits, files, churn", name)
+		}
+	}
+
+	if set.Commits+set.Files+set.Churn == 0 {
+		return errors.New("at least one weight must be positive")
+	}
+
+	*w = set
+	return nil
+}
+
+// BreadthScore returns the breadth score of an author, from 0 to 100,
+// being the mean of their shares of all commits, of all distinct files
+// touched and of all line churn, weighted by the weights, times 100. A
+// share of a total of 0 counts as 0.
+func BreadthScore(stats AuthorStats, weights Weights) float64 {
+	sum := weights.Commits + weights.Files + weights.Churn
+	if sum == 0 {
+		return 0
+	}
+
+	score := weights.Commits*ratio(stats.Commits, stats.TotalCommits) +
+		weights.Files*ratio(stats.Files, stats.TotalFiles) +
+		weights.Churn*ratio(stats.Churn, stats.TotalChurn)
+
+	return 100 * score / sum
+}
+
+// breadthStats returns the stats of the breadth score of each author of
+// the commits, which are expected newest first like git log writes them,
+// along with the mean number of files changed by their commits. Merge
+// commits are left out. With followRenames, the paths of a renamed file
+// count as one file.
+func breadthStats(commits []commit, followRenames bool) (map[string]AuthorStats, map[string]float64) {
+	var nonMerge []commit
+	for _, c := range commits {
+		if !c.isMerge() {
+			nonMerge = append(nonMerge, c)
+		}
+	}
+
+	touched := countFilesTouched(nonMerge, followRenames)
+	all := make(map[string]bool)
+	eachFile(nonMerge, followRenames, func(_, path string) {
+		all[path] = true
+	})
+
+	stats := make(map[string]AuthorStats)
+	changed := make(map[string]int)
+	var total AuthorStats
+	for _, c := range nonMerge {
+		s := stats[c.Author]
+		s.Commits++
+		s.Churn += c.LineChanges().Sum()
+		stats[c.Author] = s
+
+		changed[c.Author] += len(c.Files)
+		total.Commits++
+		total.Churn += c.LineChanges().Sum()
+	}
+
+	perCommit := make(map[string]float64, len(stats))
+	for author, s := range stats {
+		s.Files = touched[author]
+		s.TotalCommits, s.TotalFiles, s.TotalChurn = total.Commits, len(all), total.Churn
+		stats[author] = s
+		perCommit[author] = ratio(changed[author], s.Commits)
+	}
+
+	return stats, perCommit
+}
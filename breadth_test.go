@@ -0,0 +1,65 @@
+package gitcontrib
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_BreadthScore(t *testing.T) {
+	stats := AuthorStats{
+		Commits: 1, Files: 3, Churn: 10,
+		TotalCommits: 4, TotalFiles: 4, TotalChurn: 40,
+	}
+
+	// (1*0.25 + 2*0.75 + 1*0.25) / 4
+	if got := BreadthScore(stats, defaultWeights); math.Abs(got-50) > 1e-9 {
+		t.Errorf("Expected a score of 50, got: %f", got)
+	}
+	if got := BreadthScore(stats, Weights{Files: 1}); math.Abs(got-75) > 1e-9 {
+		t.Errorf("Expected a score of 75 by the files only, got: %f", got)
+	}
+	if got := BreadthScore(AuthorStats{}, defaultWeights); got != 0 {
+		t.Errorf("Expected a score of 0 without any totals, got: %f", got)
+	}
+}
+
+func Test_BreadthWeights(t *testing.T) {
+	w := defaultWeights
+	if err := w.Set("files=3, churn=0.5"); err != nil {
+		t.Fatal(err)
+	}
+	if w != (Weights{Commits: 1, Files: 3, Churn: 0.5}) {
+		t.Errorf("Expected the listed weights to be replaced, got: %v", w)
+	}
+
+	for _, list := range []string{"files", "files=-1", "lines=1", "commits=0,files=0,churn=0"} {
+		w := defaultWeights
+		if err := w.Set(list); err == nil {
+			t.Errorf("Expected an error for %q", list)
+		}
+	}
+}
+
+func Test_BreadthStats(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "a.txt", "1\n2\n3\n")
+	r.commit("Author One", "b.txt", "1\n")
+	r.commit("Author Two", "a.txt", "1\n")
+
+	commits, err := logCommits(r.options())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, perCommit := breadthStats(commits, false)
+	exp := AuthorStats{
+		Commits: 2, Files: 2, Churn: 4,
+		TotalCommits: 3, TotalFiles: 2, TotalChurn: 6,
+	}
+	if stats["Author One"] != exp {
+		t.Errorf("Expected %+v, got: %+v", exp, stats["Author One"])
+	}
+	if perCommit["Author One"] != 1 || perCommit["Author Two"] != 1 {
+		t.Errorf("Expected one file per commit, got: %v", perCommit)
+	}
+}
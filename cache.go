@@ -0,0 +1,199 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cacheVersion is part of every cache key, and is increased whenever the
+// cached reports change meaning, so that older entries are not reused.
+const cacheVersion = 1
+
+// cacheFlags holds the flags controlling the report cache.
+type cacheFlags struct {
+	noCache  bool
+	cacheDir string
+}
+
+func (cf *cacheFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&cf.noCache, "no-cache", false, "neither read nor write the report cache")
+	fs.StringVar(&cf.cacheDir, "cache-dir", "", "directory of the report cache (default $XDG_CACHE_HOME/gitcontrib)")
+}
+
+// cache returns the report cache selected by the flags.
+func (cf *cacheFlags) cache() (reportCache, error) {
+	if cf.cacheDir != "" {
+		return reportCache(cf.cacheDir), nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding cache directory: %w", err)
+	}
+
+	return reportCache(filepath.Join(dir, "gitcontrib")), nil
+}
+
+// reportCache is a directory of reports stored as JSON, each in a file
+// named by its key.
+type reportCache string
+
+// cacheKey returns the key of a report computed from the history of the
+// repo of o at the given commits, with the given parameters. The commits
+// are the resolved hashes of the analysed revisions, so that moving HEAD
+// or a branch gives a new key, as does editing the mailmap.
+func cacheKey(o options, commits string, params any) (string, error) {
+	gitDir, err := o.git("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+
+	mailmap, err := mailmapHash(o)
+	if err != nil {
+		return "", err
+	}
+
+	buf, err := json.Marshal(struct {
+		Version int
+		GitDir  string
+		Commits string
+		Mailmap string
+		Params  any
+	}{cacheVersion, strings.TrimSpace(gitDir), commits, mailmap, params})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// mailmapHash returns a hash of the mailmap of the repo of o, which the
+// authors are mapped through, or an empty string with --no-mailmap. Like
+// git, it reads the .mailmap file of the work tree, the file named by
+// mailmap.file and the blob named by mailmap.blob, which defaults to
+// HEAD:.mailmap in bare repos. Missing files count as empty.
+func mailmapHash(o options) (string, error) {
+	if o.noMailmap {
+		return "", nil
+	}
+
+	h := sha256.New()
+
+	f, err := openTopLevel(o, ".mailmap")
+	if err != nil {
+		return "", err
+	}
+	if f != nil {
+		defer f.Close()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+	}
+	h.Write([]byte{0})
+
+	// git config fails when the variables aren't set
+	if file, _ := o.git("config", "--path", "mailmap.file"); strings.TrimSpace(file) != "" {
+		path := strings.TrimSpace(file)
+		if !filepath.IsAbs(path) && o.dir != "" {
+			path = filepath.Join(o.dir, path)
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		h.Write(buf)
+	}
+	h.Write([]byte{0})
+
+	blob, _ := o.git("config", "mailmap.blob")
+	blob = strings.TrimSpace(blob)
+	if blob == "" {
+		if bare, _ := o.git("rev-parse", "--is-bare-repository"); strings.TrimSpace(bare) == "true" {
+			blob = "HEAD:.mailmap"
+		}
+	}
+	if blob != "" {
+		// the hash of the blob stands for its contents
+		if id, err := o.git("rev-parse", "--verify", "--quiet", blob); err == nil {
+			h.Write([]byte(strings.TrimSpace(id)))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resolveRev returns the hashes of the commits a revision or range of the
+// repo of o refers to, HEAD when empty, or those of --commits or of the
+// tips of --all-branches.
+func resolveRev(o options) (string, error) {
+	revs := o.shas
+	if len(revs) == 0 {
+		revs = o.tips
+	}
+	if len(revs) == 0 {
+		rev := o.rev
+		if rev == "" {
+			rev = "HEAD"
+		}
+		revs = []string{rev}
+	}
+
+	out, err := o.git(append([]string{"rev-parse"}, revs...)...)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(strings.Fields(out), " "), nil
+}
+
+// path returns the path of the file of the report with the given key.
+func (c reportCache) path(key string) string {
+	return filepath.Join(string(c), key+".json")
+}
+
+// load returns the report with the given key, and whether it was found.
+// Unreadable entries are treated as missing.
+func (c reportCache) load(key string) (report, bool) {
+	buf, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			debugf("error reading cache: %s", err)
+		}
+		return report{}, false
+	}
+
+	var r report
+	if err := json.Unmarshal(buf, &r); err != nil {
+		debugf("error decoding cache entry %s: %s", key, err)
+		return report{}, false
+	}
+
+	return r, true
+}
+
+// store writes the report with the given key. The report is written to a
+// temporary file first, so that concurrent runs never read a partial one.
+func (c reportCache) store(key string, r report) error {
+	buf, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(string(c), 0o755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.path(key), buf)
+}
@@ -0,0 +1,95 @@
+package gitcontrib
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_SummaryCache(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.commit("Author Two", "two.txt", "1\n")
+
+	sf := summaryFlags{options: r.options()}
+	sf.cacheDir = t.TempDir()
+
+	key, err := sf.cacheKey()
+	if err != nil {
+		t.Fatalf("error computing cache key: %s", err)
+	}
+
+	summaries, totals, err := sf.analyse()
+	if err != nil {
+		t.Fatalf("error analysing: %s", err)
+	}
+
+	c, _ := sf.cache()
+	cached, ok := c.load(key)
+	if !ok {
+		t.Fatal("Expected the report to be cached")
+	}
+	if !reflect.DeepEqual(cached.Authors, summaries) || !cached.Totals.Last.Equal(totals.Last) {
+		t.Errorf("Expected cached report to equal the computed one, got: %+v", cached)
+	}
+
+	sf.author = "One"
+	if k, _ := sf.cacheKey(); k == key {
+		t.Error("Expected another key for other flags")
+	}
+	sf.author = ""
+
+	r.commit("Author Two", "three.txt", "1\n")
+	if k, _ := sf.cacheKey(); k == key {
+		t.Error("Expected another key after HEAD moved")
+	}
+
+	summaries, _, err = sf.analyse()
+	if err != nil {
+		t.Fatalf("error analysing: %s", err)
+	}
+	if len(summaries) != 2 || summaries[0].Commits != 2 {
+		t.Errorf("Expected the new commit to be counted, got: %+v", summaries)
+	}
+
+	key, _ = sf.cacheKey()
+	params, _ := sf.baselineParams()
+	r.write(".mailmap", "Author Uno <test@example.com> Author One <test@example.com>\n")
+	if k, _ := sf.cacheKey(); k == key {
+		t.Error("Expected another key after editing the mailmap")
+	}
+	if p, _ := sf.baselineParams(); reflect.DeepEqual(p, params) {
+		t.Error("Expected other baseline parameters after editing the mailmap")
+	}
+}
+
+func Benchmark_SummaryCache(b *testing.B) {
+	r := newTestRepo(b)
+	for i := 0; i < 200; i++ {
+		r.commit(fmt.Sprintf("Author %d", i%7), fmt.Sprintf("f%d.txt", i%13), fmt.Sprintf("%d\n", i))
+	}
+
+	b.Run("cold", func(b *testing.B) {
+		sf := summaryFlags{options: r.options()}
+		sf.noCache = true
+		for i := 0; i < b.N; i++ {
+			if _, _, err := sf.analyse(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("warm", func(b *testing.B) {
+		sf := summaryFlags{options: r.options()}
+		sf.cacheDir = b.TempDir()
+		if _, _, err := sf.analyse(); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := sf.analyse(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Cadence is the rhythm of the commits of an author, being the mean gap
+// between their consecutive commits and the longest one, telling how long
+// they were dormant. Both are zero for an author of a single commit, whose
+// cadence is undefined.
+type Cadence struct {
+	Commits int
+	Mean    time.Duration
+	Max     time.Duration
+}
+
+// CommitCadence returns the cadence of the non-merge commits of each
+// author in the checked out branch.
+func CommitCadence() (map[string]Cadence, error) {
+	return commitCadence(options{})
+}
+
+// commitCadence returns the cadence of the non-merge commits of each
+// author in the revision or range of o.
+func commitCadence(o options) (map[string]Cadence, error) {
+	commits, err := logCommits(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return cadences(commits), nil
+}
+
+// cadences returns the cadence of each author of the commits, from the
+// gaps between the dates of their commits sorted in time, whatever the
+// order of the commits. Merge commits are left out.
+func cadences(commits []commit) map[string]Cadence {
+	dates := make(map[string][]time.Time)
+	for _, c := range commits {
+		if !c.isMerge() {
+			dates[c.Author] = append(dates[c.Author], c.Date)
+		}
+	}
+
+	cadences := make(map[string]Cadence, len(dates))
+	for author, ds := range dates {
+		sort.Slice(ds, func(i, j int) bool { return ds[i].Before(ds[j]) })
+
+		c := Cadence{Commits: len(ds)}
+		for i := 1; i < len(ds); i++ {
+			if gap := ds[i].Sub(ds[i-1]); gap > c.Max {
+				c.Max = gap
+			}
+		}
+		if len(ds) > 1 {
+			c.Mean = ds[len(ds)-1].Sub(ds[0]) / time.Duration(len(ds)-1)
+		}
+		cadences[author] = c
+	}
+
+	return cadences
+}
+
+// authorCadence is the cadence of an author, as listed by the 'cadence'
+// command.
+type authorCadence struct {
+	Author string
+	Cadence
+}
+
+// sortCadences returns the cadences sorted by ascending mean gap, the
+// most frequent committers first, followed by the authors of a single
+// commit, then by name.
+func sortCadences(cadences map[string]Cadence) []authorCadence {
+	sorted := make([]authorCadence, 0, len(cadences))
+	for author, c := range cadences {
+		sorted = append(sorted, authorCadence{author, c})
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if single := a.Commits < 2; single != (b.Commits < 2) {
+			return !single
+		}
+		if a.Mean != b.Mean {
+			return a.Mean < b.Mean
+		}
+		return a.Author < b.Author
+	})
+
+	return sorted
+}
+
+// days returns the duration in days, with one decimal, or blank when the
+// cadence is undefined.
+func (c Cadence) days(d time.Duration) string {
+	if c.Commits < 2 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Hours()/24, 'f', 1, 64)
+}
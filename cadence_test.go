@@ -0,0 +1,53 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Cadences(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	commits := []commit{
+		{Hash: "e", Author: "Alice", Date: start.Add(10 * day), Parents: []string{"d"}},
+		{Hash: "d", Author: "Bob", Date: start.Add(9 * day), Parents: []string{"c", "x"}},
+		{Hash: "c", Author: "Alice", Date: start.Add(2 * day), Parents: []string{"b"}},
+		{Hash: "b", Author: "Carol", Date: start.Add(day), Parents: []string{"a"}},
+		{Hash: "a", Author: "Alice", Date: start},
+	}
+
+	exp := map[string]Cadence{
+		"Alice": {Commits: 3, Mean: 5 * day, Max: 8 * day},
+		"Carol": {Commits: 1},
+	}
+	if got := cadences(commits); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+}
+
+func Test_SortCadences(t *testing.T) {
+	day := 24 * time.Hour
+	sorted := sortCadences(map[string]Cadence{
+		"Alice": {Commits: 3, Mean: 5 * day, Max: 8 * day},
+		"Bob":   {Commits: 2, Mean: day, Max: day},
+		"Carol": {Commits: 1},
+		"Dave":  {Commits: 4, Mean: day, Max: 2 * day},
+	})
+
+	var authors []string
+	for _, c := range sorted {
+		authors = append(authors, c.Author)
+	}
+	exp := []string{"Bob", "Dave", "Alice", "Carol"}
+	if !reflect.DeepEqual(authors, exp) {
+		t.Errorf("Expected %v, got %v", exp, authors)
+	}
+
+	if got := sorted[2].days(sorted[2].Max); got != "8.0" {
+		t.Errorf("Expected a longest gap of 8.0 days, got %q", got)
+	}
+	if got := sorted[3].days(sorted[3].Max); got != "" {
+		t.Errorf("Expected no longest gap for a single commit, got %q", got)
+	}
+}
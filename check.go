@@ -0,0 +1,215 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// checkOperators are the comparison operators of the assertions, with
+// those of two characters first, so that ">=" isn't taken for ">".
+var checkOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// checkMetrics are the metrics the assertions of the 'check' command can
+// compare, computed from the repo-level metrics and the summaries.
+var checkMetrics = map[string]func(m RepoMetrics, summaries []AuthorSummary) float64{
+	"authors":     func(m RepoMetrics, _ []AuthorSummary) float64 { return float64(m.Authors) },
+	"commits":     func(m RepoMetrics, _ []AuthorSummary) float64 { return float64(m.Commits) },
+	"additions":   func(m RepoMetrics, _ []AuthorSummary) float64 { return float64(m.Additions) },
+	"deletions":   func(m RepoMetrics, _ []AuthorSummary) float64 { return float64(m.Deletions) },
+	"granularity": func(m RepoMetrics, _ []AuthorSummary) float64 { return m.Granularity },
+	"gini":        func(m RepoMetrics, _ []AuthorSummary) float64 { return m.Gini },
+	"bus_factor":  func(m RepoMetrics, _ []AuthorSummary) float64 { return float64(m.BusFactor) },
+	"days":        func(m RepoMetrics, _ []AuthorSummary) float64 { return float64(m.Days) },
+	"max_author_share": func(_ RepoMetrics, summaries []AuthorSummary) float64 {
+		var share float64
+		for _, s := range summaries {
+			if s.LineRatio > share {
+				share = s.LineRatio
+			}
+		}
+		return share
+	},
+	"max_commit_share": func(_ RepoMetrics, summaries []AuthorSummary) float64 {
+		var share float64
+		for _, s := range summaries {
+			if s.CommitRatio > share {
+				share = s.CommitRatio
+			}
+		}
+		return share
+	},
+}
+
+// checkMetricNames returns the sorted names of the metrics of the
+// assertions.
+func checkMetricNames() []string {
+	names := make([]string, 0, len(checkMetrics))
+	for k := range checkMetrics {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// assertion is a condition of the 'check' command, comparing a metric to
+// a value, like "bus_factor>=2".
+type assertion struct {
+	Metric   string
+	Operator string
+	Value    float64
+}
+
+// parseAssertion parses an assertion given as METRIC OP VALUE, with or
+// without spaces around the operator.
+func parseAssertion(def string) (assertion, error) {
+	for _, op := range checkOperators {
+		metric, value, ok := strings.Cut(def, op)
+		if !ok {
+			continue
+		}
+
+		metric = strings.TrimSpace(metric)
+		if _, ok := checkMetrics[metric]; !ok {
+			return assertion{}, fmt.Errorf(
+				"unknown metric %q, must be one of: %s",
+				metric, strings.Join(checkMetricNames(), ", "),
+			)
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return assertion{}, fmt.Errorf("invalid value of assertion %q: %w", def, err)
+		}
+
+		return assertion{metric, op, v}, nil
+	}
+
+	return assertion{}, fmt.Errorf(
+		"invalid assertion %q, expected METRIC OP VALUE with OP one of %s",
+		def, strings.Join(checkOperators, " "),
+	)
+}
+
+// String returns the assertion as shown in the TAP output, like
+// "bus factor >= 2".
+func (a assertion) String() string {
+	return fmt.Sprintf("%s %s %s",
+		strings.ReplaceAll(a.Metric, "_", " "), a.Operator,
+		strconv.FormatFloat(a.Value, 'g', -1, 64),
+	)
+}
+
+// holds reports whether the assertion holds for the actual value of its
+// metric.
+func (a assertion) holds(actual float64) bool {
+	switch a.Operator {
+	case ">=":
+		return actual >= a.Value
+	case "<=":
+		return actual <= a.Value
+	case "==":
+		return actual == a.Value
+	case "!=":
+		return actual != a.Value
+	case ">":
+		return actual > a.Value
+	case "<":
+		return actual < a.Value
+	}
+	return false
+}
+
+// assertions is the list of assertions set by the repeatable --assert
+// flag and its shorthands.
+type assertions []assertion
+
+func (as *assertions) String() string {
+	defs := make([]string, len(*as))
+	for i, a := range *as {
+		defs[i] = a.String()
+	}
+	return strings.Join(defs, ", ")
+}
+
+// Set adds the assertion of an --assert flag.
+func (as *assertions) Set(def string) error {
+	a, err := parseAssertion(def)
+	if err != nil {
+		return err
+	}
+	*as = append(*as, a)
+	return nil
+}
+
+// assertionFlag is a shorthand flag adding an assertion comparing a fixed
+// metric with a fixed operator to the value given, like
+// --require-bus-factor N for "bus_factor>=N".
+type assertionFlag struct {
+	list     *assertions
+	metric   string
+	operator string
+}
+
+func (f assertionFlag) String() string {
+	return ""
+}
+
+func (f assertionFlag) Set(v string) error {
+	return f.list.Set(f.metric + f.operator + v)
+}
+
+// checkResult is the outcome of an assertion, along with the actual value
+// of its metric.
+type checkResult struct {
+	assertion
+	Actual float64
+	OK     bool
+}
+
+// evaluate returns the outcome of each of the assertions for the report
+// of the summaries and totals.
+func evaluate(as assertions, summaries []AuthorSummary, totals Totals) []checkResult {
+	m := Aggregate(summaries, totals)
+
+	results := make([]checkResult, len(as))
+	for i, a := range as {
+		actual := checkMetrics[a.Metric](m, summaries)
+		results[i] = checkResult{a, actual, a.holds(actual)}
+	}
+
+	return results
+}
+
+// writeTAP writes the outcomes of the assertions to w as a TAP version 13
+// stream, with the actual value of each failed one in a YAML block. The
+// stream is written at once, returning the error of writing it.
+func writeTAP(w io.Writer, results []checkResult) error {
+	var b strings.Builder
+	fmt.Fprintln(&b, "TAP version 13")
+	fmt.Fprintf(&b, "1..%d\n", len(results))
+
+	for i, r := range results {
+		status := "ok"
+		if !r.OK {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s\n", status, i+1, r.assertion)
+
+		if !r.OK {
+			fmt.Fprintln(&b, "  ---")
+			fmt.Fprintf(&b, "  metric: %s\n", r.Metric)
+			fmt.Fprintf(&b, "  expected: '%s %s'\n", r.Operator, strconv.FormatFloat(r.Value, 'g', -1, 64))
+			fmt.Fprintf(&b, "  actual: %s\n", strconv.FormatFloat(r.Actual, 'g', 6, 64))
+			fmt.Fprintln(&b, "  ...")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
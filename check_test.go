@@ -0,0 +1,114 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_ParseAssertion(t *testing.T) {
+	tests := []struct {
+		def string
+		exp assertion
+		ok  bool
+	}{
+		{"bus_factor>=2", assertion{"bus_factor", ">=", 2}, true},
+		{" max_author_share <= 0.8 ", assertion{"max_author_share", "<=", 0.8}, true},
+		{"authors!=1", assertion{"authors", "!=", 1}, true},
+		{"gini<0.5", assertion{"gini", "<", 0.5}, true},
+		{"bus_factor=2", assertion{}, false},
+		{"unknown>=2", assertion{}, false},
+		{"commits>=many", assertion{}, false},
+	}
+
+	for _, tt := range tests {
+		a, err := parseAssertion(tt.def)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseAssertion(%q) error = %v, expected ok %t", tt.def, err, tt.ok)
+			continue
+		}
+		if a != tt.exp {
+			t.Errorf("parseAssertion(%q) = %+v, expected %+v", tt.def, a, tt.exp)
+		}
+	}
+}
+
+var checkSummaries = []AuthorSummary{
+	{Author: "One", Commits: 3, Additions: 60, LineRatio: 0.6, CommitRatio: 0.6},
+	{Author: "Two", Commits: 2, Additions: 40, LineRatio: 0.4, CommitRatio: 0.4},
+}
+
+var checkTotals = Totals{Commits: 5, Additions: 100}
+
+func Test_CheckPassing(t *testing.T) {
+	var as assertions
+	for _, def := range []string{"bus_factor>=1", "max_author_share<=0.8", "authors==2"} {
+		if err := as.Set(def); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeTAP(buf, evaluate(as, checkSummaries, checkTotals)); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `TAP version 13
+1..3
+ok 1 - bus factor >= 1
+ok 2 - max author share <= 0.8
+ok 3 - authors == 2
+`
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf)
+	}
+}
+
+func Test_CheckFailing(t *testing.T) {
+	var as assertions
+	if err := (assertionFlag{&as, "bus_factor", ">="}).Set("2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := (assertionFlag{&as, "max_author_share", "<="}).Set("0.5"); err != nil {
+		t.Fatal(err)
+	}
+	if err := as.Set("commits>0"); err != nil {
+		t.Fatal(err)
+	}
+
+	results := evaluate(as, checkSummaries, checkTotals)
+	buf := new(bytes.Buffer)
+	if err := writeTAP(buf, results); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `TAP version 13
+1..3
+not ok 1 - bus factor >= 2
+  ---
+  metric: bus_factor
+  expected: '>= 2'
+  actual: 1
+  ...
+not ok 2 - max author share <= 0.5
+  ---
+  metric: max_author_share
+  expected: '<= 0.5'
+  actual: 0.6
+  ...
+ok 3 - commits > 0
+`
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf)
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "check.tap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := writeTAP(f, results); err == nil {
+		t.Errorf("Expected error writing to a closed file")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardTool is a command copying its standard input to the system
+// clipboard, used when found and its display, if any, is set.
+type clipboardTool struct {
+	args    []string
+	display string // the environment variable of the display it needs
+}
+
+// clipboardTools lists the clipboard commands tried on each system, in
+// order. The other systems try those of linux, clip.exe being that of
+// Windows, found on the path under WSL.
+var clipboardTools = map[string][]clipboardTool{
+	"darwin":  {{args: []string{"pbcopy"}}},
+	"windows": {{args: []string{"clip.exe"}}},
+	"linux": {
+		{args: []string{"wl-copy"}, display: "WAYLAND_DISPLAY"},
+		{args: []string{"xclip", "-selection", "clipboard"}, display: "DISPLAY"},
+		{args: []string{"xsel", "--clipboard", "--input"}, display: "DISPLAY"},
+		{args: []string{"clip.exe"}},
+	},
+}
+
+// clipboardCommand returns the command copying to the clipboard, being
+// the one named by $GITCONTRIB_CLIPBOARD, or else the first of
+// clipboardTools found. It is an error if there is none.
+func clipboardCommand() ([]string, error) {
+	if args := strings.Fields(os.Getenv("GITCONTRIB_CLIPBOARD")); len(args) > 0 {
+		return args, nil
+	}
+
+	tools, ok := clipboardTools[runtime.GOOS]
+	if !ok {
+		tools = clipboardTools["linux"]
+	}
+
+	var names []string
+	for _, t := range tools {
+		names = append(names, t.args[0])
+		if t.display != "" && os.Getenv(t.display) == "" {
+			continue
+		}
+		if _, err := exec.LookPath(t.args[0]); err == nil {
+			return t.args, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no clipboard tool found, install one of %s", strings.Join(names, ", "))
+}
+
+// copyToClipboard copies buf to the system clipboard.
+func copyToClipboard(buf []byte) error {
+	args, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(buf)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return fmt.Errorf("error running %s: %w", args[0], err)
+	}
+
+	return nil
+}
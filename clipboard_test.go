@@ -0,0 +1,57 @@
+package gitcontrib
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_ClipboardCopy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clipboard")
+	t.Setenv("GITCONTRIB_CLIPBOARD", "dd status=none of="+path)
+
+	out := filepath.Join(t.TempDir(), "report")
+	of := outputFlags{output: out, clipboard: true}
+	err := of.write(func(w io.Writer) error {
+		_, err := w.Write([]byte("report\n"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{path, out} {
+		buf, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != "report\n" {
+			t.Errorf("got %q in %s, want the report", buf, p)
+		}
+	}
+}
+
+func Test_ClipboardMissing(t *testing.T) {
+	t.Setenv("GITCONTRIB_CLIPBOARD", "")
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := clipboardCommand()
+	if err == nil || !strings.Contains(err.Error(), "no clipboard tool found") {
+		t.Fatalf("got %v, want an error naming the tools", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "report")
+	of := outputFlags{output: out, clipboard: true}
+	err = of.write(func(w io.Writer) error {
+		_, err := w.Write([]byte("report\n"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("got %v, want the report written anyway", err)
+	}
+	if buf, _ := os.ReadFile(out); string(buf) != "report\n" {
+		t.Errorf("got %q, want the report", buf)
+	}
+}
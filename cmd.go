@@ -5,10 +5,22 @@
 package gitcontrib
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"text/tabwriter"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"text/template"
+	"time"
 
 	Z "github.com/rwxrob/bonzai/z"
 	"github.com/rwxrob/help"
@@ -37,7 +49,23 @@ var Cmd = &Z.Cmd{
 
 		// local commands (in this module)
 		AuthorChangesCmd, AuthorCommitsCmd, ContributionSummaryCmd,
-		CsvCmd,
+		RangeCmd, PRCmd, AllBranchesCmd, TagsCmd, FileCmd, DirectoriesCmd, SilosCmd, HotspotsCmd, FileEntropyCmd, TrendsCmd, MonthlyCmd, CumulativeCmd, VelocityCmd, CadenceCmd, IntensityCmd, EffortCmd, CommunityCmd, AuthorsFileCmd, AnomaliesCmd, ReviewersCmd, NetworkCmd, WordsCmd, DeletionsCmd, CommitSizesCmd, CompareCmd, CompareReposCmd, ForksCmd, RemoteCmd, CheckCmd, BadgeCmd, DescribeCmd, DoctorCmd, DumpCmd, SelfCheckCmd, CsvCmd,
+	},
+
+	// debugging commands, left out of the help
+	Hidden: []string{
+		`dump`,
+		`selfcheck`,
+	},
+
+	// Without a command, the help is shown, while an unknown command is a
+	// usage error rather than silently showing nothing.
+	Call: func(x *Z.Cmd, args ...string) error {
+		if len(args) == 0 {
+			help.Cmd.Caller = x
+			return help.Cmd.Call(help.Cmd)
+		}
+		return failUsage(x, unknownCommand(x, args[0]))
 	},
 
 	// Add custom BonzaiMark template extensions (or overwrite existing ones).
@@ -47,37 +75,4325 @@ var Cmd = &Z.Cmd{
 		"pwd":   func() string { return Z.Out("pwd") },
 	},
 
-	// WARNING: The Description will be dedented using the exact
-	// runes from the beginning of the first line to the first
-	// non-whitespace character. This means that mixing spaces and tabs in
-	// your indentation will created unwanted truncation. Make sure each
-	// line has the same indentation exactly.
+	// WARNING: The Description will be dedented using the exact
+	// runes from the beginning of the first line to the first
+	// non-whitespace character. This means that mixing spaces and tabs in
+	// your indentation will created unwanted truncation. Make sure each
+	// line has the same indentation exactly.
+
+	Description: `
+		The {{aka}} command is a command tree for analysing author
+		contributions in git repositories. The most extensive overview is the
+		'summary' subcommand, that has the alias 's'. This shows aggregated
+		metrics like line change ratio and commit granularity per author.
+		Larger numbers means higher contribution for all categories.
+
+		All commands accept the --verbose flag, which logs each git command
+		that is run and how long it took to standard error, along with a
+		spinner while it runs when attached to a terminal. Warnings git
+		writes to standard error, like about line endings, are logged as
+		well, and never mixed into what is analysed. Standard output only
+		ever holds the report itself, so it can still be piped.
+
+		To see exactly which git commands produce the numbers, --explain logs
+		each git command line to standard error before running it, in a form
+		that can be pasted into a shell. With --dry-run, the commands walking
+		the history are logged without being run, and no report is written.
+		The quick commands looking up what to walk, like finding the checked
+		out branch, still run, as the command lines depend on them.
+
+		For audits, --save-raw DIR archives the exact git output a report
+		was computed from. The standard output of each git command is saved
+		as is to a file of the directory named by its number and the git
+		command, like 001-log.txt, and the file commands.txt lists each of
+		them along with the command line giving it, so that the inputs of
+		the parsers are kept alongside the report. The directory is created
+		when missing, and is best empty, as the numbering starts anew.
+
+		The messages written to standard error have levels, the debug
+		messages of --verbose, info messages, warnings, like for skipped
+		commits, and errors. The --log-level flag only writes those of the
+		given level and above, one of debug, info, warn or error, defaulting
+		to info. The command lines of --explain and --dry-run are not
+		messages, and are always written. Programs embedding the
+		commands can instead set the package-level Logger to a log/slog
+		logger of their own, receiving all messages.
+
+		The commands analyse the repo in the current directory, or the one
+		in the directory given with --repo DIR.
+
+		A repo without any commits yet, as right after 'git init', has
+		nothing to report, so the commands only write "repository has no
+		commits" to standard error and exit with status code 0. With the
+		--strict flag of the 'summary' family of reports, they fail instead.
+		The commands scanning several repos, like 'forks', skip those
+		without commits.
+
+		The commands analyse the checked out branch, unless another branch or
+		ref is given with --branch REF. On a detached HEAD, as in many CI
+		checkouts, the history of the commit HEAD points to is analysed.
+		With --default-branch, the default branch of the repo is analysed
+		instead, being the branch origin/HEAD points to, as set when
+		cloning, so that CI reports the mainline rather than whatever
+		branch is checked out. Without origin/HEAD, the checked out branch
+		is used, and it is an error if there is none.
+
+		With --all-branches, the union of the histories of all local and
+		remote-tracking branches is analysed instead, like 'git log
+		--branches --remotes', counting the work not merged yet too. A
+		commit on several branches is counted once. Unlike the 'allbranches'
+		report, with a row per author and branch, this gives one report
+		across all branches. The reports of the current state of the files,
+		like --surviving, still read those of HEAD.
+
+		The analysed branch or ref is resolved to its commit once, when the
+		command starts, and every git invocation of the analysis reads the
+		history of that commit, so that the numbers all come from the same
+		history even when the branch moves meanwhile, like when pulling
+		while a report is computed. The ends of a range are resolved alike.
+		The resolved commit is written by --verbose.
+
+		Release managers wanting the commits since the last release can give
+		--since-tag, analysing only the commits after the most recent tag
+		reachable from the analysed branch, like 'range TAG HEAD'. Another
+		tag is given as --since-tag=TAG, with the '=' being required. It is
+		an error if the repo has no tags.
+
+		Audits of a hand-picked set of commits, like those flagged by a
+		security review, can give them with --commits SHA,..., or list them
+		in a file given with --commits-file FILE, one per line, as written
+		by 'git log --format=%H'. Only these commits are analysed, without
+		walking their history, like 'git log --no-walk'. Blank lines and
+		lines starting with # are skipped. Every commit is checked to exist
+		first, and it is an error, listing them, if some don't. The flags
+		can't be combined with --branch, --default-branch or --since-tag.
+
+		The history can be limited to the commits touching some paths, like
+		a directory of a monorepo, with the repeatable --path flag, counting
+		only the line changes within them. The history of a file from
+		before it was moved is left out, unless --follow is given, following
+		the file across renames like 'git log --follow'. As git can only
+		follow a single file, --follow is ignored with a warning when
+		several paths are given.
+
+		The analysed history can be limited in time with --since DATE and
+		--until DATE, taking any date git understands, like "2023-01-01" or
+		"6 months ago". Authors are reported as mapped by the .mailmap file
+		of the repo, which merges the identities of the same person. With
+		--no-mailmap, they are reported as they committed instead.
+
+		With --as-of DATE, the repo is analysed as it was at that date,
+		reproducing a report of the past. Unlike --until, which only limits
+		the history, it analyses the last commit made before the date on
+		the analysed branch, found with 'git rev-list -1 --before=DATE', as
+		if it were checked out, so that the reports of the files of the
+		code, like --surviving and 'silos', look at the files of that
+		commit too. It is an error if no commit was made before the date,
+		and it can't be combined with --all-branches, --commits or ranges.
+
+		Commits are dated by their author date, when the change was first
+		made. Rebasing or cherry-picking a commit keeps its author date but
+		sets its committer date to when it was rewritten, so the two differ
+		in rewritten history. With --date-type committer, the time-based
+		metrics, like the first and last commits, --decay and the svg
+		activity, use the committer date instead, telling when changes
+		landed rather than when they were written. Note that git always
+		limits the history by committer date with --since and --until.
+
+		Dates are taken in the time zone of each commit, as recorded by its
+		author, so commits of people in different regions fall on their own
+		local days and months, rather than those of UTC, also when counting
+		commits per month. The --tz ZONE flag converts all dates to one
+		time zone before they are grouped by day or month, like in the
+		timeline, trends and velocity reports, making them comparable. The
+		zone is UTC, Local for that of the computer, or an IANA name like
+		America/New_York, and it is an error to give an unknown one.
+
+		A single commit importing a vendored dependency or squashing a whole
+		branch can add so many lines that it swamps the day-to-day work of
+		everyone else. With --max-commit-lines N, the line changes of the
+		commits changing more than N lines are skipped, while the commits
+		still count as commits, unless --exclude-commit is given, skipping
+		them altogether. The number of skipped commits is reported on
+		standard error.
+
+		As a lighter alternative, --cap-lines-per-file N counts at most N
+		added lines of each file of a commit, so that a file imported in
+		one go weighs no more than N lines, while the other files of the
+		commit, and its deletions, count in full. The number of added lines
+		left out is reported on standard error. The additions are capped
+		before --max-commit-lines sizes the commits.
+
+		The line changes can be limited to some kinds of file changes with
+		--diff-filter LETTERS, passed on to git log. The letters are A for
+		added files, M for modified, D for deleted, R for renamed, C for
+		copied, T for changed in type, U for unmerged, X for unknown and B
+		for broken pairings, and lowercase letters leave those kinds out
+		instead. For instance, --diff-filter A only counts the lines of
+		newly added files, telling who creates new modules rather than
+		edits existing ones, and --diff-filter d leaves out the lines of
+		deleted files. The commits still count as commits, and the reports
+		built from the commits, like the trends, are not limited.
+
+		With --code-only, only the commits touching at least one code file
+		count, both as commits and for their line changes, leaving out
+		those only changing documentation or configuration. A counted
+		commit keeps all of its line changes. The code files are known by
+		their extension, one of c, cc, cpp, cs, dart, ex, exs, go, h, hpp,
+		java, js, jsx, kt, lua, m, php, pl, py, rb, rs, scala, sh, sql,
+		swift, ts, tsx, vue or zig, unless others are given with the
+		repeatable --code-ext EXT,... flag, like --code-ext go,proto.
+
+		The file, reviewers, community, words, network and authorsfile
+		commands don't read the files of the commits, and refuse
+		--max-commit-lines, --exclude-commit and --code-only rather than
+		ignore them.
+
+		On repos merging pull requests by squashing them, the commit of a
+		pull request is often authored by the maintainer merging it, while
+		the people who wrote it are only credited in its message. Commits
+		whose subject ends in the number of a pull request, like "Add
+		feature (#123)", are taken for squash merges, and --squash-author
+		chooses who they are credited to. With author, the default, they
+		are credited to their author, like any commit. With committer, they
+		are credited to whoever committed them, and with body, to the people
+		of their Co-authored-by trailers, mapped through the .mailmap file
+		like the authors. A squash merge then counts as a commit of each of
+		them, with its line changes split evenly between them, so the line
+		totals are unchanged. A squash merge without Co-authored-by trailers
+		keeps its author.
+
+		A change that is reverted counts twice, as the line changes of its
+		author and again as those of the revert, though they cancel out.
+		With --exclude-reverts, the line changes of the revert commits are
+		skipped, while they still count as commits. A commit is taken for a
+		revert when its subject starts with 'Revert "', or its message has a
+		"This reverts commit SHA" line, as written by git revert. With
+		--exclude-reverted, the line changes of the commits they revert are
+		skipped too, when in the analysed history, so that the pair leaves
+		no trace. The numbers of reverts and pairs skipped are reported on
+		standard error.
+
+		Generated and vendored files are often marked as such in the
+		.gitattributes file of the repo, for GitHub to leave them out of its
+		statistics. With --respect-gitattributes, the line changes of the
+		files marked linguist-generated or linguist-vendored by the
+		.gitattributes file in the top-level directory of the last commit
+		analysed are skipped too, while the commits changing them still
+		count as commits. Uncommitted changes of the file are not read.
+		The patterns are matched like git does, a pattern without a slash
+		matching the file name in any directory, like *.pb.go, and one with
+		a slash matching from the top-level directory, like vendor/** for
+		everything under vendor. The last line naming an attribute for a
+		file wins, so that -linguist-generated or linguist-generated=false
+		unmarks some of the files marked by an earlier line.
+
+		The first commit of a repo is often the import of an existing code
+		base, crediting whoever imported it with every line of it. The
+		--exclude-first-commit flag leaves out the root commits, the commits
+		without parents, from all analyses, as commits and for their line
+		changes. A history merging unrelated histories has several, and all
+		of them are left out. The number of commits and lines left out is
+		reported on standard error. The reports of the current state of the
+		files, like --surviving, still credit the lines of the root commits.
+
+		Some imported histories have commits without an author name, or
+		with one of only whitespace. Their commits and line changes are
+		counted together under the author "(unknown)", or the label given
+		with --unknown-author LABEL, rather than being dropped or credited
+		to the author of the commit before. The 'anomalies' command lists
+		these commits.
+
+		The human-readable tables left-align the author names and right-align
+		the numbers, with two spaces between the columns. The --minwidth and
+		--padding flags, accepted by all commands, set the minimum width of
+		the columns, including the padding, and the number of spaces between
+		them.
+
+		Very long author names, like email addresses used as names, widen
+		the tables past the screen. With --max-name-width N, the names and
+		other leading text cells of the tables are cut to N characters,
+		ending in an ellipsis. Only the tables are affected, so the other
+		formats and the CSV output still hold the full names.
+
+		The rows of every report are in a fixed order, so that the same
+		history always gives the same output, like for reports committed to
+		version control or compared between runs. The rows are sorted by
+		their metric, as told for each command or chosen with --sort, and
+		the rows tied on it by author name. As the authors of a report are
+		told apart by their name, after mapping it through the .mailmap
+		file, the name settles every tie, making the order a total one.
+
+		The commands writing reports accept --output FILE, writing the report
+		to the given file instead of standard output. An existing file is
+		truncated. The file is only created once the analysis is done, so a
+		failing analysis leaves an existing file untouched.
+
+		When standard output is a terminal, reports are shown through the
+		pager named by the PAGER environment variable, 'less -S' by default,
+		so that long and wide tables can be scrolled. Unless the LESS
+		environment variable is set, less quits right away when the report
+		fits on one screen. Paging is turned off with --pager=false, and is
+		never done when the output is piped or written with --output.
+
+		With --clipboard, the report is also copied to the system clipboard,
+		for pasting into a chat or document, while being shown or written as
+		usual. The clipboard is written with pbcopy on macOS, clip.exe on
+		Windows, and wl-copy, xclip, xsel or the clip.exe of WSL elsewhere,
+		the first one found being used. The GITCONTRIB_CLIPBOARD environment
+		variable names another command, reading the report on its standard
+		input. Without any, a warning is logged and the report is only
+		shown.
+
+		Unknown commands and flags, as well as missing or extra arguments,
+		are rejected with the usage of the command, exiting with status 2,
+		while other errors exit with status 1. A mistyped command suggests
+		the commands with similar names.
+		`,
+}
+
+var AuthorCommitsCmd = &Z.Cmd{
+	Name:    `authorcommits`,
+	Summary: `lists the number of commits per author in current dir`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--sort KEY] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Aliases: []string{"ac"},
+	Description: `
+		The {{aka}} subcommand lists the number of non-merge commits of each
+		author, the most first. The --sort flag orders the rows by another
+		key instead, as for the 'csv' commands.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		var af anonymizeFlags
+		sortBy := "commits"
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.StringVar(&sortBy, "sort", sortBy, "sort the rows by author, commits, additions, deletions or lines")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commitMap, err := authorCommits(o)
+		if err != nil {
+			return fmt.Errorf("error extracting commit counts: %w", err)
+		}
+
+		if err := ff.filterMaps(o, commitMap, nil); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		summaries := commitSummaries(commitMap)
+		af.pseudonyms().rename(summaries)
+		if err := sortSummaries(summaries, sortBy); err != nil {
+			return err
+		}
+
+		t := newTable(1, "Author", "Commits")
+		for _, s := range summaries {
+			t.row(s.Author, strconv.Itoa(s.Commits))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var AuthorChangesCmd = &Z.Cmd{
+	Name:    `authorchanges`,
+	Summary: `lists the line changes per author in current branch`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--sort KEY] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Aliases: []string{"ach"},
+	Description: `
+		The {{aka}} subcommand lists the added and deleted lines of each
+		author, the most changed lines first. The --sort flag orders the rows
+		by another key instead, as for the 'csv' commands.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		var af anonymizeFlags
+		sortBy := "lines"
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.StringVar(&sortBy, "sort", sortBy, "sort the rows by author, commits, additions, deletions or lines")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		lineChangesMap, err := mapLineChanges(o)
+		if err != nil {
+			return fmt.Errorf("error extracting line changes: %w", err)
+		}
+
+		if err := ff.filterMaps(o, nil, lineChangesMap); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		summaries := changeSummaries(lineChangesMap)
+		af.pseudonyms().rename(summaries)
+		if err := sortSummaries(summaries, sortBy); err != nil {
+			return err
+		}
+
+		t := newTable(1, "Author", "Additions", "Deletions")
+		for _, s := range summaries {
+			t.row(s.Author, strconv.Itoa(s.Additions), strconv.Itoa(s.Deletions))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var ContributionSummaryCmd = &Z.Cmd{
+	Name:    `summary`,
+	Summary: `lists commits, line changes and aggregated metrics`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--strict] [--shortstat [--shortstat-threshold SHARE]] [--fail-on-empty] [--max-authors N] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--recompute-ratios] [--decay [--half-life DAYS]] [--files [--follow-renames]] [--breadth [--breadth-weights NAME=WEIGHT,...]] [--count-merges-separately] [--surviving] [--complexity] [--ratios-extra] [--self-ratios] [--score [--score-mode relative|share]] [--rank [--rank-by KEY]] [--columns LIST] [--aggregate-only] [--format FORMAT] [--delimiter SEP] [--timestamp TIME] [--box [--ascii]] [--thousands] [--no-footer] [--template TEMPLATE|@FILE|--preset NAME|--list-presets] [--output FILE] [--clipboard] [--display name|email|both] [--include-working-tree] [--anonymize [--key KEY]] [--submodules] [--no-cache] [--cache-dir DIR] [--baseline FILE] [--input-json FILE|-] [--compare-to FILE] [--watch [--interval DURATION]]`,
+	Aliases: []string{"s"},
+	Description: `
+		The {{aka}} subcommand lists the commits, line changes, line change
+		ratio, commit ratio and commit granularity of each author, followed by
+		the overall commit granularity of the repo, the Gini coefficient of
+		the line changes of the authors and the dates of the first and last
+		commits. The Gini coefficient tells how unequally the line changes
+		are distributed among the authors, from 0 when all authors changed
+		equally many lines towards 1 when a single author changed them all.
+
+		The --first-parent flag only follows the first parent of merge
+		commits, analysing the mainline history of the branch rather than
+		every commit reachable from it. As merge commits are never counted as
+		commits, the commits made on merged branches then drop out of the
+		commit counts. Their line changes are instead credited to the author
+		of the merge commit, as git compares each merge against its first
+		parent. This is useful in repos where work lands through merges and
+		the mainline is what matters.
+
+		The --author and --min-commits flags filter which authors are listed.
+		The --author flag only lists the authors whose name matches the given
+		regular expression, for instance the members of a team. The
+		--min-commits flag omits the authors with fewer non-merge commits than
+		the given number, like drive-by contributors.
+
+		Authors like CI bots can be left out of all reports by listing them
+		in a .gitcontribignore file in the top-level directory of the repo,
+		one glob pattern per line, or with the repeatable --ignore-author
+		flag. Blank lines and lines starting with '#' are skipped. A pattern
+		matches the whole name or any email of an author, ignoring case, with
+		'*' matching any number of characters and '?' any single one. Other
+		characters match themselves, so '*[bot]' leaves out all authors
+		ending in "[bot]". Ignored authors are also left out of the totals
+		the ratios are relative to, unless --ignored-in-totals is given.
+
+		Authors can also be selected by the domains of their emails, like
+		to tell internal from external contributions. The repeatable
+		--include-domain flag only reports the authors having committed with
+		an email in one of the given domains or their subdomains, while the
+		repeatable --exclude-domain flag leaves out those having committed
+		with an email in any of them. Authors without any valid email are
+		reported regardless of the domain flags, unless --drop-no-email is
+		given. Authors left out by domain are treated like ignored authors,
+		including for the totals.
+
+		The commit counts leave out merge commits, while the line changes
+		are read from all commits, so an author of only merge commits is
+		reported without any commits, and an author of only empty commits
+		without any line changes. Their granularity is 0. With --strict, the
+		report fails instead, listing the authors found by only one of them.
+		Line changes that git output parses into can't be right either, being
+		negative, or numstat lines that can't be parsed at all, as when a
+		new version of git changed its format. They fail the report with
+		--strict, and are only warned about otherwise.
+
+		As a further check, --shortstat reads the history a second time
+		with 'git log --shortstat', and compares its totals of inserted and
+		deleted lines with those of 'git log --numstat', which the line
+		changes are parsed from. They differ on a parser bug, or when a
+		version of git writes either format differently, and are then
+		warned about, or fail the report with --strict, like other bad line
+		changes. The totals must agree exactly, unless a share of the line
+		changes they may differ by is given with --shortstat-threshold,
+		like 0.01 for 1%. The totals of the report are checked against
+		those of shortstat too, unless flags make them differ, like
+		--max-commit-lines, --ignore-author or --recompute-ratios. The check
+		reads the whole history selected, and the report is not cached
+		meanwhile.
+
+		People committing under several names or emails can be merged into
+		one row with --identity-map FILE, independently of the .mailmap file
+		of the repo, so that the same grouping applies across repos. The
+		file is a YAML map from the canonical name of each person to the
+		list of glob patterns of the names and emails they committed with:
+
+		    Jane Doe:
+		      - jane@example.com
+		      - "*@jane.dev"
+		      - jdoe
+
+		The patterns match like those of --ignore-author. The identities are
+		merged before --normalize-names and --team apply.
+
+		Authors committing under variants of their name differing only in
+		case or whitespace, like "john smith" and "John  Smith", are listed
+		as different authors. With --normalize-names, the variants are
+		merged into one row, named by the variant with the most commits.
+		This needs no .mailmap file, but catches fewer variants. The --team
+		and --author flags apply to the merged rows.
+
+		Several authors can be reported as one with --team NAME=AUTHOR,...,
+		summing their commits and line changes into a single row labelled
+		NAME, for instance the members of a team. The flag can be repeated to
+		define several teams, and an author can only be in one team. Authors
+		not in any team are listed as usual, unless --teams-only is given.
+		The --author and --min-commits filters apply to the team rows rather
+		than to their members.
+
+		Rather than leaving bots out, --collapse-bots sums all of them into a
+		single row labelled "bots", so that their share stays visible
+		without a row for each. The bots are the authors whose name matches
+		the regular expression given with --bot-pattern, by default
+		'(?i)\[bot\]$|dependabot|github-actions|renovate', matching the
+		GitHub apps ending in "[bot]", Dependabot, GitHub Actions and
+		Renovate. A bot listed in a --team is reported with the team.
+
+		By default the ratios and the overall granularity are relative to all
+		authors in the repo, also when filtering, so the ratios of the listed
+		authors no longer sum to 1. With --recompute-ratios they are instead
+		recomputed after all filtering, making the ratios and overall
+		granularity relative to the listed authors only. The ratios then sum
+		to 1 across the listed rows. Without any filters, the flag makes no
+		difference.
+
+		The --decay flag adds a Weighted column of recency-weighted line
+		changes, telling who changed the code most recently rather than who
+		changed it most over all time. The line changes of each commit are
+		weighted by the age of the commit, the weight halving for every
+		--half-life days, 180 by default. A commit made today counts in full,
+		one made a half-life ago counts half, and one made two half-lives ago
+		a quarter.
+
+		The --files flag adds a Files column with the number of distinct
+		files each author touched, telling generalists touching much of the
+		code base from specialists keeping to a few files. A file changed in
+		several commits counts once. By default the paths a file had before
+		being renamed count as different files. With --follow-renames, they
+		count as one.
+
+		The --breadth flag adds a Files/commit column with the mean number
+		of files changed by the commits of each author, and a Breadth column
+		with a score from 0 to 100 of how much of the code each author
+		reaches, for telling who has the broadest impact. The score is the
+		weighted mean of the shares of the author in all commits, in all
+		distinct files touched and in all changed lines, times 100. The
+		weights are commits=1,files=2,churn=1 by default, the files touched
+		counting as much as the other two together, and are changed with
+		--breadth-weights, like --breadth-weights files=1,churn=0 for the
+		weights of the commits and files only. Merge commits are left out,
+		and the shares are relative to the whole analysed history, even with
+		--recompute-ratios.
+
+		The commit counts leave out merge commits, though merging is real
+		integration work, often done by the maintainers. The
+		--count-merges-separately flag adds a Merges column with the merge
+		commits of each author, telling integrating from authoring.
+
+		The --surviving flag adds a Surviving column with the lines of each
+		author still in the code at the end of the history, as attributed by
+		git blame, and a Footprint column with their share of all lines of
+		the code. As the additions also count the lines deleted since, the
+		difference between the Additions and Surviving columns shows how
+		much of the work of an author was rewritten, while the footprint
+		answers who owns the current code. Binary files are skipped. Beware
+		that git blame is run for every file of the code, which takes far
+		longer than the rest of the report on large repos, though the files
+		are blamed in parallel. The --since and --until flags don't limit
+		the blamed lines, and git blame always maps the authors through
+		.mailmap, also with --no-mailmap.
+
+		Line counts treat a 100-line mechanical rename like 100 lines of
+		dense logic, while many small scattered edits often take more
+		thought than one big block. As a rough proxy of that, the
+		--complexity flag adds a Hunks column with the number of distinct
+		blocks of changed lines of the commits of each author, and a
+		Complexity column with their line changes weighted by how scattered
+		they are, the lines of each commit times one plus the base-2
+		logarithm of its hunks. A commit changing 100 lines in one block
+		weighs 100, and one changing them in 8 hunks weighs 400. The hunks
+		are read from the patches of all commits, a slower walk of the
+		history than the rest of the report, whose lines are counted
+		regardless of --max-commit-lines, --code-only and the like. Merge
+		commits and binary files are left out.
+
+		The --ratios-extra flag adds a Del/add ratio column with the number
+		of deleted lines per added line of each author. High ratios tell the
+		authors mostly cleaning up or refactoring code, work the other
+		metrics credit poorly. Authors only deleting lines have a ratio of
+		∞, and those without any line changes a blank one. The column is
+		shown by the table and org formats, while templates can use the
+		.DeletionsPerAddition method with any format.
+
+		The --self-ratios flag adds the Add % and Del % columns, with the
+		shares of added and deleted lines in the own line changes of each
+		author, like 80% and 20%. Unlike the line ratio, they don't depend
+		on the other authors, telling apart those mostly building new code
+		from those mostly maintaining it. Authors without any line changes
+		have blank shares. Templates can use the .AdditionShare and
+		.DeletionShare methods instead.
+
+		Line ratios like 0.037 are hard to compare at a glance, so --score
+		adds a Score column scaling the line ratios to scores from 0 to 100,
+		with one decimal, for audiences preferring them. The --score-mode
+		flag chooses the scale. With relative, the default, the author with
+		the most line changes scores 100 and the others in proportion, so
+		that half their line changes score 50. With share, the scores are
+		the shares of the reported authors in their line changes, summing to
+		100 even when some authors are left out, like by --author. Only the
+		rendering changes, the ratios and totals being computed as usual.
+
+		For leaderboards, --rank adds a leading Rank column numbering the
+		authors from 1 by the metric of --rank-by, one of commits, the
+		default, additions, deletions, lines or author, and sorts the rows
+		by it. Authors tied on the metric share a rank, and the next one is
+		ranked as if they weren't, like 1, 2, 2, 4.
+
+		The --format flag selects the output format, one of:
+
+		    table  aligned human-readable table (default)
+		    html   standalone HTML page with a sortable table, suited for
+		           sharing with others
+		    influx points in the InfluxDB line protocol, one per author
+		           like gitcontrib,author=...,repo=... commits=42i,...
+		           followed by a gitcontrib_repo point of the totals,
+		           for storing snapshots in a time-series database
+		    json   single JSON object with an "authors" array and a
+		           "totals" object, using the snake_case names of the
+		           template fields
+		    pretty-json
+		           json indented by two spaces, with the authors sorted
+		           by name and the fields in a fixed order, so that the
+		           reports of two runs are byte-identical unless their
+		           numbers changed, for committing reports to a repo
+		           and reviewing their diffs
+		    ndjson one JSON object per author and line, streamed as the
+		           authors are written, followed by a line with the
+		           totals, which is marked by a "_total" field set to true
+		    yaml   YAML document with the same structure as for json
+		    svg    standalone SVG image with a sparkline of the commits
+		           per month of each author, on a shared scale
+		    prometheus
+		           gauges in the Prometheus text exposition format, like
+		           gitcontrib_author_commits{author="...",repo="..."},
+		           for scraping through a textfile collector
+		    org    Org-mode table, aligned like Org aligns it, for pasting
+		           into Org documents
+		    plain  unaligned rows of cells separated by --delimiter, a
+		           single space by default, headed by the column names,
+		           for piping into awk or cut
+		    readme ranked Markdown list of the authors in bold with their
+		           commits and share of them, like "1. Author — 120
+		           commits (45%)", for the contributors section of a README
+		    xlsx   Excel workbook with the table on one sheet and the
+		           overall metrics on another, for sharing with those
+		           preferring spreadsheets; needs --output FILE
+		    gnuplot
+		           gnuplot data file of the author, commits, additions and
+		           deletions of each author, for charts of your own
+		    markdown-details
+		           GitHub Markdown with a collapsible <details> block per
+		           author, headed by their name and commits and holding a
+		           table of their metrics, for long reports in PR
+		           descriptions and docs
+
+		The influx points are timestamped with the current time, or the time
+		given with --timestamp, either as an RFC 3339 date like
+		2024-01-31T00:00:00Z or as Unix seconds, for backfilling snapshots
+		of past dates. Spaces, commas and equals signs in the author names
+		are escaped as the line protocol requires.
+
+		The repo label of the influx and prometheus formats is the name of
+		the directory of the repo, unless another is given with --repo-name
+		NAME, like a canonical project identifier for clones in directories
+		of meaningless or clashing names. In a worktree added with git
+		worktree add, it is the name of the repo it was added to, not of the
+		directory of the worktree.
+
+		The plain format neither pads nor quotes its cells, unlike the
+		table and the csv command, so that each row splits cleanly into
+		its fields. As author names may contain spaces, a --delimiter found
+		in no name is safer for splitting by field, like a tab:
+
+		    gitcontrib summary --format plain --delimiter "$(printf '\t')" |
+		        cut -f1,3
+
+		The gnuplot format always has the same four columns, in this order,
+		so that plots can refer to them by number with 'using': 1 the
+		author, 2 the commits, 3 the additions and 4 the deletions. They
+		are separated by spaces and headed by a comment line naming them,
+		"# author commits additions deletions". As gnuplot splits the
+		fields at spaces and starts comments at '#', those in the author
+		names are replaced by underscores. For a bar chart of the commits:
+
+		    gitcontrib summary --format gnuplot --output authors.dat
+		    gnuplot -p -e "set style fill solid; plot 'authors.dat' \
+		        using 2:xtic(1) with boxes title 'commits'"
+
+		The --box flag draws borders around the table and between its
+		columns with box-drawing characters, for polished terminal reports.
+		Terminals without them can use --ascii, drawing the borders with
+		'+', '-' and '|' instead.
+
+		The counts of large repos, like 3897123 added lines, are easier to
+		read with --thousands, grouping the digits of the integer columns of
+		the table by thousands, like 3,897,123. Only the table is affected,
+		the other formats keeping plain integers for scripts.
+
+		The --columns LIST flag selects which columns are shown and in what
+		order, given as a comma-separated list of the snake_case names of the
+		json fields, like --columns author,commits,net. Besides those, net
+		gives the added minus the deleted lines, deletions_per_addition the
+		deleted lines per added line, additions_ratio and deletions_ratio the
+		shares of all added and of all deleted lines in the repo, and
+		addition_share and deletion_share the shares of --self-ratios. The
+		columns added by flags, like weighted and files_touched, still need
+		their flag. The selection applies to the table, org, plain, json,
+		pretty-json, ndjson, yaml and markdown-details formats, and it is an
+		error to name an unknown column or to use it with other formats or
+		--template.
+
+		The --aggregate-only flag only writes the repo-level metrics, being
+		the number of authors, the commits, additions and deletions, the
+		overall granularity, the Gini coefficient, the bus factor and the
+		dates of the first and last commits, without any rows of authors.
+		The bus factor is the smallest number of authors who together made
+		at least half of the line changes. With the json, pretty-json and
+		ndjson formats, the metrics are written as a single JSON object, and
+		with yaml as a YAML document, suited for monitoring the repo over
+		time:
+
+		    {"authors":12,"commits":340,...,"bus_factor":2,...}
+
+		Only the table, json, pretty-json, ndjson and yaml formats are
+		supported, and the flag can't be combined with --template or
+		--columns.
+
+		The --no-footer flag, or its alias --quiet, leaves out the overall
+		metrics following the table, so that only the table is written. This
+		is useful when piping the table to tools like 'column -t'.
+
+		The --template flag replaces the table with the output of a Go
+		text/template, executed once per author. The template is given
+		directly or read from a file when prefixed with '@'. A newline is
+		written after each author. The following fields are available:
+
+		    .Author         author name
+		    .Rank           rank of the author with --rank, or else its
+		                    position in the report, from 1
+		    .Commits        non-merge commits
+		    .Additions      added lines
+		    .Deletions      deleted lines
+		    .LineRatio      share of all line changes in the repo
+		    .CommitRatio    share of all commits in the repo
+		    .Granularity    commits per changed line
+		    .AdditionsRatio share of all added lines in the repo
+		    .DeletionsRatio share of all deleted lines in the repo
+		    .Weighted       recency-weighted line changes, with --decay
+		    .FilesTouched   distinct files touched, with --files
+		    .FilesPerCommit mean files changed per commit, with --breadth
+		    .Breadth        breadth score from 0 to 100, with --breadth
+		    .Merges         merge commits, with --count-merges-separately
+		    .Surviving      lines surviving today, with --surviving
+		    .Footprint      share of all surviving lines, with --surviving
+		    .Hunks          blocks of changed lines, with --complexity
+		    .Complexity     line changes weighted by hunks, with --complexity
+		    .DeletionsPerAddition
+		                    deleted lines per added line
+		    .AdditionShare  share of added lines in the own line changes
+		    .DeletionShare  share of deleted lines in the own line changes
+		    .Score          line ratio as a score from 0 to 100, with --score
+
+		The repo-wide metrics are available through the .Totals field, which
+		has the fields .Commits, .Additions, .Deletions, .Granularity, .Gini,
+		.First and .Last, the latter two being the dates of the first and last
+		commits. The number of days between them is given by .Days.
+		A --template takes precedence over --format.
+
+		Example:
+
+		    gitcontrib summary --template '{{"{{"}}.Author{{"}}"}}: {{"{{"}}.Commits{{"}}"}}'
+
+		The --preset flag uses a built-in template instead, one of
+		leaderboard, ranking the authors with their commits and line
+		changes, compact, with one short line per author, or detailed, with
+		a block of the main metrics per author. The --list-presets flag
+		lists them along with their templates, as a starting point for a
+		--template of one's own. A --preset can't be combined with a
+		--template.
+
+		The authors are grouped and labelled by name. With --display, they
+		are still grouped by name, but labelled by email with email, or as
+		"Name <email>" with both, in all formats. An author committing with
+		several emails is shown with the one of most commits, ties going to
+		the most recently used. Authors without an email, like teams, keep
+		their name. The --author filter still matches the names.
+
+		To see a change in progress reflected in the report, the
+		--include-working-tree flag adds the uncommitted line changes of the
+		working tree and the index, as given by 'git diff --numstat' and
+		'git diff --cached --numstat', to those of the git user, set by
+		user.name. Their row is marked with a *, and their uncommitted line
+		changes are written to standard error. New files only count once
+		added to the index. Without uncommitted changes, the flag changes
+		nothing. The report isn't cached, and the flag can't be combined
+		with --submodules or --baseline.
+
+		To share the shape of the contributions without exposing who made
+		them, --anonymize replaces the author names with pseudonyms in all
+		formats. By default the authors are numbered "Author 1" to
+		"Author N", by descending commits and then line changes, so the
+		numbers only hold within a report. With --key KEY, each author is
+		instead named by a pseudonym like "author-1a2b3c4d", computed from
+		the name and the secret key, so that the same author gets the same
+		pseudonym in every report made with the same key. Keep the key
+		secret, as the names can be guessed with it.
+
+		Contributions to submodules are not part of the history of the repo
+		using them. With --submodules, the checked out commits of all
+		initialized submodules, including nested ones, are analysed along
+		with the repo, each from the commit checked out. The rows of the
+		authors of a submodule are labelled by prefixing the path of the
+		submodule, like "vendor/lib: Author", while the totals cover the
+		repo and all submodules. Submodules that are not initialized are
+		skipped. The --branch flag only applies to the repo itself, and
+		--submodules can't be combined with --decay, --files, --breadth,
+		--count-merges-separately, --surviving, --complexity or the svg
+		format. Reports with --submodules are not cached.
+
+		Reports are cached, so that analysing the same commits again, like in
+		repeated CI runs, doesn't walk the history again. A report is only
+		reused for the same repo, the same analysed commits and the same
+		flags, .gitcontribignore file and mailmap, so moving HEAD or the
+		analysed branch, or editing the .mailmap file, computes a new one. The
+		cache is kept in the gitcontrib directory of $XDG_CACHE_HOME, ~/.cache
+		by default, or in the directory given with --cache-dir. The --no-cache
+		flag neither reads nor writes the cache. Reports with --decay, --since
+		or --until are never cached, as they change with time. Old entries are
+		not removed, but the directory can be deleted at any time.
+
+		Nightly reports of very large repos can be made incremental with
+		--baseline FILE. The file stores the analysed commit along with the
+		commit counts and line changes of each author, and the next run only
+		analyses the commits added since, like 'git log BASE..HEAD', adding
+		their counts and line changes to the stored ones before the authors
+		are filtered and the ratios computed. The file is updated to the newly
+		analysed commit. The whole history is analysed instead, and the file
+		replaced, when it doesn't exist yet, when it was made with other flags
+		selecting the history, like --path or --first-parent, or another
+		mailmap, or when its commit is no longer part of the history, as after
+		a force-push. The flags adding columns, like --files or --surviving,
+		still analyse the whole history. The flag can't be combined with
+		--since, --until, --since-tag, --commits or --submodules, as the
+		stored counts would no longer match.
+
+		With --watch, the report is rendered again each time HEAD moves to
+		another commit, like for a live dashboard, until interrupted with
+		Ctrl-C. HEAD is checked every --interval, 5s by default, and the
+		screen is cleared before each report. The report isn't paged, and
+		unless standard output is a terminal, --watch has no effect, nor
+		with --output.
+
+		Errors are written to standard error as text. With the json,
+		pretty-json and ndjson formats, they are written as a JSON object
+		instead, holding the message and a code telling the kind of error,
+		one of not_a_repo, git_missing, bad_ref, no_commits, no_branch,
+		dubious_ownership, parse_drift, no_contributions, too_many_authors
+		or, for all other errors, error:
+
+		    {"error":"repository has no commits","code":"no_commits"}
+
+		An empty report, as when --author or --min-commits leave out every
+		author, is written like any other and the command succeeds. For CI
+		guards, --fail-on-empty makes the command fail instead, writing "no
+		contributions in the analysed history" to standard error and
+		nothing to standard output. It fails the same way when --since,
+		--until or --path leave no commit to analyse, including in a range,
+		and on a repo without any commits.
+
+		As a sanity check in automation, --max-authors N makes the command
+		fail before writing the report when it holds more than N authors,
+		which usually tells of a misconfiguration, like a mailmap that
+		wasn't read, leaving the identities of each author apart, or bots
+		committing under many names that --ignore-author should leave out.
+
+		The --input-json flag renders a report previously written with the
+		json or ndjson formats instead of analysing the repo, reading it from
+		the given file or from standard input when given as '-'. Git is not
+		run at all, so results captured once, like in CI, can be reformatted
+		later for different audiences:
+
+		    gitcontrib summary --format ndjson --output report.ndjson
+		    gitcontrib summary --input-json report.ndjson --format html
+
+		The --author, --min-commits and --recompute-ratios flags still apply
+		to the report read. The flags selecting the history or adding columns
+		have no effect, while the Weighted and Files columns are shown when
+		the report has them. It is an error if a record of the report can't
+		be decoded, holds fields unknown to gitcontrib, or if the totals are
+		missing, as when the ndjson stream was cut short. With --strict, it
+		is an error too if the ratios of an author aren't their share of the
+		totals, as in an edited report, also for the report of --compare-to.
+		A cached report failing this check is computed anew.
+
+		For recurring reports, --compare-to FILE shows what changed since a
+		report previously written with the json or ndjson formats, like the
+		one of last week, instead of the report itself:
+
+		    gitcontrib summary --format json --output last-week.json
+		    gitcontrib summary --compare-to last-week.json
+
+		The table lists the commits, additions and deletions of each author,
+		each followed by its change since the previous report, like +3. The
+		authors absent from the previous report are marked new, and those
+		only in the previous report are marked gone and listed last, with
+		their numbers dropping to 0. The numbers of new and departed
+		contributors follow the table. The --author and --min-commits flags
+		apply to both reports, so the authors left out of either by
+		--min-commits count as new or gone. Combined with --input-json, two
+		saved reports are compared without running git. Only the table
+		format is supported, and the flag can't be combined with
+		--template, --columns or --aggregate-only.
+		`,
+	Call: func(x *Z.Cmd, args ...string) (err error) {
+
+		var sf summaryFlags
+		var input string
+		var watching, listPresets bool
+		interval := 5 * time.Second
+		fs := newFlagSet(x)
+		sf.register(fs)
+		fs.BoolVar(&listPresets, "list-presets", false, "list the --preset names and their templates")
+		fs.StringVar(&input, "input-json", "", "render a json or ndjson report read from this file")
+		fs.StringVar(&sf.compareTo, "compare-to", "", "show the changes since the json or ndjson report in this file")
+		fs.StringVar(&sf.baseline, "baseline", "", "only analyse the commits since the baseline stored in this file, updating it")
+		fs.BoolVar(&watching, "watch", false, "render the report again each time HEAD changes")
+		fs.DurationVar(&interval, "interval", interval, "how often HEAD is checked for changes with --watch")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+		defer func() { err = sf.reportError(err) }()
+
+		if listPresets {
+			return sf.write(writePresets)
+		}
+
+		if sf.compareTo != "" {
+			if err := sf.loadPrevious(); err != nil {
+				return err
+			}
+		}
+
+		if input != "" {
+			if err := sf.resolvePreset(); err != nil {
+				return err
+			}
+			summaries, totals, err := sf.load(input)
+			if err := sf.checkReported(summaries, err); err != nil {
+				return err
+			}
+
+			return sf.write(func(w io.Writer) error {
+				return sf.render(w, summaries, totals)
+			})
+		}
+
+		if err := sf.resolve(); err != nil {
+			return sf.emptyRepo(err)
+		}
+
+		if watching && sf.output == "" && !dryRun && isTerminal(os.Stdout) {
+			if interval <= 0 {
+				return errors.New("--interval must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			// HEAD is watched as given, and pinned again for each report
+			o := sf.options
+			o.rev = o.ref
+			return watch(ctx, o, interval, os.Stdout, func(w io.Writer) error {
+				sf.rev = sf.ref
+				if err := sf.pin(); err != nil {
+					return err
+				}
+				summaries, totals, err := sf.analyse()
+				if err != nil {
+					return err
+				}
+				return sf.render(w, summaries, totals)
+			})
+		}
+
+		summaries, totals, err := sf.analyse()
+		if err := sf.checkReported(summaries, err); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		return sf.write(func(w io.Writer) error {
+			return sf.render(w, summaries, totals)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var RangeCmd = &Z.Cmd{
+	Name:    `range`,
+	Summary: `lists the 'summary' report for the commits between two revisions`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--since DATE] [--until DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--strict] [--shortstat [--shortstat-threshold SHARE]] [--fail-on-empty] [--max-authors N] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--recompute-ratios] [--decay [--half-life DAYS]] [--files [--follow-renames]] [--breadth [--breadth-weights NAME=WEIGHT,...]] [--count-merges-separately] [--surviving] [--complexity] [--ratios-extra] [--self-ratios] [--score [--score-mode relative|share]] [--rank [--rank-by KEY]] [--columns LIST] [--aggregate-only] [--format FORMAT] [--delimiter SEP] [--timestamp TIME] [--box [--ascii]] [--thousands] [--no-footer] [--template TEMPLATE|@FILE|--preset NAME] [--output FILE] [--clipboard] [--display name|email|both] [--include-working-tree] [--anonymize [--key KEY]] [--submodules] [--no-cache] [--cache-dir DIR] FROM TO`,
+	Description: `
+		The {{aka}} subcommand gives the same report as the 'summary'
+		subcommand, restricted to the commits reachable from TO but not from
+		FROM, i.e. the git revision range FROM..TO. Both revisions can be
+		anything git understands as a commit, like tags, branches or hashes.
+		It is an error if the range contains no commits.
+
+		Example, listing who contributed to a release:
+
+		    gitcontrib range v1.0 v1.1
+
+		The same flags as for 'summary' are supported, see 'summary help'.
+		`,
+	Call: func(x *Z.Cmd, args ...string) (err error) {
+
+		var sf summaryFlags
+		fs := newFlagSet(x)
+		sf.register(fs)
+		if err := parseFlags(x, fs, args, 2); err != nil {
+			return err
+		}
+		defer func() { err = sf.reportError(err) }()
+
+		if sf.rev != "" || sf.defaultBranch || sf.sinceTag.set || sf.commitsGiven() || sf.asOf != "" {
+			return errors.New("--branch, --default-branch, --since-tag, --commits and --as-of can't be used with a range")
+		}
+
+		if err := sf.resolve(); err != nil {
+			return sf.emptyRepo(err)
+		}
+
+		sf.rev, err = revRange(sf.options, fs.Arg(0), fs.Arg(1))
+		if err != nil {
+			return err
+		}
+
+		summaries, totals, err := sf.analyse()
+		if err := sf.checkReported(summaries, err); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		return sf.write(func(w io.Writer) error {
+			return sf.render(w, summaries, totals)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var PRCmd = &Z.Cmd{
+	Name:    `pr`,
+	Summary: `lists the 'summary' report for the commits brought in by a merge`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--since DATE] [--until DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--strict] [--shortstat [--shortstat-threshold SHARE]] [--fail-on-empty] [--max-authors N] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--recompute-ratios] [--decay [--half-life DAYS]] [--files [--follow-renames]] [--breadth [--breadth-weights NAME=WEIGHT,...]] [--count-merges-separately] [--surviving] [--complexity] [--ratios-extra] [--self-ratios] [--score [--score-mode relative|share]] [--rank [--rank-by KEY]] [--columns LIST] [--aggregate-only] [--format FORMAT] [--delimiter SEP] [--timestamp TIME] [--box [--ascii]] [--thousands] [--no-footer] [--template TEMPLATE|@FILE|--preset NAME] [--output FILE] [--clipboard] [--display name|email|both] [--include-working-tree] [--anonymize [--key KEY]] [--submodules] [--no-cache] [--cache-dir DIR] MERGE`,
+	Description: `
+		The {{aka}} subcommand gives the same report as the 'summary'
+		subcommand, restricted to the commits brought in by the merge commit
+		MERGE, like those of a merged pull request, telling who contributed
+		to it. These are the commits reachable from the second parent of
+		MERGE but not from its first, i.e. the git revision range
+		MERGE^1..MERGE^2, so the commits of the target branch made while the
+		pull request was open are left out. MERGE can be anything git
+		understands as a commit, like a hash or a tag. It is an error if
+		MERGE is not a merge commit, or brought in no commits. The parents
+		of an octopus merge after the second are not analysed.
+
+		Example, listing who contributed to the pull request merged by
+		1a2b3c4:
+
+		    gitcontrib pr 1a2b3c4
+
+		The same flags as for 'summary' are supported, see 'summary help'.
+		`,
+	Call: func(x *Z.Cmd, args ...string) (err error) {
+
+		var sf summaryFlags
+		fs := newFlagSet(x)
+		sf.register(fs)
+		if err := parseFlags(x, fs, args, 1); err != nil {
+			return err
+		}
+		defer func() { err = sf.reportError(err) }()
+
+		if sf.rev != "" || sf.defaultBranch || sf.allBranches || sf.sinceTag.set || sf.commitsGiven() || sf.asOf != "" {
+			return errors.New("--branch, --default-branch, --all-branches, --since-tag, --commits and --as-of can't be used with a merge")
+		}
+
+		if err := sf.resolve(); err != nil {
+			return sf.emptyRepo(err)
+		}
+
+		sf.rev, err = mergeRange(sf.options, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		summaries, totals, err := sf.analyse()
+		if err := sf.checkReported(summaries, err); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		return sf.write(func(w io.Writer) error {
+			return sf.render(w, summaries, totals)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var AllBranchesCmd = &Z.Cmd{
+	Name:    `allbranches`,
+	Summary: `lists the 'summary' report of every branch in one table`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--remote] [--jobs N] [--since DATE] [--until DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--strict] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--recompute-ratios] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Aliases: []string{"ab"},
+	Description: `
+		The {{aka}} subcommand gives the 'summary' report of each local
+		branch, combined into one table with a leading Branch column. The
+		ratios of each row are relative to the branch of that row. With
+		--remote, the remote-tracking branches are included as well. For a
+		single report of all branches together, counting each commit once,
+		give --all-branches to the 'summary' command instead.
+
+		As the history of each branch is analysed separately, several
+		branches are analysed at the same time. The --jobs flag sets how
+		many, defaulting to the number of CPUs.
+
+		The --first-parent flag and the flags selecting and grouping authors,
+		like --author, --ignore-author and --team, work as for the 'summary'
+		command, applied to each branch separately, as does the
+		.gitcontribignore file.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var remote bool
+		jobs := runtime.NumCPU()
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.BoolVar(&remote, "remote", false, "include remote-tracking branches")
+		fs.IntVar(&jobs, "jobs", jobs, "number of branches analysed at the same time")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if o.rev != "" || o.defaultBranch || o.sinceTag.set || o.commitsGiven() || o.allBranches || o.asOf != "" {
+			return errors.New("--branch, --default-branch, --since-tag, --commits, --all-branches and --as-of can't be used with allbranches")
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		names, err := listBranches(o, remote)
+		if err != nil {
+			return fmt.Errorf("error listing branches: %w", err)
+		}
+
+		branches := make([]branchSummary, len(names))
+		err = parallel(len(names), jobs, func(i int) error {
+			bo := o
+			bo.rev = names[i]
+
+			summaries, totals, err := ff.collect(bo)
+			if err != nil {
+				return fmt.Errorf("error analysing %s: %w", names[i], err)
+			}
+
+			branches[i] = branchSummary{names[i], summaries, totals}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		for _, b := range branches {
+			an.rename(b.Summaries)
+		}
+
+		return of.write(func(w io.Writer) error {
+			return writeBranchTable(w, branches)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var TagsCmd = &Z.Cmd{
+	Name:    `tags`,
+	Summary: `lists the 'summary' report of each release tag`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--jobs N] [--since DATE] [--until DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--strict] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--recompute-ratios] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand attributes the contributions to the release
+		they landed in, giving a 'summary' report of each tag. The tags are
+		taken in the order they were created, and each release holds the
+		commits reachable from its tag but not from the tag before, like the
+		'range' command. The first release holds all history up to its tag.
+		The commits after the last tag are reported as unreleased, up to the
+		checked out branch or the ref given with --branch. Releases without
+		any commits, like two tags of the same commit, are left out.
+
+		The ratios of each release are relative to that release. As with the
+		'allbranches' command, several releases are analysed at the same
+		time, as many as set by --jobs, defaulting to the number of CPUs.
+
+		The --first-parent flag and the flags selecting and grouping authors
+		work as for the 'summary' command, applied to each release
+		separately.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		jobs := runtime.NumCPU()
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.IntVar(&jobs, "jobs", jobs, "number of releases analysed at the same time")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if o.sinceTag.set || o.commitsGiven() || o.asOf != "" {
+			return errors.New("--since-tag, --commits and --as-of can't be used with tags")
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		tags, err := listTags(o)
+		if err != nil {
+			return fmt.Errorf("error listing tags: %w", err)
+		}
+		if len(tags) == 0 {
+			return errors.New("no tags in repo")
+		}
+
+		head := o.rev
+		if head == "" {
+			head = "HEAD"
+		}
+
+		var rs []release
+		for _, r := range releases(tags, head) {
+			ok, err := hasRangeCommits(o, r.Range)
+			if err != nil {
+				return fmt.Errorf("error counting commits of %s: %w", r.Name, err)
+			}
+			if ok {
+				rs = append(rs, r)
+			}
+		}
+
+		summaries := make([]releaseSummary, len(rs))
+		err = parallel(len(rs), jobs, func(i int) error {
+			ro := o
+			ro.rev = rs[i].Range
+
+			s, totals, err := ff.collect(ro)
+			if err != nil {
+				return fmt.Errorf("error analysing %s: %w", rs[i].Name, err)
+			}
+
+			summaries[i] = releaseSummary{rs[i], s, totals}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		for _, s := range summaries {
+			an.rename(s.Summaries)
+		}
+
+		return of.write(func(w io.Writer) error {
+			return writeReleaseTables(w, summaries)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var FileCmd = &Z.Cmd{
+	Name:    `file`,
+	Summary: `lists the commits per author touching a single file`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--cap-lines-per-file N] [--diff-filter LETTERS] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--anonymize [--key KEY]] [--output FILE] [--clipboard] PATH`,
+	Aliases: []string{"f"},
+	Description: `
+		The {{aka}} subcommand lists who modified the file at PATH and how
+		often, along with the dates of the first and last commits of each
+		author touching it, most commits first. This helps judging the risk
+		of changing a hot file, and who to ask about it. The file is
+		followed across renames, so commits touching it under its earlier
+		names count as well. Merge commits are not counted.
+
+		Example:
+
+		    gitcontrib file config/db.go
+
+		With --repo, PATH is relative to the given directory. The --branch
+		and --first-parent flags work as for the 'summary' command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		if err := parseFlags(x, fs, args, 1); err != nil {
+			return err
+		}
+
+		if err := o.rejectCommitFilters("file"); err != nil {
+			return err
+		}
+
+		if len(o.paths) > 0 || o.follow {
+			return errors.New("--path and --follow can't be used with file, which always follows PATH")
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		touchers, err := fileTouchers(o, fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("error reading history of %s: %w", fs.Arg(0), err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		if len(touchers) == 0 {
+			return fmt.Errorf("no commits touching %s", fs.Arg(0))
+		}
+
+		an := af.pseudonyms()
+		t := newTable(1, "Author", "Commits", "First Touch", "Last Touch")
+		for _, ft := range touchers {
+			t.row(
+				an.name(ft.Author),
+				strconv.Itoa(ft.Commits),
+				ft.First.Format("2006-01-02"),
+				ft.Last.Format("2006-01-02"),
+			)
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var DirectoriesCmd = &Z.Cmd{
+	Name:    `directories`,
+	Summary: `lists the author owning most of each top-level directory`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Aliases: []string{"dirs"},
+	Description: `
+		The {{aka}} subcommand lists the owner of each top-level directory of
+		the repo, being the author with the most changed lines in it, along
+		with their share of the changed lines of the directory and the total
+		number of them. Files at the top level of the repo are counted under
+		the directory ".". This shows who to ask about each part of a large
+		codebase. Binary files have no changed lines, so they don't count.
+
+		The --branch and --first-parent flags work as for the 'summary'
+		command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		t := newTable(2, "Directory", "Top Author", "Share", "Total Lines")
+		for _, d := range directoryOwners(commits) {
+			t.row(
+				d.Directory,
+				an.name(d.Author),
+				fmt.Sprintf("%.3f", d.Share()),
+				strconv.Itoa(d.Total),
+			)
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var SilosCmd = &Z.Cmd{
+	Name:    `silos`,
+	Summary: `lists the files only ever changed by a single author`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--follow-renames] [--list] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand finds the knowledge silos of the repo, being
+		the files only ever changed by a single author, who may then be the
+		only one knowing them. For each author, the number of files only
+		they changed is listed, the most first, along with their share of
+		all the files changed. With --list, the paths of the files are
+		listed instead, with a row per file, grouped by author.
+
+		Only the files still in the analysed commit count, leaving out the
+		deleted ones. By default, the paths a file had before being renamed
+		count as different files, so that the changes made before the
+		rename are left out. With --follow-renames, they count as one.
+
+		The commits are limited like for the 'summary' report, so that with
+		--since only the recent changes count, finding the files only a
+		single author has changed lately.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		var followRenames, list bool
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.BoolVar(&followRenames, "follow-renames", false, "count the paths of a renamed file as one")
+		fs.BoolVar(&list, "list", false, "list the paths of the siloed files")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		current, err := currentFiles(o, blameRev(o.rev))
+		if err != nil {
+			return fmt.Errorf("error listing files: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		silos := findSilos(commits, current, followRenames)
+		an := af.pseudonyms()
+		for i, s := range silos {
+			silos[i].Author = an.name(s.Author)
+		}
+
+		if list {
+			t := newTable(2, "Author", "File")
+			for _, s := range silos {
+				for _, path := range s.Files {
+					t.row(s.Author, path)
+				}
+			}
+			return of.write(t.write)
+		}
+
+		changed := make(map[string]bool)
+		eachFile(commits, followRenames, func(_, path string) {
+			if current[path] {
+				changed[path] = true
+			}
+		})
+
+		var siloed int
+		t := newTable(1, "Author", "Siloed files", "Share")
+		for _, s := range silos {
+			siloed += len(s.Files)
+			t.row(s.Author, strconv.Itoa(len(s.Files)), fmt.Sprintf("%.3f", float64(len(s.Files))/float64(len(changed))))
+		}
+
+		return of.write(func(w io.Writer) error {
+			if err := t.write(w); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\n Siloed files: %d of %d\n", siloed, len(changed))
+			return nil
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var HotspotsCmd = &Z.Cmd{
+	Name:    `hotspots`,
+	Summary: `lists the files of the most churn, with their top author`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--follow-renames] [--top N] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand finds the hotspots of the repo, being the
+		files changed the most, as a file-centric view of risk and
+		ownership. The files are ranked by their churn, the sum of the lines
+		added and deleted in them over the history, the most first, and
+		listed with their top author, being the author with the most changed
+		lines in them, along with the share of the churn of the file made by
+		that author. The --top flag sets how many files are listed, 10 by
+		default. Binary files have no changed lines, so they don't count.
+
+		By default, the paths a file had before being renamed count as
+		different files. With --follow-renames, they count as one, under the
+		path the file has after its latest rename. Deleted files are listed
+		like the others, as their churn still tells where the work went.
+
+		The commits are limited like for the 'summary' report, so that with
+		--since only the recent changes count.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		var followRenames bool
+		top := 10
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.BoolVar(&followRenames, "follow-renames", false, "count the paths of a renamed file as one")
+		fs.IntVar(&top, "top", top, "number of files listed")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if top <= 0 {
+			return errors.New("--top must be positive")
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		hotspots := findHotspots(commits, followRenames)
+		if len(hotspots) > top {
+			hotspots = hotspots[:top]
+		}
+
+		an := af.pseudonyms()
+		t := newTable(1, "File", "Total Churn", "Top Author", "Top Author Share")
+		for _, h := range hotspots {
+			t.row(h.File, strconv.Itoa(h.Churn), an.name(h.Author), fmt.Sprintf("%.3f", h.Share()))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var FileEntropyCmd = &Z.Cmd{
+	Name:    `fileentropy`,
+	Summary: `lists how evenly the authorship of each file is shared`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--follow-renames] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand tells the files of concentrated authorship
+		from those of healthily shared authorship. For each file, it lists
+		the number of authors who changed lines in it, and the Shannon
+		entropy in bits of their shares of its changed lines. The entropy is
+		0 for a file of a single author, and grows as more authors share
+		it more evenly, being 1 for two authors of half the lines each, and
+		2 for four authors of a quarter each. The files are listed with the
+		lowest entropy first, surfacing the most concentrated ones. Binary
+		files have no changed lines, so they don't count.
+
+		By default, the paths a file had before being renamed count as
+		different files. With --follow-renames, they count as one, under the
+		path the file has after its latest rename.
+
+		The commits are limited like for the 'summary' report, so that with
+		--since only the recent changes count.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var followRenames bool
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		fs.BoolVar(&followRenames, "follow-renames", false, "count the paths of a renamed file as one")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		t := newTable(1, "File", "Authors", "Entropy")
+		for _, e := range fileEntropies(commits, followRenames) {
+			t.row(e.File, strconv.Itoa(e.Authors), fmt.Sprintf("%.3f", e.Entropy))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var TrendsCmd = &Z.Cmd{
+	Name:    `trends`,
+	Summary: `lists whether the monthly line changes of each author grow or shrink`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand tells which authors contribute more and more,
+		and which less and less. The line changes of each author are summed
+		per calendar month, and a straight line is fitted through the months
+		from the first month the author was active in to the last month of
+		the history. Its slope, in changed lines per month, is listed along
+		with an arrow pointing up for a growing trend, down for a shrinking
+		one, and sideways for a flat one, changing less than one line per
+		month. Authors only active in a single month have a flat trend.
+
+		The Recent column holds the changed lines of the last three months of
+		the history, which end with its last commit rather than today, so
+		that the column also makes sense with --until. The authors are listed
+		by descending slope, the fastest growing first.
+
+		The --branch, --since and --until flags work as for the 'summary'
+		command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		t := newTable(1, "Author", "Recent", "Slope", "Direction")
+		for _, tr := range authorTrends(commits) {
+			t.row(
+				an.name(tr.Author),
+				strconv.Itoa(tr.Recent),
+				fmt.Sprintf("%+.1f", tr.Slope),
+				tr.Direction(),
+			)
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var MonthlyCmd = &Z.Cmd{
+	Name:    `monthly`,
+	Summary: `lists the commits and line changes of each author per month`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--months N] [--dense] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand gives a table per calendar month, oldest
+		first, of the non-merge commits, added and deleted lines of each
+		author in that month, for monthly reports. The authors of a month
+		are listed by descending commits, then by name.
+
+		The --months N flag only lists the last N months of the history,
+		which end with the month of its last commit rather than today, like
+		for the 'trends' command, so that it also makes sense with --until.
+
+		Only the authors active in a month are listed in its table, and the
+		months without any commits are left out. With --dense, every author
+		of the listed months has a row in every month, with zeros when
+		absent, and the months without commits are listed too, so that the
+		tables line up.
+
+		The months are those of the author dates in the time zone of each
+		commit, or in that of --tz. The other flags selecting the history
+		work as for the 'summary' command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		var months int
+		var dense bool
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.IntVar(&months, "months", 0, "only list the last N months of the history")
+		fs.BoolVar(&dense, "dense", false, "list every author in every month, with zeros when absent")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if months < 0 {
+			return errors.New("--months can't be negative")
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		reports := monthlyReports(commits, months, dense)
+		an := af.pseudonyms()
+		for _, r := range reports {
+			for i, a := range r.Authors {
+				r.Authors[i].Author = an.name(a.Author)
+			}
+		}
+		return of.write(func(w io.Writer) error {
+			return writeMonthTables(w, reports)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var CumulativeCmd = &Z.Cmd{
+	Name:    `cumulative`,
+	Summary: `lists the cumulative added lines of each author per month`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--format table|csv|json] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand gives the growth of the contributions, as the
+		lines each author added up to the end of each calendar month, for
+		stacked area charts. Each month is a row, oldest first, and each
+		author a column, with the most lines added over the whole history
+		first, ties being ordered by name. Every month from the first commit
+		to the last is listed, also those without commits, and authors have
+		zeros in the months before their first commit, so that each column
+		only ever grows. Merge commits are left out.
+
+		The --format flag selects the output format, one of:
+
+		    table  aligned human-readable table (default)
+		    csv    the same matrix as CSV, headed by "month" and the
+		           author names, quoted as needed, for charting tools
+		    json   single JSON object with the "authors" in the order of
+		           the columns, and the "months", each with its "month"
+		           and the "lines" of each author, like
+		           {"authors":["Alice","Bob"],"months":[{"month":"2023-01",
+		           "lines":[120,0]},...]}
+
+		The months are those of the author dates in the time zone of each
+		commit, or in that of --tz, like for the 'monthly' command. The
+		other flags selecting the history work as for the 'summary'
+		command, with the lines counted from the start of the selected
+		history, like of --since.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		format := "table"
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.StringVar(&format, "format", format, "output format, table, csv or json")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		var write func(r cumulativeReport, w io.Writer) error
+		switch format {
+		case "table":
+			write = cumulativeReport.writeTable
+		case "csv":
+			write = cumulativeReport.writeCSV
+		case "json":
+			write = cumulativeReport.writeJSON
+		default:
+			return fmt.Errorf("unknown format %q, must be one of: csv, json, table", format)
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		r := cumulativeLines(commits)
+		an := af.pseudonyms()
+		for i, author := range r.Authors {
+			r.Authors[i] = an.name(author)
+		}
+		return of.write(func(w io.Writer) error {
+			return write(r, w)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var IntensityCmd = &Z.Cmd{
+	Name:    `intensity`,
+	Summary: `lists the changed lines of each author per 1000 lines of the current code`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--exclude PATHSPEC] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand normalizes the churn against the size of the
+		project, giving the churn intensity of each author and of the whole
+		repo: the added and deleted lines per 1000 lines of the current
+		code. An intensity of 1000 means as many lines were changed as the
+		code has today. The authors are listed by descending changed lines,
+		followed by the current lines of code and the overall intensity.
+
+		The current lines of code are those of the text files tracked at the
+		end of the analysed history, being the checked out branch or the end
+		of the range given with --branch. Binary files don't count. The
+		repeatable --exclude PATHSPEC flag leaves matching files out of the
+		lines of code, like vendored dependencies with --exclude vendor,
+		while their changes still count, unless left out by the
+		.gitcontribignore file. The --path flag limits both.
+
+		The other flags selecting the history work as for the 'summary'
+		command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		var exclude stringList
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.Var(&exclude, "exclude", "leave the files matching this pathspec out of the lines of code (repeatable)")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		lineChangesMap, err := mapLineChanges(o)
+		if err != nil {
+			return fmt.Errorf("error extracting line changes: %w", err)
+		}
+
+		loc, err := currentLines(o, exclude)
+		if err != nil {
+			return fmt.Errorf("error counting lines of code: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		authors, total := churnIntensities(lineChangesMap, loc)
+
+		an := af.pseudonyms()
+		t := newTable(1, "Author", "Changed lines", "Per 1000 LOC")
+		for _, a := range authors {
+			t.row(an.name(a.Author), strconv.Itoa(a.Lines), fmt.Sprintf("%.1f", a.Intensity))
+		}
+
+		return of.write(func(w io.Writer) error {
+			if err := t.write(w); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\n Current lines of code: %d\n", loc)
+			fmt.Fprintf(w, " Overall churn intensity: %.1f per 1000 lines\n", total.Intensity)
+			return nil
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var CadenceCmd = &Z.Cmd{
+	Name:    `cadence`,
+	Summary: `lists the mean and longest gaps between the commits of each author`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand gives the engagement of each author over
+		time, from the dates of their commits in time order. The Mean Gap
+		column holds the mean number of days between two consecutive
+		commits of the author, their cadence, and the Longest Gap column the
+		longest one, telling how long they were dormant. The authors are
+		listed by ascending mean gap, the most frequent committers first,
+		followed by the authors of a single commit, whose gaps are blank.
+		Merge commits are not counted.
+
+		The commits are dated by when they were authored, or committed with
+		--date-type committer, and limited like for the 'summary' report,
+		so that with --since only the recent cadence counts.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		cadences, err := commitCadence(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		t := newTable(1, "Author", "Commits", "Mean Gap (days)", "Longest Gap (days)")
+		for _, c := range sortCadences(cadences) {
+			t.row(an.name(c.Author), strconv.Itoa(c.Commits), c.days(c.Mean), c.days(c.Max))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var VelocityCmd = &Z.Cmd{
+	Name:    `velocity`,
+	Summary: `lists the changed lines per day and commits per week of the repo`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--active-only] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand gives the velocity of the repo as two single
+		numbers, the average number of lines changed per day and of commits
+		per week. By default, the days span the history from the day of the
+		first commit to that of the last, both included, and the weeks are
+		the days divided by seven. Merge commits are not counted.
+
+		Long dormant periods water these rates down, so --active-only only
+		counts the days with commits, and the distinct calendar weeks with
+		commits, giving the pace of the repo while it is being worked on.
+
+		The history is selected like for the 'summary' command, so that
+		--since "1 year ago" gives the velocity of the last year.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var activeOnly bool
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		fs.BoolVar(&activeOnly, "active-only", false, "only count the days and weeks with commits")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		v := repoVelocity(commits, activeOnly)
+		if v.Commits == 0 {
+			return ErrNoCommits
+		}
+
+		days, weeks := "Days", "Weeks"
+		if activeOnly {
+			days, weeks = "Active days", "Active weeks"
+		}
+
+		return of.write(func(w io.Writer) error {
+			fmt.Fprintf(w, " Commits: %d\n", v.Commits)
+			fmt.Fprintf(w, " Lines changed: %d\n", v.Lines)
+			fmt.Fprintf(w, " %s: %d\n", days, v.Days)
+			fmt.Fprintf(w, " %s: %.1f\n", weeks, v.Weeks)
+			fmt.Fprintf(w, " Lines changed per day: %.1f\n", v.LinesPerDay())
+			fmt.Fprintf(w, " Commits per week: %.1f\n", v.CommitsPerWeek())
+			return nil
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var EffortCmd = &Z.Cmd{
+	Name:    `effort`,
+	Summary: `lists the commits and changed lines per kind of work, like fixes`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--category NAME=REGEX] [--by-author] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand estimates where the effort went, tallying the
+		commits and their changed lines by the kind of work their subjects
+		tell. Each commit is put in the first category whose pattern matches
+		its subject, ignoring case, or in the "other" category when none
+		does. The categories are listed in the order they are tried, with
+		"other" last. Merge commits are not counted.
+
+		By default, the categories are fix, feat, refactor, test and docs,
+		matching subjects with a word starting with "fix", "feat",
+		"refactor" or "test", or the word "doc" or "docs", like the types
+		of Conventional Commits. The repeatable --category NAME=REGEX flag
+		replaces them with categories of its own, tried in the order given:
+
+		    gitcontrib effort --category 'fix=\bfix|bug' --category 'ci=^ci\b'
+
+		With --by-author, the commits of each author are tallied separately,
+		with an Author column, the authors of each category being listed by
+		descending commits.
+
+		The flags selecting the history work as for the 'summary' command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		var cs categories
+		var byAuthor bool
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.Var(&cs, "category", "tally the commits whose subject matches REGEX as NAME, given as NAME=REGEX (repeatable)")
+		fs.BoolVar(&byAuthor, "by-author", false, "tally the commits of each author separately")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+		if len(cs) == 0 {
+			cs = defaultCategories
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		header := []string{"Category", "Commits", "Lines"}
+		if byAuthor {
+			header = []string{"Category", "Author", "Commits", "Lines"}
+		}
+
+		an := af.pseudonyms()
+		t := newTable(len(header)-2, header...)
+		for _, r := range effort(commits, cs, byAuthor) {
+			row := []string{r.Category}
+			if byAuthor {
+				row = append(row, an.name(r.Author))
+			}
+			t.row(append(row, strconv.Itoa(r.Commits), strconv.Itoa(r.Lines))...)
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var CommunityCmd = &Z.Cmd{
+	Name:    `community`,
+	Summary: `lists the number of contributors and the new ones`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--cap-lines-per-file N] [--diff-filter LETTERS] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand gives the health of the community of a repo,
+		listing the new contributors of a period, along with the dates of
+		their first commits, followed by the total number of contributors
+		and the number of new ones. The period is given like for the other
+		commands, with --since and --until, or --since-tag. An author is new
+		when their first commit in the whole history of the branch is in the
+		period, also when made right at its start. Without a period, all
+		contributors are new. Merge commits are not counted.
+
+		Example, listing the contributors who joined in the last quarter:
+
+		    gitcontrib community --since "3 months ago"
+
+		The --branch and --first-parent flags work as for the 'summary'
+		command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.rejectCommitFilters("community"); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		newcomers, total, err := newContributors(o)
+		if err != nil {
+			return fmt.Errorf("error finding new contributors: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		return of.write(func(w io.Writer) error {
+			t := newTable(1, "Author", "First Commit")
+			for _, c := range newcomers {
+				t.row(an.name(c.Author), c.First.Format("2006-01-02"))
+			}
+			if err := t.write(w); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(w, "\n Total contributors: %d\n", total)
+			fmt.Fprintf(w, " New contributors: %d\n", len(newcomers))
+			return nil
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var AuthorsFileCmd = &Z.Cmd{
+	Name:    `authorsfile`,
+	Aliases: []string{"authors-file"},
+	Summary: `writes an AUTHORS file listing every contributor`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--cap-lines-per-file N] [--diff-filter LETTERS] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--sort first|name] [--min-commits N] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand writes the contributors of a repo in the
+		form of an AUTHORS file, one "Name <email>" line per author, for
+		committing to the repo:
+
+		    gitcontrib authorsfile --output AUTHORS
+
+		Each author is listed once, by the name the mailmap gives them, so
+		that the identities consolidated by the .mailmap file of the repo
+		share a line, along with the email of their newest commit. With
+		--no-mailmap, the names and emails are taken as committed instead.
+		Merge commits, and commits without an author name, are not counted.
+
+		The authors are listed in the order of their first commits, the
+		earliest first, or by name with --sort name. The --min-commits N
+		flag leaves out the authors of fewer than N commits, like those of a
+		single typo fix.
+
+		The commits are limited like for the 'summary' report, so that with
+		--since only the recent contributors are listed.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		var sortBy string
+		var minCommits int
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.StringVar(&sortBy, "sort", "first", "sort the authors by their first commit or by name, first or name")
+		fs.IntVar(&minCommits, "min-commits", 0, "only list the authors of at least this many commits")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.rejectCommitFilters("authorsfile"); err != nil {
+			return err
+		}
+
+		if sortBy != "first" && sortBy != "name" {
+			return fmt.Errorf("invalid --sort %q, must be first or name", sortBy)
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		entries, err := authorEntries(o, sortBy == "name")
+		if err != nil {
+			return fmt.Errorf("error listing authors: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		// the emails would give away the names
+		if an := af.pseudonyms(); an != nil {
+			for i, e := range entries {
+				entries[i].Name, entries[i].Email = an.name(e.Name), ""
+			}
+		}
+
+		return of.write(func(w io.Writer) error {
+			return writeAuthorsFile(w, entries, minCommits)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var AnomaliesCmd = &Z.Cmd{
+	Name:    `anomalies`,
+	Summary: `lists the commits with data-quality issues skewing the metrics`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand lists how many commits have issues that
+		otherwise silently skew the metrics of the other reports, following
+		the abbreviated hashes of up to three of them. The issues are:
+
+		    empty author      the author name is empty or only whitespace
+		    empty commit      a commit other than a merge changing no files
+		    malformed numstat a line of changes git wrote for the commit
+		                      couldn't be parsed, so its changes are lost
+
+		The --branch and --first-parent flags work as for the 'summary'
+		command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		t := newTable(2, "Anomaly", "Examples", "Count")
+		for _, a := range findAnomalies(commits) {
+			t.row(a.Kind, a.examples(3), strconv.Itoa(len(a.Commits)))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var ReviewersCmd = &Z.Cmd{
+	Name:    `reviewers`,
+	Summary: `lists the number of commits reviewed per person`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--cap-lines-per-file N] [--diff-filter LETTERS] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Aliases: []string{"rv"},
+	Description: `
+		The {{aka}} subcommand lists how many commits each person reviewed,
+		as recorded by the Reviewed-by and Signed-off-by trailers of the
+		commit messages, most reviews first. This shows how the review work
+		is distributed, which authorship alone doesn't.
+
+		A Reviewed-by trailer always counts as a review. A Signed-off-by
+		trailer only counts when signed by someone other than the author of
+		the commit, like a maintainer applying a patch, as authors commonly
+		sign off their own commits. A person listed in several trailers of
+		the same commit is counted once for it. Commits without any such
+		trailers are not counted.
+
+		The --first-parent flag works as for the 'summary' command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.rejectCommitFilters("reviewers"); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		counts, err := reviewCounts(o)
+		if err != nil {
+			return fmt.Errorf("error counting reviews: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		names := make([]string, 0, len(counts))
+		for k := range counts {
+			names = append(names, k)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if counts[names[i]] != counts[names[j]] {
+				return counts[names[i]] > counts[names[j]]
+			}
+			return names[i] < names[j]
+		})
+
+		an := af.pseudonyms()
+		t := newTable(1, "Reviewer", "Review Count")
+		for _, k := range names {
+			t.row(an.name(k), strconv.Itoa(counts[k]))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var WordsCmd = &Z.Cmd{
+	Name:    `words`,
+	Summary: `lists the most frequent words of the commit subjects per author`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--cap-lines-per-file N] [--diff-filter LETTERS] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--top N] [--stopwords-file FILE] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand lists the words each author uses most in the
+		subjects of their commits, with the number of commits using them,
+		as data for a word cloud, or a glimpse of what each author mostly
+		works on. The authors are listed by name, each with their --top
+		words, 10 by default, most used first.
+
+		The subjects are lowercased and split into words at every character
+		that is neither a letter nor a digit, so "Fix parser (#12)" gives
+		"fix" and "parser". Plain numbers, like those of pull requests, are
+		left out, as are common English words like "the" and "and". A word
+		used twice in a subject counts once. Merge commits are left out, as
+		their subjects are written by git.
+
+		The --stopwords-file flag replaces the list of left out words with
+		those in the given file, separated by spaces or newlines, like
+		project jargon. Blank lines and lines starting with # are skipped.
+		Authors whose subjects have no words left aren't listed.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		var stopwordsFile string
+		top := 10
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.IntVar(&top, "top", top, "number of words listed per author")
+		fs.StringVar(&stopwordsFile, "stopwords-file", "", "read the words left out from this file instead")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.rejectCommitFilters("words"); err != nil {
+			return err
+		}
+
+		if top <= 0 {
+			return errors.New("--top must be positive")
+		}
+
+		stopwords := defaultStopwords
+		if stopwordsFile != "" {
+			var err error
+			if stopwords, err = readStopwords(stopwordsFile); err != nil {
+				return err
+			}
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		counts, err := subjectWords(o, stopwordSet(stopwords))
+		if err != nil {
+			return fmt.Errorf("error counting words: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		t := newTable(2, "Author", "Word", "Count")
+		for _, w := range topWords(counts, top) {
+			t.row(an.name(w.Author), w.Word, strconv.Itoa(w.Count))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var DeletionsCmd = &Z.Cmd{
+	Name:    `deletions`,
+	Summary: `lists the commits of an author only deleting lines`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--output FILE] [--clipboard] AUTHOR`,
+	Description: `
+		The {{aka}} subcommand lists the commits of an author that are pure
+		removals, deleting lines without adding any, like cleanups of dead
+		code, newest first. Each is listed with its abbreviated hash, its
+		subject and the number of lines it deleted, for recognizing cleanup
+		work or finding where code went.
+
+		A commit touching several files is only listed when none of them
+		has added lines. Merge commits, and commits only touching binary
+		files, which have no line changes, are never listed.
+
+		The author is given as a regular expression matched against the
+		author names, ignoring case, like for the 'compare' command. An
+		author named exactly like the expression is chosen even when it
+		matches others too. It is an error if it matches no author or
+		several, which are then listed.
+
+		The flags selecting the history, like --since and --path, work as
+		for the 'summary' command, --path only counting the lines of the
+		files below the path.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		if err := parseFlags(x, fs, args, 1); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		author, err := matchAuthor(commitAuthors(commits), fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		t := newTable(2, "Commit", "Subject", "Deleted")
+		for _, c := range pureDeletions(commits, author) {
+			hash := c.Hash
+			if len(hash) > 12 {
+				hash = hash[:12]
+			}
+			t.row(hash, c.Subject, strconv.Itoa(c.LineChanges().Deletions))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var NetworkCmd = &Z.Cmd{
+	Name:    `network`,
+	Summary: `lists who co-authored commits with whom`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--cap-lines-per-file N] [--diff-filter LETTERS] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--format table|dot] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand shows who pairs with whom, as recorded by the
+		Co-authored-by trailers of the commit messages. Each pair of people
+		authoring commits together is listed with their number of commits,
+		most first. The author of a commit is paired with each of its
+		co-authors, and the co-authors with each other. The co-authors are
+		mapped through the .mailmap file like the authors, unless
+		--no-mailmap is given. A person listed as their own co-author is not
+		paired with themself, and commits without co-authors are not
+		counted.
+
+		With --format dot, the network is written as a Graphviz DOT graph
+		instead of a table, with a node per person and an edge per pair,
+		labelled and weighted by their number of commits together. Any
+		author name is quoted safely. Example, drawing the network:
+
+		    gitcontrib network --format dot | dot -Tpng -o network.png
+
+		The --first-parent flag works as for the 'summary' command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		format := "table"
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.StringVar(&format, "format", format, "output format, table or dot")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.rejectCommitFilters("network"); err != nil {
+			return err
+		}
+
+		if format != "table" && format != "dot" {
+			return fmt.Errorf("unknown format %q, must be one of: dot, table", format)
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		edges, err := coAuthorNetwork(o)
+		if err != nil {
+			return fmt.Errorf("error reading co-authors: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		for i, e := range edges {
+			edges[i].A, edges[i].B = an.name(e.A), an.name(e.B)
+		}
+
+		if format == "dot" {
+			return of.write(func(w io.Writer) error {
+				return writeNetworkDot(w, edges)
+			})
+		}
+
+		t := newTable(2, "Author", "Co-author", "Commits")
+		for _, e := range edges {
+			t.row(e.A, e.B, strconv.Itoa(e.Commits))
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var CommitSizesCmd = &Z.Cmd{
+	Name:    `commitsizes`,
+	Summary: `lists the number of commits per author in each size range`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--buckets BOUNDS] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
+	Aliases: []string{"cs"},
+	Description: `
+		The {{aka}} subcommand counts the commits of each author by size,
+		the size being the number of changed lines, telling many tiny
+		commits apart from a few huge ones. This is a richer view than the
+		single commit granularity of the 'summary' report.
+
+		The commits are counted in the size ranges 1-10, 11-100, 101-1000
+		and 1001+ lines, with a column for each. The --buckets flag sets the
+		upper bounds of the ranges as a comma-separated list of ascending
+		numbers, the last range holding all larger commits. The default is
+		10,100,1000. Commits without line changes, like merges and commits
+		only changing binary files, are not counted.
+
+		The --first-parent flag works as for the 'summary' command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		var af anonymizeFlags
+		var buckets string
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.StringVar(&buckets, "buckets", defaultBuckets, "comma-separated upper bounds of the size ranges")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		bounds, err := parseBuckets(buckets)
+		if err != nil {
+			return err
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			return fmt.Errorf("error reading commits: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		sizes := commitSizes(commits, bounds)
+		totals := make(map[string]int, len(sizes))
+		names := make([]string, 0, len(sizes))
+		for k, v := range sizes {
+			for _, n := range v {
+				totals[k] += n
+			}
+			names = append(names, k)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if totals[names[i]] != totals[names[j]] {
+				return totals[names[i]] > totals[names[j]]
+			}
+			return names[i] < names[j]
+		})
+
+		t := newTable(1, append([]string{"Author"}, bucketLabels(bounds)...)...)
+		an := af.pseudonyms()
+		for _, k := range names {
+			row := []string{an.name(k)}
+			for _, n := range sizes[k] {
+				row = append(row, strconv.Itoa(n))
+			}
+			t.row(row...)
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+// summaryFlags holds the flags shared by the commands outputting the
+// 'summary' report.
+type summaryFlags struct {
+	options
+	filterFlags
+	outputFlags
+	cacheFlags
+	anonymizeFlags
+	renderOptions
+	format    string
+	template  string
+	preset    string
+	timestamp string
+	halfLife  float64 // days
+	columns   string
+	scoreMode string
+	rankBy    string
+	weights   Weights
+
+	aggregateOnly bool
+
+	followRenames bool
+	submodules    bool
+	baseline      string
+	display       string
+	failOnEmpty   bool
+	maxAuthors    int
+
+	shortstat          bool
+	shortstatThreshold float64
+
+	compareTo string          // the previous report of --compare-to
+	previous  []AuthorSummary // its summaries, if so
+}
+
+func (sf *summaryFlags) register(fs *flag.FlagSet) {
+	sf.options.register(fs)
+	sf.filterFlags.register(fs)
+	sf.outputFlags.register(fs)
+	sf.cacheFlags.register(fs)
+	fs.BoolVar(&sf.submodules, "submodules", false, "include the contributions to the initialized submodules")
+	fs.BoolVar(&sf.decay, "decay", false, "add a column of recency-weighted line changes")
+	fs.Float64Var(&sf.halfLife, "half-life", 180, "days for the --decay weight to halve")
+	fs.BoolVar(&sf.files, "files", false, "add a column of distinct files touched")
+	fs.BoolVar(&sf.merges, "count-merges-separately", false, "add a column of merge commits")
+	fs.BoolVar(&sf.survive, "surviving", false, "add columns of the lines surviving in the code today, found by git blame")
+	fs.BoolVar(&sf.hunks, "complexity", false, "add columns of the hunks and the line changes weighted by them, found in the patches")
+	fs.BoolVar(&sf.followRenames, "follow-renames", false, "count the paths of a renamed file as one with --files and --breadth")
+	fs.BoolVar(&sf.breadth, "breadth", false, "add columns of the files changed per commit and the breadth score")
+	sf.weights = defaultWeights
+	fs.Var(&sf.weights, "breadth-weights", "weights of the --breadth score, like commits=1,files=2,churn=1")
+	fs.StringVar(&sf.display, "display", displayName, "label the authors by name, email or both")
+	fs.BoolVar(&sf.workingTree, "include-working-tree", false, "credit the uncommitted changes to the git user, marked with a *")
+	fs.BoolVar(&sf.failOnEmpty, "fail-on-empty", false, "fail when no author is reported, instead of writing an empty report")
+	fs.IntVar(&sf.maxAuthors, "max-authors", 0, "fail when more authors are reported, as when they are misgrouped")
+	fs.BoolVar(&sf.shortstat, "shortstat", false, "check the line changes against the totals of git log --shortstat")
+	fs.Float64Var(&sf.shortstatThreshold, "shortstat-threshold", defaultShortstatThreshold, "share of the line changes by which the --shortstat totals may differ")
+	sf.registerRender(fs)
+}
+
+// checkReported checks the number of authors reported in the summaries
+// against --fail-on-empty and --max-authors. It returns the error of the
+// analysis giving them, or ErrNoContributions with --fail-on-empty when no
+// author is reported, as when no commit is left to analyse, and
+// ErrTooManyAuthors with --max-authors when more authors are reported.
+func (sf *summaryFlags) checkReported(summaries []AuthorSummary, err error) error {
+	if err == nil && sf.maxAuthors > 0 && len(summaries) > sf.maxAuthors {
+		return fmt.Errorf("%w: %d reported, more than the %d of --max-authors; check the mailmap and --ignore-author",
+			ErrTooManyAuthors, len(summaries), sf.maxAuthors)
+	}
+
+	switch {
+	case !sf.failOnEmpty:
+		return err
+	case errors.Is(err, ErrNoCommits):
+		return ErrNoContributions
+	case err == nil && len(summaries) == 0 && !dryRun:
+		return ErrNoContributions
+	}
+	return err
+}
+
+// registerRender registers the flags only changing how the report is
+// rendered, for the commands getting their summaries without git.
+func (sf *summaryFlags) registerRender(fs *flag.FlagSet) {
+	sf.anonymizeFlags.register(fs)
+	fs.BoolVar(&sf.noFooter, "no-footer", false, "omit the lines following the table")
+	fs.BoolVar(&sf.noFooter, "quiet", false, "same as --no-footer")
+	fs.StringVar(&sf.format, "format", "table", "output format")
+	fs.StringVar(&sf.delimiter, "delimiter", " ", "separator of the cells of the plain format")
+	fs.StringVar(&sf.timestamp, "timestamp", "", "time of the influx points, as RFC 3339 or Unix seconds, instead of now")
+	fs.BoolVar(&sf.box, "box", false, "draw borders around the table")
+	fs.BoolVar(&sf.ascii, "ascii", false, "draw the --box borders with ASCII characters")
+	fs.BoolVar(&sf.grouped, "thousands", false, "group the digits of the counts of the table by thousands, like 3,897,123")
+	fs.StringVar(&sf.template, "template", "", "Go template executed per author")
+	fs.StringVar(&sf.preset, "preset", "", "built-in template executed per author, see --list-presets")
+	fs.BoolVar(&sf.extra, "ratios-extra", false, "add a column of deleted lines per added line")
+	fs.BoolVar(&sf.selfRatios, "self-ratios", false, "add columns of the shares of added and deleted lines in each author's own line changes")
+	fs.BoolVar(&sf.score, "score", false, "add a column of the line ratios as scores from 0 to 100")
+	fs.StringVar(&sf.scoreMode, "score-mode", scoreRelative, "scale the --score to the top author, relative, or to the sum of all, share")
+	fs.BoolVar(&sf.rank, "rank", false, "add a leading column of the ranks of the authors, tied authors sharing one")
+	fs.StringVar(&sf.rankBy, "rank-by", "commits", "rank and sort the authors by author, commits, additions, deletions or lines with --rank")
+	fs.StringVar(&sf.columns, "columns", "", "comma-separated columns to show, in this order")
+	fs.BoolVar(&sf.aggregateOnly, "aggregate-only", false, "only write the repo-level metrics, without the authors")
+}
+
+// resolve resolves the options like options.resolve, and checks the
+// rendering flags before the history is analysed.
+func (sf *summaryFlags) resolve() error {
+	if err := sf.options.resolve(); err != nil {
+		return err
+	}
+	if sf.shortstatThreshold < 0 {
+		return errors.New("--shortstat-threshold can't be negative")
+	}
+	if err := checkDisplay(sf.display); err != nil {
+		return err
+	}
+	if err := sf.resolveBots(); err != nil {
+		return err
+	}
+	return sf.resolveRender()
+}
+
+// emptyRepo returns the error of resolving the options like emptyRepo,
+// a repo without commits failing the report with --strict, and with
+// ErrNoContributions with --fail-on-empty.
+func (sf *summaryFlags) emptyRepo(err error) error {
+	if sf.strict || sf.failOnEmpty {
+		return sf.checkReported(nil, err)
+	}
+	return emptyRepo(err)
+}
+
+// emptyRepo returns the error of resolving the options of a report, or
+// nil for a repo without commits, after writing so to standard error, so
+// that its report ends right away without failing.
+func emptyRepo(err error) error {
+	if errors.Is(err, ErrNoCommits) {
+		fmt.Fprintln(os.Stderr, err)
+		return nil
+	}
+	return err
+}
+
+// resolvePreset replaces the --preset with its template.
+func (sf *summaryFlags) resolvePreset() error {
+	if sf.preset == "" {
+		return nil
+	}
+	if sf.template != "" {
+		return errors.New("--preset can't be combined with --template")
+	}
+
+	text, err := lookupPreset(sf.preset)
+	if err != nil {
+		return err
+	}
+	sf.template, sf.preset = text, ""
+	return nil
+}
+
+// resolveRender checks the flags of registerRender, like the --columns
+// list.
+func (sf *summaryFlags) resolveRender() error {
+	if err := sf.resolvePreset(); err != nil {
+		return err
+	}
+
+	if sf.columns != "" {
+		if _, err := selectColumns(sf.columns, sf.renderOptions); err != nil {
+			return err
+		}
+	}
+
+	if sf.delimiter == "" {
+		return errors.New("--delimiter can't be empty")
+	}
+
+	if err := checkScoreMode(sf.scoreMode); err != nil {
+		return err
+	}
+
+	if _, ok := sortOrders[sf.rankBy]; !ok {
+		return fmt.Errorf(
+			"invalid --rank-by %q, must be one of: %s",
+			sf.rankBy, strings.Join(sortKeyNames(), ", "),
+		)
+	}
+
+	if sf.timestamp != "" {
+		ts, err := parseTimestamp(sf.timestamp)
+		if err != nil {
+			return err
+		}
+		sf.renderOptions.timestamp = ts
+	}
+
+	return nil
+}
+
+// analyse returns the summaries of the history selected by the flags,
+// along with the repo-wide totals, and sets the repo name labelling the
+// prometheus and influx formats. A report computed before from the same
+// commits with the same flags is read from the cache instead, and new
+// reports are stored in it. Reports weighted by recency are not cached, as
+// their weights change with time.
+func (sf *summaryFlags) analyse() ([]AuthorSummary, Totals, error) {
+	if sf.format == "prometheus" || sf.format == "influx" {
+		var err error
+		sf.repo, err = getRepoDirName(sf.options)
+		if err != nil {
+			return nil, Totals{}, fmt.Errorf("error getting repo name: %w", err)
+		}
+	}
+
+	if sf.noCache || sf.decay || sf.submodules || sf.baseline != "" || sf.workingTree || sf.shortstat || sf.since != "" || sf.until != "" || dryRun {
+		return sf.compute()
+	}
+
+	c, err := sf.cache()
+	if err != nil {
+		debugf("not caching: %s", err)
+		return sf.compute()
+	}
+
+	key, err := sf.cacheKey()
+	if err != nil {
+		return nil, Totals{}, fmt.Errorf("error computing cache key: %w", err)
+	}
+
+	if r, ok := c.load(key); ok {
+		if err := checkRatios(r.Authors, r.Totals); err != nil {
+			debugf("ignoring cached report %s: %s", c.path(key), err)
+		} else {
+			debugf("using cached report %s", c.path(key))
+			return r.Authors, r.Totals, nil
+		}
+	}
+
+	summaries, totals, err := sf.compute()
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	if err := c.store(key, report{summaries, totals}); err != nil {
+		debugf("error writing cache: %s", err)
+	}
+
+	return summaries, totals, nil
+}
+
+// compute analyses the history selected by the flags.
+func (sf *summaryFlags) compute() ([]AuthorSummary, Totals, error) {
+	collect := sf.collect
+	if sf.submodules {
+		if sf.decay || sf.files || sf.breadth || sf.merges || sf.survive || sf.hunks || sf.format == "svg" {
+			return nil, Totals{}, errors.New("--submodules can't be combined with --decay, --files, --breadth, --count-merges-separately, --surviving, --complexity or the svg format")
+		}
+		collect = sf.collectSubmodules
+	}
+	if sf.baseline != "" {
+		if sf.submodules {
+			return nil, Totals{}, errors.New("--baseline can't be combined with --submodules")
+		}
+		collect = sf.collectIncremental
+	}
+	if sf.workingTree && (sf.submodules || sf.baseline != "") {
+		return nil, Totals{}, errors.New("--include-working-tree can't be combined with --submodules or --baseline")
+	}
+
+	summaries, totals, err := repoFor(sf.options).collect(collect)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	if sf.shortstat {
+		o := sf.options
+		o.failDrift = sf.strict
+		reported, err := sf.shortstatTotals(totals)
+		if err != nil {
+			return nil, Totals{}, err
+		}
+		if err := verifyShortstat(o, sf.shortstatThreshold, reported); err != nil {
+			return nil, Totals{}, fmt.Errorf("error checking against shortstat: %w", err)
+		}
+	}
+
+	if err := sf.extend(summaries); err != nil {
+		return nil, Totals{}, err
+	}
+
+	// the columns are looked up by name, so the authors are labelled last
+	marked, err := sf.uncommittedRow(summaries)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+	if err := sf.labelAuthors(summaries); err != nil {
+		return nil, Totals{}, err
+	}
+	if marked >= 0 {
+		summaries[marked].uncommitted = true
+	}
+
+	return summaries, totals, nil
+}
+
+// shortstatTotals returns the line changes of the totals of the report,
+// for --shortstat to check against its own, or nil when the flags make
+// them differ from those of the history selected: by skipping or capping
+// line changes, leaving authors out of the totals, recomputing them for
+// the reported authors, or adding uncommitted or submodule changes.
+func (sf *summaryFlags) shortstatTotals(totals Totals) (*LineChanges, error) {
+	o := sf.options
+	if o.maxCommitLines > 0 || o.capLinesPerFile > 0 || o.codeOnly || o.excludeReverts || len(o.attributes) > 0 ||
+		sf.recomputeRatios || sf.workingTree || sf.submodules {
+		return nil, nil
+	}
+
+	if !sf.ignoredInTotals {
+		ignored, err := sf.ignored(o)
+		if err != nil {
+			return nil, err
+		}
+		if len(ignored) > 0 {
+			return nil, nil
+		}
+	}
+
+	return &LineChanges{Additions: totals.Additions, Deletions: totals.Deletions}, nil
+}
+
+// summaryParams are the flags of the summary report changing its
+// numbers, keying its cache along with the analysed commits.
+type summaryParams struct {
+	FirstParent     bool
+	NoMailmap       bool
+	DateType        string
+	TZ              string
+	MaxCommitLines  int
+	ExcludeCommit   bool
+	CapLinesPerFile int
+	DiffFilter      string
+	CodeOnly        bool
+	CodeExts        []string
+	SquashAuthor    string
+	ExcludeReverts  bool
+	ExcludeReverted bool
+	Gitattributes   []string
+	Roots           []string
+	UnknownAuthor   string
+	Display         string
+	Paths           []string
+	Follow          bool
+	Author          string
+	MinCommits      int
+	RecomputeRatios bool
+	IgnoreAuthors   []string
+	IgnoreFile      []string
+	IgnoredInTotals bool
+	Teams           teams
+	TeamsOnly       bool
+	CollapseBots    bool
+	BotPattern      string
+	IncludeDomains  []string
+	ExcludeDomains  []string
+	DropNoEmail     bool
+	Files           bool
+	Breadth         bool
+	Weights         Weights
+	Merges          bool
+	Surviving       bool
+	Complexity      bool
+	FollowRenames   bool
+	Activity        bool
+	Strict          bool
+	NormalizeNames  bool
+	Identities      identityMap
+}
+
+// cacheKey returns the cache key of the report selected by the flags, made
+// from the analysed commits and every flag and file changing the report.
+func (sf *summaryFlags) cacheKey() (string, error) {
+	commits, err := resolveRev(sf.options)
+	if err != nil {
+		return "", err
+	}
+
+	globs, err := readIgnoreFile(sf.options)
+	if err != nil {
+		return "", err
+	}
+
+	var identities identityMap
+	if sf.identityFile != "" {
+		identities, err = readIdentityMap(sf.identityFile)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return cacheKey(sf.options, commits, summaryParams{
+		FirstParent:     sf.firstParent,
+		NoMailmap:       sf.noMailmap,
+		DateType:        sf.dateType,
+		TZ:              sf.tz,
+		MaxCommitLines:  sf.maxCommitLines,
+		ExcludeCommit:   sf.excludeCommit,
+		CapLinesPerFile: sf.capLinesPerFile,
+		DiffFilter:      sf.diffFilter,
+		CodeOnly:        sf.codeOnly,
+		CodeExts:        sf.codeExts,
+		SquashAuthor:    sf.squashAuthor,
+		ExcludeReverts:  sf.excludeReverts,
+		ExcludeReverted: sf.excludeReverted,
+		Gitattributes:   sf.attributes.lines(),
+		Roots:           sf.roots,
+		UnknownAuthor:   sf.unknownAuthor,
+		Display:         sf.display,
+		Paths:           sf.paths,
+		Follow:          sf.follow,
+		Author:          sf.author,
+		MinCommits:      sf.minCommits,
+		RecomputeRatios: sf.recomputeRatios,
+		IgnoreAuthors:   sf.ignoreAuthors,
+		IgnoreFile:      globs,
+		IgnoredInTotals: sf.ignoredInTotals,
+		Teams:           sf.teams,
+		TeamsOnly:       sf.teamsOnly,
+		CollapseBots:    sf.collapseBots,
+		BotPattern:      sf.botPattern,
+		IncludeDomains:  sf.domains.include,
+		ExcludeDomains:  sf.domains.exclude,
+		DropNoEmail:     sf.domains.dropNoEmail,
+		Files:           sf.files,
+		Breadth:         sf.breadth,
+		Weights:         sf.weights,
+		Merges:          sf.merges,
+		Surviving:       sf.survive,
+		Complexity:      sf.hunks,
+		FollowRenames:   sf.followRenames,
+		Activity:        sf.format == "svg" && sf.template == "",
+		Strict:          sf.strict,
+		NormalizeNames:  sf.normalize,
+		Identities:      identities,
+	})
+}
+
+// extend sets the columns of the summaries computed from the individual
+// commits, when asked for by --decay, --files or --breadth, and the
+// monthly activity drawn by the svg format, along with the merge commits
+// when asked for by --count-merges-separately.
+func (sf *summaryFlags) extend(summaries []AuthorSummary) error {
+	if sf.merges {
+		if err := sf.countMerges(summaries); err != nil {
+			return err
+		}
+	}
+
+	if sf.survive {
+		if err := sf.countSurviving(summaries); err != nil {
+			return err
+		}
+	}
+
+	if sf.hunks {
+		if err := sf.countHunks(summaries); err != nil {
+			return err
+		}
+	}
+
+	activity := sf.format == "svg" && sf.template == ""
+	if !sf.decay && !sf.files && !sf.breadth && !activity {
+		return nil
+	}
+
+	halfLife := time.Duration(sf.halfLife * float64(24*time.Hour))
+	if sf.decay && halfLife <= 0 {
+		return errors.New("--half-life must be positive")
+	}
+
+	commits, err := logCommits(sf.options)
+	if err != nil {
+		return fmt.Errorf("error reading commits: %w", err)
+	}
+
+	ids, err := sf.identities(sf.options)
+	if err != nil {
+		return err
+	}
+
+	for i, c := range commits {
+		commits[i].Author = sf.rowName(ids.name(c.Author))
+	}
+
+	var weighted map[string]float64
+	if sf.decay {
+		weighted = weighCommits(commits, halfLife, time.Now())
+	}
+
+	var files map[string]int
+	if sf.files {
+		files = countFilesTouched(commits, sf.followRenames)
+	}
+
+	var stats map[string]AuthorStats
+	var perCommit map[string]float64
+	if sf.breadth {
+		stats, perCommit = breadthStats(commits, sf.followRenames)
+	}
+
+	var months map[string][]int
+	if activity {
+		months = monthlyCommits(commits)
+	}
+
+	for i, s := range summaries {
+		summaries[i].Weighted = weighted[s.Author]
+		summaries[i].FilesTouched = files[s.Author]
+		summaries[i].Activity = months[s.Author]
+		if sf.breadth {
+			summaries[i].FilesPerCommit = perCommit[s.Author]
+			summaries[i].Breadth = BreadthScore(stats[s.Author], sf.weights)
+		}
+	}
+
+	return nil
+}
+
+// uncommittedRow returns the index of the summary the uncommitted changes
+// of --include-working-tree were credited to, or -1 when there were none
+// or the row was left out. Their line changes are logged, as a note on
+// the row marked with a *.
+func (sf *summaryFlags) uncommittedRow(summaries []AuthorSummary) (int, error) {
+	u := sf.uncommitted
+	if u.Author == "" {
+		return -1, nil
+	}
+
+	ids, err := sf.identities(sf.options)
+	if err != nil {
+		return -1, err
+	}
+
+	name := sf.rowName(ids.name(u.Author))
+	for i, s := range summaries {
+		if s.Author == name {
+			logf(slog.LevelInfo, "* %s includes %d added and %d deleted uncommitted lines",
+				name, u.Additions, u.Deletions)
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// labelAuthors relabels the authors of the summaries with their emails,
+// when asked for by --display. The authors are still grouped by name, each
+// shown with the email they made most commits with.
+func (sf *summaryFlags) labelAuthors(summaries []AuthorSummary) error {
+	if sf.display == "" || sf.display == displayName {
+		return nil
+	}
+
+	counts, order, err := authorEmailCounts(sf.options)
+	if err != nil {
+		return fmt.Errorf("error reading emails: %w", err)
+	}
+
+	ids, err := sf.identities(sf.options)
+	if err != nil {
+		return err
+	}
+
+	setDisplay(summaries, representativeEmails(counts, order, ids), sf.display)
+	return nil
+}
+
+// countMerges sets the merge commits of the summaries, counted by a
+// separate walk over the merge commits only, as the commit counts leave
+// them out.
+func (sf *summaryFlags) countMerges(summaries []AuthorSummary) error {
+	merges, err := mergeCommits(sf.options)
+	if err != nil {
+		return fmt.Errorf("error counting merge commits: %w", err)
+	}
+
+	if err := sf.mergeAuthors(merges); err != nil {
+		return err
+	}
+
+	for i, s := range summaries {
+		summaries[i].Merges = merges[s.Author]
+	}
+
+	return nil
+}
+
+// countSurviving sets the lines of the summaries surviving in the code at
+// the end of the history, along with their share of all surviving lines.
+func (sf *summaryFlags) countSurviving(summaries []AuthorSummary) error {
+	lines, err := survivingLines(sf.options)
+	if err != nil {
+		return fmt.Errorf("error counting surviving lines: %w", err)
+	}
+
+	if err := sf.mergeAuthors(lines); err != nil {
+		return err
+	}
+
+	var total int
+	for _, n := range lines {
+		total += n
+	}
+
+	for i, s := range summaries {
+		summaries[i].Surviving = lines[s.Author]
+		summaries[i].Footprint = ratio(lines[s.Author], total)
+	}
+
+	return nil
+}
+
+// countHunks sets the hunks of the summaries, along with their line
+// changes weighted by the hunks of each commit, read from the patches of
+// the commits in a separate walk.
+func (sf *summaryFlags) countHunks(summaries []AuthorSummary) error {
+	commits, err := authorHunks(sf.options)
+	if err != nil {
+		return fmt.Errorf("error counting hunks: %w", err)
+	}
+
+	ids, err := sf.identities(sf.options)
+	if err != nil {
+		return err
+	}
+
+	hunks := make(map[string]int)
+	complexity := make(map[string]float64)
+	for _, c := range commits {
+		name := sf.rowName(ids.name(c.Author))
+		hunks[name] += c.Hunks
+		complexity[name] += c.Complexity()
+	}
+
+	for i, s := range summaries {
+		summaries[i].Hunks = hunks[s.Author]
+		summaries[i].Complexity = complexity[s.Author]
+	}
+
+	return nil
+}
+
+// mergeAuthors merges the authors of the counts by the identities, teams
+// and bots of the flags, like the rows of the report.
+func (sf *summaryFlags) mergeAuthors(counts map[string]int) error {
+	ids, err := sf.identities(sf.options)
+	if err != nil {
+		return err
+	}
+	ids.merge(counts, nil)
+	sf.teams.merge(counts, nil)
+	sf.mergeBots(counts, nil)
+	return nil
+}
+
+// register adds the flags selecting the analysed history to fs.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.dir, "repo", "", "analyse the repo in this directory instead of the current one")
+	fs.StringVar(&o.repoName, "repo-name", "", "name the repo by this in CSV rows and metric labels instead of by its directory")
+	fs.StringVar(&o.rev, "branch", "", "analyse the given branch or ref instead of the checked out branch")
+	fs.BoolVar(&o.firstParent, "first-parent", false, "only follow the first parent of merge commits")
+	fs.BoolVar(&o.defaultBranch, "default-branch", false, "analyse the default branch of origin instead of the checked out branch")
+	fs.BoolVar(&o.allBranches, "all-branches", false, "analyse the commits of all branches, each counted once")
+	fs.Var(&o.commitList, "commits", "only analyse these comma-separated commits (repeatable)")
+	fs.StringVar(&o.commitsFile, "commits-file", "", "only analyse the commits listed in this file, one per line")
+	fs.Var(&o.sinceTag, "since-tag", "only analyse the commits after the most recent tag, or after the tag given as --since-tag=TAG")
+	fs.StringVar(&o.since, "since", "", "only analyse commits more recent than this date")
+	fs.StringVar(&o.until, "until", "", "only analyse commits older than this date")
+	fs.StringVar(&o.asOf, "as-of", "", "analyse the repo as of the last commit before this date, history and files alike")
+	fs.Var(&o.paths, "path", "only analyse the commits touching this path (repeatable)")
+	fs.BoolVar(&o.follow, "follow", false, "follow a single --path across renames")
+	fs.BoolVar(&o.noMailmap, "no-mailmap", false, "report authors as committed, without mapping them through .mailmap")
+	fs.StringVar(&o.dateType, "date-type", "author", "date commits by when they were authored or committed, author or committer")
+	fs.StringVar(&o.tz, "tz", "", "convert all dates to this time zone, like UTC, Local or America/New_York")
+	fs.IntVar(&o.maxCommitLines, "max-commit-lines", 0, "skip the line changes of commits changing more lines")
+	fs.BoolVar(&o.excludeCommit, "exclude-commit", false, "skip the commits of --max-commit-lines altogether")
+	fs.IntVar(&o.capLinesPerFile, "cap-lines-per-file", 0, "count at most this many additions of each file of a commit")
+	fs.StringVar(&o.diffFilter, "diff-filter", "", "only count the line changes of these kinds of file changes, like A for added files")
+	fs.BoolVar(&o.codeOnly, "code-only", false, "only count the commits touching at least one code file")
+	fs.Var(&o.codeExts, "code-ext", "the comma-separated extensions of the code files of --code-only (repeatable)")
+	fs.StringVar(&o.squashAuthor, "squash-author", "author", "credit squash merges to their author, committer or the authors in their body")
+	fs.BoolVar(&o.excludeFirstCommit, "exclude-first-commit", false, "leave out the root commits, like initial imports")
+	fs.BoolVar(&o.excludeReverts, "exclude-reverts", false, "skip the line changes of revert commits")
+	fs.BoolVar(&o.excludeReverted, "exclude-reverted", false, "also skip the line changes of the commits reverted, with --exclude-reverts")
+	fs.BoolVar(&o.respectGitattributes, "respect-gitattributes", false, "skip the line changes of the files marked generated or vendored in .gitattributes")
+	fs.StringVar(&o.unknownAuthor, "unknown-author", defaultUnknownAuthor, "label of the commits without an author name")
+}
+
+// filterFlags holds the flags selecting which authors are reported.
+type filterFlags struct {
+	author          string
+	minCommits      int
+	recomputeRatios bool
+	ignoreAuthors   stringList
+	ignoredInTotals bool
+	teams           teams
+	teamsOnly       bool
+	collapseBots    bool
+	botPattern      string
+	bots            *regexp.Regexp // the compiled botPattern, with collapseBots
+	domains         domainFilter
+	strict          bool
+	normalize       bool
+	identityFile    string
+
+	// workingTree credits the uncommitted changes to the git user, who
+	// collect sets uncommitted to when there are any.
+	workingTree bool
+	uncommitted uncommitted
+}
+
+func (ff *filterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&ff.author, "author", "", "only report authors matching this regular expression")
+	fs.IntVar(&ff.minCommits, "min-commits", 0, "omit authors with fewer commits")
+	fs.BoolVar(&ff.recomputeRatios, "recompute-ratios", false, "compute ratios over the reported authors only")
+	fs.Var(&ff.ignoreAuthors, "ignore-author", "leave out authors whose name or email matches this glob (repeatable)")
+	fs.BoolVar(&ff.ignoredInTotals, "ignored-in-totals", false, "keep ignored authors in the totals")
+	fs.Var(&ff.teams, "team", "report the listed authors as one, given as NAME=AUTHOR,AUTHOR (repeatable)")
+	fs.BoolVar(&ff.teamsOnly, "teams-only", false, "only report the --team rows")
+	fs.BoolVar(&ff.collapseBots, "collapse-bots", false, "report all bots as a single bots row")
+	fs.StringVar(&ff.botPattern, "bot-pattern", "", "regular expression matching the names of the bots of --collapse-bots")
+	fs.Var(&ff.domains.include, "include-domain", "only report authors with an email in this domain (repeatable)")
+	fs.Var(&ff.domains.exclude, "exclude-domain", "leave out authors with an email in this domain (repeatable)")
+	fs.BoolVar(&ff.domains.dropNoEmail, "drop-no-email", false, "leave out authors without a valid email")
+	fs.BoolVar(&ff.normalize, "normalize-names", false, "merge author names differing only in case or whitespace")
+	fs.StringVar(&ff.identityFile, "identity-map", "", "merge the names and emails of each person listed in this YAML file")
+	fs.BoolVar(&ff.strict, "strict", false, "fail when the authors of commit counts and line changes differ, the line changes are bad, or the ratios of a report read don't match its totals")
+}
+
+// ignored returns the names of the authors to leave out of the history
+// selected by o, as matched by the --ignore-author flags and the ignore
+// file of the repo, or left out by the domain of their emails.
+func (ff *filterFlags) ignored(o options) (map[string]bool, error) {
+	globs, err := readIgnoreFile(o)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", ignoreFile, err)
+	}
+	globs = append(globs, ff.ignoreAuthors...)
+
+	if len(globs) == 0 && !ff.domains.active() {
+		return make(map[string]bool), nil
+	}
+
+	emails, err := authorEmails(o)
+	if err != nil {
+		return nil, fmt.Errorf("error matching ignored authors: %w", err)
+	}
+
+	ignored := ignoredAuthors(emails, compileAuthorPatterns(globs))
+	for name := range ff.domains.filtered(emails) {
+		ignored[name] = true
+	}
+
+	return ignored, nil
+}
+
+// identities returns the authors of the history selected by o mapped to
+// their canonical name by the --identity-map file, or none without the
+// flag.
+func (ff *filterFlags) identities(o options) (teams, error) {
+	if ff.identityFile == "" {
+		return nil, nil
+	}
+
+	im, err := readIdentityMap(ff.identityFile)
+	if err != nil {
+		return nil, err
+	}
+
+	emails, err := authorEmails(o)
+	if err != nil {
+		return nil, fmt.Errorf("error matching identities: %w", err)
+	}
+
+	return im.identities(emails)
+}
+
+// authorRegexp returns the compiled --author expression, matching all
+// authors when the flag is not given.
+func (ff *filterFlags) authorRegexp() (*regexp.Regexp, error) {
+	re, err := regexp.Compile(ff.author)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --author expression: %w", err)
+	}
+	return re, nil
+}
+
+// filterMaps deletes the authors not selected by the flags from the given
+// maps, either of which may be nil. The commit counts needed for
+// --min-commits are fetched when no commitMap is given.
+func (ff *filterFlags) filterMaps(
+	o options,
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) error {
+
+	re, err := ff.authorRegexp()
+	if err != nil {
+		return err
+	}
+	if err := ff.resolveBots(); err != nil {
+		return err
+	}
+
+	ignored, err := ff.ignored(o)
+	if err != nil {
+		return err
+	}
+
+	ids, err := ff.identities(o)
+	if err != nil {
+		return err
+	}
+
+	dropAuthors(ignored, commitMap, lineChangesMap)
+	ids.merge(commitMap, lineChangesMap)
+	variants := ff.normalizeNames(commitMap, lineChangesMap)
+	ff.mergeTeams(commitMap, lineChangesMap)
+	dropUnmatched(re, commitMap, lineChangesMap)
+
+	if ff.minCommits > 0 {
+		if commitMap == nil {
+			commitMap, err = authorCommits(o)
+			if err != nil {
+				return fmt.Errorf("error extracting commit counts: %w", err)
+			}
+			dropAuthors(ignored, commitMap, nil)
+			ids.merge(commitMap, nil)
+			variants.merge(commitMap, nil)
+			ff.mergeTeams(commitMap, nil)
+		}
+		dropMinCommits(ff.minCommits, commitMap, lineChangesMap)
+	}
+
+	return nil
+}
+
+// mergeTeams merges the authors of each --team into a single entry of the
+// given maps, either of which may be nil, and the bots with
+// --collapse-bots. With --teams-only, the authors not in any team are
+// deleted.
+func (ff *filterFlags) mergeTeams(
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) {
+	ff.teams.merge(commitMap, lineChangesMap)
+	ff.mergeBots(commitMap, lineChangesMap)
+	if !ff.teamsOnly {
+		return
+	}
+
+	for k := range commitMap {
+		if !ff.teams.has(k) {
+			delete(commitMap, k)
+		}
+	}
+	for k := range lineChangesMap {
+		if !ff.teams.has(k) {
+			delete(lineChangesMap, k)
+		}
+	}
+}
+
+// collect returns the 'summary' report of the authors selected by the
+// flags, over the history selected by o.
+func (ff *filterFlags) collect(o options) ([]AuthorSummary, Totals, error) {
+	o.failDrift = ff.strict
+
+	commitMap, err := authorCommits(o)
+	if err != nil {
+		return nil, Totals{}, fmt.Errorf("error extracting commit counts: %w", err)
+	}
+
+	lineChangesMap, err := mapLineChanges(o)
+	if err != nil {
+		return nil, Totals{}, fmt.Errorf("error extracting line changes: %w", err)
+	}
+
+	if ff.workingTree {
+		ff.uncommitted, err = readUncommitted(o)
+		if err != nil {
+			return nil, Totals{}, err
+		}
+		if u := ff.uncommitted; u.Author != "" {
+			// listed in both maps, as --strict expects
+			commitMap[u.Author] += 0
+			lineChangesMap[u.Author] = lineChangesMap[u.Author].Merge(u.LineChanges)
+		}
+	}
+
+	if ff.strict {
+		if err := checkAuthors(commitMap, lineChangesMap); err != nil {
+			return nil, Totals{}, err
+		}
+	}
+
+	summaries, totals, err := ff.summarize(o, commitMap, lineChangesMap)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	totals.First, totals.Last, err = dateSpan(o)
+	if err != nil {
+		return nil, Totals{}, fmt.Errorf("error getting date span: %w", err)
+	}
+
+	return summaries, totals, nil
+}
+
+// collectIncremental returns the 'summary' report of the authors selected
+// by the flags like collect, only analysing the commits added since the
+// baseline of --baseline.
+func (sf *summaryFlags) collectIncremental(o options) ([]AuthorSummary, Totals, error) {
+	if err := o.checkBaseline(); err != nil {
+		return nil, Totals{}, err
+	}
+
+	o.failDrift = sf.strict
+	b, err := incremental(o, sf.baseline)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	if sf.strict {
+		if err := checkAuthors(b.Commits, b.LineChanges); err != nil {
+			return nil, Totals{}, err
+		}
+	}
+
+	summaries, totals, err := sf.summarize(o, b.Commits, b.LineChanges)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+	totals.First, totals.Last = b.First, b.Last
+
+	return summaries, totals, nil
+}
+
+// collectSubmodules returns the 'summary' report of the authors selected
+// by the flags, over the history selected by o together with the checked
+// out commits of its initialized submodules. The authors of a submodule
+// are labelled by prefixing its path, like "vendor/lib: Author", and the
+// totals cover all of them.
+func (ff *filterFlags) collectSubmodules(o options) ([]AuthorSummary, Totals, error) {
+	top, err := o.git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, Totals{}, fmt.Errorf("error finding top-level directory: %w", err)
+	}
+	o.dir = strings.TrimSpace(top)
+
+	paths, err := listSubmodules(o)
+	if err != nil {
+		return nil, Totals{}, fmt.Errorf("error listing submodules: %w", err)
+	}
+
+	commitMap := make(map[string]int)
+	lineChangesMap := make(map[string]LineChanges)
+	ignored := make(map[string]bool)
+	var totals Totals
+
+	for _, path := range append([]string{""}, paths...) {
+		po, prefix := o, ""
+		if path != "" {
+			po = options{
+				dir:         filepath.Join(o.dir, path),
+				firstParent: o.firstParent,
+				noMailmap:   o.noMailmap,
+				dateType:    o.dateType,
+			}
+			prefix = path + ": "
+		}
+
+		commits, err := authorCommits(po)
+		if err != nil {
+			return nil, Totals{}, fmt.Errorf("error extracting commit counts of %s: %w", po.dir, err)
+		}
+
+		lineChanges, err := mapLineChanges(po)
+		if err != nil {
+			return nil, Totals{}, fmt.Errorf("error extracting line changes of %s: %w", po.dir, err)
+		}
+
+		if ff.strict {
+			if err := checkAuthors(commits, lineChanges); err != nil {
+				return nil, Totals{}, fmt.Errorf("%s: %w", po.dir, err)
+			}
+		}
+
+		ign, err := ff.prepare(po, commits, lineChanges)
+		if err != nil {
+			return nil, Totals{}, err
+		}
+
+		for k, v := range commits {
+			commitMap[prefix+k] += v
+		}
+		for k, v := range lineChanges {
+			lineChangesMap[prefix+k] = lineChangesMap[prefix+k].Merge(v)
+		}
+		for k := range ign {
+			ignored[prefix+k] = true
+		}
+
+		first, last, err := dateSpan(po)
+		if err != nil {
+			return nil, Totals{}, fmt.Errorf("error getting date span of %s: %w", po.dir, err)
+		}
+		if totals.First.IsZero() || first.Before(totals.First) {
+			totals.First = first
+		}
+		if last.After(totals.Last) {
+			totals.Last = last
+		}
+	}
+
+	summaries, t, err := ff.report(ignored, commitMap, lineChangesMap)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+	t.First, t.Last = totals.First, totals.Last
+
+	return summaries, t, nil
+}
+
+// summarize returns the summaries of the authors selected by the flags,
+// in the history selected by o. Unless ratios are recomputed, the ratios
+// and totals cover all authors but the ignored ones, which are only
+// covered with --ignored-in-totals.
+func (ff *filterFlags) summarize(
+	o options,
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) ([]AuthorSummary, Totals, error) {
+
+	ignored, err := ff.prepare(o, commitMap, lineChangesMap)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	return ff.report(ignored, commitMap, lineChangesMap)
+}
+
+// prepare deletes the ignored authors of the history selected by o from
+// the given maps, unless they are kept in the totals, and merges the
+// identities, name variants, teams and bots. The ignored authors are
+// returned, to be left out of the report.
+func (ff *filterFlags) prepare(
+	o options,
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) (map[string]bool, error) {
+
+	if err := ff.resolveBots(); err != nil {
+		return nil, err
+	}
+
+	ignored, err := ff.ignored(o)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := ff.identities(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ff.ignoredInTotals {
+		dropAuthors(ignored, commitMap, lineChangesMap)
+	}
+
+	ids.merge(commitMap, lineChangesMap)
+	variants := ff.normalizeNames(commitMap, lineChangesMap)
+	for _, merged := range []teams{ids, variants} {
+		for variant, display := range merged {
+			if ignored[variant] {
+				ignored[display] = true
+			}
+		}
+	}
+
+	ff.teams.merge(commitMap, lineChangesMap)
+	ff.mergeBots(commitMap, lineChangesMap)
+
+	return ignored, nil
+}
+
+// normalizeNames merges the author names of the given maps differing only
+// in case or whitespace into their most frequent variant, when asked for
+// by --normalize-names, and returns the merged variants. Either map may be
+// nil.
+func (ff *filterFlags) normalizeNames(
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) teams {
+
+	if !ff.normalize {
+		return nil
+	}
+
+	variants := nameVariants(commitMap, lineChangesMap)
+	variants.merge(commitMap, lineChangesMap)
+	return variants
+}
+
+// report returns the summaries of the authors of the prepared maps
+// selected by the flags, leaving out the ignored ones.
+func (ff *filterFlags) report(
+	ignored map[string]bool,
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) ([]AuthorSummary, Totals, error) {
+
+	re, err := ff.authorRegexp()
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	summaries, totals, err := Summarize(commitMap, lineChangesMap)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	summaries = filterSummaries(summaries, func(s AuthorSummary) bool {
+		return !ignored[s.Author] && (!ff.teamsOnly || ff.teams.has(s.Author))
+	})
+	summaries = filterAuthors(summaries, re)
+	summaries = filterMinCommits(summaries, ff.minCommits)
+
+	if ff.recomputeRatios {
+		summaries, totals = Relativize(summaries)
+	}
+
+	return summaries, totals, nil
+}
+
+// reportError writes err to standard error as a JSON object with the
+// code of its kind for the json, pretty-json and ndjson formats, so that
+// scripts can tell what failed, and returns errReported. For the other
+// formats, err is returned as is.
+func (sf *summaryFlags) reportError(err error) error {
+	var ue *usageError
+	if err == nil || errors.Is(err, flag.ErrHelp) || errors.As(err, &ue) {
+		return err
+	}
+	if sf.template != "" || sf.format != "json" && sf.format != "pretty-json" && sf.format != "ndjson" {
+		return err
+	}
+
+	if werr := writeErrorJSON(os.Stderr, err); werr != nil {
+		return err
+	}
+
+	return errReported
+}
+
+// load returns the summaries and totals of the json or ndjson report in
+// the named file, or on standard input when the name is "-", filtered by
+// the flags. The columns added by --decay and --files are shown when the
+// report has them.
+func (sf *summaryFlags) load(name string) ([]AuthorSummary, Totals, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, Totals{}, fmt.Errorf("error opening input: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	summaries, totals, err := readReport(r)
+	if err != nil {
+		return nil, Totals{}, fmt.Errorf("error reading %s: %w", name, err)
+	}
+	if sf.strict {
+		if err := checkRatios(summaries, totals); err != nil {
+			return nil, Totals{}, fmt.Errorf("error reading %s: %w", name, err)
+		}
+	}
+
+	re, err := sf.authorRegexp()
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	summaries = filterAuthors(summaries, re)
+	summaries = filterMinCommits(summaries, sf.minCommits)
+
+	if sf.recomputeRatios {
+		first, last := totals.First, totals.Last
+		summaries, totals = Relativize(summaries)
+		totals.First, totals.Last = first, last
+	}
+
+	for _, s := range summaries {
+		sf.decay = sf.decay || s.Weighted != 0
+		sf.files = sf.files || s.FilesTouched != 0
+		sf.breadth = sf.breadth || s.Breadth != 0
+		sf.merges = sf.merges || s.Merges != 0
+		sf.survive = sf.survive || s.Surviving != 0
+		sf.hunks = sf.hunks || s.Hunks != 0
+	}
+
+	return summaries, totals, nil
+}
+
+// loadPrevious reads the summaries of the report of --compare-to, filtered
+// like the current ones by --author and --min-commits. The changes are
+// only shown as a table.
+func (sf *summaryFlags) loadPrevious() error {
+	switch {
+	case sf.template != "" || sf.preset != "" || sf.columns != "" || sf.aggregateOnly:
+		return errors.New("--compare-to can't be combined with --template, --preset, --columns or --aggregate-only")
+	case sf.format != "table":
+		return fmt.Errorf("--compare-to can't be used with the %s format", sf.format)
+	}
+
+	f, err := os.Open(sf.compareTo)
+	if err != nil {
+		return fmt.Errorf("error opening previous report: %w", err)
+	}
+	defer f.Close()
+
+	summaries, totals, err := readReport(f)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", sf.compareTo, err)
+	}
+	if sf.strict {
+		if err := checkRatios(summaries, totals); err != nil {
+			return fmt.Errorf("error reading %s: %w", sf.compareTo, err)
+		}
+	}
+
+	re, err := sf.authorRegexp()
+	if err != nil {
+		return err
+	}
+
+	sf.previous = filterMinCommits(filterAuthors(summaries, re), sf.minCommits)
+	return nil
+}
+
+// render writes the summaries to w as selected by the flags, with the
+// author names replaced by pseudonyms when anonymizing.
+func (sf *summaryFlags) render(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+) error {
+
+	if sf.anonymize {
+		anonymize(summaries, sf.key)
+	}
+
+	if sf.score {
+		setScores(summaries, sf.scoreMode)
+	}
+
+	if sf.rank {
+		if err := setRanks(summaries, sf.rankBy); err != nil {
+			return err
+		}
+	}
+
+	if sf.compareTo != "" {
+		return writeDeltaTable(w, diffReports(sf.previous, summaries))
+	}
+
+	if sf.aggregateOnly {
+		if sf.template != "" || sf.columns != "" {
+			return errors.New("--aggregate-only can't be used with --template or --columns")
+		}
+		return writeRepoMetrics(w, Aggregate(summaries, totals), sf.format)
+	}
+
+	if sf.template != "" {
+		if sf.columns != "" {
+			return errors.New("--columns can't be used with --template")
+		}
+		tmpl, err := parseTemplate(sf.template)
+		if err != nil {
+			return err
+		}
+		return executeTemplate(w, tmpl, summaries, totals)
+	}
+
+	r, ok := renderers[sf.format]
+	if !ok {
+		return fmt.Errorf(
+			"unknown format %q, must be one of: %s",
+			sf.format, strings.Join(formatNames(), ", "),
+		)
+	}
+
+	if sf.format == "xlsx" && sf.output == "" {
+		return errors.New("the xlsx format needs a file to write to, given by --output")
+	}
+
+	if sf.columns != "" {
+		switch sf.format {
+		case "table", "org", "plain", "json", "pretty-json", "ndjson", "yaml", "markdown-details":
+		default:
+			return fmt.Errorf("--columns can't be used with the %s format", sf.format)
+		}
+
+		var err error
+		sf.renderOptions.columns, err = selectColumns(sf.columns, sf.renderOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return r(w, summaries, totals, sf.renderOptions)
+}
+
+var CompareCmd = &Z.Cmd{
+	Name:    `compare`,
+	Summary: `lists the 'summary' metrics of two authors side by side`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--recompute-ratios] [--anonymize [--key KEY]] [--output FILE] [--clipboard] AUTHOR1 AUTHOR2`,
+	Description: `
+		The {{aka}} subcommand compares two authors head to head, listing
+		each metric of the 'summary' report on a row of its own, with the
+		values of both authors side by side, followed by the difference of
+		the first to the second. The ratios are relative to all authors,
+		unless --recompute-ratios makes them relative to the two only.
+
+		Each author is given as a regular expression matched against the
+		author names, ignoring case, so that a part of the name is enough.
+		An author named exactly like the expression is chosen even when it
+		matches others too. It is an error if an expression matches no
+		author or several, which are then listed, or if both match the same
+		author.
+
+		Example:
+
+		    gitcontrib compare alice bob
+
+		Metrics say little about the value of the work of a person, so use
+		the comparison with care, as a starting point for a conversation
+		rather than a verdict.
+
+		The flags selecting the history and grouping authors, like --since,
+		--ignore-author and --team, work as for the 'summary' command, so
+		that teams can be compared as well.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		var af anonymizeFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		af.register(fs)
+		if err := parseFlags(x, fs, args, 2); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		recompute := ff.recomputeRatios
+		ff.recomputeRatios = false
+		summaries, _, err := ff.collect(o)
+		if err != nil {
+			return err
+		}
+
+		a, err := findAuthor(summaries, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		b, err := findAuthor(summaries, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		if a.Author == b.Author {
+			return fmt.Errorf("%q and %q both match %s", fs.Arg(0), fs.Arg(1), a.Author)
+		}
+
+		if recompute {
+			pair, _ := Relativize([]AuthorSummary{a, b})
+			a, b = pair[0], pair[1]
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		an := af.pseudonyms()
+		a.Author, b.Author = an.name(a.Author), an.name(b.Author)
+
+		rows := compareRows(a, b)
+		t := newTable(1, rows[0]...)
+		for _, row := range rows[1:] {
+			t.row(row...)
+		}
+
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var CompareReposCmd = &Z.Cmd{
+	Name:    `compare-repos`,
+	Summary: `lists the repo-level metrics of two repos side by side`,
+	Usage:   `[--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--output FILE] [--clipboard] PATH_A PATH_B`,
+	Description: `
+		The {{aka}} subcommand compares two repos as a whole, like two
+		candidate implementations of a library or a fork and its upstream,
+		rather than their authors like 'compare'. It analyses the repos in
+		the directories PATH_A and PATH_B like the 'summary' command with
+		--repo, and lists their repo-level metrics, those of --aggregate-only,
+		each on a row of its own, with the values of both repos side by side,
+		followed by the difference of the first to the second:
+
+		    Commits       total of the non-merge commits
+		    Contributors  number of authors
+		    Bus Factor    fewest authors making half the line changes
+		    Additions     number of added lines
+		    Deletions     number of deleted lines
+		    Churn         added and deleted lines together
+		    Granularity   overall repo commit granularity
+		    Gini          Gini coefficient of the line changes
+		    First Commit  date of the first commit
+		    Last Commit   date of the last commit
+		    Days          days from the first to the last commit
+
+		The repos are named after their directory, or after the paths as
+		given when both directories have the same name. A repo that can't be
+		analysed, like one without commits or a directory that isn't a repo,
+		has its error reported and its column left blank, rather than ending
+		the comparison, which only fails when neither repo can be analysed.
+
+		The flags selecting the history and the authors, like --since and
+		--author, apply to both repos, like for the 'summary' command.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		if err := parseFlags(x, fs, args, 2); err != nil {
+			return err
+		}
+
+		if o.dir != "" || o.repoName != "" {
+			return errors.New("--repo and --repo-name can't be used, the repos are given as arguments")
+		}
+
+		repos := []repoSummary{
+			summarizeRepo(o, &ff, fs.Arg(0)),
+			summarizeRepo(o, &ff, fs.Arg(1)),
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		var failed int
+		for _, r := range repos {
+			if r.err != nil {
+				Logger.Error(fmt.Sprintf("error analysing %s: %s", r.dir, r.err))
+				failed++
+			}
+		}
+		if failed == len(repos) {
+			return errors.New("neither repo could be analysed")
+		}
+
+		rows := compareRepoRows(repos[0], repos[1])
+		t := newTable(1, rows[0]...)
+		for _, row := range rows[1:] {
+			t.row(row...)
+		}
 
+		return of.write(t.write)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var ForksCmd = &Z.Cmd{
+	Name:    `forks`,
+	Summary: `lists the contributions per repo of a directory of repos, like forks`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--full-path|--root DIR] [--jobs N] [--anonymize [--key KEY]] [--output FILE] [--clipboard]`,
 	Description: `
-		The {{aka}} command is a command tree for analysing author
-		contributions in git repositories. The most extensive overview is the
-		'summary' subcommand, that has the alias 's'. This shows aggregated
-		metrics like line change ratio and commit granularity per author.
-		Larger numbers means higher contribution for all categories.
+		The {{aka}} subcommand compares the repos in and below the current
+		directory, or the one given with --repo, like a directory of clones
+		of the forks of a project, with a row per repo rather than per
+		author. Each row sums the commits, additions and deletions of the
+		authors of the repo, and names its top author, the one with the most
+		commits. The repos with the most commits are listed first, ties
+		being ordered by their line changes.
+
+		The repos are found and analysed like for 'csv summary --recursive',
+		also with --jobs, and are identified by the name of their directory,
+		or by their path with --full-path or --root DIR. Repos without
+		commits are skipped, and a repo that can't be analysed doesn't stop
+		the others, but fails the command at the end.
+
+		The flags selecting the history and the authors work as for the
+		'summary' command, so that --author REGEX gives the contributions of
+		some authors to each fork.
 		`,
-}
+	Call: func(x *Z.Cmd, args ...string) error {
 
-var AuthorCommitsCmd = &Z.Cmd{
-	Name:    `authorcommits`,
-	Summary: `lists the number of commits per author in current dir`,
-	Aliases: []string{"ac"},
-	Call: func(_ *Z.Cmd, _ ...string) error { // note conventional _
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		var af anonymizeFlags
+		var cf csvFlags
+		jobs := runtime.NumCPU()
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		af.register(fs)
+		fs.BoolVar(&cf.fullPath, "full-path", false, "identify the repos by their full path")
+		fs.StringVar(&cf.root, "root", "", "identify the repos by their path relative to this directory")
+		fs.IntVar(&jobs, "jobs", jobs, "number of repos analysed at the same time")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if o.repoName != "" {
+			return errors.New("--repo-name can't be used when scanning several repos")
+		}
+
+		root := o.dir
+		if root == "" {
+			root = "."
+		}
+		dirs, err := findRepos(root)
+		if err != nil {
+			return fmt.Errorf("error finding repos: %w", err)
+		}
+
+		repos := cf.summarizeAll(o, &ff, dirs, jobs)
 
-		w := new(tabwriter.Writer)
+		if dryRun {
+			return nil
+		}
+
+		failed := failedRepos(repos)
 
-		// minwidth, tabwidth, padding, padchar, flags
-		w.Init(os.Stdout, 8, 8, 0, '\t', 0)
-		defer w.Flush()
+		an := af.pseudonyms()
+		t := newTable(2, "Repo", "Top Author", "Commits", "Additions", "Deletions")
+		for _, f := range forkSummaries(repos) {
+			t.row(f.Repo, an.name(f.TopAuthor), strconv.Itoa(f.Commits), strconv.Itoa(f.Additions), strconv.Itoa(f.Deletions))
+		}
+		if err := of.write(t.write); err != nil {
+			return err
+		}
 
-		fmt.Fprintf(w, " %s\t%s\n", "Author", "Commits")
-		fmt.Fprintf(w, " %s\t%s\n", "------", "-------")
-		for k, v := range AuthorCommits() {
-			fmt.Fprintf(w, " %s\t%d\n", k, v)
+		if failed > 0 {
+			return fmt.Errorf("%d of %d repos failed", failed, len(repos))
 		}
 
 		return nil
@@ -85,86 +4401,447 @@ var AuthorCommitsCmd = &Z.Cmd{
 	Commands: []*Z.Cmd{help.Cmd},
 }
 
-var AuthorChangesCmd = &Z.Cmd{
-	Name:    `authorchanges`,
-	Summary: `lists the line changes per author in current branch`,
-	Aliases: []string{"ach"},
-	Call: func(_ *Z.Cmd, _ ...string) error { // note conventional _
+var CheckCmd = &Z.Cmd{
+	Name:    `check`,
+	Summary: `checks assertions on the contributions, writing TAP`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--strict] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--recompute-ratios] [--assert METRIC<OP>VALUE] [--require-bus-factor N] [--max-author-share SHARE] [--no-cache] [--cache-dir DIR] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand turns gitcontrib into a gate on the health
+		of the repo, like in CI. It checks assertions on the metrics of the
+		'summary' report and writes their outcomes as a TAP stream, the Test
+		Anything Protocol read by many test harnesses, failing when any of
+		them doesn't hold:
+
+		    TAP version 13
+		    1..2
+		    ok 1 - bus factor >= 2
+		    not ok 2 - max author share <= 0.8
+		      ---
+		      metric: max_author_share
+		      expected: '<= 0.8'
+		      actual: 0.93
+		      ...
+
+		Each assertion is given with the repeatable --assert flag as METRIC,
+		an operator and a number, like --assert 'bus_factor>=2', quoted as
+		the operators mean something to the shell. The operators are >=, <=,
+		>, <, == and !=, and the metrics are:
+
+		    authors           number of authors
+		    commits           number of non-merge commits
+		    additions         number of added lines
+		    deletions         number of deleted lines
+		    granularity       overall repo commit granularity
+		    gini              Gini coefficient of the line changes
+		    bus_factor        fewest authors making half the line changes
+		    days              days from the first to the last commit
+		    max_author_share  largest line ratio of an author
+		    max_commit_share  largest commit ratio of an author
+
+		The shares are fractions, from 0 to 1. The most common assertions
+		have shorthands, --require-bus-factor N being 'bus_factor>=N' and
+		--max-author-share SHARE being 'max_author_share<=SHARE'. The
+		assertions are checked in the order given, and it is an error to
+		give none. The flags selecting the history and the authors apply
+		like for the 'summary' report.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var sf summaryFlags
+		var as assertions
+		fs := newFlagSet(x)
+		sf.options.register(fs)
+		sf.filterFlags.register(fs)
+		sf.outputFlags.register(fs)
+		sf.cacheFlags.register(fs)
+		fs.Var(&as, "assert", "check that METRIC<OP>VALUE holds, like bus_factor>=2 (repeatable)")
+		fs.Var(assertionFlag{&as, "bus_factor", ">="}, "require-bus-factor", "check that the bus factor is at least N")
+		fs.Var(assertionFlag{&as, "max_author_share", "<="}, "max-author-share", "check that no author made a larger share of the line changes")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
 
-		w := new(tabwriter.Writer)
+		if len(as) == 0 {
+			return errors.New("no assertions given, see --assert")
+		}
+
+		if err := sf.options.resolve(); err != nil {
+			return sf.emptyRepo(err)
+		}
+
+		summaries, totals, err := sf.analyse()
+		if err != nil {
+			return err
+		}
 
-		// minwidth, tabwidth, padding, padchar, flags
-		w.Init(os.Stdout, 8, 8, 0, '\t', 0)
-		defer w.Flush()
+		if dryRun {
+			return nil
+		}
 
-		fmt.Fprintf(w, " %s\t%s\t%s\n", "Author", "Additions", "Deletions")
-		fmt.Fprintf(w, " %s\t%s\t%s\n", "------", "---------", "---------")
-		for k, v := range MapLineChanges() {
-			fmt.Fprintf(w, " %s\t%d\t%d\n", k, v.Additions, v.Deletions)
+		results := evaluate(as, summaries, totals)
+		if err := sf.write(func(w io.Writer) error {
+			return writeTAP(w, results)
+		}); err != nil {
+			return err
 		}
 
+		var failed int
+		for _, r := range results {
+			if !r.OK {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d assertions failed", failed, len(results))
+		}
 		return nil
 	},
 	Commands: []*Z.Cmd{help.Cmd},
 }
 
-var ContributionSummaryCmd = &Z.Cmd{
-	Name:    `summary`,
-	Summary: `lists commits, line changes and aggregated metrics`,
-	Aliases: []string{"s"},
-	Call: func(_ *Z.Cmd, _ ...string) error { // note conventional _
+var BadgeCmd = &Z.Cmd{
+	Name:    `badge`,
+	Summary: `writes a metric of the repo as a shields.io endpoint badge`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--strict] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--metric contributors|commits|bus-factor] [--no-cache] [--cache-dir DIR] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand writes one of the repo-level metrics of the
+		'summary' report as the JSON of a shields.io endpoint badge, for a
+		live badge in a README:
+
+		    {"schemaVersion":1,"label":"contributors","message":"42","color":"blue"}
+
+		Publish the output where shields.io can fetch it, like from a
+		scheduled CI job, and point the badge at it with
+		https://img.shields.io/endpoint?url=URL.
 
-		commitMap := AuthorCommits()
-		lineChangesMap := MapLineChanges()
-		commitRatioMap := make(map[string]float64)
-		lineRatioMap := make(map[string]float64)
-		granularityMap := make(map[string]float64)
+		The --metric flag selects what the badge shows, being the number of
+		contributors by default, the number of non-merge commits with
+		commits, or the bus factor with bus-factor. The bus factor badge is
+		red for a bus factor of 1, yellow for 2 and green above, the others
+		are blue. The flags selecting the history and the authors apply
+		like for the 'summary' report.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var sf summaryFlags
+		var metric string
+		fs := newFlagSet(x)
+		sf.options.register(fs)
+		sf.filterFlags.register(fs)
+		sf.outputFlags.register(fs)
+		sf.cacheFlags.register(fs)
+		fs.StringVar(&metric, "metric", "contributors", "the metric shown: contributors, commits or bus-factor")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := checkBadgeMetric(metric); err != nil {
+			return err
+		}
+
+		if err := sf.options.resolve(); err != nil {
+			return sf.emptyRepo(err)
+		}
 
-		// calculate aggregate metrics
-		commitTotal := 0
-		for _, v := range commitMap {
-			commitTotal += v
+		summaries, totals, err := sf.analyse()
+		if err != nil {
+			return err
 		}
 
-		lineTotal := 0
-		for _, v := range lineChangesMap {
-			lineTotal += v.Sum()
+		if dryRun {
+			return nil
 		}
 
-		for k, v := range lineChangesMap {
-			linesum := v.Sum()
-			lineRatio := float64(linesum) / float64(lineTotal)
-			lineRatioMap[k] = lineRatio
-			commitRatio := float64(commitMap[k]) / float64(commitTotal)
-			commitRatioMap[k] = commitRatio
-			granularityMap[k] = 1.0 / (float64(linesum) / float64(commitMap[k]))
+		b := makeBadge(metric, Aggregate(summaries, totals))
+		return sf.write(func(w io.Writer) error {
+			return writeBadge(w, b)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var RemoteCmd = &Z.Cmd{
+	Name:    `remote`,
+	Summary: `lists the 'summary' report of a GitHub repo from its API, without cloning`,
+	Usage:   `[--format FORMAT] [--delimiter SEP] [--timestamp TIME] [--box [--ascii]] [--thousands] [--no-footer] [--ratios-extra] [--self-ratios] [--score [--score-mode relative|share]] [--rank [--rank-by KEY]] [--columns LIST] [--aggregate-only] [--template TEMPLATE|@FILE|--preset NAME] [--anonymize [--key KEY]] [--output FILE] [--clipboard] URL`,
+	Description: `
+		The {{aka}} subcommand gives a quick look at the contributions to a
+		GitHub repo that isn't cloned, from the contributor statistics of
+		the GitHub API rather than from git. The repo is given by its URL,
+		like https://github.com/OWNER/REPO, git@github.com:OWNER/REPO.git
+		or github.com/OWNER/REPO. Other hosts aren't supported.
+
+		The statistics are mapped into the 'summary' report, and the flags
+		rendering it, like --format and --columns, work as for the 'summary'
+		command. The numbers differ slightly from those of a local analysis,
+		as GitHub attributes the commits to accounts rather than to the
+		names of the commits, leaves out the merge commits and lists only
+		the top 100 contributors, so the report is labelled as coming from
+		the API on standard error. The first and last dates are those of the
+		weeks of the first and last commits.
+
+		The GitHub API limits how often it can be queried anonymously. Set
+		the GITHUB_TOKEN environment variable to a token to authenticate,
+		which is also needed for private repos. GitHub computes the
+		statistics in the background when they aren't cached, answering
+		202 Accepted meanwhile, so they are asked for again, waiting longer
+		between each attempt, and it is an error if they still aren't ready
+		after about a minute. Each request is given up after 30 seconds.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var sf summaryFlags
+		fs := newFlagSet(x)
+		sf.outputFlags.register(fs)
+		sf.registerRender(fs)
+		if err := parseFlags(x, fs, args, 1); err != nil {
+			return err
 		}
 
-		// output results
-		w := new(tabwriter.Writer)
+		if err := sf.resolveRender(); err != nil {
+			return err
+		}
 
-		w.Init(os.Stdout, 8, 8, 0, '\t', 0) // setting up table dimensions
+		owner, repo, err := parseGitHubRemote(fs.Arg(0))
+		if err != nil {
+			return err
+		}
 
-		fmt.Fprintf(w, " %s\t%s\t%s\t%s\t%s\t%s\t%s\n", "Author", "Commits", "Additions", "Deletions", "Line ratio", "Commit ratio", "Granularity")
-		fmt.Fprintf(w, " %s\t%s\t%s\t%s\t%s\t%s\t%s\n", "------", "-------", "---------", "---------", "----------", "------------", "-----------")
-		for k, v := range MapLineChanges() {
-			fmt.Fprintf(w, " %s\t%v\t%v\t%v\t%.3f\t%.3f\t%.3f\n", k, commitMap[k], v.Additions, v.Deletions, lineRatioMap[k], commitRatioMap[k], granularityMap[k])
+		if dryRun {
+			printCommand(fmt.Sprintf("GET %s/repos/%s/%s/stats/contributors", githubAPI, owner, repo))
+			return nil
 		}
-		err := w.Flush()
+
+		contributors, err := githubContributors(owner, repo)
 		if err != nil {
-			return fmt.Errorf("failed to flush output buffer: %w", err)
+			return err
 		}
 
-		fmt.Printf(
-			"\n Overall repo commit granularity: %.3f\n",
-			1.0/(float64(lineTotal)/float64(commitTotal)),
-		)
+		summaries, totals, err := githubSummaries(contributors)
+		if err != nil {
+			return err
+		}
+		sf.repo = repo
+		fmt.Fprintf(os.Stderr, "contributions to %s/%s from the GitHub API contributor statistics, not from git\n", owner, repo)
 
+		return sf.outputFlags.write(func(w io.Writer) error {
+			return sf.render(w, summaries, totals)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var DescribeCmd = &Z.Cmd{
+	Name:    `describe`,
+	Summary: `prints the JSON schema of the machine-readable reports`,
+	Usage:   `[--output FILE] [--clipboard]`,
+	Aliases: []string{"schema"},
+	Description: `
+		The {{aka}} subcommand prints the JSON schema of the report written
+		by the json, ndjson and yaml formats of the 'summary' command,
+		giving the name, type and meaning of each field of the authors and
+		of the repo-wide totals. The fields only present with some flags,
+		like --decay and --files, are not required, and their descriptions
+		name the flags. The schema is generated from the types the reports
+		are encoded from, so it always matches the output of the running
+		version.
+
+		The json and yaml formats write a single object with an "authors"
+		array and a "totals" object. The ndjson format writes each author
+		as an object on a line of its own, followed by a line with the
+		totals and a "_total" field set to true.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var of outputFlags
+		fs := newFlagSet(x)
+		of.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		return of.write(writeSchema)
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var DoctorCmd = &Z.Cmd{
+	Name:    `doctor`,
+	Summary: `checks that the installed git works with the parsers`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand checks that the installed git works with
+		gitcontrib, turning reports looking wrong with some version of git
+		into something actionable. The output formats of git occasionally
+		change across versions, which can make the parsing of a report fail
+		silently.
+
+		It checks that git is found, prints its version, and runs each of
+		the git invocations of the reports against the repo, checking that
+		their output is parsed into sane numbers: that authors are found,
+		with no blank names, that the counts aren't negative and that every
+		numstat line is understood. Last, it checks that the commit counts
+		and line changes parsed from the different invocations agree.
+
+		Each check is written as a row of a table, with its outcome and some
+		details, like the number of authors found. The command fails when
+		any check does, so it can be run in CI. The checks stop early when
+		git or the repo isn't found, as the others depend on them. Please
+		include the output when reporting wrong numbers.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		checks := diagnose(o)
+
+		if dryRun {
+			return nil
+		}
+
+		var failed int
+		t := newTable(3, "Check", "Result", "Detail")
+		for _, c := range checks {
+			result, detail := "ok", c.Detail
+			if c.Err != nil {
+				failed++
+				result = "FAIL"
+				if detail != "" {
+					detail += ": "
+				}
+				detail += c.Err.Error()
+			}
+			t.row(c.Name, result, detail)
+		}
+
+		if err := of.write(t.write); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+		}
 		return nil
 	},
 	Commands: []*Z.Cmd{help.Cmd},
 }
 
+var DumpCmd = &Z.Cmd{
+	Name:    `dump`,
+	Summary: `prints the parsed commit counts and line changes for debugging`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand prints the commit counts and line changes of
+		each author exactly as parsed from the git output, before any
+		filtering, merging or summarizing, as a JSON object with a "commits"
+		and a "line_changes" object keyed by author. When the numbers of a
+		report look wrong, this tells whether the parsing or the later steps
+		are to blame, and the output can be diffed against the expected one.
+		The command is meant for debugging, so it is left out of the help.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var of outputFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		of.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		commitMap, err := authorCommits(o)
+		if err != nil {
+			return fmt.Errorf("error extracting commit counts: %w", err)
+		}
+
+		lineChangesMap, err := mapLineChanges(o)
+		if err != nil {
+			return fmt.Errorf("error extracting line changes: %w", err)
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		return of.write(func(w io.Writer) error {
+			return writeDump(w, commitMap, lineChangesMap)
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
+var SelfCheckCmd = &Z.Cmd{
+	Name:    `selfcheck`,
+	Summary: `checks that the csv and json reports read back as rendered`,
+	Usage:   `[--repo DIR] [--repo-name NAME] [--branch REF|--default-branch|--all-branches] [--since-tag[=TAG]] [--commits SHA,...|--commits-file FILE] [--since DATE] [--until DATE] [--as-of DATE] [--first-parent] [--path PATH [--follow]] [--no-mailmap] [--date-type author|committer] [--tz ZONE] [--max-commit-lines N [--exclude-commit]] [--cap-lines-per-file N] [--diff-filter LETTERS] [--code-only [--code-ext EXT,...]] [--squash-author author|committer|body] [--exclude-reverts [--exclude-reverted]] [--respect-gitattributes] [--exclude-first-commit] [--unknown-author LABEL] [--author REGEX] [--min-commits N] [--ignore-author GLOB] [--include-domain DOMAIN] [--exclude-domain DOMAIN] [--drop-no-email] [--identity-map FILE] [--normalize-names] [--team NAME=AUTHOR,...] [--collapse-bots [--bot-pattern REGEX]] [--output FILE] [--clipboard]`,
+	Description: `
+		The {{aka}} subcommand guards the machine-readable reports against
+		formatting mistakes, like fields left unquoted or ratios losing
+		precision. It renders the 'summary' report of the repo as the rows
+		of 'csv summary', at full precision, and in the json and ndjson
+		formats, reads each back, with a CSV parser and like --compare-to
+		does, and checks that the authors and totals read back are exactly
+		those rendered. It writes the number of authors checked, or fails
+		listing the fields that differ, for each format.
+
+		The flags selecting the history and the authors apply like for the
+		'summary' command, so that the check can be run on the repos whose
+		author names are the most likely to trip the formats. The command is
+		meant for debugging, so it is left out of the help.
+		`,
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		summaries, totals, err := ff.collect(o)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		if err := roundTrip(summaries, totals); err != nil {
+			return fmt.Errorf("reports not read back as rendered: %w", err)
+		}
+
+		return of.write(func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "%d authors read back as rendered from csv, json and ndjson\n", len(summaries))
+			return err
+		})
+	},
+	Commands: []*Z.Cmd{help.Cmd},
+}
+
 // CsvCmd provides a subtree command containing CSV-outputing equvalents of the
 // basic `gitcontrib` reports.
 var CsvCmd = &Z.Cmd{
@@ -186,10 +4863,165 @@ var CsvCmd = &Z.Cmd{
 		name of the repo directory itself, the rest follow the same order as 
 		the original command. Strings are wrapped in double quotes.
 
+		As repos in different places can have directories of the same name,
+		the repo can instead be identified by the full path of its directory
+		with --full-path, or by its path relative to a directory with --root
+		DIR, like the directory holding all analysed repos. These tell apart
+		the rows of repos of the same name when aggregating the output of
+		several repos. It is an error if the repo is not below the --root
+		directory.
+
+		The --repo-name NAME flag names the repo by NAME in the first field
+		instead, like a canonical project identifier keying the rows of a
+		dashboard, whatever the directory the repo was cloned into. It can't
+		be combined with --recursive, as all repos would get the same name.
+
+		With --anonymize, the author names are replaced by pseudonyms like
+		for the 'summary' command, an author keeping the same pseudonym in all
+		the repos of the output, or across runs with --key KEY.
+
 		Do 'cmd COMMAND help' for further details.
 		`,
 }
 
+// csvFlags holds the flags selecting how the CSV commands identify the
+// repo in the first field of each row, and whether they name the authors.
+type csvFlags struct {
+	fullPath   bool
+	root       string
+	precision  int
+	withTotals bool
+	anonymizeFlags
+}
+
+func (cf *csvFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&cf.fullPath, "full-path", false, "identify the repo by its full path")
+	fs.StringVar(&cf.root, "root", "", "identify the repo by its path relative to this directory")
+	fs.IntVar(&cf.precision, "precision", 3, "decimals of the ratio fields, -1 for full precision")
+	fs.BoolVar(&cf.withTotals, "with-totals", false, "follow the rows of each repo by a row of its totals, of the TOTAL author")
+	cf.anonymizeFlags.register(fs)
+}
+
+// float formats a ratio field with the decimals set by --precision, or
+// with the fewest digits reading back as the same number when negative.
+func (cf *csvFlags) float(f float64) string {
+	if cf.precision < 0 {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'f', cf.precision, 64)
+}
+
+// row returns the CSV row of the default fields of s, following the repo
+// field.
+func (cf *csvFlags) row(repo string, s AuthorSummary) string {
+	return fmt.Sprintf("%s,%s,%v,%v,%v,%s,%s,%s", csvQuote(repo), csvQuote(s.Author), s.Commits, s.Additions, s.Deletions, cf.float(s.LineRatio), cf.float(s.CommitRatio), cf.float(s.Granularity))
+}
+
+// fields returns the CSV row of the columns of s, following the repo
+// field.
+func (cf *csvFlags) fields(repo string, s AuthorSummary, cols []column) string {
+	fields := []string{csvQuote(repo)}
+	for _, c := range cols {
+		switch v := c.value(s).(type) {
+		case string:
+			fields = append(fields, csvQuote(v))
+		case float64:
+			fields = append(fields, cf.float(v))
+		case nil:
+			fields = append(fields, "")
+		default:
+			fields = append(fields, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// csvQuote returns the string field wrapped in double quotes, those in it
+// being doubled as in RFC 4180.
+func csvQuote(field string) string {
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
+
+// repoName returns the identifier of the repo of o, which is the name of
+// its directory unless a path or another name is asked for.
+func (cf *csvFlags) repoName(o options) (string, error) {
+	if o.repoName != "" || !cf.fullPath && cf.root == "" {
+		return getRepoDirName(o)
+	}
+
+	path, _, err := getRepoPath(o)
+	if err != nil || cf.root == "" {
+		return path, err
+	}
+
+	// git resolves symbolic links in the paths it reports
+	root, err := filepath.Abs(cf.root)
+	if err != nil {
+		return "", fmt.Errorf("error resolving --root: %w", err)
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("error resolving --root: %w", err)
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("repo %s is not below --root %s", path, cf.root)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// repoSummary holds the 'summary' report of a single repo of a recursive
+// scan, or the error analysing it.
+type repoSummary struct {
+	Repo      string
+	Summaries []AuthorSummary
+	Totals    Totals
+
+	dir string
+	err error
+}
+
+// summarize returns the 'summary' report of the repo of o with the
+// authors selected by ff, identified as selected by the flags.
+func (cf *csvFlags) summarize(o options, ff *filterFlags) (repoSummary, error) {
+	r := repoSummary{dir: o.dir}
+	o.failDrift = ff.strict
+
+	if err := o.resolve(); err != nil {
+		return r, err
+	}
+
+	commitMap, err := authorCommits(o)
+	if err != nil {
+		return r, fmt.Errorf("error extracting commit counts: %w", err)
+	}
+
+	lineChangesMap, err := mapLineChanges(o)
+	if err != nil {
+		return r, fmt.Errorf("error extracting line changes: %w", err)
+	}
+
+	if ff.strict {
+		if err := checkAuthors(commitMap, lineChangesMap); err != nil {
+			return r, err
+		}
+	}
+
+	r.Summaries, r.Totals, err = ff.summarize(o, commitMap, lineChangesMap)
+	if err != nil {
+		return r, err
+	}
+
+	r.Repo, err = cf.repoName(o)
+	if err != nil {
+		return r, fmt.Errorf("error getting repo name: %w", err)
+	}
+
+	return r, nil
+}
+
 // CsvAuthorCommitsCmd provides a CSV-outputing equivalent of AuthorCommitsCmd
 var CsvAuthorCommitsCmd = &Z.Cmd{
 	Name:    `authorcommits`,
@@ -201,25 +5033,87 @@ var CsvAuthorCommitsCmd = &Z.Cmd{
 		of the human-readable tabulated output of the original command. The
 		first field of each row is the name of the repo directory itself, the
 		rest follow the same order as the original command. Strings are wrapped
-		in double quotes, and the CSV header is not printed to accomodate
-		scripting.
+		in double quotes, any double quote in them being doubled, and the CSV
+		header is not printed to accomodate scripting.
 
 		The fields of this command is the following, in the given order:
 
 		Repo directory, Author, Commits
+
+		With --with-totals, the rows are followed by a totals row, with
+		"TOTAL" as the author and the sum of the commits of the rows, like
+		"repo","TOTAL",42.
+
+		The rows are sorted with the most commits first, ties being ordered by
+		author, so that the output is the same on every run, like for
+		reports committed to version control. The --sort KEY flag orders
+		them by another key, one of author, commits, additions, deletions or
+		lines, all but author putting the highest counts first.
+
+		The --first-parent flag and the flags selecting and grouping authors,
+		like --author, --ignore-author and --team, work as for the 'summary'
+		command, as does the .gitcontribignore file.
 		`,
-	Call: func(_ *Z.Cmd, _ ...string) error { // note conventional _
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		var cf csvFlags
+		sortBy := "commits"
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		fs.StringVar(&sortBy, "sort", sortBy, "sort the rows by author, commits, additions, deletions or lines")
+		cf.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
 
-		reponame, err := getRepoDirName()
+		commitMap, err := authorCommits(o)
+		if err != nil {
+			return fmt.Errorf("error extracting commit counts: %w", err)
+		}
+
+		if err := ff.filterMaps(o, commitMap, nil); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		reponame, err := cf.repoName(o)
 		if err != nil {
 			return fmt.Errorf("error getting repo name: %w", err)
 		}
 
-		for k, v := range AuthorCommits() {
-			fmt.Printf("\"%s\",\"%s\",%d\n", reponame, k, v)
+		summaries := commitSummaries(commitMap)
+		cf.pseudonyms().rename(summaries)
+		if err := sortSummaries(summaries, sortBy); err != nil {
+			return err
 		}
 
-		return nil
+		if cf.withTotals {
+			var total int
+			for _, s := range summaries {
+				total += s.Commits
+			}
+			summaries = append(summaries, AuthorSummary{Author: totalAuthor, Commits: total})
+		}
+
+		return of.write(func(w io.Writer) error {
+			for _, s := range summaries {
+				fmt.Fprintf(w, "%s,%s,%d\n", csvQuote(reponame), csvQuote(s.Author), s.Commits)
+			}
+
+			return nil
+		})
 	},
 	Commands: []*Z.Cmd{help.Cmd},
 }
@@ -241,22 +5135,85 @@ var CsvAuthorChangesCmd = &Z.Cmd{
 		The fields of this command is the following, in the given order:
 
 		Repo directory, Author, Additions, Deletions
+
+		With --with-totals, the rows are followed by a totals row, with
+		"TOTAL" as the author and the sums of the additions and deletions of
+		the rows, like "repo","TOTAL",1200,300.
+
+		The rows are sorted with the most changed lines first, ties being
+		ordered by author, so that the output is the same on every run, like
+		for reports committed to version control. The --sort KEY flag orders
+		them by another key, one of author, commits, additions, deletions or
+		lines, all but author putting the highest counts first.
+
+		The --first-parent flag and the flags selecting and grouping authors,
+		like --author, --ignore-author and --team, work as for the 'summary'
+		command, as does the .gitcontribignore file.
 		`,
-	Call: func(_ *Z.Cmd, _ ...string) error { // note conventional _
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		var cf csvFlags
+		sortBy := "lines"
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		fs.StringVar(&sortBy, "sort", sortBy, "sort the rows by author, commits, additions, deletions or lines")
+		cf.register(fs)
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
 
-		reponame, err := getRepoDirName()
+		if err := o.resolve(); err != nil {
+			return emptyRepo(err)
+		}
+
+		lineChangesMap, err := mapLineChanges(o)
+		if err != nil {
+			return fmt.Errorf("error extracting line changes: %w", err)
+		}
+
+		if err := ff.filterMaps(o, nil, lineChangesMap); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		reponame, err := cf.repoName(o)
 		if err != nil {
 			return fmt.Errorf("error getting repo name: %w", err)
 		}
 
-		for k, v := range MapLineChanges() {
-			fmt.Printf(
-				"\"%s\",\"%s\",%d,%d\n",
-				reponame, k, v.Additions, v.Deletions,
-			)
+		summaries := changeSummaries(lineChangesMap)
+		cf.pseudonyms().rename(summaries)
+		if err := sortSummaries(summaries, sortBy); err != nil {
+			return err
 		}
 
-		return nil
+		if cf.withTotals {
+			total := AuthorSummary{Author: totalAuthor}
+			for _, s := range summaries {
+				total.Additions += s.Additions
+				total.Deletions += s.Deletions
+			}
+			summaries = append(summaries, total)
+		}
+
+		return of.write(func(w io.Writer) error {
+			for _, s := range summaries {
+				fmt.Fprintf(w,
+					"%s,%s,%d,%d\n",
+					csvQuote(reponame), csvQuote(s.Author), s.Additions, s.Deletions,
+				)
+			}
+
+			return nil
+		})
 	},
 	Commands: []*Z.Cmd{help.Cmd},
 }
@@ -281,43 +5238,152 @@ var CsvContributionSummaryCmd = &Z.Cmd{
 
 		Repo directory, Author, Commits, Additions, Deletions, Line ratio,
 		Commit ratio, Granularity.
+
+		The ratio fields are written with three decimals, or as many as set
+		by --precision N. With --precision -1, they are written with the
+		fewest digits reading back as the same number, at full precision,
+		for tools aggregating them further. The json format of the 'summary'
+		command always writes full precision.
+
+		The --columns LIST flag selects the fields following the repo field
+		and their order, given by name like for the 'summary' command, as in
+		--columns author,commits,net. The columns added by flags of the
+		'summary' command, like weighted, are not available.
+
+		With --with-totals, the rows of each repo are followed by a totals
+		row with the same fields, "TOTAL" being the author, holding the
+		repo-wide totals the ratios are relative to, like the footer of the
+		'summary' table: the commits, additions and deletions, ratios of 1
+		and the overall granularity, like
+
+		    "repo","TOTAL",340,12000,3000,1.000,1.000,0.023
+
+		The row is left out by default so that parsers expecting only rows
+		of authors keep working. The json format of the 'summary' command
+		always holds the totals in its "totals" object, and the ndjson
+		format on its last line, marked by a "_total" field set to true.
+
+		With --recursive, every repo in and below the current directory, or
+		the one given with --repo, is analysed, like a directory of clones.
+		The directories of a repo are not searched for further repos. The
+		repos are analysed at the same time, as many as set by --jobs,
+		defaulting to the number of CPUs, and their rows are written sorted
+		by the repo field. Repos without commits are skipped. A repo that
+		can't be analysed doesn't stop the others: its error is written to
+		standard error, the rows of the other repos are still written, and
+		the command fails at the end.
+
+		The rows are sorted with the most commits first, ties being ordered by
+		author, so that the output is the same on every run, like for
+		reports committed to version control. The --sort KEY flag orders
+		them by another key, one of author, commits, additions, deletions or
+		lines, all but author putting the highest counts first.
+
+		The --first-parent flag and the flags selecting and grouping authors,
+		like --author, --ignore-author and --team, work as for the 'summary'
+		command, as does the .gitcontribignore file.
 		`,
 
-	Call: func(_ *Z.Cmd, _ ...string) error { // note conventional _
+	Call: func(x *Z.Cmd, args ...string) error {
+
+		var o options
+		var ff filterFlags
+		var of outputFlags
+		var cf csvFlags
+		var recursive bool
+		var columnList string
+		jobs := runtime.NumCPU()
+		sortBy := "commits"
+		fs := newFlagSet(x)
+		o.register(fs)
+		ff.register(fs)
+		of.register(fs)
+		fs.StringVar(&sortBy, "sort", sortBy, "sort the rows by author, commits, additions, deletions or lines")
+		cf.register(fs)
+		fs.StringVar(&columnList, "columns", "", "comma-separated fields to write after the repo, in this order")
+		fs.BoolVar(&recursive, "recursive", false, "analyse every repo in and below the directory")
+		fs.IntVar(&jobs, "jobs", jobs, "number of repos analysed at the same time with --recursive")
+		if err := parseFlags(x, fs, args, 0); err != nil {
+			return err
+		}
+
+		var cols []column
+		if columnList != "" {
+			var err error
+			cols, err = parseColumns(columnList)
+			if err != nil {
+				return err
+			}
+			for _, c := range cols {
+				if c.flag != "" {
+					return fmt.Errorf("the %s column isn't available in CSV rows", c.name)
+				}
+			}
+		}
+
+		dirs := []string{o.dir}
+		if recursive {
+			if o.repoName != "" {
+				return errors.New("--repo-name can't be combined with --recursive")
+			}
+
+			root := o.dir
+			if root == "" {
+				root = "."
+			}
 
-		commitMap := AuthorCommits()
-		lineChangesMap := MapLineChanges()
-		commitRatioMap := make(map[string]float64)
-		lineRatioMap := make(map[string]float64)
-		granularityMap := make(map[string]float64)
+			var err error
+			dirs, err = findRepos(root)
+			if err != nil {
+				return fmt.Errorf("error finding repos: %w", err)
+			}
+		}
 
-		// calculate aggregate metrics
-		commitTotal := 0
-		for _, v := range commitMap {
-			commitTotal += v
+		repos := cf.summarizeAll(o, &ff, dirs, jobs)
+		if !recursive && repos[0].err != nil {
+			return repos[0].err
 		}
 
-		lineTotal := 0
-		for _, v := range lineChangesMap {
-			lineTotal += v.Sum()
+		if dryRun {
+			return nil
 		}
 
-		for k, v := range lineChangesMap {
-			linesum := v.Sum()
-			lineRatio := float64(linesum) / float64(lineTotal)
-			lineRatioMap[k] = lineRatio
-			commitRatio := float64(commitMap[k]) / float64(commitTotal)
-			commitRatioMap[k] = commitRatio
-			granularityMap[k] = 1.0 / (float64(linesum) / float64(commitMap[k]))
+		// the repos are analysed concurrently, but written in order
+		sort.SliceStable(repos, func(i, j int) bool {
+			return repos[i].Repo < repos[j].Repo
+		})
+		an := cf.pseudonyms()
+		for i, r := range repos {
+			an.rename(r.Summaries)
+			if err := sortSummaries(r.Summaries, sortBy); err != nil {
+				return err
+			}
+			if cf.withTotals && r.err == nil {
+				repos[i].Summaries = append(r.Summaries, totalSummary(r.Totals))
+			}
 		}
 
-		reponame, err := getRepoDirName()
+		failed := failedRepos(repos)
+
+		err := of.write(func(w io.Writer) error {
+			for _, r := range repos {
+				for _, s := range r.Summaries {
+					if cols != nil {
+						fmt.Fprintln(w, cf.fields(r.Repo, s, cols))
+						continue
+					}
+					fmt.Fprintln(w, cf.row(r.Repo, s))
+				}
+			}
+
+			return nil
+		})
 		if err != nil {
-			return fmt.Errorf("error getting repo name: %w", err)
+			return err
 		}
 
-		for k, v := range MapLineChanges() {
-			fmt.Printf("\"%s\",\"%s\",%v,%v,%v,%.3f,%.3f,%.3f\n", reponame, k, commitMap[k], v.Additions, v.Deletions, lineRatioMap[k], commitRatioMap[k], granularityMap[k])
+		if failed > 0 {
+			return fmt.Errorf("%d of %d repos failed", failed, len(repos))
 		}
 
 		return nil
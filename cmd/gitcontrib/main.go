@@ -6,4 +6,4 @@ package main
 import gitcontrib "github.com/morngrar/gitcontrib"
 
 // tree grown from branch
-func main() { gitcontrib.Cmd.Run() }
+func main() { gitcontrib.Run() }
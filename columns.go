@@ -0,0 +1,340 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// column is a column of the 'summary' report, which --columns selects by
+// name. The names are those of the json fields.
+type column struct {
+	name   string
+	header string
+	value  func(s AuthorSummary) interface{}
+
+	// cell formats the value in the table, when not formatted like the
+	// other values of its type.
+	cell func(s AuthorSummary) string
+
+	// percent shows the fractions of the column as percentages.
+	percent bool
+
+	// flag is the flag computing the values of the column, when they are
+	// only computed on demand, and enabled tells whether it was given.
+	flag    string
+	enabled func(opts renderOptions) bool
+
+	// leading shows the column before the default ones when enabled,
+	// rather than after them.
+	leading bool
+}
+
+// columns lists all columns of the 'summary' report, in the order they
+// are shown by default.
+var columns = []column{
+	{name: "author", header: "Author", value: func(s AuthorSummary) interface{} { return s.Author }},
+	{name: "commits", header: "Commits", value: func(s AuthorSummary) interface{} { return s.Commits }},
+	{name: "additions", header: "Additions", value: func(s AuthorSummary) interface{} { return s.Additions }},
+	{name: "deletions", header: "Deletions", value: func(s AuthorSummary) interface{} { return s.Deletions }},
+	{name: "net", header: "Net", value: func(s AuthorSummary) interface{} { return s.Additions - s.Deletions }},
+	{name: "line_ratio", header: "Line ratio", value: func(s AuthorSummary) interface{} { return s.LineRatio }},
+	{name: "commit_ratio", header: "Commit ratio", value: func(s AuthorSummary) interface{} { return s.CommitRatio }},
+	{name: "granularity", header: "Granularity", value: func(s AuthorSummary) interface{} { return s.Granularity }},
+	{name: "additions_ratio", header: "Add ratio", value: func(s AuthorSummary) interface{} { return s.AdditionsRatio }},
+	{name: "deletions_ratio", header: "Del ratio", value: func(s AuthorSummary) interface{} { return s.DeletionsRatio }},
+	{
+		name: "weighted", header: "Weighted",
+		value: func(s AuthorSummary) interface{} { return s.Weighted },
+		cell:  func(s AuthorSummary) string { return fmt.Sprintf("%.1f", s.Weighted) },
+		flag:  "--decay", enabled: func(opts renderOptions) bool { return opts.decay },
+	},
+	{
+		name: "files_touched", header: "Files",
+		value: func(s AuthorSummary) interface{} { return s.FilesTouched },
+		flag:  "--files", enabled: func(opts renderOptions) bool { return opts.files },
+	},
+	{
+		name: "files_per_commit", header: "Files/commit",
+		value: func(s AuthorSummary) interface{} { return s.FilesPerCommit },
+		cell:  func(s AuthorSummary) string { return fmt.Sprintf("%.1f", s.FilesPerCommit) },
+		flag:  "--breadth", enabled: func(opts renderOptions) bool { return opts.breadth },
+	},
+	{
+		name: "breadth", header: "Breadth",
+		value: func(s AuthorSummary) interface{} { return s.Breadth },
+		cell:  func(s AuthorSummary) string { return fmt.Sprintf("%.1f", s.Breadth) },
+		flag:  "--breadth", enabled: func(opts renderOptions) bool { return opts.breadth },
+	},
+	{
+		name: "merges", header: "Merges",
+		value: func(s AuthorSummary) interface{} { return s.Merges },
+		flag:  "--count-merges-separately", enabled: func(opts renderOptions) bool { return opts.merges },
+	},
+	{
+		name: "surviving", header: "Surviving",
+		value: func(s AuthorSummary) interface{} { return s.Surviving },
+		flag:  "--surviving", enabled: func(opts renderOptions) bool { return opts.survive },
+	},
+	{
+		name: "footprint", header: "Footprint",
+		value: func(s AuthorSummary) interface{} { return s.Footprint },
+		flag:  "--surviving", enabled: func(opts renderOptions) bool { return opts.survive },
+	},
+	{
+		name: "hunks", header: "Hunks",
+		value: func(s AuthorSummary) interface{} { return s.Hunks },
+		flag:  "--complexity", enabled: func(opts renderOptions) bool { return opts.hunks },
+	},
+	{
+		name: "complexity", header: "Complexity",
+		value: func(s AuthorSummary) interface{} { return s.Complexity },
+		cell:  func(s AuthorSummary) string { return fmt.Sprintf("%.1f", s.Complexity) },
+		flag:  "--complexity", enabled: func(opts renderOptions) bool { return opts.hunks },
+	},
+	{
+		name: "score", header: "Score",
+		value: func(s AuthorSummary) interface{} { return s.Score },
+		cell:  func(s AuthorSummary) string { return fmt.Sprintf("%.1f", s.Score) },
+		flag:  "--score", enabled: func(opts renderOptions) bool { return opts.score },
+	},
+	{
+		name: "rank", header: "Rank",
+		value: func(s AuthorSummary) interface{} { return s.Rank },
+		flag:  "--rank", enabled: func(opts renderOptions) bool { return opts.rank },
+		leading: true,
+	},
+	{
+		name: "deletions_per_addition", header: "Del/add ratio",
+		value: func(s AuthorSummary) interface{} {
+			r := s.DeletionsPerAddition()
+			if math.IsInf(r, 0) || s.Additions == 0 {
+				return nil
+			}
+			return r
+		},
+		cell: deletionsPerAdditionCell,
+	},
+	{
+		name: "addition_share", header: "Add %", percent: true,
+		value: func(s AuthorSummary) interface{} { return selfShare(s, s.AdditionShare()) },
+	},
+	{
+		name: "deletion_share", header: "Del %", percent: true,
+		value: func(s AuthorSummary) interface{} { return selfShare(s, s.DeletionShare()) },
+	},
+}
+
+// selfShare returns a share of the own line changes of s, or nil for an
+// author without any, whose shares are undefined.
+func selfShare(s AuthorSummary, share float64) interface{} {
+	if s.Additions+s.Deletions == 0 {
+		return nil
+	}
+	return share
+}
+
+// defaultColumns are shown unless others are selected, followed by those
+// added by the flags of opts.
+var defaultColumns = []string{"author", "commits", "additions", "deletions", "line_ratio", "commit_ratio", "granularity"}
+
+// columnNames returns the names of all columns, in their default order.
+func columnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// findColumn returns the column of the given name.
+func findColumn(name string) (column, bool) {
+	for _, c := range columns {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+// parseColumns returns the columns named by a comma-separated list, in
+// its order. It is an error to name an unknown column.
+func parseColumns(list string) ([]column, error) {
+	var selected []column
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		c, ok := findColumn(name)
+		if !ok {
+			return nil, fmt.Errorf(
+				"unknown column %q, must be one of: %s",
+				name, strings.Join(columnNames(), ", "),
+			)
+		}
+
+		selected = append(selected, c)
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no columns given, must be some of: %s", strings.Join(columnNames(), ", "))
+	}
+
+	return selected, nil
+}
+
+// selectColumns returns the columns named by a comma-separated list, like
+// parseColumns. It is also an error to name a column whose values are
+// only computed with a flag missing from opts.
+func selectColumns(list string, opts renderOptions) ([]column, error) {
+	selected, err := parseColumns(list)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range selected {
+		if c.enabled != nil && !c.enabled(opts) {
+			return nil, fmt.Errorf("the %s column needs %s", c.name, c.flag)
+		}
+	}
+
+	return selected, nil
+}
+
+// shownColumns returns the columns selected by opts, or else the default
+// ones along with those added by its flags.
+func (opts renderOptions) shownColumns() []column {
+	if len(opts.columns) > 0 {
+		return opts.columns
+	}
+
+	enabled := func(c column, leading bool) bool {
+		return c.leading == leading && c.enabled != nil && c.enabled(opts)
+	}
+
+	var shown []column
+	for _, c := range columns {
+		if enabled(c, true) {
+			shown = append(shown, c)
+		}
+	}
+	for _, name := range defaultColumns {
+		c, _ := findColumn(name)
+		shown = append(shown, c)
+	}
+	for _, c := range columns {
+		if enabled(c, false) {
+			shown = append(shown, c)
+		}
+	}
+	if opts.extra {
+		c, _ := findColumn("deletions_per_addition")
+		shown = append(shown, c)
+	}
+	if opts.selfRatios {
+		for _, name := range []string{"addition_share", "deletion_share"} {
+			c, _ := findColumn(name)
+			shown = append(shown, c)
+		}
+	}
+
+	return shown
+}
+
+// textColumns returns the number of leading text columns of the tables
+// of the columns shown by opts, left-aligned, being the author column,
+// along with the rank column before it.
+func (opts renderOptions) textColumns() int {
+	shown := opts.shownColumns()
+	if len(shown) > 1 && shown[0].name == "rank" && shown[1].name == "author" {
+		return 2
+	}
+	return 1
+}
+
+// text returns the table cell of the column for s, blank when it has no
+// value.
+func (c column) text(s AuthorSummary) string {
+	if c.cell != nil {
+		return c.cell(s)
+	}
+
+	switch v := c.value(s).(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		if c.percent {
+			return fmt.Sprintf("%.1f%%", 100*v)
+		}
+		return fmt.Sprintf("%.3f", v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// selectedSummary is a summary encoded with only the selected columns, in
+// their order, by the json, ndjson and yaml formats.
+type selectedSummary struct {
+	columns []column
+	summary AuthorSummary
+}
+
+// selectSummaries returns the summaries to encode with only the columns.
+func selectSummaries(summaries []AuthorSummary, cols []column) []selectedSummary {
+	selected := make([]selectedSummary, len(summaries))
+	for i, s := range summaries {
+		selected[i] = selectedSummary{cols, s}
+	}
+	return selected
+}
+
+func (s selectedSummary) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, c := range s.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(c.name)
+		value, err := json.Marshal(c.value(s.summary))
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+func (s selectedSummary) MarshalYAML() (interface{}, error) {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for _, c := range s.columns {
+		var value yaml.Node
+		if err := value.Encode(c.value(s.summary)); err != nil {
+			return nil, err
+		}
+		n.Content = append(n.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: c.name},
+			&value,
+		)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,56 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Columns(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author One", Commits: 3, Additions: 10, Deletions: 4, LineRatio: 0.5},
+	}
+
+	opts := renderOptions{}
+	var err error
+	opts.columns, err = selectColumns("net, author,commits", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rows := summaryRows(summaries, opts)
+	exp := [][]string{{"Net", "Author", "Commits"}, {"6", "Author One", "3"}}
+	if !reflect.DeepEqual(rows, exp) {
+		t.Errorf("Expected rows %v, got %v", exp, rows)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeSummaryJSON(buf, summaries, Totals{Commits: 3}, opts); err != nil {
+		t.Fatalf("error rendering json: %s", err)
+	}
+	if !strings.HasPrefix(buf.String(), `{"authors":[{"net":6,"author":"Author One","commits":3}],"totals":{`) {
+		t.Errorf("Expected the selected fields in order, got %s", buf)
+	}
+
+	buf.Reset()
+	if err := writeSummaryYAML(buf, summaries, Totals{Commits: 3}, opts); err != nil {
+		t.Fatalf("error rendering yaml: %s", err)
+	}
+	if !strings.Contains(buf.String(), "  - net: 6\n    author: Author One\n    commits: 3\n") {
+		t.Errorf("Expected the selected fields in order, got:\n%s", buf)
+	}
+
+	_, err = selectColumns("author,lines", opts)
+	if err == nil || !strings.Contains(err.Error(), "must be one of: author, commits") {
+		t.Errorf("Expected the valid columns listed for an unknown one, got %v", err)
+	}
+
+	if _, err := selectColumns("author,files_touched", opts); err == nil {
+		t.Error("Expected an error selecting a column without its flag")
+	}
+	opts.files = true
+	if _, err := selectColumns("author,files_touched", opts); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
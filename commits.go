@@ -0,0 +1,442 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commitFormat is the git log format of the header line of each commit
+// parsed by parseCommits. Every field is preceded by a NUL byte, which
+// can't occur in any of them, so the header can't be mistaken for a
+// numstat line.
+const commitFormat = "--format=%x00%H%x00%P%x00%aN%x00%aE%x00%aI%x00%s"
+
+// commit is a single commit of the history, along with the line changes
+// of each file it touched. Numstat lines of the commit that couldn't be
+// parsed are kept in Malformed rather than failing the whole history. A
+// commit without an author name has NoAuthor set once its Author is
+// labelled by authorName.
+type commit struct {
+	Hash      string
+	Parents   []string
+	Author    string
+	Email     string
+	Date      time.Time
+	Subject   string
+	Files     []fileChange
+	Malformed []string
+	NoAuthor  bool
+}
+
+// fileChange holds the line changes of one file in a commit. Binary files
+// are listed without any line changes. Renamed files have the path they
+// were renamed from as OldPath.
+type fileChange struct {
+	Path    string
+	OldPath string
+	LineChanges
+}
+
+// isMerge reports whether the commit has more than one parent. Merge
+// commits are listed without any files by git log --numstat.
+func (c commit) isMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// LineChanges returns the sum of the line changes of all files of the
+// commit.
+func (c commit) LineChanges() LineChanges {
+	var lc LineChanges
+	for _, f := range c.Files {
+		lc = lc.Merge(f.LineChanges)
+	}
+	return lc
+}
+
+// logCommits returns the commits in the revision or range of o, using HEAD
+// when none is given, newest first. The commits are read in a single pass
+// over git log, as git writes them.
+func logCommits(o options) ([]commit, error) {
+	commits, err := o.readCommits(o.walkStream, "log")
+	if err != nil {
+		return nil, err
+	}
+
+	commits, _ = o.skipNonCode(commits)
+	commits, _ = o.skipAttributed(commits)
+	commits, _ = o.capFiles(commits)
+	commits, _ = o.skipLarge(commits)
+	commits, _, _, err = o.skipReverts(commits)
+	if err != nil {
+		return nil, err
+	}
+	return o.attributeSquashes(commits)
+}
+
+// readCommits returns the commits of the history of o walked by walk with
+// the given arguments, like walkStream with "log" or streamDiffs, parsed as
+// git writes them, dated in the time zone of --tz and with the authors
+// named by authorName.
+func (o options) readCommits(walk func(func(io.Reader) error, ...string) error, args ...string) ([]commit, error) {
+	var commits []commit
+	err := walk(func(r io.Reader) error {
+		return readCommits(r, func(c commit) error {
+			commits = append(commits, o.reported(c))
+			return nil
+		})
+	}, append(args, "--numstat", o.logFormat(commitFormat))...)
+	if err = o.drift(err); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// reported returns the commit as reported, dated in the time zone of --tz
+// and with its author named by authorName.
+func (o options) reported(c commit) commit {
+	c.Date = o.inZone(c.Date)
+	c.NoAuthor = strings.TrimSpace(c.Author) == ""
+	c.Author = o.authorName(c.Author)
+	return c
+}
+
+// skipLarge returns the commits without the line changes of those
+// changing more lines than --max-commit-lines, like a vendored dependency
+// being imported, along with their number. With --exclude-commit, these
+// commits are left out altogether.
+func (o options) skipLarge(commits []commit) ([]commit, int) {
+	if o.maxCommitLines <= 0 {
+		return commits, 0
+	}
+
+	kept := commits[:0]
+	var skipped int
+	for _, c := range commits {
+		if c.LineChanges().Sum() > o.maxCommitLines {
+			skipped++
+			if o.excludeCommit {
+				continue
+			}
+			c.Files = nil
+		}
+		kept = append(kept, c)
+	}
+
+	return kept, skipped
+}
+
+// rejectCommitFilters returns an error when --max-commit-lines,
+// --exclude-commit or --code-only is given to the named command, which
+// doesn't read the files of the commits to tell which ones they leave out.
+func (o options) rejectCommitFilters(name string) error {
+	var given []string
+	if o.maxCommitLines > 0 {
+		given = append(given, "--max-commit-lines")
+	}
+	if o.excludeCommit {
+		given = append(given, "--exclude-commit")
+	}
+	if o.codeOnly {
+		given = append(given, "--code-only")
+	}
+	if len(given) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s can't be used with %s, which doesn't read the files of the commits", strings.Join(given, " and "), name)
+}
+
+// capFiles returns the commits with the additions of each of their files
+// capped to --cap-lines-per-file, so that a file imported in one go, like
+// a vendored dependency, adds no more than that to the lines of its
+// author, along with the number of added lines left out. Unlike with
+// --max-commit-lines, the other files of the commit count in full.
+func (o options) capFiles(commits []commit) ([]commit, int) {
+	if o.capLinesPerFile <= 0 {
+		return commits, 0
+	}
+
+	var capped int
+	for _, c := range commits {
+		for i, f := range c.Files {
+			if f.Additions > o.capLinesPerFile {
+				capped += f.Additions - o.capLinesPerFile
+				c.Files[i].Additions = o.capLinesPerFile
+			}
+		}
+	}
+
+	return commits, capped
+}
+
+// defaultCodeExts are the extensions of the code files of --code-only,
+// unless others are given with --code-ext.
+var defaultCodeExts = []string{
+	"c", "cc", "cpp", "cs", "dart", "ex", "exs", "go", "h", "hpp", "java",
+	"js", "jsx", "kt", "lua", "m", "php", "pl", "py", "rb", "rs", "scala",
+	"sh", "sql", "swift", "ts", "tsx", "vue", "zig",
+}
+
+// codeExtSet returns the set of the extensions of code files, without
+// their leading dots. The --code-ext flags take comma-separated lists.
+func (o options) codeExtSet() map[string]bool {
+	exts := defaultCodeExts
+	if len(o.codeExts) > 0 {
+		exts = nil
+		for _, list := range o.codeExts {
+			exts = append(exts, strings.Split(list, ",")...)
+		}
+	}
+
+	set := make(map[string]bool, len(exts))
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext != "" {
+			set[ext] = true
+		}
+	}
+	return set
+}
+
+// skipNonCode returns the commits without those touching no code file,
+// like documentation changes, along with their number, with --code-only.
+// Merge commits, listed without files, are kept.
+func (o options) skipNonCode(commits []commit) ([]commit, int) {
+	if !o.codeOnly {
+		return commits, 0
+	}
+
+	exts := o.codeExtSet()
+	kept := commits[:0]
+	var skipped int
+	for _, c := range commits {
+		if c.isMerge() || touchesCode(c, exts) {
+			kept = append(kept, c)
+			continue
+		}
+		skipped++
+	}
+
+	return kept, skipped
+}
+
+// touchesCode reports whether the commit changed a file with one of the
+// extensions.
+func touchesCode(c commit, exts map[string]bool) bool {
+	for _, f := range c.Files {
+		ext := strings.ToLower(strings.TrimPrefix(path.Ext(f.Path), "."))
+		if exts[ext] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCommits parses the output of git log --numstat with commitFormat
+// into commits, returned along with the errors of readCommits.
+func parseCommits(gitOutput string) ([]commit, error) {
+	var commits []commit
+	err := readCommits(strings.NewReader(gitOutput), func(c commit) error {
+		commits = append(commits, c)
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrParseDrift) {
+		return nil, err
+	}
+	return commits, err
+}
+
+// readCommits reads the output of git log --numstat with commitFormat from
+// r as git writes it, calling fn with each commit once its lines are read,
+// and stopping at the first error of fn, which is returned. Once all
+// commits are read, an ErrParseDrift error is returned when a file has
+// negative line changes, or when numstat lines couldn't be parsed, which
+// are kept in the Malformed lines of their commits and counted in the
+// error.
+func readCommits(r io.Reader, fn func(commit) error) error {
+	var c *commit
+	var negative error
+	var malformed int
+
+	// flush passes the commit read so far to fn
+	flush := func() error {
+		if c == nil {
+			return nil
+		}
+		for _, f := range c.Files {
+			if negative == nil && (f.Additions < 0 || f.Deletions < 0) {
+				negative = fmt.Errorf("%w: %s of commit %s has %d additions and %d deletions",
+					ErrParseDrift, f.Path, c.Hash, f.Additions, f.Deletions)
+			}
+		}
+		return fn(*c)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := toUTF8(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "\x00") {
+			if err := flush(); err != nil {
+				return err
+			}
+			header, err := parseCommitHeader(line)
+			if err != nil {
+				return err
+			}
+			c = &header
+			continue
+		}
+
+		if c == nil {
+			return fmt.Errorf("numstat line before any commit: %q", line)
+		}
+
+		f, err := parseNumstat(line)
+		if err != nil {
+			debugf("skipping %s", err)
+			c.Malformed = append(c.Malformed, line)
+			malformed++
+			continue
+		}
+
+		c.Files = append(c.Files, f)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if negative != nil {
+		return negative
+	}
+	if malformed > 0 {
+		return fmt.Errorf("%w: skipped %d malformed numstat lines", ErrParseDrift, malformed)
+	}
+	return nil
+}
+
+// parseCommitHeader parses a commit header line written with
+// commitFormat.
+func parseCommitHeader(line string) (commit, error) {
+	fields := strings.Split(line, "\x00")
+	if len(fields) != 7 {
+		return commit{}, fmt.Errorf("malformed commit header: %q", line)
+	}
+
+	date, err := time.Parse(time.RFC3339, fields[5])
+	if err != nil {
+		return commit{}, fmt.Errorf("error parsing date of %s: %w", fields[1], err)
+	}
+
+	return commit{
+		Hash:    fields[1],
+		Parents: strings.Fields(fields[2]),
+		Author:  fields[3],
+		Email:   fields[4],
+		Date:    date,
+		Subject: fields[6],
+	}, nil
+}
+
+// parseNumstat parses a single numstat line of additions, deletions and
+// path, separated by tabs. Binary files have dashes instead of numbers.
+func parseNumstat(line string) (fileChange, error) {
+	fields := strings.SplitN(line, "\t", 3)
+	if len(fields) != 3 {
+		return fileChange{}, fmt.Errorf("malformed numstat line: %q", line)
+	}
+
+	var f fileChange
+	f.OldPath, f.Path = splitRename(fields[2])
+	f.OldPath, f.Path = unquotePath(f.OldPath), unquotePath(f.Path)
+	if fields[0] == "-" && fields[1] == "-" {
+		return f, nil
+	}
+
+	var err error
+	f.Additions, err = strconv.Atoi(fields[0])
+	if err != nil {
+		return fileChange{}, fmt.Errorf("error parsing additions: %w", err)
+	}
+
+	f.Deletions, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return fileChange{}, fmt.Errorf("error parsing deletions: %w", err)
+	}
+
+	return f, nil
+}
+
+// sumNumstat returns the sum of the line changes of the numstat lines of
+// the git output, skipping any other lines.
+func sumNumstat(gitOutput string) LineChanges {
+	var sum LineChanges
+	for _, line := range strings.Split(gitOutput, "\n") {
+		if f, err := parseNumstat(line); err == nil {
+			sum.Additions += f.Additions
+			sum.Deletions += f.Deletions
+		}
+	}
+	return sum
+}
+
+// splitRename returns the old and new paths of a numstat path, which git
+// writes as "old => new" for renamed files, or with the differing part in
+// braces, like "cmd/{old => new}/main.go". The old path is empty for
+// files that weren't renamed.
+func splitRename(path string) (oldPath, newPath string) {
+	i := strings.Index(path, "{")
+	j := strings.LastIndex(path, "}")
+	if i >= 0 && j > i {
+		o, n, ok := strings.Cut(path[i+1:j], " => ")
+		if ok {
+			prefix, suffix := path[:i], path[j+1:]
+			return joinRenamePath(prefix, o, suffix), joinRenamePath(prefix, n, suffix)
+		}
+	}
+
+	o, n, ok := strings.Cut(path, " => ")
+	if ok {
+		return o, n
+	}
+
+	return "", path
+}
+
+// unquotePath returns a path of numstat output as is, unless git quoted
+// it like a C string for holding tabs, quotes or non-ASCII characters,
+// which are written as octal escapes.
+func unquotePath(path string) string {
+	if len(path) < 2 || path[0] != '"' || path[len(path)-1] != '"' {
+		return path
+	}
+
+	unquoted, err := strconv.Unquote(path)
+	if err != nil {
+		return path
+	}
+	return unquoted
+}
+
+// joinRenamePath joins the parts of a path split by a rename, in which the
+// braced part is empty for directories added or removed by the rename.
+func joinRenamePath(prefix, part, suffix string) string {
+	path := prefix + part + suffix
+	path = strings.ReplaceAll(path, "//", "/")
+	return strings.TrimPrefix(path, "/")
+}
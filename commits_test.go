@@ -0,0 +1,302 @@
+package gitcontrib
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_ParseCommits(t *testing.T) {
+	output := "\x00abc\x00p1\x00Author One\x00one@example.com\x002023-03-02T10:00:00+01:00\x00Add things\n" +
+		"\n" +
+		"3\t1\tmain.go\n" +
+		"-\t-\tlogo.png\n" +
+		"\x00def\x00p1 p2\x00Author Two\x00two@example.com\x002023-03-01T09:00:00Z\x00Merge branch 'x'\n" +
+		"\x00ghi\x00\x00Author Two\x00two@example.com\x002023-02-28T09:00:00Z\x00Fix\n" +
+		"\n" +
+		"0\t2\tdir/with space.go\n" +
+		"x\ty\tbroken.go\n"
+
+	commits, err := parseCommits(output)
+	if !errors.Is(err, ErrParseDrift) || !strings.Contains(err.Error(), "skipped 1 malformed numstat lines") {
+		t.Fatalf("Expected ErrParseDrift for the malformed numstat line, got: %v", err)
+	}
+
+	if len(commits) != 3 {
+		t.Fatalf("Expected 3 commits, got: %+v", commits)
+	}
+
+	c := commits[0]
+	if c.Hash != "abc" || c.Author != "Author One" || c.Email != "one@example.com" || c.Subject != "Add things" {
+		t.Errorf("unexpected commit header: %+v", c)
+	}
+	if !c.Date.Equal(time.Date(2023, 3, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date: %s", c.Date)
+	}
+	if len(c.Files) != 2 || c.Files[1].Path != "logo.png" || c.Files[1].Sum() != 0 {
+		t.Errorf("unexpected files: %+v", c.Files)
+	}
+	if lc := c.LineChanges(); lc.Additions != 3 || lc.Deletions != 1 {
+		t.Errorf("unexpected line changes: %v", lc)
+	}
+
+	if len(commits[1].Files) != 0 {
+		t.Errorf("Expected no files for merge, got: %+v", commits[1].Files)
+	}
+
+	if !commits[1].isMerge() || commits[0].isMerge() || len(commits[2].Parents) != 0 {
+		t.Errorf("unexpected parents: %q, %q, %q", commits[0].Parents, commits[1].Parents, commits[2].Parents)
+	}
+
+	if commits[2].Files[0].Path != "dir/with space.go" {
+		t.Errorf("unexpected path: %q", commits[2].Files[0].Path)
+	}
+	if len(commits[2].Malformed) != 1 || len(commits[2].Files) != 1 {
+		t.Errorf("Expected the malformed numstat line to be kept apart, got: %+v", commits[2])
+	}
+
+	_, err = parseCommits("1\t2\tfile.go\n")
+	if err == nil {
+		t.Errorf("Expected error for numstat line before any commit")
+	}
+}
+
+func Test_SplitRename(t *testing.T) {
+	tests := []struct{ path, oldPath, newPath string }{
+		{"main.go", "", "main.go"},
+		{"a.txt => b.txt", "a.txt", "b.txt"},
+		{"cmd/{old => new}/main.go", "cmd/old/main.go", "cmd/new/main.go"},
+		{"cmd/{ => client}/client.go", "cmd/client.go", "cmd/client/client.go"},
+		{"{lib => }/util.go", "lib/util.go", "util.go"},
+	}
+
+	for _, tt := range tests {
+		oldPath, newPath := splitRename(tt.path)
+		if oldPath != tt.oldPath || newPath != tt.newPath {
+			t.Errorf("splitRename(%q) = %q, %q, expected %q, %q",
+				tt.path, oldPath, newPath, tt.oldPath, tt.newPath)
+		}
+	}
+}
+
+func Test_ParseNumstatQuoted(t *testing.T) {
+	tests := []struct{ line, oldPath, newPath string }{
+		{"1\t0\tmy dir/file name.txt", "", "my dir/file name.txt"},
+		{"1\t0\t\"my dir/\\303\\251 name.txt\"", "", "my dir/é name.txt"},
+		{"0\t0\t\"tab\\tx.txt\" => \"tab\\ty.txt\"", "tab\tx.txt", "tab\ty.txt"},
+		{"0\t0\tmy dir/{a b.txt => c d.txt}", "my dir/a b.txt", "my dir/c d.txt"},
+	}
+
+	for _, tt := range tests {
+		f, err := parseNumstat(tt.line)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %s", tt.line, err)
+			continue
+		}
+		if f.OldPath != tt.oldPath || f.Path != tt.newPath {
+			t.Errorf("parseNumstat(%q) paths = %q, %q, expected %q, %q",
+				tt.line, f.OldPath, f.Path, tt.oldPath, tt.newPath)
+		}
+	}
+}
+
+func Test_MaxCommitLines(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.commit("Author Two", "vendor.txt", strings.Repeat("x\n", 1000))
+	r.commit("Author Two", "two.txt", "1\n")
+
+	logs := captureLogs(t)
+	o := r.options()
+	o.maxCommitLines = 100
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error extracting line changes: %s", err)
+	}
+	if lineChanges["Author Two"] != (LineChanges{Additions: 1}) || lineChanges["Author One"] != (LineChanges{Additions: 2}) {
+		t.Errorf("Expected the giant commit to be skipped, got %v", lineChanges)
+	}
+
+	commits, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	if commits["Author Two"] != 2 {
+		t.Errorf("Expected the giant commit to still count, got %v", commits)
+	}
+
+	o.excludeCommit = true
+	commits, err = authorCommits(o)
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	if commits["Author Two"] != 1 || commits["Author One"] != 1 {
+		t.Errorf("Expected the giant commit to be excluded, got %v", commits)
+	}
+
+	logs.Reset()
+	if _, err := mapLineChanges(o); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "skipped 1 commit changing more than 100 lines") {
+		t.Errorf("Expected the skipped commits to be reported, got %q", logs.String())
+	}
+}
+
+func Test_CodeOnly(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "main.go", "package main\n")
+	r.commit("Author Two", "README.md", "# Title\n\nText\n")
+	r.commit("Author Two", "lib.PY", "x = 1\n")
+
+	o := r.options()
+	o.codeOnly = true
+
+	commits, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	if commits["Author One"] != 1 || commits["Author Two"] != 1 {
+		t.Errorf("Expected the docs-only commit to be excluded, got %v", commits)
+	}
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error extracting line changes: %s", err)
+	}
+	if lineChanges["Author Two"] != (LineChanges{Additions: 1}) {
+		t.Errorf("Expected the docs-only line changes to be excluded, got %v", lineChanges)
+	}
+
+	o.codeExts = stringList{".md"}
+	commits, err = authorCommits(o)
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	if len(commits) != 1 || commits["Author Two"] != 1 {
+		t.Errorf("Expected only the commit of --code-ext md, got %v", commits)
+	}
+}
+
+func Test_ParseCommitsDrift(t *testing.T) {
+	out := "\x00abc\x00\x00Author\x00a@example.com\x002023-05-01T10:00:00+02:00\x00Subject\n3\t-1\ta.go\n"
+
+	commits, err := parseCommits(out)
+	if !errors.Is(err, ErrParseDrift) || !strings.Contains(err.Error(), "a.go of commit abc has 3 additions and -1 deletions") {
+		t.Fatalf("Expected ErrParseDrift for the negative deletions, got: %v", err)
+	}
+	if len(commits) != 1 {
+		t.Errorf("Expected the commit to be kept, got: %v", commits)
+	}
+}
+
+func Test_CapFiles(t *testing.T) {
+	buf, err := os.ReadFile("testdata/numstat-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := parseCommits(string(buf))
+	if err != nil {
+		t.Fatalf("error parsing commits: %s", err)
+	}
+
+	o := options{capLinesPerFile: 500}
+	commits, capped := o.capFiles(commits)
+	if capped != 49800 {
+		t.Errorf("Expected 49800 added lines to be capped, got %d", capped)
+	}
+
+	exp := []LineChanges{{12, 4}, {530, 2}, {500, 0}}
+	for i, c := range commits {
+		if got := c.LineChanges(); got != exp[i] {
+			t.Errorf("Expected %v for commit %s, got %v", exp[i], c.Hash, got)
+		}
+	}
+}
+
+func Test_CapLinesPerFile(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.commit("Author Two", "vendor.txt", strings.Repeat("x\n", 1000))
+	r.commit("Author Two", "two.txt", "1\n")
+
+	logs := captureLogs(t)
+	o := r.options()
+	o.capLinesPerFile = 100
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error extracting line changes: %s", err)
+	}
+	if lineChanges["Author Two"] != (LineChanges{Additions: 101}) || lineChanges["Author One"] != (LineChanges{Additions: 2}) {
+		t.Errorf("Expected the giant file to be capped, got %v", lineChanges)
+	}
+	if !strings.Contains(logs.String(), "capped 900 added lines") {
+		t.Errorf("Expected the capped lines to be reported, got %q", logs.String())
+	}
+
+	o.capLinesPerFile = -1
+	if err := o.resolve(); err == nil {
+		t.Error("Expected a negative cap to be rejected")
+	}
+}
+
+func Test_DiffFilter(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "a.txt", "1\n2\n3\n")
+	r.write("a.txt", "1\n2\nchanged\n")
+	r.write("b.txt", "1\n2\n")
+	r.git("add", ".")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Author Two", "GIT_AUTHOR_EMAIL=two@example.com"}, "commit", "-qm", "modify a, add b")
+
+	o := r.options()
+	o.diffFilter = "A"
+	want := map[string]LineChanges{
+		"Author One": {Additions: 3},
+		"Author Two": {Additions: 2},
+	}
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error extracting line changes: %s", err)
+	}
+	if !reflect.DeepEqual(lineChanges, want) {
+		t.Errorf("Expected only the lines of added files, got %v", lineChanges)
+	}
+
+	o.maxCommitLines = 1000
+	lineChanges, err = mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error extracting line changes of the commits: %s", err)
+	}
+	if !reflect.DeepEqual(lineChanges, want) {
+		t.Errorf("Expected only the lines of added files of the commits, got %v", lineChanges)
+	}
+
+	o.diffFilter = "AZ"
+	if err := o.resolve(); err == nil {
+		t.Error("Expected an invalid filter letter to be rejected")
+	}
+}
+
+func Test_RejectCommitFilters(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+
+	for _, args := range [][]string{
+		{"--code-only", "one.txt"},
+		{"--max-commit-lines", "10", "one.txt"},
+	} {
+		args = append([]string{"--repo", r.dir}, args...)
+		if err := FileCmd.Call(FileCmd, args...); err == nil || !strings.Contains(err.Error(), "can't be used with file") {
+			t.Errorf("Expected %v to be refused, got: %v", args, err)
+		}
+	}
+	if err := ReviewersCmd.Call(ReviewersCmd, "--repo", r.dir, "--exclude-commit"); err == nil {
+		t.Error("Expected --exclude-commit to be refused by reviewers")
+	}
+}
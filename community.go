@@ -0,0 +1,124 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// contributor is an author along with their first commit.
+type contributor struct {
+	Author string
+	Hash   string
+	First  time.Time
+}
+
+// NewContributors returns the authors of the checked out branch whose
+// first commit was made at or after since, sorted by the date of that
+// commit.
+func NewContributors(since time.Time) ([]string, error) {
+	newcomers, _, err := newContributors(options{since: since.Format(time.RFC3339)})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(newcomers))
+	for i, c := range newcomers {
+		names[i] = c.Author
+	}
+
+	return names, nil
+}
+
+// newContributors returns the authors whose first non-merge commit in the
+// whole history of the revision of o is in the history of o, as limited by
+// its time limits or range, sorted by the date of that commit, then by
+// name. The number of authors in the whole history is returned as well.
+func newContributors(o options) ([]contributor, int, error) {
+	whole := o
+	whole.since, whole.until = "", ""
+	if i := strings.LastIndex(whole.rev, ".."); i >= 0 {
+		whole.rev = whole.rev[i+2:]
+	}
+
+	out, err := whole.walk("log", "--no-merges", o.logFormat("--format=%H%x00%aN%x00%aI"))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	first, err := parseFirstCommits(out)
+	if err != nil {
+		return nil, 0, err
+	}
+	for k, c := range first {
+		c.First = o.inZone(c.First)
+		first[k] = c
+	}
+
+	out, err = o.walk("log", "--no-merges", "--format=%H")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	inPeriod := make(map[string]bool)
+	for _, hash := range strings.Fields(out) {
+		inPeriod[hash] = true
+	}
+
+	var newcomers []contributor
+	for _, c := range first {
+		if inPeriod[c.Hash] {
+			newcomers = append(newcomers, c)
+		}
+	}
+	sort.Slice(newcomers, func(i, j int) bool {
+		if !newcomers[i].First.Equal(newcomers[j].First) {
+			return newcomers[i].First.Before(newcomers[j].First)
+		}
+		return newcomers[i].Author < newcomers[j].Author
+	})
+
+	return newcomers, len(first), nil
+}
+
+// parseFirstCommits returns the first commit of each author in the output
+// of git log with the hash, author name and date of each commit separated
+// by NUL bytes, newest first. Of commits with the same date, the one
+// listed last is taken as the first.
+func parseFirstCommits(gitOutput string) (map[string]contributor, error) {
+	first := make(map[string]contributor)
+
+	scanner := bufio.NewScanner(strings.NewReader(gitOutput))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed log line: %q", line)
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date of %s: %w", fields[0], err)
+		}
+
+		c, ok := first[fields[1]]
+		if !ok || !date.After(c.First) {
+			first[fields[1]] = contributor{fields[1], fields[0], date}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return first, nil
+}
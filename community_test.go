@@ -0,0 +1,43 @@
+package gitcontrib
+
+import "testing"
+
+func Test_NewContributors(t *testing.T) {
+	r := newTestRepo(t)
+	commitAt := func(author, name, date string) {
+		r.write(name, author+"\n")
+		r.git("add", name)
+		r.gitEnv([]string{
+			"GIT_AUTHOR_NAME=" + author,
+			"GIT_AUTHOR_DATE=" + date,
+			"GIT_COMMITTER_DATE=" + date,
+		}, "commit", "-q", "-m", "change "+name)
+	}
+	commitAt("Old Timer", "a.txt", "2020-01-01T10:00:00Z")
+	commitAt("Boundary", "b.txt", "2023-01-01T00:00:00Z")
+	commitAt("Old Timer", "c.txt", "2023-02-01T10:00:00Z")
+	commitAt("Newcomer", "d.txt", "2023-03-01T10:00:00Z")
+
+	o := r.options()
+	o.since = "2023-01-01T00:00:00Z"
+	newcomers, total, err := newContributors(o)
+	if err != nil {
+		t.Fatalf("error finding new contributors: %s", err)
+	}
+
+	if total != 3 {
+		t.Errorf("Expected 3 contributors in total, got: %d", total)
+	}
+	if len(newcomers) != 2 || newcomers[0].Author != "Boundary" || newcomers[1].Author != "Newcomer" {
+		t.Errorf("Expected the author at the boundary and the newcomer, got: %+v", newcomers)
+	}
+
+	o.since = ""
+	newcomers, _, err = newContributors(o)
+	if err != nil {
+		t.Fatalf("error finding new contributors: %s", err)
+	}
+	if len(newcomers) != 3 || newcomers[0].Author != "Old Timer" {
+		t.Errorf("Expected all authors new without a period, got: %+v", newcomers)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// findAuthor returns the summary of the single author whose name matches
+// the regular expression, ignoring case. An author named exactly like the
+// expression is chosen even when others match it too.
+func findAuthor(summaries []AuthorSummary, expr string) (AuthorSummary, error) {
+	names := make([]string, len(summaries))
+	for i, s := range summaries {
+		names[i] = s.Author
+	}
+
+	name, err := matchAuthor(names, expr)
+	if err != nil {
+		return AuthorSummary{}, err
+	}
+	for _, s := range summaries {
+		if s.Author == name {
+			return s, nil
+		}
+	}
+	return AuthorSummary{}, nil
+}
+
+// matchAuthor returns the single one of the author names matching the
+// regular expression, like findAuthor.
+func matchAuthor(names []string, expr string) (string, error) {
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return "", fmt.Errorf("invalid author expression: %w", err)
+	}
+
+	var matches []string
+	for _, name := range names {
+		if name == expr {
+			return name, nil
+		}
+		if re.MatchString(name) {
+			matches = append(matches, name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no author matches %q", expr)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf(
+			"%q matches several authors: %s",
+			expr, strings.Join(matches, ", "),
+		)
+	}
+}
+
+// compareRows returns the cells of the 'compare' table, headed by the
+// names of the authors, with a row per metric of the 'summary' report
+// holding the values of both and the difference of the first to the
+// second.
+func compareRows(a, b AuthorSummary) [][]string {
+	rows := [][]string{{"Metric", a.Author, b.Author, "Delta"}}
+	for _, c := range columns {
+		if c.name == "author" || c.enabled != nil {
+			continue
+		}
+		rows = append(rows, []string{c.header, c.text(a), c.text(b), c.delta(a, b)})
+	}
+
+	return rows
+}
+
+// delta returns the signed difference of the values of the column for a
+// and b, in percentage points for percentages, blank when either has
+// none.
+func (c column) delta(a, b AuthorSummary) string {
+	switch x := c.value(a).(type) {
+	case int:
+		if y, ok := c.value(b).(int); ok {
+			return fmt.Sprintf("%+d", x-y)
+		}
+	case float64:
+		if y, ok := c.value(b).(float64); ok {
+			if c.percent {
+				return fmt.Sprintf("%+.1f", 100*(x-y))
+			}
+			return fmt.Sprintf("%+.3f", x-y)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_FindAuthor(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Alice Smith"},
+		{Author: "Bob"},
+		{Author: "Bobby Tables"},
+	}
+
+	s, err := findAuthor(summaries, "alice")
+	if err != nil || s.Author != "Alice Smith" {
+		t.Errorf("Expected Alice Smith, got %q, %v", s.Author, err)
+	}
+
+	s, err = findAuthor(summaries, "Bob")
+	if err != nil || s.Author != "Bob" {
+		t.Errorf("Expected the exact name to win, got %q, %v", s.Author, err)
+	}
+
+	_, err = findAuthor(summaries, "bob")
+	if err == nil || !strings.Contains(err.Error(), "Bob, Bobby Tables") {
+		t.Errorf("Expected the matching authors listed, got %v", err)
+	}
+
+	if _, err := findAuthor(summaries, "carol"); err == nil {
+		t.Error("Expected an error for an author matching nobody")
+	}
+}
+
+func Test_CompareRows(t *testing.T) {
+	a := AuthorSummary{Author: "Alice", Commits: 5, Additions: 30, Deletions: 10, LineRatio: 0.8, CommitRatio: 0.625, Granularity: 0.125, AdditionsRatio: 1, DeletionsRatio: 0.5}
+	b := AuthorSummary{Author: "Bob", Commits: 3, Additions: 0, Deletions: 10, LineRatio: 0.2, CommitRatio: 0.375, Granularity: 0.3, DeletionsRatio: 0.5}
+
+	rows := compareRows(a, b)
+	exp := [][]string{
+		{"Metric", "Alice", "Bob", "Delta"},
+		{"Commits", "5", "3", "+2"},
+		{"Additions", "30", "0", "+30"},
+		{"Deletions", "10", "10", "+0"},
+		{"Net", "20", "-10", "+30"},
+		{"Line ratio", "0.800", "0.200", "+0.600"},
+		{"Commit ratio", "0.625", "0.375", "+0.250"},
+		{"Granularity", "0.125", "0.300", "-0.175"},
+		{"Add ratio", "1.000", "0.000", "+1.000"},
+		{"Del ratio", "0.500", "0.500", "+0.000"},
+		{"Del/add ratio", "0.333", "∞", ""},
+		{"Add %", "75.0%", "0.0%", "+75.0"},
+		{"Del %", "25.0%", "100.0%", "-75.0"},
+	}
+	if !reflect.DeepEqual(rows, exp) {
+		t.Errorf("Expected rows\n%v\ngot\n%v", exp, rows)
+	}
+}
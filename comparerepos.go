@@ -0,0 +1,126 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// repoMetric is a row of the 'compare-repos' table, giving a metric of
+// the RepoMetrics of a repo as an int, a float64 or a time.Time.
+type repoMetric struct {
+	header string
+	value  func(m RepoMetrics) interface{}
+}
+
+// repoMetrics are the rows of the 'compare-repos' table, in their order.
+var repoMetrics = []repoMetric{
+	{"Commits", func(m RepoMetrics) interface{} { return m.Commits }},
+	{"Contributors", func(m RepoMetrics) interface{} { return m.Authors }},
+	{"Bus Factor", func(m RepoMetrics) interface{} { return m.BusFactor }},
+	{"Additions", func(m RepoMetrics) interface{} { return m.Additions }},
+	{"Deletions", func(m RepoMetrics) interface{} { return m.Deletions }},
+	{"Churn", func(m RepoMetrics) interface{} { return m.Additions + m.Deletions }},
+	{"Granularity", func(m RepoMetrics) interface{} { return m.Granularity }},
+	{"Gini", func(m RepoMetrics) interface{} { return m.Gini }},
+	{"First Commit", func(m RepoMetrics) interface{} { return m.First }},
+	{"Last Commit", func(m RepoMetrics) interface{} { return m.Last }},
+	{"Days", func(m RepoMetrics) interface{} { return m.Days }},
+}
+
+// summarizeRepo returns the 'summary' report of the repo in the directory,
+// with the history and authors selected by o and ff, along with its date
+// span, which the reports of a recursive scan go without. An error
+// analysing the repo is held by the report rather than returned.
+func summarizeRepo(o options, ff *filterFlags, dir string) repoSummary {
+	r := repoSummary{dir: dir}
+	o.dir = dir
+
+	if r.err = o.resolve(); r.err != nil {
+		return r
+	}
+	if r.Summaries, r.Totals, r.err = ff.collect(o); r.err != nil {
+		return r
+	}
+	if r.Repo, r.err = getRepoDirName(o); r.err != nil {
+		r.err = fmt.Errorf("error getting repo name: %w", r.err)
+	}
+
+	return r
+}
+
+// compareRepoRows returns the cells of the 'compare-repos' table, headed
+// by the names of the repos, with a row per metric holding the values of
+// both and the difference of the first to the second. The cells of a
+// repo that couldn't be analysed are blank, as are the differences. A
+// repo is named after its directory as given when it has no name, or the
+// same as the other.
+func compareRepoRows(a, b repoSummary) [][]string {
+	names := [2]string{a.Repo, b.Repo}
+	if names[0] == "" || names[0] == names[1] {
+		names[0] = a.dir
+	}
+	if names[1] == "" || names[0] == names[1] {
+		names[1] = b.dir
+	}
+
+	var metrics [2]*RepoMetrics
+	for i, r := range []repoSummary{a, b} {
+		if r.err == nil {
+			m := Aggregate(r.Summaries, r.Totals)
+			metrics[i] = &m
+		}
+	}
+
+	rows := [][]string{{"Metric", names[0], names[1], "Delta"}}
+	for _, rm := range repoMetrics {
+		row := []string{rm.header, "", "", ""}
+		for i, m := range metrics {
+			if m != nil {
+				row[i+1] = metricText(rm.value(*m))
+			}
+		}
+		if metrics[0] != nil && metrics[1] != nil {
+			row[3] = metricDelta(rm.value(*metrics[0]), rm.value(*metrics[1]))
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// metricText returns the value of a metric as written in the
+// 'compare-repos' table, dates being blank when there are no commits.
+func metricText(v interface{}) string {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return fmt.Sprintf("%.3f", v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02")
+	}
+	return ""
+}
+
+// metricDelta returns the signed difference of the values x and y of a
+// metric, blank for dates, whose difference is that of the days.
+func metricDelta(x, y interface{}) string {
+	switch x := x.(type) {
+	case int:
+		if y, ok := y.(int); ok {
+			return fmt.Sprintf("%+d", x-y)
+		}
+	case float64:
+		if y, ok := y.(float64); ok {
+			return fmt.Sprintf("%+.3f", x-y)
+		}
+	}
+	return ""
+}
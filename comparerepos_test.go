@@ -0,0 +1,65 @@
+package gitcontrib
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_CompareRepoRows(t *testing.T) {
+	small := newTestRepo(t)
+	small.commit("Author One", "a.txt", "1\n")
+
+	big := newTestRepo(t)
+	big.commit("Author One", "a.txt", "1\n2\n3\n")
+	big.commit("Author Two", "b.txt", "1\n2\n3\n4\n")
+	big.commit("Author Two", "a.txt", "1\n")
+
+	repos := []repoSummary{
+		summarizeRepo(options{}, &filterFlags{}, big.dir),
+		summarizeRepo(options{}, &filterFlags{}, small.dir),
+	}
+	for _, r := range repos {
+		if r.err != nil {
+			t.Fatalf("error analysing %s: %s", r.dir, r.err)
+		}
+	}
+
+	rows := compareRepoRows(repos[0], repos[1])
+	if h := rows[0]; h[1] != repos[0].Repo || h[2] != repos[1].Repo || h[3] != "Delta" {
+		t.Errorf("Expected the repos to head the columns, got %q", h)
+	}
+
+	expected := map[string][]string{
+		"Commits":      {"3", "1", "+2"},
+		"Contributors": {"2", "1", "+1"},
+		"Bus Factor":   {"1", "1", "+0"},
+		"Additions":    {"7", "1", "+6"},
+		"Deletions":    {"2", "0", "+2"},
+		"Churn":        {"9", "1", "+8"},
+		"Days":         {"0", "0", "+0"},
+	}
+	for _, row := range rows[1:] {
+		if exp, ok := expected[row[0]]; ok && !reflect.DeepEqual(row[1:], exp) {
+			t.Errorf("Expected %s of %q, got %q", row[0], exp, row[1:])
+		}
+	}
+
+	empty := summarizeRepo(options{}, &filterFlags{}, newTestRepo(t).dir)
+	if !errors.Is(empty.err, ErrNoCommits) {
+		t.Fatalf("Expected ErrNoCommits for a repo without commits, got: %v", empty.err)
+	}
+	rows = compareRepoRows(repos[0], empty)
+	if row := rows[1]; row[1] != "3" || row[2] != "" || row[3] != "" {
+		t.Errorf("Expected blank cells for a repo without commits, got %q", row)
+	}
+
+	repos[1] = repoSummary{dir: "missing", err: errors.New("not a repo")}
+	rows = compareRepoRows(repos[0], repos[1])
+	if rows[0][2] != "missing" {
+		t.Errorf("Expected a failed repo to be named after its directory, got %q", rows[0][2])
+	}
+	if row := rows[1]; row[1] != "3" || row[2] != "" || row[3] != "" {
+		t.Errorf("Expected blank cells for a failed repo, got %q", row)
+	}
+}
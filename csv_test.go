@@ -0,0 +1,181 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func Test_CsvPrecision(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.commit("Author Two", "two.txt", "1\n2\n")
+
+	run := func(args ...string) []string {
+		t.Helper()
+		out := filepath.Join(t.TempDir(), "out.csv")
+		args = append(args, "--repo", r.dir, "--output", out)
+		if err := CsvContributionSummaryCmd.Call(CsvContributionSummaryCmd, args...); err != nil {
+			t.Fatalf("error running csv summary: %s", err)
+		}
+		buf, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSpace(string(buf)), "\n")
+	}
+
+	if got := run()[0]; !strings.HasSuffix(got, ",0.333,0.500,1.000") {
+		t.Errorf("Expected three decimals by default, got: %s", got)
+	}
+	if got := run("--precision", "6")[0]; !strings.HasSuffix(got, ",0.333333,0.500000,1.000000") {
+		t.Errorf("Expected six decimals, got: %s", got)
+	}
+	if got := run("--precision", "-1")[0]; !strings.HasSuffix(got, ",0.3333333333333333,0.5,1") {
+		t.Errorf("Expected full precision, got: %s", got)
+	}
+}
+
+func Test_CsvDeterministic(t *testing.T) {
+	r := newTestRepo(t)
+	for _, author := range []string{"Author C", "Author A", "Author B", "Author D", "Author E"} {
+		r.commit(author, author+".txt", "1\n")
+	}
+	r.commit("Author E", "e.txt", "1\n2\n")
+
+	run := func(cmd *Z.Cmd, args ...string) string {
+		t.Helper()
+		out := filepath.Join(t.TempDir(), "out.csv")
+		args = append(args, "--repo", r.dir, "--output", out)
+		if err := cmd.Call(cmd, args...); err != nil {
+			t.Fatalf("error running csv %s: %s", cmd.Name, err)
+		}
+		buf, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf)
+	}
+
+	for _, cmd := range []*Z.Cmd{CsvAuthorCommitsCmd, CsvAuthorChangesCmd, CsvContributionSummaryCmd} {
+		first := run(cmd)
+		for i := 0; i < 5; i++ {
+			if got := run(cmd); got != first {
+				t.Fatalf("Expected identical output of csv %s, got:\n%s\nand:\n%s", cmd.Name, first, got)
+			}
+		}
+	}
+
+	lines := strings.Split(run(CsvAuthorCommitsCmd), "\n")
+	if !strings.Contains(lines[0], `"Author E",2`) || !strings.Contains(lines[1], `"Author A",1`) {
+		t.Errorf("Expected the most commits first, ties by author, got:\n%s", strings.Join(lines, "\n"))
+	}
+
+	lines = strings.Split(run(CsvAuthorChangesCmd, "--sort", "author"), "\n")
+	if !strings.Contains(lines[0], `"Author A"`) || !strings.Contains(lines[4], `"Author E"`) {
+		t.Errorf("Expected rows sorted by author, got:\n%s", strings.Join(lines, "\n"))
+	}
+
+	err := CsvAuthorCommitsCmd.Call(CsvAuthorCommitsCmd, "--repo", r.dir, "--sort", "size")
+	if err == nil {
+		t.Error("Expected error for unknown sort key")
+	}
+}
+
+func Test_CsvWithTotals(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.commit("Author Two", "two.txt", "1\n2\n")
+	r.commit("Author Two", "two.txt", "1\n2\n3\n")
+	repo := filepath.Base(r.dir)
+
+	run := func(cmd *Z.Cmd, args ...string) []string {
+		t.Helper()
+		out := filepath.Join(t.TempDir(), "out.csv")
+		args = append(args, "--repo", r.dir, "--output", out)
+		if err := cmd.Call(cmd, args...); err != nil {
+			t.Fatalf("error running csv %s: %s", cmd.Name, err)
+		}
+		buf, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSpace(string(buf)), "\n")
+	}
+
+	for _, tc := range []struct {
+		cmd  *Z.Cmd
+		args []string
+		exp  string
+	}{
+		{CsvAuthorCommitsCmd, nil, `"TOTAL",3`},
+		{CsvAuthorChangesCmd, nil, `"TOTAL",4,0`},
+		{CsvContributionSummaryCmd, nil, `"TOTAL",3,4,0,1.000,1.000,0.750`},
+		{CsvContributionSummaryCmd, []string{"--columns", "author,net"}, `"TOTAL",4`},
+	} {
+		lines := run(tc.cmd, append(tc.args, "--with-totals")...)
+		if got, exp := lines[len(lines)-1], `"`+repo+`",`+tc.exp; got != exp {
+			t.Errorf("Expected csv %s %v to end with %s, got: %s", tc.cmd.Name, tc.args, exp, got)
+		}
+
+		for _, line := range run(tc.cmd, tc.args...) {
+			if strings.Contains(line, "TOTAL") {
+				t.Errorf("Expected no totals row of csv %s without --with-totals, got: %s", tc.cmd.Name, line)
+			}
+		}
+	}
+}
+
+func Test_CsvRepoNameOverride(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+
+	for _, cmd := range []*Z.Cmd{CsvContributionSummaryCmd, CsvAuthorCommitsCmd, CsvAuthorChangesCmd} {
+		out := filepath.Join(t.TempDir(), "out.csv")
+		args := []string{"--repo", r.dir, "--repo-name", "project", "--full-path", "--output", out}
+		if err := cmd.Call(cmd, args...); err != nil {
+			t.Fatalf("error running csv %s: %s", cmd.Name, err)
+		}
+		buf, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(buf), `"project","Author One",`) {
+			t.Errorf("Expected the repo to be named project by csv %s, got: %s", cmd.Name, buf)
+		}
+	}
+
+	err := CsvContributionSummaryCmd.Call(CsvContributionSummaryCmd, "--repo", r.dir, "--repo-name", "project", "--recursive")
+	if err == nil || !strings.Contains(err.Error(), "--recursive") {
+		t.Errorf("Expected --repo-name to be rejected with --recursive, got: %v", err)
+	}
+
+	o := options{dir: r.dir, repoName: "project"}
+	if name, err := getRepoDirName(o); err != nil || name != "project" {
+		t.Errorf("Expected the name of the metric labels to be project, got: %q, %v", name, err)
+	}
+}
+
+func Test_CsvStrict(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.git("checkout", "-q", "-b", "feature")
+	r.commit("Author One", "two.txt", "1\n")
+	r.git("checkout", "-q", "main")
+	r.commit("Author One", "three.txt", "1\n")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Author Two"}, "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+
+	out := filepath.Join(t.TempDir(), "out.csv")
+	args := []string{"--repo", r.dir, "--output", out}
+	if err := CsvContributionSummaryCmd.Call(CsvContributionSummaryCmd, args...); err != nil {
+		t.Fatalf("error running csv summary: %s", err)
+	}
+
+	err := CsvContributionSummaryCmd.Call(CsvContributionSummaryCmd, append(args, "--strict")...)
+	if err == nil || !strings.Contains(err.Error(), "only in line changes: Author Two") {
+		t.Errorf("Expected the author of only a merge to fail --strict, got: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// cumulativeMonth holds the added lines of each author up to the end of a
+// calendar month, in the order of the authors of the report.
+type cumulativeMonth struct {
+	Month string `json:"month"` // like 2023-01
+	Lines []int  `json:"lines"`
+}
+
+// cumulativeReport is the matrix of the 'cumulative' command, of the
+// added lines of each author up to the end of each month, for stacked
+// area charts of the growth of the contributions.
+type cumulativeReport struct {
+	Authors []string          `json:"authors"`
+	Months  []cumulativeMonth `json:"months"`
+}
+
+// cumulativeLines returns the added lines of each author of the non-merge
+// commits up to the end of each calendar month, from the month of the
+// first commit to that of the last, months without commits included. The
+// authors are sorted by descending added lines at the end, then by name,
+// and have zeros in the months before their first commit.
+func cumulativeLines(commits []commit) cumulativeReport {
+	var first, last int
+	var found bool
+	added := make(map[string]map[int]int) // author to month to lines
+	totals := make(map[string]int)
+	for _, c := range commits {
+		if c.isMerge() {
+			continue
+		}
+		m := monthIndex(c.Date)
+		if !found || m < first {
+			first = m
+		}
+		if !found || m > last {
+			last = m
+		}
+		found = true
+
+		if added[c.Author] == nil {
+			added[c.Author] = make(map[int]int)
+		}
+		lines := c.LineChanges().Additions
+		added[c.Author][m] += lines
+		totals[c.Author] += lines
+	}
+	if !found {
+		return cumulativeReport{}
+	}
+
+	var r cumulativeReport
+	for author := range added {
+		r.Authors = append(r.Authors, author)
+	}
+	sort.Slice(r.Authors, func(i, j int) bool {
+		a, b := r.Authors[i], r.Authors[j]
+		if totals[a] != totals[b] {
+			return totals[a] > totals[b]
+		}
+		return a < b
+	})
+
+	sums := make([]int, len(r.Authors))
+	for m := first; m <= last; m++ {
+		for i, author := range r.Authors {
+			sums[i] += added[author][m]
+		}
+		r.Months = append(r.Months, cumulativeMonth{monthName(m), append([]int(nil), sums...)})
+	}
+
+	return r
+}
+
+// writeTable writes the human-readable 'cumulative' report to w,
+// a row per month with a column per author.
+func (r cumulativeReport) writeTable(w io.Writer) error {
+	t := newTable(1, append([]string{"Month"}, r.Authors...)...)
+	for _, m := range r.Months {
+		t.row(append([]string{m.Month}, itoas(m.Lines)...)...)
+	}
+	return t.write(w)
+}
+
+// writeCSV writes the 'cumulative' report to w as CSV, headed by a row of
+// "month" and the authors, quoted as needed.
+func (r cumulativeReport) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"month"}, r.Authors...))
+	for _, m := range r.Months {
+		cw.Write(append([]string{m.Month}, itoas(m.Lines)...))
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing csv: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes the 'cumulative' report to w as a JSON object.
+func (r cumulativeReport) writeJSON(w io.Writer) error {
+	if r.Authors == nil {
+		r.Authors, r.Months = []string{}, []cumulativeMonth{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
+
+// itoas returns the numbers as strings.
+func itoas(ns []int) []string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_CumulativeLines(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	files := func(additions int) []fileChange {
+		return []fileChange{{Path: "a.go", LineChanges: LineChanges{Additions: additions, Deletions: 1}}}
+	}
+
+	// newest first, like git log writes them
+	commits := []commit{
+		{Author: "Bob, Jr.", Date: date("2023-04-02"), Files: files(50)},
+		{Author: "Carol", Date: date("2023-04-01"), Files: files(15)},
+		{Author: "Alice", Date: date("2023-04-01"), Parents: []string{"p", "q"}},
+		{Author: "Alice", Date: date("2023-01-20"), Files: files(5)},
+		{Author: "Alice", Date: date("2023-01-10"), Files: files(10)},
+	}
+
+	var csv bytes.Buffer
+	if err := cumulativeLines(commits).writeCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	exp := `month,"Bob, Jr.",Alice,Carol
+2023-01,0,15,0
+2023-02,0,15,0
+2023-03,0,15,0
+2023-04,50,15,15
+`
+	if csv.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, csv.String())
+	}
+
+	var json bytes.Buffer
+	if err := cumulativeLines(nil).writeJSON(&json); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "{\"authors\":[],\"months\":[]}\n"; json.String() != exp {
+		t.Errorf("Expected %q without commits, got: %q", exp, json.String())
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// WeightedContributions returns the recency-weighted line changes of each
+// author in the checked out branch. The line changes of each commit are
+// weighted by how long ago it was authored, the weight halving for every
+// halfLife of age, so that a commit authored now counts in full and one
+// authored halfLife ago counts half.
+func WeightedContributions(halfLife time.Duration) (map[string]float64, error) {
+	return weightedContributions(options{}, halfLife, time.Now())
+}
+
+// weightedContributions returns the line changes of each author in the
+// revision or range of o, weighted by their age at now.
+func weightedContributions(
+	o options,
+	halfLife time.Duration,
+	now time.Time,
+) (map[string]float64, error) {
+
+	if halfLife <= 0 {
+		return nil, errors.New("half-life must be positive")
+	}
+
+	commits, err := logCommits(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return weighCommits(commits, halfLife, now), nil
+}
+
+// weighCommits sums the line changes of each author's commits, each
+// weighted by decayWeight.
+func weighCommits(
+	commits []commit,
+	halfLife time.Duration,
+	now time.Time,
+) map[string]float64 {
+
+	weighted := make(map[string]float64)
+	for _, c := range commits {
+		lines := float64(c.LineChanges().Sum())
+		weighted[c.Author] += lines * decayWeight(now.Sub(c.Date), halfLife)
+	}
+
+	return weighted
+}
+
+// decayWeight returns the exponential decay weight of something of the
+// given age, 1 when new and halving for every halfLife. Negative ages, as
+// from commits with dates in the future, weigh 1.
+func decayWeight(age, halfLife time.Duration) float64 {
+	if age < 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
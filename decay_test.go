@@ -0,0 +1,31 @@
+package gitcontrib
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_WeighCommits(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	lines := func(n int) []fileChange {
+		return []fileChange{{Path: "file", LineChanges: LineChanges{Additions: n}}}
+	}
+
+	commits := []commit{
+		{Author: "New", Date: now, Files: lines(10)},
+		{Author: "New", Date: now.Add(day), Files: lines(1)},
+		{Author: "Old", Date: now.Add(-10 * day), Files: lines(10)},
+		{Author: "Older", Date: now.Add(-20 * day), Files: lines(10)},
+	}
+
+	weighted := weighCommits(commits, 10*day, now)
+
+	expected := map[string]float64{"New": 11, "Old": 5, "Older": 2.5}
+	for k, v := range expected {
+		if math.Abs(weighted[k]-v) > 1e-9 {
+			t.Errorf("Expected %s to weigh %v, got: %v", k, v, weighted[k])
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+// commitAuthors returns the names of the authors of the commits, each
+// listed once, in the order of their first commit.
+func commitAuthors(commits []commit) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, c := range commits {
+		if !seen[c.Author] {
+			seen[c.Author] = true
+			names = append(names, c.Author)
+		}
+	}
+	return names
+}
+
+// isPureDeletion reports whether the commit only removed lines, deleting
+// some without adding any to any of its files. Merge commits, listed
+// without files, and commits only touching binary files, listed without
+// line changes, are not.
+func isPureDeletion(c commit) bool {
+	lc := c.LineChanges()
+	return lc.Additions == 0 && lc.Deletions > 0
+}
+
+// pureDeletions returns the commits of the author only removing lines, in
+// the order of the commits.
+func pureDeletions(commits []commit, author string) []commit {
+	var deletions []commit
+	for _, c := range commits {
+		if c.Author == author && isPureDeletion(c) {
+			deletions = append(deletions, c)
+		}
+	}
+	return deletions
+}
@@ -0,0 +1,60 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_PureDeletions(t *testing.T) {
+	buf, err := os.ReadFile("testdata/numstat-deletions")
+	if err != nil {
+		t.Fatalf("unable to read file: %s", err)
+	}
+
+	commits, err := parseCommits(string(buf))
+	if err != nil {
+		t.Fatalf("unable to parse output: %s", err)
+	}
+
+	if got, exp := commitAuthors(commits), []string{"Author One", "Author Two"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected authors %v, got: %v", exp, got)
+	}
+
+	var hashes []string
+	for _, c := range pureDeletions(commits, "Author One") {
+		hashes = append(hashes, c.Hash)
+	}
+	// the mixed, binary-only and merge commits are left out, as is the
+	// pure deletion of the other author
+	if exp := []string{"abc6", "abc1"}; !reflect.DeepEqual(hashes, exp) {
+		t.Errorf("Expected pure deletions %v, got: %v", exp, hashes)
+	}
+}
+
+func Test_DeletionsCmd(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n3\n")
+	r.commit("Author Two", "one.txt", "1\n")
+	r.commit("Author Two", "one.txt", "1\n4\n")
+
+	out := filepath.Join(t.TempDir(), "out")
+	if err := DeletionsCmd.Call(DeletionsCmd, "--repo", r.dir, "--output", out, "two"); err != nil {
+		t.Fatalf("error running deletions: %s", err)
+	}
+	buf, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[2], " 2") {
+		t.Errorf("Expected the commit deleting 2 lines, got:\n%s", buf)
+	}
+
+	if err := DeletionsCmd.Call(DeletionsCmd, "--repo", r.dir, "author"); err == nil || !strings.Contains(err.Error(), "several authors") {
+		t.Errorf("Expected an ambiguous author to be rejected, got: %v", err)
+	}
+}
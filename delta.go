@@ -0,0 +1,87 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// authorDelta is the change of the summary of an author since a previous
+// report, with the summary missing from either report being zero.
+type authorDelta struct {
+	Author string
+	Now    AuthorSummary
+	Before AuthorSummary
+	Status string // "new", "gone", or empty for authors in both reports
+}
+
+// diffReports returns the changes of the summaries since the previous
+// ones, in the order of the current summaries, followed by the authors
+// only in the previous ones, the departed, sorted by name. The authors
+// only in the current summaries are the new ones.
+func diffReports(before, now []AuthorSummary) []authorDelta {
+	previous := make(map[string]AuthorSummary, len(before))
+	for _, s := range before {
+		previous[s.Author] = s
+	}
+
+	current := make(map[string]bool, len(now))
+	deltas := make([]authorDelta, 0, len(now))
+	for _, s := range now {
+		current[s.Author] = true
+		d := authorDelta{Author: s.Author, Now: s}
+		if p, ok := previous[s.Author]; ok {
+			d.Before = p
+		} else {
+			d.Status = "new"
+		}
+		deltas = append(deltas, d)
+	}
+
+	var gone []authorDelta
+	for _, s := range before {
+		if !current[s.Author] {
+			gone = append(gone, authorDelta{Author: s.Author, Before: s, Status: "gone"})
+		}
+	}
+	sort.Slice(gone, func(i, j int) bool { return gone[i].Author < gone[j].Author })
+
+	return append(deltas, gone...)
+}
+
+// writeDeltaTable writes the changes of the authors to w as a table of
+// their current commits and line changes, each followed by its change
+// since the previous report, followed by the numbers of new and departed
+// authors.
+func writeDeltaTable(w io.Writer, deltas []authorDelta) error {
+	signed := func(n int) string { return fmt.Sprintf("%+d", n) }
+
+	var added, gone int
+	t := newTable(1, "Author", "Commits", "+/-", "Additions", "+/-", "Deletions", "+/-", "Status")
+	for _, d := range deltas {
+		t.row(
+			d.Author,
+			strconv.Itoa(d.Now.Commits), signed(d.Now.Commits-d.Before.Commits),
+			strconv.Itoa(d.Now.Additions), signed(d.Now.Additions-d.Before.Additions),
+			strconv.Itoa(d.Now.Deletions), signed(d.Now.Deletions-d.Before.Deletions),
+			d.Status,
+		)
+		switch d.Status {
+		case "new":
+			added++
+		case "gone":
+			gone++
+		}
+	}
+	if err := t.write(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n New contributors: %d\n", added)
+	fmt.Fprintf(w, " Departed contributors: %d\n", gone)
+	return nil
+}
@@ -0,0 +1,48 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_DiffReports(t *testing.T) {
+	before := []AuthorSummary{
+		{Author: "Stays", Commits: 5, Additions: 50, Deletions: 5},
+		{Author: "Left B", Commits: 2, Additions: 20},
+		{Author: "Left A", Commits: 1, Additions: 1},
+	}
+	now := []AuthorSummary{
+		{Author: "Joined", Commits: 1, Additions: 3},
+		{Author: "Stays", Commits: 8, Additions: 70, Deletions: 4},
+	}
+
+	deltas := diffReports(before, now)
+
+	var got []string
+	for _, d := range deltas {
+		got = append(got, d.Author+":"+d.Status)
+	}
+	exp := []string{"Joined:new", "Stays:", "Left A:gone", "Left B:gone"}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected the new authors, then the departed by name, %v, got %v", exp, got)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeDeltaTable(buf, deltas); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"Joined        1   +1          3   +3          0   +0     new",
+		"Stays         8   +3         70  +20          4   -1",
+		"Left A        0   -1          0   -1          0   +0    gone",
+		"New contributors: 1",
+		"Departed contributors: 2",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the delta table:\n%s", exp, out)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// detailsSpecial replaces the characters having a meaning in Markdown
+// text, other than those escaped by html.EscapeString, with their HTML
+// character references, which show the same whether the text ends up
+// being read as Markdown or as HTML.
+var detailsSpecial = strings.NewReplacer(
+	`\`, "&#92;", "`", "&#96;", `*`, "&#42;", `_`, "&#95;", `[`, "&#91;",
+	`]`, "&#93;", `#`, "&#35;", `|`, "&#124;", `~`, "&#126;",
+)
+
+// escapeDetails escapes the text for both Markdown and the HTML of the
+// markdown-details format.
+func escapeDetails(s string) string {
+	return detailsSpecial.Replace(html.EscapeString(s))
+}
+
+// writeSummaryDetails writes the summaries to w as GitHub Markdown, each
+// author in a collapsible block, headed by their name and commits, and
+// holding a table of the metrics of the other shown columns, like:
+//
+//	<details><summary>Author One — 120 commits</summary>
+//
+//	| Metric | Value |
+//	| --- | --- |
+//	| Additions | 4000 |
+//	...
+//
+//	</details>
+//
+// The blank lines let GitHub render the table within the block.
+func writeSummaryDetails(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	var cols []column
+	for _, c := range opts.shownColumns() {
+		if c.name != "author" {
+			cols = append(cols, c)
+		}
+	}
+
+	var b strings.Builder
+	for i, s := range summaries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		noun := "commits"
+		if s.Commits == 1 {
+			noun = "commit"
+		}
+		fmt.Fprintf(&b, "<details><summary>%s — %d %s</summary>\n\n", escapeDetails(s.Author), s.Commits, noun)
+
+		b.WriteString("| Metric | Value |\n| --- | --- |\n")
+		for _, c := range cols {
+			fmt.Fprintf(&b, "| %s | %s |\n", escapeDetails(c.header), escapeDetails(c.text(s)))
+		}
+
+		b.WriteString("\n</details>\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("error writing markdown: %w", err)
+	}
+
+	return nil
+}
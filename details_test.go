@@ -0,0 +1,54 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_WriteSummaryDetails(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author <One> & *Co*", Commits: 2, Additions: 10, Deletions: 3},
+		{Author: "Two", Commits: 1, Additions: 1},
+	}
+
+	opts := renderOptions{columns: []column{mustColumn(t, "author"), mustColumn(t, "commits"), mustColumn(t, "additions")}}
+	buf := new(bytes.Buffer)
+	if err := writeSummaryDetails(buf, summaries, Totals{}, opts); err != nil {
+		t.Fatalf("error rendering markdown details: %s", err)
+	}
+
+	exp := `<details><summary>Author &lt;One&gt; &amp; &#42;Co&#42; — 2 commits</summary>
+
+| Metric | Value |
+| --- | --- |
+| Commits | 2 |
+| Additions | 10 |
+
+</details>
+
+<details><summary>Two — 1 commit</summary>
+
+| Metric | Value |
+| --- | --- |
+| Commits | 1 |
+| Additions | 1 |
+
+</details>
+`
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf)
+	}
+	if strings.Contains(buf.String(), "<One>") {
+		t.Errorf("Expected the author to be escaped, got:\n%s", buf)
+	}
+}
+
+func mustColumn(t *testing.T, name string) column {
+	t.Helper()
+	c, ok := findColumn(name)
+	if !ok {
+		t.Fatalf("unknown column %s", name)
+	}
+	return c
+}
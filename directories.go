@@ -0,0 +1,78 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"sort"
+	"strings"
+)
+
+// rootDirectory is the directory the files at the top level of the repo
+// are counted under.
+const rootDirectory = "."
+
+// directoryOwner holds the author with the most line changes in a top-level
+// directory, along with their changes and those of all authors.
+type directoryOwner struct {
+	Directory string
+	Author    string
+	Lines     int
+	Total     int
+}
+
+// Share returns the share of the line changes of the directory made by
+// its owner.
+func (d directoryOwner) Share() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(d.Lines) / float64(d.Total)
+}
+
+// topDirectory returns the top-level directory of a path as written by
+// git, or rootDirectory for files at the top level.
+func topDirectory(path string) string {
+	dir, _, ok := strings.Cut(path, "/")
+	if !ok {
+		return rootDirectory
+	}
+	return dir
+}
+
+// directoryOwners returns the owner of each top-level directory touched
+// by the commits, being the author with the most changed lines in it, ties
+// going to the first name alphabetically. Directories of only binary
+// changes are left out. The owners are sorted by directory.
+func directoryOwners(commits []commit) []directoryOwner {
+	lines := make(map[string]map[string]int) // directory to author to lines
+	for _, c := range commits {
+		for _, f := range c.Files {
+			dir := topDirectory(f.Path)
+			if lines[dir] == nil {
+				lines[dir] = make(map[string]int)
+			}
+			lines[dir][c.Author] += f.Sum()
+		}
+	}
+
+	var owners []directoryOwner
+	for dir, authors := range lines {
+		o := directoryOwner{Directory: dir}
+		for author, n := range authors {
+			o.Total += n
+			if n > o.Lines || n == o.Lines && (o.Author == "" || author < o.Author) {
+				o.Author, o.Lines = author, n
+			}
+		}
+		if o.Total > 0 {
+			owners = append(owners, o)
+		}
+	}
+
+	sort.Slice(owners, func(i, j int) bool {
+		return owners[i].Directory < owners[j].Directory
+	})
+
+	return owners
+}
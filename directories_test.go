@@ -0,0 +1,47 @@
+package gitcontrib
+
+import "testing"
+
+func Test_TopDirectory(t *testing.T) {
+	for path, exp := range map[string]string{
+		"main.go":         ".",
+		"cmd/main.go":     "cmd",
+		"internal/a/b.go": "internal",
+	} {
+		if got := topDirectory(path); got != exp {
+			t.Errorf("Expected %q for %q, got: %q", exp, path, got)
+		}
+	}
+}
+
+func Test_DirectoryOwners(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "cmd/main.go", "1\n2\n3\n")
+	r.commit("Author Two", "cmd/flags.go", "1\n")
+	r.commit("Author Two", "README", "1\n2\n")
+	r.commit("Author One", "docs/a.md", "1\n")
+	r.commit("Author Two", "docs/b.md", "1\n")
+
+	commits, err := logCommits(r.options())
+	if err != nil {
+		t.Fatalf("error reading commits: %s", err)
+	}
+
+	exp := []directoryOwner{
+		{Directory: ".", Author: "Author Two", Lines: 2, Total: 2},
+		{Directory: "cmd", Author: "Author One", Lines: 3, Total: 4},
+		{Directory: "docs", Author: "Author One", Lines: 1, Total: 2},
+	}
+	owners := directoryOwners(commits)
+	if len(owners) != len(exp) {
+		t.Fatalf("Expected %d directories, got: %+v", len(exp), owners)
+	}
+	for i := range exp {
+		if owners[i] != exp[i] {
+			t.Errorf("Expected %+v, got: %+v", exp[i], owners[i])
+		}
+	}
+	if share := owners[1].Share(); share != 0.75 {
+		t.Errorf("Expected a share of 0.75, got: %f", share)
+	}
+}
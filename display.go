@@ -0,0 +1,123 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The ways --display labels the authors of the reports.
+const (
+	displayName  = "name"
+	displayEmail = "email"
+	displayBoth  = "both"
+)
+
+// checkDisplay returns an error for a --display that isn't one of the
+// above.
+func checkDisplay(mode string) error {
+	switch mode {
+	case displayName, displayEmail, displayBoth:
+		return nil
+	}
+	return fmt.Errorf("invalid --display %q, must be %s, %s or %s", mode, displayName, displayEmail, displayBoth)
+}
+
+// authorEmailCounts returns the number of commits of each author in the
+// revision or range of o, using HEAD when none is given, by the email
+// they were made with, along with the emails of each author in the order
+// first seen, being from the most recent commit back.
+func authorEmailCounts(o options) (map[string]map[string]int, map[string][]string, error) {
+	out, err := o.walk("log", o.logFormat("--format=%aN <%aE>"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts, order := parseAuthorEmails(out)
+	return counts, order, nil
+}
+
+// parseAuthorEmails counts the emails of each author in the output of git
+// log with the format "%aN <%aE>", returning the emails of each author in
+// the order first seen too.
+func parseAuthorEmails(gitOutput string) (map[string]map[string]int, map[string][]string) {
+	counts := make(map[string]map[string]int)
+	order := make(map[string][]string)
+
+	for _, line := range strings.Split(gitOutput, "\n") {
+		i := strings.LastIndex(line, " <")
+		if i <= 0 || !strings.HasSuffix(line, ">") {
+			continue
+		}
+		name, email := line[:i], line[i+2:len(line)-1]
+
+		if counts[name] == nil {
+			counts[name] = make(map[string]int)
+		}
+		if counts[name][email] == 0 {
+			order[name] = append(order[name], email)
+		}
+		counts[name][email]++
+	}
+
+	return counts, order
+}
+
+// representativeEmails returns the email shown for each author, the one
+// they made most commits with, ties going to the most recently used. The
+// authors merged by ids are counted under their canonical name.
+func representativeEmails(counts map[string]map[string]int, order map[string][]string, ids teams) map[string]string {
+	merged := make(map[string]map[string]int)
+	mergedOrder := make(map[string][]string)
+	for name, emails := range order {
+		canonical := ids.name(name)
+		if merged[canonical] == nil {
+			merged[canonical] = make(map[string]int)
+		}
+		for _, e := range emails {
+			if merged[canonical][e] == 0 {
+				mergedOrder[canonical] = append(mergedOrder[canonical], e)
+			}
+			merged[canonical][e] += counts[name][e]
+		}
+	}
+
+	chosen := make(map[string]string, len(merged))
+	for name, emails := range mergedOrder {
+		var best string
+		var most int
+		for _, e := range emails {
+			if e != "" && merged[name][e] > most {
+				best, most = e, merged[name][e]
+			}
+		}
+		if best != "" {
+			chosen[name] = best
+		}
+	}
+
+	return chosen
+}
+
+// displayLabel returns the label of the author of the given name and
+// email as chosen by --display. An author without an email, like a team,
+// is labelled by name.
+func displayLabel(name, email, mode string) string {
+	switch {
+	case email == "" || mode == displayName:
+		return name
+	case mode == displayEmail:
+		return email
+	}
+	return name + " <" + email + ">"
+}
+
+// setDisplay relabels the authors of the summaries as chosen by --display,
+// given their emails.
+func setDisplay(summaries []AuthorSummary, emails map[string]string, mode string) {
+	for i, s := range summaries {
+		summaries[i].Author = displayLabel(s.Author, emails[s.Author], mode)
+	}
+}
@@ -0,0 +1,44 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Display(t *testing.T) {
+	out := "Author One <one@new.example>\n" +
+		"Author One <one@old.example>\n" +
+		"Author One <one@old.example>\n" +
+		"Author Two <two@example.com>\n" +
+		"A2 <two@work.example>\n" +
+		"A2 <two@work.example>\n" +
+		"No Email <>\n"
+
+	counts, order := parseAuthorEmails(out)
+	emails := representativeEmails(counts, order, teams{"A2": "Author Two"})
+	exp := map[string]string{
+		"Author One": "one@old.example",
+		"Author Two": "two@work.example",
+	}
+	if !reflect.DeepEqual(emails, exp) {
+		t.Errorf("Expected emails %v, got %v", exp, emails)
+	}
+
+	summaries := []AuthorSummary{{Author: "Author One"}, {Author: "Author Two"}, {Author: "No Email"}}
+	setDisplay(summaries, emails, displayBoth)
+	for i, exp := range []string{"Author One <one@old.example>", "Author Two <two@work.example>", "No Email"} {
+		if summaries[i].Author != exp {
+			t.Errorf("Expected %q, got %q", exp, summaries[i].Author)
+		}
+	}
+
+	if l := displayLabel("Author One", "one@old.example", displayEmail); l != "one@old.example" {
+		t.Errorf("Expected the email, got %q", l)
+	}
+	if l := displayLabel("Author One", "one@old.example", displayName); l != "Author One" {
+		t.Errorf("Expected the name, got %q", l)
+	}
+	if err := checkDisplay("login"); err == nil {
+		t.Error("Expected an error for an unknown --display")
+	}
+}
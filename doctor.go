@@ -0,0 +1,186 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// doctorCheck is the outcome of a check of the 'doctor' command, failed
+// when Err is set.
+type doctorCheck struct {
+	Name   string
+	Detail string
+	Err    error
+}
+
+// diagnose checks that git is found and that the output of each of the
+// git invocations of the reports, run against the repo of o, is parsed
+// into sane numbers. The checks stop at the first one the others depend
+// on failing.
+func diagnose(o options) []doctorCheck {
+	// the commits only changing files left out by --diff-filter are still
+	// counted by shortlog, failing the checks of the parsers agreeing
+	o.diffFilter = ""
+
+	var checks []doctorCheck
+	add := func(name, detail string, err error) bool {
+		checks = append(checks, doctorCheck{name, detail, err})
+		return err == nil
+	}
+
+	path, err := exec.LookPath("git")
+	if !add("git", path, err) {
+		return checks
+	}
+
+	out, err := o.git("--version")
+	version := strings.TrimSpace(out)
+	if err == nil && !strings.HasPrefix(version, "git version ") {
+		err = fmt.Errorf("unexpected version %q", version)
+	}
+	if !add("git version", version, err) {
+		return checks
+	}
+
+	out, err = o.git("rev-parse", "--show-toplevel")
+	if !add("repository", strings.TrimSpace(out), err) {
+		return checks
+	}
+
+	if o.ref == "" && len(o.shas) == 0 {
+		out, err = o.git("branch")
+		var branch string
+		if err == nil {
+			branch, err = extractCheckedOutBranch(out)
+		}
+		if errors.Is(err, ErrDetachedHead) {
+			branch, err = "detached HEAD", nil
+		}
+		add("checked out branch", branch, err)
+	}
+
+	counts, err := authorCommits(o)
+	if err == nil {
+		err = checkCounts(counts)
+	}
+	var commitSum int
+	for _, n := range counts {
+		commitSum += n
+	}
+	add("commit counts (shortlog)", fmt.Sprintf("%d authors, %d commits", len(counts), commitSum), err)
+
+	lineChanges, malformed, err := diagnoseLineChanges(o)
+	var lineSum LineChanges
+	for _, lc := range lineChanges {
+		lineSum = lineSum.Merge(lc)
+	}
+	add("line changes (numstat)", fmt.Sprintf("%d authors, +%d -%d", len(lineChanges), lineSum.Additions, lineSum.Deletions), err)
+	if malformed > 0 {
+		add("numstat lines", "", fmt.Errorf("%d lines not parsed", malformed))
+	}
+
+	commits, err := logCommits(o)
+	if err == nil {
+		err = checkCommits(commits)
+	}
+	add("commits (commit-delimited)", fmt.Sprintf("%d commits", len(commits)), err)
+
+	var nonMerge int
+	var commitLines LineChanges
+	for _, c := range commits {
+		if !c.isMerge() {
+			nonMerge++
+		}
+		commitLines = commitLines.Merge(c.LineChanges())
+	}
+	err = nil
+	switch {
+	case nonMerge != commitSum:
+		err = fmt.Errorf("%d non-merge commits parsed, but %d counted by shortlog", nonMerge, commitSum)
+	case commitLines != lineSum:
+		err = fmt.Errorf("+%d -%d lines parsed from the commits, but +%d -%d from numstat",
+			commitLines.Additions, commitLines.Deletions, lineSum.Additions, lineSum.Deletions)
+	}
+	add("parsers agree", "", err)
+
+	return checks
+}
+
+// diagnoseLineChanges returns the line changes of each author like
+// mapLineChanges, along with the number of numstat lines that couldn't be
+// parsed.
+func diagnoseLineChanges(o options) (map[string]LineChanges, int, error) {
+	if o.maxCommitLines > 0 || o.capLinesPerFile > 0 || o.codeOnly || o.excludeReverts || len(o.attributes) > 0 {
+		lineChanges, err := mapLineChanges(o)
+		return lineChanges, 0, err
+	}
+
+	out, err := o.walk("log", "--numstat", o.logFormat(lineChangesFormat))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var malformed int
+	for _, line := range strings.Split(out, "\n") {
+		if _, err := parseNumstat(line); err != nil && numstatPrefix.MatchString(line) {
+			malformed++
+		}
+	}
+
+	lineChanges, err := parseLineChanges(out)
+	if err != nil {
+		return nil, 0, err
+	}
+	lineChanges = o.bucketLineChanges(lineChanges)
+	for author, lc := range lineChanges {
+		if author == "" || lc.Additions < 0 || lc.Deletions < 0 {
+			return lineChanges, malformed, fmt.Errorf("bad line changes %+v of author %q", lc, author)
+		}
+	}
+
+	return lineChanges, malformed, nil
+}
+
+// checkCounts returns an error when the commit counts have a blank author
+// or a count that isn't positive.
+func checkCounts(counts map[string]int) error {
+	for author, n := range counts {
+		if strings.TrimSpace(author) == "" {
+			return fmt.Errorf("blank author with %d commits", n)
+		}
+		if n <= 0 {
+			return fmt.Errorf("author %q has %d commits", author, n)
+		}
+	}
+	return nil
+}
+
+// checkCommits returns an error when a commit lacks its hash, author or
+// date, has a negative line change or numstat lines that couldn't be
+// parsed.
+func checkCommits(commits []commit) error {
+	for _, c := range commits {
+		switch {
+		case c.Hash == "":
+			return errors.New("commit without hash")
+		case c.Author == "":
+			return fmt.Errorf("commit %s without author", c.Hash)
+		case c.Date.IsZero():
+			return fmt.Errorf("commit %s without date", c.Hash)
+		}
+		if len(c.Malformed) > 0 {
+			return fmt.Errorf("commit %s has %d numstat lines not parsed", c.Hash, len(c.Malformed))
+		}
+		for _, f := range c.Files {
+			if f.Additions < 0 || f.Deletions < 0 {
+				return fmt.Errorf("commit %s has bad line changes of %s", c.Hash, f.Path)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package gitcontrib
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Diagnose(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.commit("Author Two", "two.txt", "1\n")
+
+	checks := diagnose(r.options())
+	if len(checks) < 8 {
+		t.Fatalf("Expected all checks to run, got %v", checks)
+	}
+	for _, c := range checks {
+		if c.Err != nil {
+			t.Errorf("check %q failed: %s", c.Name, c.Err)
+		}
+	}
+}
+
+func Test_DiagnoseNoRepo(t *testing.T) {
+	checks := diagnose(options{dir: t.TempDir()})
+	last := checks[len(checks)-1]
+	if last.Name != "repository" || last.Err == nil {
+		t.Errorf("Expected the checks to stop at the missing repo, got %v", checks)
+	}
+}
+
+func Test_CheckCommits(t *testing.T) {
+	date := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		commit commit
+		ok     bool
+	}{
+		{commit{Hash: "a", Author: "One", Date: date}, true},
+		{commit{Hash: "a", Date: date}, false},
+		{commit{Hash: "a", Author: "One"}, false},
+		{commit{Hash: "a", Author: "One", Date: date, Malformed: []string{"x\t1\tfile"}}, false},
+		{commit{Hash: "a", Author: "One", Date: date, Files: []fileChange{{Path: "f", LineChanges: LineChanges{-1, 0}}}}, false},
+	}
+
+	for _, tt := range tests {
+		if err := checkCommits([]commit{tt.commit}); (err == nil) != tt.ok {
+			t.Errorf("checkCommits(%+v) = %v, expected ok %t", tt.commit, err, tt.ok)
+		}
+	}
+
+	if err := checkCounts(map[string]int{"One": 1, " ": 2}); err == nil {
+		t.Error("Expected a blank author to fail")
+	}
+}
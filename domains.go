@@ -0,0 +1,76 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "strings"
+
+// domainFilter selects authors by the domains of their emails, as set by
+// the --include-domain, --exclude-domain and --drop-no-email flags.
+type domainFilter struct {
+	include     stringList
+	exclude     stringList
+	dropNoEmail bool
+}
+
+// active reports whether the filter leaves out any authors.
+func (df domainFilter) active() bool {
+	return len(df.include) > 0 || len(df.exclude) > 0 || df.dropNoEmail
+}
+
+// filtered returns the names of the authors left out by the filter, given
+// the emails of each author. Authors with an email in an excluded domain
+// are left out, as are those without any email in an included domain when
+// domains are included. Authors without any valid email are only left out
+// with dropNoEmail.
+func (df domainFilter) filtered(emails map[string][]string) map[string]bool {
+	filtered := make(map[string]bool)
+	for name, list := range emails {
+		var domains []string
+		for _, e := range list {
+			if d, ok := emailDomain(e); ok {
+				domains = append(domains, d)
+			}
+		}
+
+		var drop bool
+		switch {
+		case len(domains) == 0:
+			drop = df.dropNoEmail
+		case inDomains(domains, df.exclude):
+			drop = true
+		case len(df.include) > 0:
+			drop = !inDomains(domains, df.include)
+		}
+
+		if drop {
+			filtered[name] = true
+		}
+	}
+
+	return filtered
+}
+
+// emailDomain returns the lower-cased domain of an email, and whether the
+// email is valid, having a single '@' with text on both sides.
+func emailDomain(email string) (string, bool) {
+	local, domain, ok := strings.Cut(strings.TrimSpace(email), "@")
+	if !ok || local == "" || domain == "" || strings.ContainsAny(domain, "@ ") {
+		return "", false
+	}
+	return strings.ToLower(domain), true
+}
+
+// inDomains reports whether any of the domains equals, or is a subdomain
+// of, any of the listed ones, ignoring case.
+func inDomains(domains, listed []string) bool {
+	for _, d := range domains {
+		for _, l := range listed {
+			l = strings.ToLower(strings.TrimPrefix(l, "@"))
+			if d == l || strings.HasSuffix(d, "."+l) {
+				return true
+			}
+		}
+	}
+	return false
+}
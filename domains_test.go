@@ -0,0 +1,71 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_DomainFilter(t *testing.T) {
+	emails := map[string][]string{
+		"Employee":   {"employee@Corp.example"},
+		"Subsidiary": {"sub@eu.corp.example"},
+		"Contractor": {"contractor@agency.example", "contractor@corp.example"},
+		"Outsider":   {"outsider@mail.example"},
+		"Anonymous":  {"", "not an email"},
+	}
+
+	tests := []struct {
+		df  domainFilter
+		exp []string
+	}{
+		{domainFilter{}, nil},
+		{domainFilter{include: stringList{"corp.example"}}, []string{"Outsider"}},
+		{domainFilter{exclude: stringList{"@corp.example"}}, []string{"Contractor", "Employee", "Subsidiary"}},
+		{domainFilter{dropNoEmail: true}, []string{"Anonymous"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for name := range tt.df.filtered(emails) {
+			got = append(got, name)
+		}
+		sort.Strings(got)
+
+		if !reflect.DeepEqual(got, tt.exp) {
+			t.Errorf("%+v: Expected %v left out, got: %v", tt.df, tt.exp, got)
+		}
+	}
+}
+
+func Test_DomainFilterCollect(t *testing.T) {
+	r := newTestRepo(t)
+	for _, a := range []struct{ name, email, file string }{
+		{"Employee", "employee@corp.example", "one.txt"},
+		{"Outsider", "outsider@mail.example", "two.txt"},
+		{"Anonymous", "", "three.txt"},
+	} {
+		r.write(a.file, "1\n")
+		r.git("add", a.file)
+		r.gitEnv(
+			[]string{"GIT_AUTHOR_NAME=" + a.name, "GIT_AUTHOR_EMAIL=" + a.email},
+			"commit", "-q", "-m", "change "+a.file,
+		)
+	}
+
+	var ff filterFlags
+	ff.domains.exclude = stringList{"corp.example"}
+	summaries, _, err := ff.collect(r.options())
+	if err != nil {
+		t.Fatalf("error collecting summary: %s", err)
+	}
+
+	var got []string
+	for _, s := range summaries {
+		got = append(got, s.Author)
+	}
+	sort.Strings(got)
+	if exp := []string{"Anonymous", "Outsider"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got: %v", exp, got)
+	}
+}
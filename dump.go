@@ -0,0 +1,32 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// writeDump writes the parsed commit counts and line changes to w as
+// indented JSON, with the authors sorted.
+func writeDump(
+	w io.Writer,
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) error {
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(struct {
+		Commits     map[string]int         `json:"commits"`
+		LineChanges map[string]LineChanges `json:"line_changes"`
+	}{commitMap, lineChangesMap})
+	if err != nil {
+		return fmt.Errorf("error encoding json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_WriteDump(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := writeDump(buf,
+		map[string]int{"Author <One>": 2},
+		map[string]LineChanges{"Author <One>": {Additions: 3, Deletions: 1}},
+	)
+	if err != nil {
+		t.Fatalf("error writing dump: %s", err)
+	}
+
+	exp := `{
+  "commits": {
+    "Author <One>": 2
+  },
+  "line_changes": {
+    "Author <One>": {
+      "additions": 3,
+      "deletions": 1,
+      "sum": 4
+    }
+  }
+}
+`
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// otherCategory is the category of the commits matching no other.
+const otherCategory = "other"
+
+// category is a kind of work, recognized by its pattern matching the
+// subjects of the commits doing it.
+type category struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// defaultCategories are the categories used without any --category flag,
+// matching the common keywords of commit subjects, like the types of
+// Conventional Commits.
+var defaultCategories = categories{
+	{"fix", regexp.MustCompile(`(?i)\bfix`)},
+	{"feat", regexp.MustCompile(`(?i)\bfeat`)},
+	{"refactor", regexp.MustCompile(`(?i)\brefactor`)},
+	{"test", regexp.MustCompile(`(?i)\btest`)},
+	{"docs", regexp.MustCompile(`(?i)\bdocs?\b`)},
+}
+
+// categories is the list of categories set by the repeatable --category
+// flag, in the order they are tried.
+type categories []category
+
+func (cs *categories) String() string {
+	defs := make([]string, len(*cs))
+	for i, c := range *cs {
+		defs[i] = c.Name + "=" + c.Pattern.String()
+	}
+	return strings.Join(defs, " ")
+}
+
+// Set adds the category of a --category flag, given as NAME=REGEX. The
+// expression ignores case.
+func (cs *categories) Set(def string) error {
+	name, expr, ok := strings.Cut(def, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || expr == "" {
+		return fmt.Errorf("invalid category %q, expected NAME=REGEX", def)
+	}
+
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return fmt.Errorf("invalid expression of category %q: %w", name, err)
+	}
+
+	*cs = append(*cs, category{name, re})
+	return nil
+}
+
+// classify returns the name of the first category matching the subject,
+// or otherCategory when none does.
+func (cs categories) classify(subject string) string {
+	for _, c := range cs {
+		if c.Pattern.MatchString(subject) {
+			return c.Name
+		}
+	}
+	return otherCategory
+}
+
+// effortRow holds the commits and changed lines of a category, of a
+// single author when split by author.
+type effortRow struct {
+	Category string
+	Author   string
+	Commits  int
+	Lines    int
+}
+
+// effort tallies the non-merge commits and their changed lines by the
+// category of their subjects, and by author when asked for. The rows
+// follow the order of the categories, with otherCategory last, and the
+// authors of a category are sorted by descending commits, then by name.
+func effort(commits []commit, cs categories, byAuthor bool) []effortRow {
+	type key struct{ category, author string }
+	tally := make(map[key]*effortRow)
+
+	for _, c := range commits {
+		if c.isMerge() {
+			continue
+		}
+
+		k := key{category: cs.classify(c.Subject)}
+		if byAuthor {
+			k.author = c.Author
+		}
+
+		r, ok := tally[k]
+		if !ok {
+			r = &effortRow{Category: k.category, Author: k.author}
+			tally[k] = r
+		}
+		r.Commits++
+		r.Lines += c.LineChanges().Sum()
+	}
+
+	order := make(map[string]int, len(cs)+1)
+	for i, c := range cs {
+		if _, ok := order[c.Name]; !ok {
+			order[c.Name] = i
+		}
+	}
+	order[otherCategory] = len(cs)
+
+	rows := make([]effortRow, 0, len(tally))
+	for _, r := range tally {
+		rows = append(rows, *r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if order[a.Category] != order[b.Category] {
+			return order[a.Category] < order[b.Category]
+		}
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Author < b.Author
+	})
+
+	return rows
+}
@@ -0,0 +1,71 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Classify(t *testing.T) {
+	tests := []struct{ subject, category string }{
+		{"fix: crash on empty repo", "fix"},
+		{"Fixes #12", "fix"},
+		{"feat(cli): add --sort", "feat"},
+		{"Refactored the parser", "refactor"},
+		{"Add tests for the parser", "test"},
+		{"docs: explain --since", "docs"},
+		{"Update README and doc comments", "docs"},
+		{"Prefix the output", "other"},
+		{"Bump version", "other"},
+	}
+
+	for _, tt := range tests {
+		if got := defaultCategories.classify(tt.subject); got != tt.category {
+			t.Errorf("classify(%q) = %q, expected %q", tt.subject, got, tt.category)
+		}
+	}
+}
+
+func Test_Effort(t *testing.T) {
+	lines := func(n int) []fileChange {
+		return []fileChange{{Path: "a", LineChanges: LineChanges{Additions: n}}}
+	}
+	commits := []commit{
+		{Author: "Alice", Subject: "fix: off by one", Files: lines(2)},
+		{Author: "Bob", Subject: "Fix typo", Files: lines(1)},
+		{Author: "Bob", Subject: "fix: race", Files: lines(4)},
+		{Author: "Alice", Subject: "Release 1.0", Files: lines(10)},
+		{Author: "Alice", Subject: "Merge branch 'x'", Parents: []string{"a", "b"}},
+		{Author: "Bob", Subject: "ci: cache modules", Files: lines(3)},
+	}
+
+	var cs categories
+	for _, def := range []string{"ci=^ci\\b", "fix=fix|typo"} {
+		if err := cs.Set(def); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cs.Set("broken"); err == nil {
+		t.Error("Expected an error for a category without expression")
+	}
+
+	rows := effort(commits, cs, false)
+	expected := []effortRow{
+		{Category: "ci", Commits: 1, Lines: 3},
+		{Category: "fix", Commits: 3, Lines: 7},
+		{Category: "other", Commits: 1, Lines: 10},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, rows)
+	}
+
+	rows = effort(commits, cs, true)
+	expected = []effortRow{
+		{Category: "ci", Author: "Bob", Commits: 1, Lines: 3},
+		{Category: "fix", Author: "Bob", Commits: 2, Lines: 5},
+		{Category: "fix", Author: "Alice", Commits: 1, Lines: 2},
+		{Category: "other", Author: "Alice", Commits: 1, Lines: 10},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, rows)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"math"
+	"sort"
+)
+
+// Entropy returns the Shannon entropy in bits of the shares, like those of
+// the authors in the changed lines of a file, being 0 for a single author
+// and the base-2 logarithm of their number for authors sharing equally,
+// like 1 for two. The shares are normalized by their sum, and those that
+// aren't positive are left out.
+func Entropy(shares []float64) float64 {
+	var sum float64
+	for _, s := range shares {
+		if s > 0 {
+			sum += s
+		}
+	}
+
+	var h float64
+	for _, s := range shares {
+		if s > 0 {
+			p := s / sum
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+// fileEntropy holds the entropy of the shares of the authors in the
+// changed lines of a file.
+type fileEntropy struct {
+	File    string
+	Authors int
+	Entropy float64
+}
+
+// fileEntropies returns the entropy of the authorship of each file changed
+// by the commits, following renames like fileAuthorLines. Binary files
+// have no changed lines, so they are left out, as are the authors of no
+// changed lines in a file. The files are sorted by ascending entropy, the
+// most concentrated first, then by path.
+func fileEntropies(commits []commit, followRenames bool) []fileEntropy {
+	var entropies []fileEntropy
+	for path, authors := range fileAuthorLines(commits, followRenames) {
+		var shares []float64
+		for _, n := range authors {
+			if n > 0 {
+				shares = append(shares, float64(n))
+			}
+		}
+		if len(shares) == 0 {
+			continue
+		}
+		sort.Float64s(shares) // summed in a fixed order, for stable ties
+
+		entropies = append(entropies, fileEntropy{path, len(shares), Entropy(shares)})
+	}
+
+	sort.Slice(entropies, func(i, j int) bool {
+		if entropies[i].Entropy != entropies[j].Entropy {
+			return entropies[i].Entropy < entropies[j].Entropy
+		}
+		return entropies[i].File < entropies[j].File
+	})
+
+	return entropies
+}
@@ -0,0 +1,48 @@
+package gitcontrib
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_Entropy(t *testing.T) {
+	cases := []struct {
+		shares []float64
+		exp    float64
+	}{
+		{nil, 0},
+		{[]float64{7}, 0},
+		{[]float64{1, 1}, 1},
+		{[]float64{3, 3}, 1},
+		{[]float64{1, 1, 1, 1}, 2},
+		{[]float64{1, 0, 1}, 1},
+		{[]float64{3, 1}, 0.8112781244591328},
+	}
+
+	for _, c := range cases {
+		if got := Entropy(c.shares); math.Abs(got-c.exp) > 1e-12 {
+			t.Errorf("Expected entropy %v of %v, got %v", c.exp, c.shares, got)
+		}
+	}
+}
+
+func Test_FileEntropies(t *testing.T) {
+	lc := func(a, d int) LineChanges { return LineChanges{a, d} }
+
+	commits := []commit{
+		{Author: "One", Files: []fileChange{{Path: "shared.go", LineChanges: lc(3, 2)}, {Path: "logo.png"}}},
+		{Author: "Two", Files: []fileChange{{Path: "shared.go", LineChanges: lc(5, 0)}}},
+		{Author: "Two", Files: []fileChange{{Path: "mixed.go", LineChanges: lc(1, 0)}, {Path: "solo.go", LineChanges: lc(9, 9)}}},
+		{Author: "One", Files: []fileChange{{Path: "mixed.go", LineChanges: lc(3, 0)}}},
+	}
+
+	exp := []fileEntropy{
+		{"solo.go", 1, 0},
+		{"mixed.go", 2, Entropy([]float64{1, 3})},
+		{"shared.go", 2, 1},
+	}
+	if got := fileEntropies(commits, false); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os/exec"
+)
+
+// The codes of the errors written by the machine-readable formats. They
+// are stable, so that scripts can branch on them.
+const (
+	CodeNotARepo         = "not_a_repo"
+	CodeGitMissing       = "git_missing"
+	CodeBadRef           = "bad_ref"
+	CodeNoCommits        = "no_commits"
+	CodeNoBranch         = "no_branch"
+	CodeDubiousOwnership = "dubious_ownership"
+	CodeParseDrift       = "parse_drift"
+	CodeNoContributions  = "no_contributions"
+	CodeTooManyAuthors   = "too_many_authors"
+	CodeError            = "error"
+)
+
+// Error is an error of a known kind, told by its code.
+type Error struct {
+	Code string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// errorCode returns the code of the kind of err, or CodeError when it is
+// of no known kind.
+func errorCode(err error) string {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e.Code
+	case errors.Is(err, exec.ErrNotFound):
+		return CodeGitMissing
+	case errors.Is(err, ErrNoCommits):
+		return CodeNoCommits
+	case errors.Is(err, ErrNoBranch):
+		return CodeNoBranch
+	case errors.Is(err, ErrDubiousOwnership):
+		return CodeDubiousOwnership
+	case errors.Is(err, ErrParseDrift):
+		return CodeParseDrift
+	case errors.Is(err, ErrNoContributions):
+		return CodeNoContributions
+	case errors.Is(err, ErrTooManyAuthors):
+		return CodeTooManyAuthors
+	}
+	return CodeError
+}
+
+// errReported is returned for an error already written to standard error,
+// so that the command fails without writing it again.
+var errReported = errors.New("")
+
+// writeErrorJSON writes err to w as a JSON object holding its message and
+// code.
+func writeErrorJSON(w io.Writer, err error) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}{err.Error(), errorCode(err)})
+}
@@ -0,0 +1,127 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_ErrorCode(t *testing.T) {
+	r := newTestRepo(t)
+
+	_, err := authorCommits(options{dir: t.TempDir()})
+	if got := errorCode(err); got != CodeNotARepo {
+		t.Errorf("Expected %s outside a repo, got %s for: %v", CodeNotARepo, got, err)
+	}
+
+	_, err = authorCommits(r.options())
+	if got := errorCode(err); got != CodeNoCommits {
+		t.Errorf("Expected %s without commits, got %s for: %v", CodeNoCommits, got, err)
+	}
+
+	r.commit("Author One", "a.txt", "1\n")
+	_, err = revRange(r.options(), "nope", "HEAD")
+	if got := errorCode(err); got != CodeBadRef {
+		t.Errorf("Expected %s for an unknown ref, got %s for: %v", CodeBadRef, got, err)
+	}
+
+	err = fmt.Errorf("git log: %w", exec.ErrNotFound)
+	if got := errorCode(err); got != CodeGitMissing {
+		t.Errorf("Expected %s without git, got: %s", CodeGitMissing, got)
+	}
+
+	if got := errorCode(errors.New("other")); got != CodeError {
+		t.Errorf("Expected %s for other errors, got: %s", CodeError, got)
+	}
+}
+
+func Test_WriteErrorJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := fmt.Errorf("error getting date span: %w", ErrNoCommits)
+	if err := writeErrorJSON(buf, err); err != nil {
+		t.Fatalf("error writing json: %s", err)
+	}
+
+	exp := `{"error":"error getting date span: repository has no commits","code":"no_commits"}` + "\n"
+	if buf.String() != exp {
+		t.Errorf("Expected %s, got: %s", exp, buf)
+	}
+}
+
+func Test_MaxAuthors(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "a.txt", "1\n")
+	r.commit("Author Two", "b.txt", "1\n")
+	r.commit("Author Three", "c.txt", "1\n")
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	args := []string{"--repo", r.dir, "--no-cache", "--output", out}
+	if err := ContributionSummaryCmd.Call(ContributionSummaryCmd, append(args, "--max-authors", "3")...); err != nil {
+		t.Fatalf("Expected a report of as many authors as --max-authors, got: %s", err)
+	}
+
+	os.Remove(out)
+	err := ContributionSummaryCmd.Call(ContributionSummaryCmd, append(args, "--max-authors", "2")...)
+	if !errors.Is(err, ErrTooManyAuthors) || errorCode(err) != CodeTooManyAuthors {
+		t.Errorf("Expected %v, got: %v", ErrTooManyAuthors, err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "3 reported, more than the 2") {
+		t.Errorf("Expected the numbers of authors in the error, got: %s", err)
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Error("Expected no report to be written")
+	}
+
+	err = ContributionSummaryCmd.Call(ContributionSummaryCmd, append(args, "--max-authors", "2", "--min-commits", "2")...)
+	if err != nil {
+		t.Errorf("Expected only the reported authors to count, got: %s", err)
+	}
+}
+
+func Test_FailOnEmpty(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "a.txt", "1\n")
+	r.commit("Author Two", "b.txt", "1\n")
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	args := []string{"--repo", r.dir, "--author", "Nobody", "--no-cache", "--output", out}
+	if err := ContributionSummaryCmd.Call(ContributionSummaryCmd, args...); err != nil {
+		t.Fatalf("Expected an empty report without --fail-on-empty, got: %s", err)
+	}
+
+	os.Remove(out)
+	err := ContributionSummaryCmd.Call(ContributionSummaryCmd, append(args, "--fail-on-empty")...)
+	if !errors.Is(err, ErrNoContributions) || errorCode(err) != CodeNoContributions {
+		t.Errorf("Expected %v, got: %v", ErrNoContributions, err)
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Error("Expected no report to be written")
+	}
+
+	err = RangeCmd.Call(RangeCmd, "--repo", r.dir, "--author", "Nobody", "--fail-on-empty", "--output", out, "HEAD~1", "HEAD")
+	if !errors.Is(err, ErrNoContributions) {
+		t.Errorf("Expected %v for a range, got: %v", ErrNoContributions, err)
+	}
+
+	err = RangeCmd.Call(RangeCmd, "--repo", r.dir, "--since", "2099-01-01", "--fail-on-empty", "--output", out, "HEAD~1", "HEAD")
+	if !errors.Is(err, ErrNoContributions) {
+		t.Errorf("Expected %v for a range without commits since, got: %v", ErrNoContributions, err)
+	}
+
+	if err := RangeCmd.Call(RangeCmd, "--repo", r.dir, "--fail-on-empty", "--output", out, "HEAD~1", "HEAD"); err != nil {
+		t.Errorf("Expected a range with commits to pass, got: %s", err)
+	}
+
+	empty := newTestRepo(t)
+	if code, stderr := runMain(t, "summary", "--repo", empty.dir, "--fail-on-empty"); code != 1 || !strings.Contains(stderr, ErrNoContributions.Error()) {
+		t.Errorf("Expected a repo without commits to fail with status code 1, got %d: %s", code, stderr)
+	}
+	if code, stderr := runMain(t, "summary", "--repo", empty.dir); code != 0 {
+		t.Errorf("Expected a repo without commits to pass without --fail-on-empty, got %d: %s", code, stderr)
+	}
+}
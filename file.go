@@ -0,0 +1,96 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileToucher holds the commits of an author touching a single file, and
+// the dates of the first and last of them.
+type fileToucher struct {
+	Author  string
+	Commits int
+	First   time.Time
+	Last    time.Time
+}
+
+// fileTouchers returns the authors of the non-merge commits touching the
+// file at path in the history of o, following the file across renames.
+// They are sorted by descending commit count, then by name.
+func fileTouchers(o options, path string) ([]fileToucher, error) {
+	out, err := o.walkPath(path, "log", "--follow", "--no-merges", o.logFormat("--format=%aN%x00%aI"))
+	if err != nil {
+		return nil, err
+	}
+
+	touchers, err := parseFileTouchers(out)
+	if err != nil {
+		return nil, err
+	}
+	for i := range touchers {
+		touchers[i].First = o.inZone(touchers[i].First)
+		touchers[i].Last = o.inZone(touchers[i].Last)
+	}
+
+	return touchers, nil
+}
+
+// parseFileTouchers parses the output of git log with the author name and
+// date of each commit separated by a NUL byte.
+func parseFileTouchers(gitOutput string) ([]fileToucher, error) {
+	byAuthor := make(map[string]*fileToucher)
+
+	scanner := bufio.NewScanner(strings.NewReader(gitOutput))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		author, date, ok := strings.Cut(line, "\x00")
+		if !ok {
+			return nil, fmt.Errorf("malformed log line: %q", line)
+		}
+
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date: %w", err)
+		}
+
+		ft, ok := byAuthor[author]
+		if !ok {
+			ft = &fileToucher{Author: author, First: t, Last: t}
+			byAuthor[author] = ft
+		}
+		ft.Commits++
+		if t.Before(ft.First) {
+			ft.First = t
+		}
+		if t.After(ft.Last) {
+			ft.Last = t
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	touchers := make([]fileToucher, 0, len(byAuthor))
+	for _, ft := range byAuthor {
+		touchers = append(touchers, *ft)
+	}
+	sort.Slice(touchers, func(i, j int) bool {
+		if touchers[i].Commits != touchers[j].Commits {
+			return touchers[i].Commits > touchers[j].Commits
+		}
+		return touchers[i].Author < touchers[j].Author
+	})
+
+	return touchers, nil
+}
@@ -0,0 +1,55 @@
+package gitcontrib
+
+import "testing"
+
+func Test_FileTouchers(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "old.go", "1\n2\n3\n4\n5\n")
+	r.commit("Author Two", "other.go", "1\n")
+	r.git("mv", "old.go", "new.go")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Author Two"}, "commit", "-q", "-m", "rename")
+	r.commit("Author One", "new.go", "1\n2\n3\n4\n5\n6\n")
+
+	touchers, err := fileTouchers(r.options(), "new.go")
+	if err != nil {
+		t.Fatalf("error reading file history: %s", err)
+	}
+
+	if len(touchers) != 2 {
+		t.Fatalf("Expected two authors, got: %+v", touchers)
+	}
+	if touchers[0].Author != "Author One" || touchers[0].Commits != 2 {
+		t.Errorf("Expected two commits by Author One across the rename, got: %+v", touchers[0])
+	}
+	if touchers[1].Author != "Author Two" || touchers[1].Commits != 1 {
+		t.Errorf("Expected the rename by Author Two, got: %+v", touchers[1])
+	}
+	if touchers[0].First.After(touchers[0].Last) {
+		t.Errorf("Expected first touch before last, got: %+v", touchers[0])
+	}
+}
+
+func Test_ParseFileTouchers(t *testing.T) {
+	gitOutput := "Author One\x002023-05-01T10:00:00+02:00\n" +
+		"Author One\x002021-01-01T10:00:00Z\n" +
+		"Author One\x002022-01-01T10:00:00Z\n"
+
+	touchers, err := parseFileTouchers(gitOutput)
+	if err != nil {
+		t.Fatalf("error parsing: %s", err)
+	}
+
+	if len(touchers) != 1 || touchers[0].Commits != 3 {
+		t.Fatalf("unexpected touchers: %+v", touchers)
+	}
+	if got := touchers[0].First.Format("2006-01-02"); got != "2021-01-01" {
+		t.Errorf("Expected first touch 2021-01-01, got: %s", got)
+	}
+	if got := touchers[0].Last.Format("2006-01-02"); got != "2023-05-01" {
+		t.Errorf("Expected last touch 2023-05-01, got: %s", got)
+	}
+
+	if _, err := parseFileTouchers("no separator\n"); err == nil {
+		t.Error("Expected error for malformed line")
+	}
+}
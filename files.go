@@ -0,0 +1,78 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+// MapFilesTouched returns the number of distinct files each author has
+// touched in the checked out branch, as a measure of breadth rather than
+// volume. A file touched in several commits counts once, while the paths
+// of a renamed file count as different files.
+func MapFilesTouched() (map[string]int, error) {
+	return mapFilesTouched(options{}, false)
+}
+
+// mapFilesTouched returns the number of distinct files each author has
+// touched in the revision or range of o. With followRenames, the paths of
+// a renamed file count as the same file.
+func mapFilesTouched(o options, followRenames bool) (map[string]int, error) {
+	commits, err := logCommits(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return countFilesTouched(commits, followRenames), nil
+}
+
+// countFilesTouched counts the distinct files touched by each author in
+// the commits, which are expected newest first like git log writes them.
+// With followRenames, the paths of a renamed file count as the path it
+// has after its latest rename.
+func countFilesTouched(commits []commit, followRenames bool) map[string]int {
+	touched := make(map[string]map[string]bool)
+	eachFile(commits, followRenames, func(author, path string) {
+		if touched[author] == nil {
+			touched[author] = make(map[string]bool)
+		}
+		touched[author][path] = true
+	})
+
+	counts := make(map[string]int, len(touched))
+	for author, paths := range touched {
+		counts[author] = len(paths)
+	}
+
+	return counts
+}
+
+// eachFile calls fn with the author and path of every file changed by the
+// commits, which are expected newest first like git log writes them. With
+// followRenames, the path of a renamed file is the path it has after its
+// latest rename.
+func eachFile(commits []commit, followRenames bool, fn func(author, path string)) {
+	eachFileChange(commits, followRenames, func(author string, f fileChange) {
+		fn(author, f.Path)
+	})
+}
+
+// eachFileChange calls fn with the author and change of every file changed
+// by the commits, like eachFile, the path of the change being the one the
+// file has after its latest rename with followRenames.
+func eachFileChange(commits []commit, followRenames bool, fn func(author string, f fileChange)) {
+	renamed := make(map[string]string) // old path to latest path
+
+	for _, c := range commits {
+		for _, f := range c.Files {
+			path := f.Path
+			if followRenames {
+				if latest, ok := renamed[path]; ok {
+					path = latest
+				}
+				if f.OldPath != "" {
+					renamed[f.OldPath] = path
+				}
+			}
+			f.Path = path
+			fn(c.Author, f)
+		}
+	}
+}
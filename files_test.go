@@ -0,0 +1,29 @@
+package gitcontrib
+
+import "testing"
+
+func Test_FilesTouched(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "a.txt", "1\n2\n3\n4\n5\n")
+	r.commit("Author One", "a.txt", "1\n2\n3\n4\n5\n6\n")
+	r.commit("Author Two", "c.txt", "1\n")
+	r.git("mv", "a.txt", "b.txt")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Author Two"}, "commit", "-q", "-m", "rename")
+	r.commit("Author One", "b.txt", "1\n2\n3\n4\n5\n6\n7\n")
+
+	counts, err := mapFilesTouched(r.options(), false)
+	if err != nil {
+		t.Fatalf("error getting files touched: %s", err)
+	}
+	if counts["Author One"] != 2 || counts["Author Two"] != 2 {
+		t.Errorf("unexpected files touched: %v", counts)
+	}
+
+	counts, err = mapFilesTouched(r.options(), true)
+	if err != nil {
+		t.Fatalf("error getting files touched: %s", err)
+	}
+	if counts["Author One"] != 1 || counts["Author Two"] != 2 {
+		t.Errorf("unexpected files touched following renames: %v", counts)
+	}
+}
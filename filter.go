@@ -0,0 +1,91 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "regexp"
+
+// dropMinCommits deletes the authors with fewer than min commits from
+// the given maps. Authors missing from commitMap have no commits. A nil
+// lineChangesMap is ignored.
+func dropMinCommits(
+	min int,
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) {
+	for k, v := range commitMap {
+		if v < min {
+			delete(commitMap, k)
+		}
+	}
+
+	for k := range lineChangesMap {
+		if commitMap[k] < min {
+			delete(lineChangesMap, k)
+		}
+	}
+}
+
+// dropUnmatched deletes the authors whose name does not match re from the
+// given maps, either of which may be nil.
+func dropUnmatched(
+	re *regexp.Regexp,
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) {
+	for k := range commitMap {
+		if !re.MatchString(k) {
+			delete(commitMap, k)
+		}
+	}
+
+	for k := range lineChangesMap {
+		if !re.MatchString(k) {
+			delete(lineChangesMap, k)
+		}
+	}
+}
+
+// dropAuthors deletes the authors in the given set from the given maps,
+// either of which may be nil.
+func dropAuthors(
+	authors map[string]bool,
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) {
+	for k := range authors {
+		delete(commitMap, k)
+		delete(lineChangesMap, k)
+	}
+}
+
+// filterMinCommits returns the summaries of the authors with at least min
+// commits, leaving their ratios untouched.
+func filterMinCommits(summaries []AuthorSummary, min int) []AuthorSummary {
+	return filterSummaries(summaries, func(s AuthorSummary) bool {
+		return s.Commits >= min
+	})
+}
+
+// filterAuthors returns the summaries of the authors whose name matches
+// re, leaving their ratios untouched.
+func filterAuthors(summaries []AuthorSummary, re *regexp.Regexp) []AuthorSummary {
+	return filterSummaries(summaries, func(s AuthorSummary) bool {
+		return re.MatchString(s.Author)
+	})
+}
+
+// filterSummaries returns the summaries for which keep returns true.
+func filterSummaries(
+	summaries []AuthorSummary,
+	keep func(AuthorSummary) bool,
+) []AuthorSummary {
+	filtered := make([]AuthorSummary, 0, len(summaries))
+	for _, s := range summaries {
+		if keep(s) {
+			filtered = append(filtered, s)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,50 @@
+package gitcontrib
+
+import "testing"
+
+func Test_DropMinCommits(t *testing.T) {
+	commitMap := map[string]int{
+		"Author One": 1,
+		"Author Two": 2,
+	}
+	lineChangesMap := map[string]LineChanges{
+		"Author One": {Additions: 5},
+		"Author Two": {Additions: 5},
+	}
+
+	dropMinCommits(2, commitMap, lineChangesMap)
+
+	if _, ok := commitMap["Author One"]; ok {
+		t.Errorf("Expected one-commit author to be excluded from commits")
+	}
+	if _, ok := lineChangesMap["Author One"]; ok {
+		t.Errorf("Expected one-commit author to be excluded from line changes")
+	}
+	if commitMap["Author Two"] != 2 {
+		t.Errorf("Expected two-commit author to remain in commits")
+	}
+	if _, ok := lineChangesMap["Author Two"]; !ok {
+		t.Errorf("Expected two-commit author to remain in line changes")
+	}
+}
+
+func Test_FilterMinCommits(t *testing.T) {
+	summaries, _, _ := Summarize(
+		map[string]int{"Author One": 1, "Author Two": 2},
+		map[string]LineChanges{
+			"Author One": {Additions: 5},
+			"Author Two": {Additions: 5},
+		},
+	)
+
+	filtered := filterMinCommits(summaries, 2)
+
+	if len(filtered) != 1 || filtered[0].Author != "Author Two" {
+		t.Fatalf("Expected only author two to remain, got: %+v", filtered)
+	}
+
+	// ratios stay relative to the full set of authors
+	if filtered[0].LineRatio != 0.5 {
+		t.Errorf("Expected line ratio 0.5, got: %f", filtered[0].LineRatio)
+	}
+}
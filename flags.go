@@ -0,0 +1,259 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	Z "github.com/rwxrob/bonzai/z"
+	"github.com/rwxrob/help"
+)
+
+// newFlagSet returns a flag set for parsing the arguments given to the
+// Call of x, holding the flags common to all commands, to be parsed with
+// parseFlags.
+func newFlagSet(x *Z.Cmd) *flag.FlagSet {
+	fs := flag.NewFlagSet(x.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&verbose, "verbose", false, "log the git commands run to stderr")
+	fs.TextVar(logLevel, "log-level", new(slog.LevelVar), "log the messages of this level and above, one of debug, info, warn or error")
+	fs.BoolVar(&explain, "explain", false, "log each git command line to stderr before running it")
+	fs.BoolVar(&dryRun, "dry-run", false, "log the git commands walking the history instead of running them")
+	fs.StringVar(&saveRaw, "save-raw", "", "save the raw output of each git command to a file in this directory")
+	fs.IntVar(&tableLayout.minWidth, "minwidth", tableLayout.minWidth, "minimum width of table columns, including padding")
+	fs.IntVar(&tableLayout.padding, "padding", tableLayout.padding, "spaces between table columns")
+	fs.IntVar(&tableLayout.maxNameWidth, "max-name-width", tableLayout.maxNameWidth, "truncate author names in tables to this many characters")
+	return fs
+}
+
+// exit ends the program with the given status code, and is replaced by
+// the tests.
+var exit = os.Exit
+
+// Run runs the command of the arguments of the program, like Cmd.Run,
+// ending the program with status code 2 on usage errors, and with 0 after
+// writing the help asked for, rather than with the 1 of other errors.
+func Run() {
+	setStatusCodes(Cmd, make(map[*Z.Cmd]bool))
+	Cmd.Run()
+}
+
+// setStatusCodes wraps the Call of x and of all commands below it, to end
+// the program with the status code of their error, as told by Run.
+func setStatusCodes(x *Z.Cmd, done map[*Z.Cmd]bool) {
+	if done[x] {
+		return
+	}
+	done[x] = true
+
+	if call := x.Call; call != nil {
+		x.Call = func(x *Z.Cmd, args ...string) error {
+			err := call(x, args...)
+			var ue *usageError
+			switch {
+			case errors.Is(err, flag.ErrHelp):
+				exit(0)
+			case errors.As(err, &ue):
+				exit(2)
+			default:
+				return err
+			}
+			return errReported
+		}
+	}
+
+	for _, c := range x.Commands {
+		setStatusCodes(c, done)
+	}
+}
+
+// usageError is a usage error of a command, like an unknown flag, already
+// written to standard error along with the usage of the command.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// parseFlags parses the flags of the command x from args, which must leave
+// nargs positional arguments. An unknown flag or a wrong number of
+// arguments is a usage error, written along with the usage of x to
+// standard error by failUsage. With -h or --help, the help of x is written
+// instead, and flag.ErrHelp returned.
+func parseFlags(x *Z.Cmd, fs *flag.FlagSet, args []string, nargs int) error {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		help.ForTerminal(x, "all")
+		return flag.ErrHelp
+	}
+	if err == nil && fs.NArg() > nargs {
+		err = fmt.Errorf("unexpected argument %q", fs.Arg(nargs))
+	}
+	if err == nil && fs.NArg() < nargs {
+		err = fmt.Errorf("expected %d arguments, got %d", nargs, fs.NArg())
+	}
+	if err != nil {
+		return failUsage(x, err)
+	}
+
+	if verbose {
+		logLevel.Set(slog.LevelDebug)
+	}
+
+	return nil
+}
+
+// failUsage writes the usage error err of the command x to standard error,
+// pointing to the usage of x, and returns it as a usageError.
+func failUsage(x *Z.Cmd, err error) error {
+	name := x.Name
+	for c := x; c.Caller != nil && c.Caller != c; c = c.Caller {
+		name = c.Caller.Name + " " + name
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+	if x.Usage != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s %s\n", name, x.Usage)
+	}
+	fmt.Fprintf(os.Stderr, "Run '%s help' for details.\n", name)
+
+	return &usageError{err}
+}
+
+// unknownCommand returns the error of the unknown command name given to x,
+// suggesting the commands of x named at most two edits away, by name or
+// alias, as likely meant instead.
+func unknownCommand(x *Z.Cmd, name string) error {
+	var similar []string
+	for _, c := range x.Commands {
+		for _, n := range append([]string{c.Name}, c.Aliases...) {
+			if editDistance(name, n) <= 2 {
+				similar = append(similar, c.Name)
+				break
+			}
+		}
+	}
+
+	if len(similar) == 0 {
+		return fmt.Errorf("unknown command %q", name)
+	}
+	return fmt.Errorf("unknown command %q, did you mean %s?", name, strings.Join(similar, " or "))
+}
+
+// editDistance returns the number of single rune insertions, deletions and
+// substitutions turning a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev = cur
+	}
+
+	return prev[len(rb)]
+}
+
+// stringList is a flag value collecting the values of a repeated flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// outputFlags holds the flags selecting where the commands writing
+// reports write them.
+type outputFlags struct {
+	output    string
+	pager     bool
+	clipboard bool
+}
+
+func (of *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&of.output, "output", "", "write the report to this file instead of stdout")
+	fs.BoolVar(&of.pager, "pager", true, "page reports written to a terminal, --pager=false disables")
+	fs.BoolVar(&of.clipboard, "clipboard", false, "also copy the report to the system clipboard")
+}
+
+// write calls fn with the file named by --output, created or truncated,
+// or with standard output when the flag is not given. Output to a terminal
+// is paged unless disabled. With --clipboard, the report is written to a
+// buffer first, copied to the clipboard and then written as usual, only
+// warning when it can't be copied.
+func (of *outputFlags) write(fn func(w io.Writer) error) error {
+	if of.clipboard {
+		var buf bytes.Buffer
+		if err := fn(&buf); err != nil {
+			return err
+		}
+
+		if err := copyToClipboard(buf.Bytes()); err != nil {
+			warnf("report not copied to the clipboard: %s", err)
+		} else {
+			debugf("copied %d bytes to the clipboard", buf.Len())
+		}
+
+		fn = func(w io.Writer) error {
+			_, err := w.Write(buf.Bytes())
+			return err
+		}
+	}
+
+	if of.output == "" {
+		if of.pager && isTerminal(os.Stdout) {
+			return page(fn)
+		}
+		return fn(os.Stdout)
+	}
+
+	f, err := os.Create(of.output)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+
+	return nil
+}
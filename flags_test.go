@@ -0,0 +1,104 @@
+package gitcontrib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func Test_OutputFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte("old and longer content\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	of := outputFlags{output: path}
+	err := of.write(func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, "new")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error writing output: %s", err)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "new\n" {
+		t.Errorf("Expected truncated file with new content, got: %q", buf)
+	}
+
+	of.output = filepath.Join(path, "not-a-dir", "report.txt")
+	err = of.write(func(w io.Writer) error { return nil })
+	if err == nil {
+		t.Errorf("Expected error creating file below a regular file")
+	}
+}
+
+func Test_ParseFlags(t *testing.T) {
+	x := &Z.Cmd{Name: "test"}
+	for _, tc := range []struct {
+		args  []string
+		nargs int
+		usage bool
+	}{
+		{[]string{"--verbose=false", "a"}, 1, false},
+		{[]string{"--unknown"}, 0, true},
+		{[]string{"extra"}, 0, true},
+		{nil, 2, true},
+	} {
+		err := parseFlags(x, newFlagSet(x), tc.args, tc.nargs)
+		var ue *usageError
+		if errors.As(err, &ue) != tc.usage || !tc.usage && err != nil {
+			t.Errorf("unexpected error for %v: %v", tc.args, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"summary", "--unknown"}, 2},
+		{[]string{"summary", "extra"}, 2},
+		{[]string{"summary", "--help"}, 0},
+	} {
+		if code, _ := runMain(t, tc.args...); code != tc.code {
+			t.Errorf("Expected exit code %d for %v, got: %d", tc.code, tc.args, code)
+		}
+	}
+}
+
+func Test_UnknownCommand(t *testing.T) {
+	err := unknownCommand(Cmd, "sumary")
+	if err == nil || err.Error() != `unknown command "sumary", did you mean summary?` {
+		t.Errorf("Expected summary suggested, got: %v", err)
+	}
+
+	err = unknownCommand(Cmd, "frobnicate")
+	if err == nil || err.Error() != `unknown command "frobnicate"` {
+		t.Errorf("Expected no suggestion, got: %v", err)
+	}
+}
+
+func Test_EditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		exp  int
+	}{
+		{"summary", "summary", 0},
+		{"sumary", "summary", 1},
+		{"smumary", "summary", 2},
+		{"", "abc", 3},
+		{"tgas", "tags", 2},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.exp {
+			t.Errorf("Expected distance %d between %q and %q, got: %d", tc.exp, tc.a, tc.b, got)
+		}
+	}
+}
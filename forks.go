@@ -0,0 +1,55 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "sort"
+
+// forkSummary holds the contributions to a single repo of a recursive
+// scan, like a fork of a project, summed over its authors.
+type forkSummary struct {
+	Repo      string
+	Commits   int
+	Additions int
+	Deletions int
+	TopAuthor string
+}
+
+// forkSummaries sums the reports of the analysed repos into a row per
+// repo, leaving out the repos that couldn't be analysed. The top author
+// of a repo is the one with the most commits, the first by name on ties.
+// The rows are sorted by descending commits, then line changes, and then
+// by repo.
+func forkSummaries(repos []repoSummary) []forkSummary {
+	forks := make([]forkSummary, 0, len(repos))
+	for _, r := range repos {
+		if r.err != nil {
+			continue
+		}
+
+		f := forkSummary{Repo: r.Repo}
+		top := -1
+		for _, s := range r.Summaries {
+			f.Commits += s.Commits
+			f.Additions += s.Additions
+			f.Deletions += s.Deletions
+			if s.Commits > top || s.Commits == top && s.Author < f.TopAuthor {
+				f.TopAuthor, top = s.Author, s.Commits
+			}
+		}
+		forks = append(forks, f)
+	}
+
+	sort.Slice(forks, func(i, j int) bool {
+		a, b := forks[i], forks[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		if a.Additions+a.Deletions != b.Additions+b.Deletions {
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		}
+		return a.Repo < b.Repo
+	})
+
+	return forks
+}
@@ -0,0 +1,33 @@
+package gitcontrib
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_ForkSummaries(t *testing.T) {
+	repos := []repoSummary{
+		{Repo: "small", Summaries: []AuthorSummary{
+			{Author: "Bob", Commits: 2, Additions: 5},
+			{Author: "Alice", Commits: 2, Additions: 1, Deletions: 1},
+		}},
+		{Repo: "broken", err: errors.New("not a repo")},
+		{Repo: "big", Summaries: []AuthorSummary{
+			{Author: "Carol", Commits: 1, Additions: 10},
+			{Author: "Dave", Commits: 3, Deletions: 4},
+		}},
+		{Repo: "same", Summaries: []AuthorSummary{
+			{Author: "Erin", Commits: 4, Additions: 2},
+		}},
+	}
+
+	expected := []forkSummary{
+		{Repo: "big", Commits: 4, Additions: 10, Deletions: 4, TopAuthor: "Dave"},
+		{Repo: "small", Commits: 4, Additions: 6, Deletions: 1, TopAuthor: "Alice"},
+		{Repo: "same", Commits: 4, Additions: 2, TopAuthor: "Erin"},
+	}
+	if forks := forkSummaries(repos); !reflect.DeepEqual(forks, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, forks)
+	}
+}
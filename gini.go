@@ -0,0 +1,29 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "sort"
+
+// Gini returns the Gini coefficient of the values, measuring how unequally
+// they are distributed. It is 0 when all values are equal, and approaches
+// 1 when a single value holds everything. Without any values, or when all
+// are zero, it is 0.
+func Gini(values []int) float64 {
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += float64(v)
+		weighted += float64(i+1) * float64(v)
+	}
+
+	if sum == 0 {
+		return 0
+	}
+
+	n := float64(len(sorted))
+	return 2*weighted/(n*sum) - (n+1)/n
+}
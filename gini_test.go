@@ -0,0 +1,26 @@
+package gitcontrib
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_Gini(t *testing.T) {
+	tests := []struct {
+		values []int
+		gini   float64
+	}{
+		{nil, 0},
+		{[]int{0, 0}, 0},
+		{[]int{7}, 0},
+		{[]int{5, 5, 5, 5}, 0},
+		{[]int{0, 0, 0, 10}, 0.75},
+		{[]int{3, 1, 2}, 2.0 / 9},
+	}
+
+	for _, tt := range tests {
+		if got := Gini(tt.values); math.Abs(got-tt.gini) > 1e-9 {
+			t.Errorf("Gini(%v) = %v, expected %v", tt.values, got, tt.gini)
+		}
+	}
+}
@@ -0,0 +1,869 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// options selects the repository git is run in and the part of its
+// history that is analysed.
+type options struct {
+	dir string // repository directory, the current directory when empty
+	rev string // revision or range, the checked out branch when empty
+	ref string // the revision as given, before pinned to commit hashes
+
+	since string // only commits more recent than this git date
+	until string // only commits older than this git date
+	asOf  string // the history up to the last commit before this git date
+
+	firstParent   bool // follow only the first parent of merge commits
+	defaultBranch bool // analyse the default branch when no rev is given
+	noMailmap     bool // report authors as committed, not as mailmapped
+
+	dateType string // "committer" to date commits by committer date
+
+	tz       string         // time zone of the dates, as given by --tz
+	location *time.Location // the resolved tz, each commit's own when nil
+
+	sinceTag tagFlag // only commits after this tag
+
+	allBranches bool     // analyse the union of all branches, by --all-branches
+	tips        []string // the resolved commits of all branches, if so
+
+	commitList  stringList // only these commits, as given by --commits
+	commitsFile string     // file listing the only commits to analyse
+	shas        []string   // the resolved commits of both, if any
+
+	paths  stringList // only commits touching these paths
+	follow bool       // follow a single path across renames
+
+	maxCommitLines int  // skip the line changes of larger commits
+	excludeCommit  bool // skip the larger commits altogether
+
+	capLinesPerFile int // cap the additions of each file of a commit
+
+	diffFilter string // only count the line changes of these kinds of file changes
+
+	codeOnly bool       // only count the commits touching code files
+	codeExts stringList // the extensions of code files, given by --code-ext
+
+	squashAuthor string // who squash merges are credited to, by --squash-author
+
+	excludeReverts  bool // skip the line changes of revert commits
+	excludeReverted bool // and of the commits they revert
+
+	respectGitattributes bool          // skip the line changes of generated and vendored files
+	attributes           gitattributes // the rules of .gitattributes marking them, if so
+
+	excludeFirstCommit bool     // leave out the root commits, like initial imports
+	roots              []string // the resolved root commits left out
+
+	repoName      string // the name of the repo set by --repo-name, instead of its directory
+	unknownAuthor string // the label of the commits without an author name, set by --unknown-author
+
+	failDrift bool // fail on line changes parsed into bad numbers, set by --strict
+}
+
+// drift returns err, unless it is an ErrParseDrift error and --strict is
+// not given, when it is only logged as a warning and the numbers parsed
+// are kept.
+func (o options) drift(err error) error {
+	if errors.Is(err, ErrParseDrift) && !o.failDrift {
+		warnf("%s", err)
+		return nil
+	}
+	return err
+}
+
+// tagFlag is the value of a flag naming a tag, which is given without a
+// value for the most recent tag.
+type tagFlag struct {
+	set  bool
+	name string // the most recent tag when empty
+}
+
+func (t *tagFlag) String() string {
+	return t.name
+}
+
+func (t *tagFlag) Set(v string) error {
+	switch v {
+	case "true":
+		*t = tagFlag{set: true}
+	case "false":
+		*t = tagFlag{}
+	default:
+		*t = tagFlag{set: true, name: v}
+	}
+	return nil
+}
+
+// IsBoolFlag makes the flag package accept the flag without a value.
+func (t *tagFlag) IsBoolFlag() bool {
+	return true
+}
+
+// logFormat returns the git log format, with the author placeholders
+// changed to ignore the mailmap, and the author date changed to the
+// committer date, when asked for.
+func (o options) logFormat(format string) string {
+	var replace []string
+	if o.noMailmap {
+		replace = append(replace, "%aN", "%an", "%aE", "%ae", "%cN", "%cn")
+	}
+	if o.dateType == "committer" {
+		replace = append(replace, "%aI", "%cI")
+	}
+	if len(replace) == 0 {
+		return format
+	}
+	return strings.NewReplacer(replace...).Replace(format)
+}
+
+// inZone returns t in the time zone of --tz, or as is without the flag,
+// keeping the offset the commit was made with.
+func (o options) inZone(t time.Time) time.Time {
+	if o.location == nil {
+		return t
+	}
+	return t.In(o.location)
+}
+
+// resolve checks the date type, time zone and squash author of o, and sets
+// its revision to the default branch of the repo, when asked for and no
+// other revision is given, and to its last commit before the date of
+// --as-of. The revision is then limited to the commits after the tag of
+// --since-tag, and pinned to the commits it refers to, leaving out the
+// root commits with --exclude-first-commit. For a repo without commits,
+// ErrNoCommits is returned, for the commands to tell whether it ends their
+// report or fails it.
+func (o *options) resolve() error {
+	switch o.dateType {
+	case "", "author", "committer":
+	default:
+		return fmt.Errorf("invalid --date-type %q, must be author or committer", o.dateType)
+	}
+
+	if o.capLinesPerFile < 0 {
+		return errors.New("--cap-lines-per-file can't be negative")
+	}
+
+	if strings.Trim(o.diffFilter, diffFilterLetters) != "" {
+		return fmt.Errorf("invalid --diff-filter %q, must be letters of %s", o.diffFilter, diffFilterLetters[:9])
+	}
+
+	switch o.squashAuthor {
+	case "", "author", "committer", "body":
+	default:
+		return fmt.Errorf("invalid --squash-author %q, must be author, committer or body", o.squashAuthor)
+	}
+
+	if o.tz != "" {
+		loc, err := time.LoadLocation(o.tz)
+		if err != nil {
+			return fmt.Errorf("invalid --tz %q, must be UTC, Local or an IANA time zone like Europe/Oslo", o.tz)
+		}
+		o.location = loc
+	}
+
+	if o.rev == "" && !o.commitsGiven() && isEmpty(*o) {
+		return ErrNoCommits
+	}
+
+	if o.defaultBranch {
+		if o.rev != "" {
+			return errors.New("--default-branch can't be combined with another revision")
+		}
+
+		rev, err := defaultBranch(*o)
+		if err != nil {
+			return err
+		}
+		o.rev = rev
+	}
+
+	if o.allBranches && (o.rev != "" || o.defaultBranch || o.sinceTag.set || o.commitsGiven()) {
+		return errors.New("--all-branches can't be combined with --branch, --default-branch, --since-tag or --commits")
+	}
+
+	if o.asOf != "" {
+		if o.allBranches || o.commitsGiven() || strings.Contains(o.rev, "..") {
+			return errors.New("--as-of can't be combined with --all-branches, --commits or a range")
+		}
+
+		rev, err := asOfCommit(*o, o.asOf)
+		if err != nil {
+			return err
+		}
+		o.rev = rev
+	}
+
+	if o.commitsGiven() {
+		if o.rev != "" || o.defaultBranch || o.sinceTag.set {
+			return errors.New("--commits and --commits-file can't be combined with --branch, --default-branch or --since-tag")
+		}
+		if err := o.resolveCommits(); err != nil {
+			return err
+		}
+	}
+
+	if o.follow && len(o.paths) != 1 {
+		Logger.Warn("--follow only works with a single --path, analysing without it")
+		o.follow = false
+	}
+
+	if o.sinceTag.set {
+		if strings.Contains(o.rev, "..") {
+			return errors.New("--since-tag can't be combined with a range")
+		}
+
+		head := o.rev
+		if head == "" {
+			head = "HEAD"
+		}
+
+		tag, err := sinceTag(*o, head)
+		if err != nil {
+			return err
+		}
+		o.rev = tag + ".." + head
+	}
+
+	if err := o.pin(); err != nil {
+		return err
+	}
+
+	if o.respectGitattributes {
+		attributes, err := readGitattributes(*o)
+		if err != nil {
+			return err
+		}
+		o.attributes = attributes
+	}
+
+	if o.excludeFirstCommit {
+		return o.excludeRoots()
+	}
+	return nil
+}
+
+// pin sets the revision of o to the hashes of the commits it refers to,
+// HEAD when empty, keeping the revision as given in ref. Every git
+// invocation of an analysis then reads the same commits, even when HEAD or
+// the branch moves meanwhile, like when pulling during a --watch. The
+// revision of a repo without commits is left empty. With --all-branches,
+// the tips of all branches are pinned instead.
+func (o *options) pin() error {
+	o.ref = o.rev
+	if o.allBranches {
+		return o.pinTips()
+	}
+	if len(o.shas) > 0 || (o.rev == "" && !hasCommits(*o)) {
+		return nil
+	}
+
+	rev, err := pinRev(*o, o.rev)
+	if err != nil {
+		return err
+	}
+
+	name := o.ref
+	if name == "" {
+		name = "HEAD"
+	}
+	debugf("analysing %s at %s", name, rev)
+
+	o.rev = rev
+	return nil
+}
+
+// pinTips sets the tips of o to the commits the local and remote-tracking
+// branches of its repo point to. It is an error if there are none.
+func (o *options) pinTips() error {
+	out, err := o.git("rev-parse", "--branches", "--remotes")
+	if err != nil {
+		return fmt.Errorf("error resolving branches: %w", err)
+	}
+
+	o.tips = strings.Fields(out)
+	if len(o.tips) == 0 {
+		return &Error{CodeNoBranch, errors.New("no branches to analyse")}
+	}
+	debugf("analysing the union of %d branches", len(o.tips))
+
+	return nil
+}
+
+// pinRev returns the revision or range with each of its ends replaced by
+// the hash of its commit, an empty end being HEAD like for git.
+func pinRev(o options, rev string) (string, error) {
+	for _, op := range []string{"...", ".."} {
+		from, to, ok := strings.Cut(rev, op)
+		if !ok {
+			continue
+		}
+
+		from, err := pinCommit(o, from)
+		if err != nil {
+			return "", err
+		}
+		to, err = pinCommit(o, to)
+		if err != nil {
+			return "", err
+		}
+		return from + op + to, nil
+	}
+
+	return pinCommit(o, rev)
+}
+
+// pinCommit returns the hash of the commit the revision refers to, HEAD
+// when empty.
+func pinCommit(o options, rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	out, err := o.git("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", &Error{CodeBadRef, fmt.Errorf("unknown revision %q", rev)}
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// excludeRoots leaves the root commits of the history of o out of its
+// analysis, being the commits without parents, like the initial import of
+// an existing code base. A history merging unrelated histories has several.
+// The number of commits and lines left out is logged.
+func (o *options) excludeRoots() error {
+	args := []string{"rev-list", "--max-parents=0"}
+	if len(o.shas) > 0 {
+		args = append(append(args, "--no-walk"), o.shas...)
+	} else if o.rev != "" {
+		args = append(args, o.rev)
+	}
+	out, err := o.git(append(args, o.tips...)...)
+	if err != nil {
+		return fmt.Errorf("error finding root commits: %w", err)
+	}
+
+	roots := strings.Fields(out)
+	if len(roots) == 0 {
+		return nil
+	}
+
+	lines, err := o.git(append(append([]string{"log", "--no-walk", "--numstat", "--format="}, roots...), "--")...)
+	if err != nil {
+		return fmt.Errorf("error reading root commits: %w", err)
+	}
+	excluded := sumNumstat(lines)
+	logf(slog.LevelInfo, "excluding %d root commits, with %d added and %d deleted lines",
+		len(roots), excluded.Additions, excluded.Deletions)
+
+	if len(o.shas) == 0 {
+		o.roots = roots
+		return nil
+	}
+
+	// the commits given are kept unless roots, as they may be abbreviated
+	out, err = o.git(append([]string{"rev-list", "--no-walk", "--min-parents=1"}, o.shas...)...)
+	if err != nil {
+		return fmt.Errorf("error finding root commits: %w", err)
+	}
+	o.shas = strings.Fields(out)
+	if len(o.shas) == 0 {
+		return errors.New("no commits left to analyse without the root commits")
+	}
+
+	return nil
+}
+
+// sinceTag returns the tag of --since-tag, being the most recent tag
+// reachable from head when none is named. It is an error if there is no
+// such tag.
+func sinceTag(o options, head string) (string, error) {
+	if o.sinceTag.name == "" {
+		out, err := o.git("describe", "--tags", "--abbrev=0", head)
+		if err != nil {
+			return "", errors.New("no tag to analyse the commits since; name one with --since-tag=TAG")
+		}
+		return strings.TrimSpace(out), nil
+	}
+
+	tag := o.sinceTag.name
+	_, err := o.git("rev-parse", "--verify", "--quiet", "refs/tags/"+tag)
+	if err != nil {
+		return "", &Error{CodeBadRef, fmt.Errorf("unknown tag %q", tag)}
+	}
+
+	return tag, nil
+}
+
+// asOfCommit returns the hash of the last commit of the revision of o,
+// HEAD when empty, committed before the date, as the snapshot of the repo
+// at that date. It is an error if no commit predates it.
+func asOfCommit(o options, date string) (string, error) {
+	head := o.rev
+	if head == "" {
+		head = "HEAD"
+	}
+
+	out, err := o.git("rev-list", "-1", "--before="+date, head)
+	if err != nil {
+		return "", fmt.Errorf("error finding the commit as of %s: %w", date, err)
+	}
+
+	commit := strings.TrimSpace(out)
+	if commit == "" {
+		return "", fmt.Errorf("no commit before --as-of %s", date)
+	}
+	debugf("analysing %s as of %s, at %s", head, date, commit)
+
+	return commit, nil
+}
+
+// commitsGiven tells whether the commits to analyse are given by
+// --commits or --commits-file.
+func (o options) commitsGiven() bool {
+	return len(o.commitList) > 0 || o.commitsFile != ""
+}
+
+// resolveCommits sets the commits of o to those of --commits and
+// --commits-file, checking that each of them exists with a single git
+// cat-file --batch-check. All missing commits are reported at once.
+func (o *options) resolveCommits() error {
+	var shas []string
+	for _, list := range o.commitList {
+		shas = append(shas, strings.FieldsFunc(list, func(r rune) bool {
+			return r == ',' || r == ' '
+		})...)
+	}
+
+	if o.commitsFile != "" {
+		listed, err := readCommitsFile(o.commitsFile)
+		if err != nil {
+			return err
+		}
+		shas = append(shas, listed...)
+	}
+
+	if len(shas) == 0 {
+		return errors.New("no commits given to analyse")
+	}
+
+	var input strings.Builder
+	for _, sha := range shas {
+		input.WriteString(sha + "^{commit}\n")
+	}
+	out, err := gitInput(o.dir, input.String(), "cat-file", "--batch-check")
+	if err != nil {
+		return fmt.Errorf("error checking commits: %w", err)
+	}
+
+	// each commit is listed as "<hash> commit <size>", in the order given,
+	// and an unknown object or an ambiguous prefix as "<sha> missing"
+	var missing []string
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for i, sha := range shas {
+		var kind string
+		if i < len(lines) {
+			if f := strings.Fields(lines[i]); len(f) == 3 {
+				kind = f[1]
+			}
+		}
+		if kind != "commit" {
+			missing = append(missing, sha)
+		}
+	}
+	if len(missing) > 0 {
+		return &Error{CodeBadRef, fmt.Errorf("unknown commits: %s", strings.Join(missing, ", "))}
+	}
+
+	o.shas = shas
+	return nil
+}
+
+// readCommitsFile returns the commits listed in the file of
+// --commits-file, one per line. Blank lines and lines starting with # are
+// skipped, as is anything following the commit on its line, like the
+// subject listed by git log --oneline.
+func readCommitsFile(path string) ([]string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading commits file: %w", err)
+	}
+
+	var shas []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		shas = append(shas, fields[0])
+	}
+
+	return shas, nil
+}
+
+// history returns args followed by the git log arguments selecting the
+// history of o.
+func (o options) history(args ...string) []string {
+	if o.since != "" {
+		args = append(args, "--since="+o.since)
+	}
+	if o.until != "" {
+		args = append(args, "--until="+o.until)
+	}
+	if o.firstParent {
+		args = append(args, "--first-parent")
+	}
+	if o.follow {
+		args = append(args, "--follow")
+	}
+	if len(o.shas) > 0 {
+		return append(append(args, "--no-walk"), o.shas...)
+	}
+	if o.rev != "" {
+		args = append(args, o.rev)
+	}
+	args = append(args, o.tips...)
+	// a root commit has no ancestors, so excluding it excludes only itself
+	for _, root := range o.roots {
+		args = append(args, "^"+root)
+	}
+	return args
+}
+
+// diffFilterLetters are the letters of --diff-filter, the kinds of file
+// changes of git log --diff-filter, uppercase to select them and lowercase
+// to leave them out.
+const diffFilterLetters = "ACDMRTUXBacdmrtuxb"
+
+// walkDiffs runs git log like walk with the given arguments, asking for
+// the changes of the files of each commit like with --numstat, limited to
+// the kinds of file changes of --diff-filter. Commits without any file
+// change of these kinds are left out by git.
+func (o options) walkDiffs(args ...string) (string, error) {
+	return o.walk(o.diffs(args...)...)
+}
+
+// streamDiffs runs git log like walkDiffs, passing its output to fn as it
+// is written, like walkStream.
+func (o options) streamDiffs(fn func(io.Reader) error, args ...string) error {
+	return o.walkStream(fn, o.diffs(args...)...)
+}
+
+// diffs returns the git log arguments of walkDiffs.
+func (o options) diffs(args ...string) []string {
+	if o.diffFilter != "" {
+		args = append(args, "--diff-filter="+o.diffFilter)
+	}
+	return append([]string{"log"}, args...)
+}
+
+// git runs git with the given arguments in the repository of o.
+func (o options) git(args ...string) (string, error) {
+	return gitOut(o.dir, args...)
+}
+
+// walk runs git with the given arguments followed by those selecting the
+// history of o, for the invocations walking the history. Under --dry-run,
+// the command line is only logged and the output is empty.
+func (o options) walk(args ...string) (string, error) {
+	return o.walkArgs(o.walkCommand(args...))
+}
+
+// walkStream runs git like walk, passing its output to fn as it is
+// written, like gitStream. Under --dry-run, fn is passed an empty output.
+func (o options) walkStream(fn func(io.Reader) error, args ...string) error {
+	args = o.walkCommand(args...)
+	if dryRun {
+		printCommand(commandLine(args))
+		return fn(strings.NewReader(""))
+	}
+	return gitStream(o.dir, fn, args...)
+}
+
+// walkCommand returns the given arguments followed by those selecting the
+// history of o and its paths, the complete arguments of walk.
+func (o options) walkCommand(args ...string) []string {
+	args = o.history(args...)
+	if len(o.paths) > 0 {
+		args = append(append(args, "--"), o.paths...)
+	}
+	return args
+}
+
+// walkPath runs git like walk, limited to the history of the given path
+// instead of the paths of o.
+func (o options) walkPath(path string, args ...string) (string, error) {
+	o.follow = false
+	return o.walkArgs(append(o.history(args...), "--", path))
+}
+
+// walkArgs runs git with the given complete arguments of a history walk,
+// only logging the command line under --dry-run.
+func (o options) walkArgs(args []string) (string, error) {
+	if dryRun {
+		printCommand(commandLine(args))
+		return "", nil
+	}
+	return o.git(args...)
+}
+
+// gitOut runs git with the given arguments in dir, or the current
+// directory when empty, and returns its standard output. Standard error is
+// captured separately, so warnings git writes there never end up in the
+// output being parsed. The warnings of a succeeding invocation are logged
+// under --verbose, while a failing invocation is returned as an error
+// carrying them. All git invocations of the package go through here, or
+// through gitStream, so they can be logged under --verbose and --explain,
+// and their output saved under --save-raw.
+func gitOut(dir string, args ...string) (string, error) {
+	return gitInput(dir, "", args...)
+}
+
+// gitInput runs git like gitOut, writing input to its standard input, for
+// the commands reading what to look up from there.
+func gitInput(dir, input string, args ...string) (string, error) {
+	cmdline := commandLine(args)
+	if explain || dryRun {
+		printCommand(cmdline)
+	}
+
+	var stderr bytes.Buffer
+	cmd := gitCommand(dir, &stderr, args...)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	start := time.Now()
+	stop := spin(cmdline)
+	out, err := cmd.Output()
+	stop()
+	debugf("%s (%s)", cmdline, time.Since(start).Round(time.Millisecond))
+
+	msg := strings.TrimSpace(stderr.String())
+	if err != nil {
+		return "", gitError(dir, args[0], msg, err)
+	}
+	if msg != "" {
+		debugf("warning from git %s: %s", args[0], msg)
+	}
+
+	if saveRaw != "" {
+		if err := saveRawOutput(dir, args, out); err != nil {
+			return "", err
+		}
+	}
+
+	return toUTF8(string(out)), nil
+}
+
+// gitStream runs git like gitOut, passing its standard output to fn as it
+// is written rather than returning it, so that the output of a large
+// history is never held in memory as a whole. Unlike with gitOut, the
+// output isn't decoded to UTF-8, which is left to fn, line by line. When
+// fn fails, git is stopped and the error of fn is returned. Under
+// --save-raw, the output is read in full to be saved, and then passed on.
+func gitStream(dir string, fn func(io.Reader) error, args ...string) error {
+	if saveRaw != "" {
+		out, err := gitOut(dir, args...)
+		if err != nil {
+			return err
+		}
+		return fn(strings.NewReader(out))
+	}
+
+	cmdline := commandLine(args)
+	if explain || dryRun {
+		printCommand(cmdline)
+	}
+
+	var stderr bytes.Buffer
+	cmd := gitCommand(dir, &stderr, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	start := time.Now()
+	stop := spin(cmdline)
+	if err := cmd.Start(); err != nil {
+		stop()
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	ferr := fn(stdout)
+	if ferr != nil {
+		// git would otherwise block on writing the output left unread
+		cmd.Process.Kill()
+	}
+	err = cmd.Wait()
+	stop()
+	debugf("%s (%s)", cmdline, time.Since(start).Round(time.Millisecond))
+
+	if ferr != nil {
+		return ferr
+	}
+	msg := strings.TrimSpace(stderr.String())
+	if err != nil {
+		return gitError(dir, args[0], msg, err)
+	}
+	if msg != "" {
+		debugf("warning from git %s: %s", args[0], msg)
+	}
+
+	return nil
+}
+
+// gitCommand returns the command running git with the given arguments in
+// dir, writing its standard error to stderr.
+func gitCommand(dir string, stderr *bytes.Buffer, args ...string) *exec.Cmd {
+	// the log output is asked for in UTF-8 whatever the configuration,
+	// which only matters for the commits recording another encoding, so
+	// the option is left out of the logged command line
+	cmd := exec.Command("git", append([]string{"-c", "i18n.logOutputEncoding=UTF-8"}, args...)...)
+	cmd.Dir = dir
+	cmd.Stderr = stderr
+	return cmd
+}
+
+// toUTF8 returns the git output with the lines that aren't valid UTF-8
+// decoded as Latin-1. These come from old commits written in another
+// encoding without recording it, which git passes on as is, so that
+// author names like "J\xf6rg" are reported as "Jörg" rather than mangled.
+// Every byte is a character in Latin-1, so no line is lost.
+func toUTF8(out string) string {
+	if utf8.ValidString(out) {
+		return out
+	}
+
+	lines := strings.SplitAfter(out, "\n")
+	for i, line := range lines {
+		if utf8.ValidString(line) {
+			continue
+		}
+		runes := make([]rune, len(line))
+		for j := 0; j < len(line); j++ {
+			runes[j] = rune(line[j])
+		}
+		lines[i] = string(runes)
+	}
+
+	return strings.Join(lines, "")
+}
+
+// ErrDubiousOwnership is returned when git refuses to read a repository
+// owned by another user, which it does since version 2.35.2.
+var ErrDubiousOwnership = errors.New("repository is owned by another user")
+
+// gitError returns the error of the failed git subcommand run in dir,
+// given what it wrote to standard error.
+func gitError(dir, subcommand, stderr string, err error) error {
+	if strings.Contains(stderr, "detected dubious ownership") {
+		if dir == "" {
+			dir = "."
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		return fmt.Errorf(
+			"%w; if it is trusted, mark it as safe with: git config --global --add safe.directory %s",
+			ErrDubiousOwnership, dir,
+		)
+	}
+
+	if stderr != "" {
+		err := fmt.Errorf("git %s: %s", subcommand, stderr)
+		switch {
+		case strings.Contains(stderr, "not a git repository"):
+			return &Error{CodeNotARepo, err}
+		case strings.Contains(stderr, "unknown revision"),
+			strings.Contains(stderr, "bad revision"),
+			strings.Contains(stderr, "ambiguous argument"):
+			return &Error{CodeBadRef, err}
+		}
+		return err
+	}
+
+	return fmt.Errorf("git %s: %w", subcommand, err)
+}
+
+// commandLine returns the git command line with the given arguments, with
+// the arguments quoted as needed to paste it into a shell.
+func commandLine(args []string) string {
+	var b strings.Builder
+	b.WriteString("git")
+	for _, arg := range args {
+		b.WriteByte(' ')
+		if arg != "" && strings.Trim(arg, shellSafe) == "" {
+			b.WriteString(arg)
+			continue
+		}
+		b.WriteString("'" + strings.ReplaceAll(arg, "'", `'\''`) + "'")
+	}
+	return b.String()
+}
+
+// shellSafe holds the characters that need no quoting in a shell.
+const shellSafe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"0123456789-_=+,./:@^%"
+
+// mergeRange returns the git revision range of the commits the merge
+// commit brought in, as when merging a pull request, being those reachable
+// from its second parent but not from its first. It is an error if the
+// commit is not a merge, or brought in no commits.
+func mergeRange(o options, merge string) (string, error) {
+	out, err := o.git("rev-parse", "--verify", "--quiet", merge+"^{commit}")
+	if err != nil {
+		return "", &Error{CodeBadRef, fmt.Errorf("unknown revision %q", merge)}
+	}
+	sha := strings.TrimSpace(out)
+
+	var parents []string
+	for _, n := range []string{"^1", "^2"} {
+		out, err := o.git("rev-parse", "--verify", "--quiet", sha+n)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a merge commit", merge)
+		}
+		parents = append(parents, strings.TrimSpace(out))
+	}
+
+	return revRange(o, parents[0], parents[1])
+}
+
+// revRange returns the git revision range from..to, after checking that
+// both revisions exist and that the range contains commits.
+func revRange(o options, from, to string) (string, error) {
+	for _, rev := range []string{from, to} {
+		_, err := o.git("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+		if err != nil {
+			return "", &Error{CodeBadRef, fmt.Errorf("unknown revision %q", rev)}
+		}
+	}
+
+	rng := from + ".." + to
+	ok, err := hasRangeCommits(o, rng)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", fmt.Errorf("no commits in range %s", rng)
+	}
+
+	return rng, nil
+}
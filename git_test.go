@@ -0,0 +1,778 @@
+package gitcontrib
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func Test_BareRepo(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n3\n")
+	r.commit("Author Two", "two.txt", "1\n")
+
+	bare := filepath.Join(t.TempDir(), "fixture.git")
+	r.git("clone", "-q", "--bare", r.dir, bare)
+	o := options{dir: bare}
+
+	name, err := getRepoDirName(o)
+	if err != nil {
+		t.Fatalf("error getting repo name: %s", err)
+	}
+	if name != "fixture" {
+		t.Errorf("Expected repo name 'fixture', got: %q", name)
+	}
+
+	commitMap, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("error getting author commits: %s", err)
+	}
+	lineChangesMap, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error getting line changes: %s", err)
+	}
+
+	summaries, totals, _ := Summarize(commitMap, lineChangesMap)
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 authors, got: %+v", summaries)
+	}
+	if totals.Commits != 2 || totals.Additions != 4 {
+		t.Errorf("unexpected totals: %+v", totals)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	err = ContributionSummaryCmd.Call(ContributionSummaryCmd,
+		"--repo", bare, "--format", "prometheus", "--no-cache", "--output", out)
+	if err != nil {
+		t.Fatalf("error running summary in the bare repo: %s", err)
+	}
+	buf, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, exp := range []string{`repo="fixture"`, `author="Author One"`, `author="Author Two"`} {
+		if !strings.Contains(string(buf), exp) {
+			t.Errorf("Expected %s in the report of the bare repo, got:\n%s", exp, buf)
+		}
+	}
+}
+
+func Test_Worktree(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n3\n")
+	r.git("checkout", "-q", "-b", "feature")
+	r.commit("Author Two", "two.txt", "1\n")
+	r.git("checkout", "-q", "main")
+
+	wt := &testRepo{t, filepath.Join(t.TempDir(), "feature")}
+	r.git("worktree", "add", "-q", wt.dir, "feature")
+	name := filepath.Base(r.dir)
+
+	if _, linked, err := linkedWorktree(options{dir: r.dir}); err != nil || linked {
+		t.Errorf("Expected the main work tree not to be linked, got %t, %v", linked, err)
+	}
+	os.Mkdir(filepath.Join(r.dir, "sub"), 0o755)
+	if _, linked, err := linkedWorktree(options{dir: filepath.Join(r.dir, "sub")}); err != nil || linked {
+		t.Errorf("Expected a subdirectory of the main work tree not to be linked, got %t, %v", linked, err)
+	}
+
+	if got, err := getRepoDirName(wt.options()); err != nil || got != name {
+		t.Errorf("Expected repo name %q in the worktree, got %q, %v", name, got, err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	err := ContributionSummaryCmd.Call(ContributionSummaryCmd,
+		"--repo", wt.dir, "--format", "prometheus", "--no-cache", "--output", out)
+	if err != nil {
+		t.Fatalf("error running summary in the worktree: %s", err)
+	}
+	buf, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, exp := range []string{`repo="` + name + `"`, `author="Author Two"`} {
+		if !strings.Contains(string(buf), exp) {
+			t.Errorf("Expected %s in the report of the worktree, got:\n%s", exp, buf)
+		}
+	}
+
+	bare := filepath.Join(t.TempDir(), "fixture.git")
+	r.git("clone", "-q", "--bare", r.dir, bare)
+	bareWt := filepath.Join(t.TempDir(), "main")
+	r.git("-C", bare, "worktree", "add", "-q", bareWt, "feature")
+	if got, err := getRepoDirName(options{dir: bareWt}); err != nil || got != "fixture" {
+		t.Errorf("Expected repo name 'fixture' in a worktree of a bare repo, got %q, %v", got, err)
+	}
+}
+
+func Test_MergeRange(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.git("checkout", "-q", "-b", "feature")
+	r.commit("Author Two", "two.txt", "1\n2\n")
+	r.commit("Author Three", "three.txt", "1\n2\n3\n")
+	r.git("checkout", "-q", "main")
+	r.commit("Author One", "four.txt", "1\n2\n3\n4\n")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Author One"}, "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+
+	o := r.options()
+	rng, err := mergeRange(o, "HEAD")
+	if err != nil {
+		t.Fatalf("error resolving the merge: %s", err)
+	}
+
+	o.rev = rng
+	commitMap, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("error getting author commits: %s", err)
+	}
+	exp := map[string]int{"Author Two": 1, "Author Three": 1}
+	if !reflect.DeepEqual(commitMap, exp) {
+		t.Errorf("Expected only the commits of the merged branch %v, got %v", exp, commitMap)
+	}
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error getting line changes: %s", err)
+	}
+	if lineChanges["Author Two"].Additions != 2 || lineChanges["Author Three"].Additions != 3 || len(lineChanges) != 2 {
+		t.Errorf("Expected only the line changes of the merged branch, got %v", lineChanges)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	if err := PRCmd.Call(PRCmd, "--repo", r.dir, "--no-cache", "--output", out, "HEAD"); err != nil {
+		t.Fatalf("error running pr: %s", err)
+	}
+	buf, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), "Author Three") || strings.Contains(string(buf), "Author One") {
+		t.Errorf("Expected the report of the merged branch only, got:\n%s", buf)
+	}
+
+	if _, err := mergeRange(o, "HEAD~1"); err == nil || !strings.Contains(err.Error(), "not a merge commit") {
+		t.Errorf("Expected an error for a commit that isn't a merge, got %v", err)
+	}
+	if _, err := mergeRange(o, "nope"); errorCode(err) != CodeBadRef {
+		t.Errorf("Expected %s for an unknown ref, got %v", CodeBadRef, err)
+	}
+}
+
+func Test_FirstParent(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.git("checkout", "-q", "-b", "feature")
+	r.commit("Author Two", "two.txt", "1\n2\n")
+	r.commit("Author Two", "three.txt", "1\n")
+	r.git("checkout", "-q", "main")
+	r.gitEnv(
+		[]string{"GIT_AUTHOR_NAME=Author One"},
+		"merge", "-q", "--no-ff", "-m", "merge feature", "feature",
+	)
+
+	o := r.options()
+	commitMap, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("error getting author commits: %s", err)
+	}
+	if commitMap["Author One"] != 1 || commitMap["Author Two"] != 2 {
+		t.Errorf("unexpected full history commits: %v", commitMap)
+	}
+
+	o.firstParent = true
+	commitMap, err = authorCommits(o)
+	if err != nil {
+		t.Fatalf("error getting first-parent author commits: %s", err)
+	}
+	if len(commitMap) != 1 || commitMap["Author One"] != 1 {
+		t.Errorf("unexpected first-parent commits: %v", commitMap)
+	}
+
+	lineChangesMap, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error getting first-parent line changes: %s", err)
+	}
+	if _, ok := lineChangesMap["Author Two"]; ok {
+		t.Errorf("feature branch author walked: %v", lineChangesMap)
+	}
+	if lineChangesMap["Author One"].Additions != 4 {
+		t.Errorf("expected merged lines credited to merger: %v", lineChangesMap)
+	}
+}
+
+func Test_CommandLine(t *testing.T) {
+	got := commandLine([]string{"log", "--pretty=format:%aN", "two words", "it's", ""})
+	exp := `git log --pretty=format:%aN 'two words' 'it'\''s' ''`
+	if got != exp {
+		t.Errorf("Expected %s, got: %s", exp, got)
+	}
+}
+
+func Test_CsvRepoName(t *testing.T) {
+	root := t.TempDir()
+	r := &testRepo{t, root}
+	r.git("init", "-q", filepath.Join("team", "app"))
+	o := options{dir: filepath.Join(root, "team", "app")}
+
+	var cf csvFlags
+	name, err := cf.repoName(o)
+	if err != nil || name != "app" {
+		t.Errorf("Expected 'app', got: %q (%v)", name, err)
+	}
+
+	cf.fullPath = true
+	name, err = cf.repoName(o)
+	if err != nil || !filepath.IsAbs(name) || filepath.Base(name) != "app" {
+		t.Errorf("Expected absolute path of app, got: %q (%v)", name, err)
+	}
+
+	cf.root = root
+	name, err = cf.repoName(o)
+	if err != nil || name != "team/app" {
+		t.Errorf("Expected 'team/app', got: %q (%v)", name, err)
+	}
+
+	cf.root = filepath.Join(root, "team", "app", "sub")
+	if _, err := cf.repoName(o); err == nil {
+		t.Errorf("Expected error for repo outside --root")
+	}
+
+	o.repoName = "project"
+	name, err = cf.repoName(o)
+	if err != nil || name != "project" {
+		t.Errorf("Expected --repo-name to override --root, got: %q (%v)", name, err)
+	}
+}
+
+func Test_DefaultBranch(t *testing.T) {
+	upstream := newTestRepo(t)
+	upstream.commit("Author One", "one.txt", "1\n")
+
+	clone := &testRepo{t, filepath.Join(t.TempDir(), "clone")}
+	upstream.git("clone", "-q", upstream.dir, clone.dir)
+	clone.git("checkout", "-q", "-b", "feature")
+	clone.commit("Author Two", "two.txt", "1\n")
+
+	o := clone.options()
+	o.defaultBranch = true
+	if err := o.resolve(); err != nil {
+		t.Fatalf("error resolving default branch: %s", err)
+	}
+	if o.ref != "origin/main" {
+		t.Errorf("Expected origin/main, got: %q", o.ref)
+	}
+
+	branch, err := defaultBranch(upstream.options())
+	if err != nil || branch != "main" {
+		t.Errorf("Expected the checked out branch without origin, got: %q, %v", branch, err)
+	}
+
+	upstream.git("checkout", "-q", "--detach")
+	if _, err := defaultBranch(upstream.options()); err == nil {
+		t.Error("Expected an error without a default or checked out branch")
+	}
+
+	o = clone.options()
+	o.rev, o.defaultBranch = "main", true
+	if err := o.resolve(); err == nil {
+		t.Error("Expected an error combining --branch and --default-branch")
+	}
+}
+
+func Test_GitStderr(t *testing.T) {
+	r := newTestRepo(t)
+
+	logs := captureLogs(t)
+
+	out, err := gitOut(r.dir, "-c", "alias.warn=!echo '    3\tAuthor One'; echo 'warning: CRLF will be replaced by LF' >&2", "warn")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	authors, err := mapAuthorCommits(out)
+	if err != nil {
+		t.Fatalf("error parsing output mixed with the warning: %s", err)
+	}
+	if len(authors) != 1 || authors["Author One"] != 3 {
+		t.Errorf("Expected only the standard output to be parsed, got: %v", authors)
+	}
+	if !strings.Contains(logs.String(), "warning: CRLF will be replaced by LF") {
+		t.Errorf("Expected the warning to be logged, got: %q", logs.String())
+	}
+}
+
+func Test_GitDubiousOwnership(t *testing.T) {
+	r := newTestRepo(t)
+
+	_, err := gitOut(r.dir, "-c", "alias.fail=!echo \"fatal: detected dubious ownership in repository at '$PWD'\" >&2; exit 128", "fail")
+	if !errors.Is(err, ErrDubiousOwnership) {
+		t.Fatalf("Expected ErrDubiousOwnership, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "safe.directory "+r.dir) {
+		t.Errorf("Expected the command marking the repo safe, got: %s", err)
+	}
+}
+
+func Test_DateType(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.txt", "1\n")
+	r.git("add", "a.txt")
+	r.gitEnv([]string{
+		"GIT_AUTHOR_DATE=2020-01-01T10:00:00Z",
+		"GIT_COMMITTER_DATE=2023-06-01T10:00:00Z",
+	}, "commit", "-q", "-m", "rebased")
+
+	for dateType, exp := range map[string]string{
+		"author":    "2020-01-01",
+		"committer": "2023-06-01",
+	} {
+		o := r.options()
+		o.dateType = dateType
+
+		first, _, err := dateSpan(o)
+		if err != nil {
+			t.Fatalf("error getting date span: %s", err)
+		}
+		if got := first.Format("2006-01-02"); got != exp {
+			t.Errorf("Expected %s date %s, got: %s", dateType, exp, got)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			t.Fatalf("error reading commits: %s", err)
+		}
+		if got := commits[0].Date.Format("2006-01-02"); got != exp {
+			t.Errorf("Expected commit %s date %s, got: %s", dateType, exp, got)
+		}
+	}
+
+	o := r.options()
+	o.dateType = "authored"
+	if err := o.resolve(); err == nil {
+		t.Error("Expected error for an invalid date type")
+	}
+}
+
+func Test_TimeZone(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.txt", "1\n")
+	r.git("add", "a.txt")
+	r.gitEnv([]string{
+		"GIT_AUTHOR_DATE=2023-01-31T23:30:00-05:00",
+		"GIT_COMMITTER_DATE=2023-01-31T23:30:00-05:00",
+	}, "commit", "-q", "-m", "late")
+
+	for tz, exp := range map[string]string{
+		"":    "2023-01-31",
+		"UTC": "2023-02-01",
+	} {
+		o := r.options()
+		o.tz = tz
+		if err := o.resolve(); err != nil {
+			t.Fatalf("error resolving --tz %q: %s", tz, err)
+		}
+
+		first, _, err := dateSpan(o)
+		if err != nil {
+			t.Fatalf("error getting date span: %s", err)
+		}
+		if got := first.Format("2006-01-02"); got != exp {
+			t.Errorf("Expected date %s with --tz %q, got: %s", exp, tz, got)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			t.Fatalf("error reading commits: %s", err)
+		}
+		if got := commits[0].Date.Format("2006-01-02"); got != exp {
+			t.Errorf("Expected commit date %s with --tz %q, got: %s", exp, tz, got)
+		}
+	}
+
+	o := r.options()
+	o.tz = "Mars/Olympus"
+	if err := o.resolve(); err == nil {
+		t.Error("Expected error for an unknown time zone")
+	}
+}
+
+func Test_PathFollow(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "old/lib.go", "1\n2\n")
+	r.commit("Author Two", "other.go", "1\n")
+	if err := os.Mkdir(filepath.Join(r.dir, "new"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r.git("mv", "old/lib.go", "new/lib.go")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Author Two"}, "commit", "-q", "-m", "move")
+	r.commit("Author Three", "new/lib.go", "1\n2\n3\n")
+
+	o := r.options()
+	o.paths = stringList{"new/lib.go"}
+	commits, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	if len(commits) != 2 || commits["Author One"] != 0 {
+		t.Errorf("Expected the history from the move only, got: %v", commits)
+	}
+
+	o.follow = true
+	if err := o.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	commits, err = authorCommits(o)
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	if len(commits) != 3 || commits["Author One"] != 1 {
+		t.Errorf("Expected the history from before the move, got: %v", commits)
+	}
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error extracting line changes: %s", err)
+	}
+	if lineChanges["Author One"].Additions != 2 || lineChanges["Author Three"].Additions != 1 {
+		t.Errorf("unexpected line changes: %v", lineChanges)
+	}
+
+	logs := captureLogs(t)
+
+	o = r.options()
+	o.paths = stringList{"new", "other.go"}
+	o.follow = true
+	if err := o.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if o.follow || !strings.Contains(logs.String(), "--follow only works with a single --path") {
+		t.Errorf("Expected --follow dropped with a warning for several paths, got: %q", logs.String())
+	}
+	if _, err := authorCommits(o); err != nil {
+		t.Errorf("error extracting commit counts of several paths: %s", err)
+	}
+}
+
+func Test_LogEncoding(t *testing.T) {
+	r := newTestRepo(t)
+
+	// a Latin-1 name without any encoding recorded in the commit
+	r.write("a.txt", "a\n")
+	r.git("add", "a.txt")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=J\xf6rg"}, "commit", "-q", "-m", "a")
+
+	// a Latin-1 name with the encoding recorded, re-encoded by git
+	r.write("b.txt", "b\nb\n")
+	r.git("add", "b.txt")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Ren\xe9"}, "-c", "i18n.commitEncoding=ISO-8859-1", "commit", "-q", "-m", "b")
+
+	// a UTF-8 name in a repo configured to log in Latin-2
+	r.commit("Łukasz", "c.txt", "c\n")
+	r.git("config", "i18n.logOutputEncoding", "ISO-8859-2")
+
+	commits, err := authorCommits(r.options())
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	lineChanges, err := mapLineChanges(r.options())
+	if err != nil {
+		t.Fatalf("error extracting line changes: %s", err)
+	}
+
+	exp := map[string]int{"Jörg": 1, "René": 1, "Łukasz": 1}
+	if !reflect.DeepEqual(commits, exp) {
+		t.Errorf("Expected commits %v, got %v", exp, commits)
+	}
+	if lineChanges["René"].Additions != 2 || len(lineChanges) != 3 {
+		t.Errorf("unexpected line changes: %v", lineChanges)
+	}
+
+	if got := toUTF8("ok\nJ\xf6rg\n"); got != "ok\nJörg\n" {
+		t.Errorf("Expected Latin-1 line to be decoded, got %q", got)
+	}
+}
+
+func Test_CommitList(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Alice", "a.txt", "1\n")
+	alice := strings.TrimSpace(r.git("rev-parse", "HEAD"))
+	r.commit("Bob", "b.txt", "1\n2\n")
+	r.commit("Carol", "c.txt", "1\n2\n3\n")
+	carol := strings.TrimSpace(r.git("rev-parse", "HEAD"))
+
+	file := filepath.Join(t.TempDir(), "commits.txt")
+	content := "# flagged by review\n" + alice + " change a.txt\n\n" + carol[:10] + "\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, set := range map[string]func(o *options){
+		"--commits":      func(o *options) { o.commitList = stringList{alice + "," + carol} },
+		"--commits-file": func(o *options) { o.commitsFile = file },
+	} {
+		o := r.options()
+		set(&o)
+		if err := o.resolve(); err != nil {
+			t.Fatalf("error resolving %s: %s", name, err)
+		}
+
+		counts, err := authorCommits(o)
+		if err != nil {
+			t.Fatalf("error counting commits of %s: %s", name, err)
+		}
+		if exp := map[string]int{"Alice": 1, "Carol": 1}; !reflect.DeepEqual(counts, exp) {
+			t.Errorf("Expected commits %v with %s, got: %v", exp, name, counts)
+		}
+
+		commits, err := logCommits(o)
+		if err != nil {
+			t.Fatalf("error reading commits of %s: %s", name, err)
+		}
+		var lines int
+		for _, c := range commits {
+			lines += c.LineChanges().Sum()
+		}
+		if len(commits) != 2 || lines != 4 {
+			t.Errorf("Expected 2 commits changing 4 lines with %s, got %d changing %d", name, len(commits), lines)
+		}
+	}
+
+	o := r.options()
+	o.commitList = stringList{alice + ",0123456789abcdef,fedcba9876543210"}
+	err := o.resolve()
+	if errorCode(err) != CodeBadRef || !strings.Contains(err.Error(), "0123456789abcdef, fedcba9876543210") {
+		t.Errorf("Expected the missing commits to be reported, got: %v", err)
+	}
+
+	o = r.options()
+	o.commitList = stringList{alice}
+	o.rev = "main"
+	if err := o.resolve(); err == nil {
+		t.Error("Expected error for --commits combined with --branch")
+	}
+}
+
+func Test_PinRev(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.git("tag", "v1")
+	r.commit("Author Two", "two.txt", "1\n")
+	first := strings.TrimSpace(r.git("rev-parse", "v1"))
+	head := strings.TrimSpace(r.git("rev-parse", "HEAD"))
+
+	o := r.options()
+	if err := o.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if o.rev != head || o.ref != "" {
+		t.Errorf("Expected HEAD pinned to %s, got %q", head, o.rev)
+	}
+
+	// moving HEAD after resolving doesn't change the analysed commits
+	r.commit("Author Three", "three.txt", "1\n")
+	commits, err := authorCommits(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 {
+		t.Errorf("Expected the commits of the pinned HEAD, got %v", commits)
+	}
+
+	got, err := pinRev(o, "v1..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := first + ".." + strings.TrimSpace(r.git("rev-parse", "HEAD")); got != exp {
+		t.Errorf("Expected %s, got %s", exp, got)
+	}
+
+	if _, err := pinRev(o, "missing..HEAD"); errorCode(err) != CodeBadRef {
+		t.Errorf("Expected %s for an unknown revision, got %v", CodeBadRef, err)
+	}
+}
+
+func Test_AsOf(t *testing.T) {
+	r := newTestRepo(t)
+	commitAt := func(author, name, date string) {
+		r.write(name, "1\n")
+		r.git("add", name)
+		r.gitEnv([]string{"GIT_AUTHOR_NAME=" + author, "GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date},
+			"commit", "-q", "-m", "change "+name)
+	}
+	commitAt("Author One", "one.txt", "2022-06-01T12:00:00Z")
+	first := strings.TrimSpace(r.git("rev-parse", "HEAD"))
+	commitAt("Author Two", "two.txt", "2023-06-01T12:00:00Z")
+
+	o := r.options()
+	o.asOf = "2023-01-01"
+	if err := o.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if o.rev != first {
+		t.Errorf("Expected the commit before the date %s, got %q", first, o.rev)
+	}
+
+	files, err := currentFiles(o, blameRev(o.rev))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, map[string]bool{"one.txt": true}) {
+		t.Errorf("Expected the files as of the date, got %v", files)
+	}
+
+	o = r.options()
+	o.asOf = "2020-01-01"
+	if err := o.resolve(); err == nil || !strings.Contains(err.Error(), "no commit before") {
+		t.Errorf("Expected an error without a commit before the date, got %v", err)
+	}
+
+	o = r.options()
+	o.asOf, o.rev = "2023-01-01", "HEAD~1..HEAD"
+	if err := o.resolve(); err == nil {
+		t.Error("Expected --as-of to be rejected with a range")
+	}
+
+	// each branch and tag would be analysed up to its own head
+	for _, x := range []*Z.Cmd{AllBranchesCmd, TagsCmd} {
+		if err := x.Call(x, "--repo", r.dir, "--as-of", "2023-01-01"); err == nil || !strings.Contains(err.Error(), "--as-of can't be used") {
+			t.Errorf("Expected --as-of to be rejected by %s, got %v", x.Name, err)
+		}
+	}
+}
+
+func Test_EmptyRepo(t *testing.T) {
+	r := newTestRepo(t)
+	for _, x := range Cmd.Commands {
+		// the reports of a single repo without positional arguments, the
+		// scans of several repos skipping those without commits instead
+		if !strings.HasPrefix(x.Usage, "[--repo DIR]") || !strings.HasSuffix(x.Usage, "]") || x == ForksCmd {
+			continue
+		}
+
+		args := []string{"--repo", r.dir}
+		if x == CheckCmd {
+			args = append(args, "--assert", "authors>0")
+		}
+
+		if err := x.Call(x, args...); err != nil {
+			t.Errorf("Expected %s to end successfully, got: %v", x.Name, err)
+		}
+	}
+
+	o := r.options()
+	if err := o.resolve(); !errors.Is(err, ErrNoCommits) {
+		t.Errorf("Expected ErrNoCommits, got: %v", err)
+	}
+
+	err := ContributionSummaryCmd.Call(ContributionSummaryCmd, "--repo", r.dir, "--strict")
+	if !errors.Is(err, ErrNoCommits) {
+		t.Errorf("Expected ErrNoCommits with --strict, got: %v", err)
+	}
+
+	o = options{dir: t.TempDir()}
+	if err := o.resolve(); err != nil {
+		t.Errorf("Expected a directory that isn't a repo left to the reports, got: %v", err)
+	}
+}
+
+func Test_ExcludeFirstCommit(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Importer", "imported.txt", strings.Repeat("imported\n", 1000))
+	r.commit("Author One", "one.txt", "1\n2\n")
+
+	// a second history with its own root, merged into the first
+	r.git("checkout", "-q", "--orphan", "vendor")
+	r.git("rm", "-q", "-rf", ".")
+	r.commit("Vendor", "vendor.txt", strings.Repeat("vendored\n", 500))
+	r.commit("Author Two", "vendor.txt", strings.Repeat("vendored\n", 500)+"patched\n")
+	r.git("checkout", "-q", "main")
+	r.git("merge", "-q", "--allow-unrelated-histories", "-m", "merge vendor", "vendor")
+
+	o := r.options()
+	o.excludeFirstCommit = true
+	if err := o.resolve(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(o.roots) != 2 {
+		t.Errorf("Expected two root commits, got %v", o.roots)
+	}
+
+	commits, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := map[string]int{"Author One": 1, "Author Two": 1}; !reflect.DeepEqual(commits, exp) {
+		t.Errorf("Expected commits %v, got %v", exp, commits)
+	}
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := map[string]LineChanges{"Author One": {Additions: 2}, "Author Two": {Additions: 1}, "Test": {}}
+	if !reflect.DeepEqual(lineChanges, exp) {
+		t.Errorf("Expected line changes %v, got %v", exp, lineChanges)
+	}
+
+	o = r.options()
+	o.excludeFirstCommit = true
+	o.commitList = stringList{strings.TrimSpace(r.git("rev-parse", "--short", "main~2"))}
+	if err := o.resolve(); err == nil {
+		t.Error("Expected an error when only root commits are given")
+	}
+
+	o = r.options()
+	o.excludeFirstCommit = true
+	o.commitList = stringList{"main~1,main~2"}
+	if err := o.resolve(); err != nil || len(o.shas) != 1 {
+		t.Errorf("Expected the root commit left out of the commits given, got %v, %v", o.shas, err)
+	}
+}
+
+func Test_GitStream(t *testing.T) {
+	r := newTestRepo(t)
+	for i := 0; i < 50; i++ {
+		r.commit("Author One", "one.txt", strings.Repeat("x\n", i+1))
+	}
+
+	args := []string{"log", "--numstat", lineChangesFormat}
+	out, err := gitOut(r.dir, args...)
+	if err != nil {
+		t.Fatalf("error running git: %s", err)
+	}
+	var streamed []byte
+	err = gitStream(r.dir, func(rd io.Reader) error {
+		streamed, err = io.ReadAll(rd)
+		return err
+	}, args...)
+	if err != nil {
+		t.Fatalf("error streaming git: %s", err)
+	}
+	if string(streamed) != out {
+		t.Errorf("Expected the streamed output to be the output of git")
+	}
+
+	stop := errors.New("stop")
+	err = gitStream(r.dir, func(rd io.Reader) error {
+		rd.Read(make([]byte, 10))
+		return stop
+	}, args...)
+	if err != stop {
+		t.Errorf("Expected the error of the reader, got: %v", err)
+	}
+
+	err = gitStream(r.dir, func(rd io.Reader) error {
+		_, err := io.ReadAll(rd)
+		return err
+	}, "log", "no-such-branch")
+	if errorCode(err) != CodeBadRef {
+		t.Errorf("Expected the error of git, got: %v", err)
+	}
+}
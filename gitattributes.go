@@ -0,0 +1,288 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// attributesFile is the name of the file in the top-level directory of a
+// repo read by --respect-gitattributes.
+const attributesFile = ".gitattributes"
+
+// skippedAttributes are the attributes marking the files whose line
+// changes --respect-gitattributes skips, as set by GitHub's linguist.
+var skippedAttributes = []string{"linguist-generated", "linguist-vendored"}
+
+// attrRule is a line of a .gitattributes file setting or unsetting some
+// of the skippedAttributes on the paths matching its pattern.
+type attrRule struct {
+	line  string          // the line, as written
+	attrs map[string]bool // whether each attribute is set or unset
+	re    *regexp.Regexp  // the pattern, as a regexp matching whole paths
+}
+
+// gitattributes are the rules of a .gitattributes file, in their order.
+type gitattributes []attrRule
+
+// lines returns the lines of the rules, telling the rules apart from
+// others when comparing the flags of analyses.
+func (ga gitattributes) lines() []string {
+	var lines []string
+	for _, r := range ga {
+		lines = append(lines, r.line)
+	}
+	return lines
+}
+
+// skips reports whether the line changes of the file at the path are
+// skipped, being marked generated or vendored. Like git, the last rule
+// matching the path and naming an attribute sets it, so that a later rule
+// can unset it for some of the files matched by an earlier one.
+func (ga gitattributes) skips(path string) bool {
+	set := make(map[string]bool, len(skippedAttributes))
+	for _, r := range ga {
+		if !r.re.MatchString(path) {
+			continue
+		}
+		for attr, v := range r.attrs {
+			set[attr] = v
+		}
+	}
+
+	for _, attr := range skippedAttributes {
+		if set[attr] {
+			return true
+		}
+	}
+	return false
+}
+
+// readGitattributes returns the rules of the .gitattributes file in the
+// top-level directory of the last commit analysed by o, or none when it
+// has no such file. The file is read from the commit rather than the work
+// tree, so that its uncommitted changes don't change the analysis of the
+// history, which works the same in a bare repo.
+func readGitattributes(o options) (gitattributes, error) {
+	blob, err := o.git("rev-parse", "--verify", "--quiet", attributesRev(o)+":"+attributesFile)
+	if err != nil {
+		return nil, nil
+	}
+
+	out, err := o.git("cat-file", "blob", strings.TrimSpace(blob))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", attributesFile, err)
+	}
+
+	return parseGitattributes(strings.NewReader(out))
+}
+
+// attributesRev returns the commit whose .gitattributes file is read by
+// readGitattributes, being the end of the revision or range of o, or HEAD
+// when it has none, like with --all-branches or --commits.
+func attributesRev(o options) string {
+	rev := o.rev
+	for _, op := range []string{"...", ".."} {
+		if _, to, ok := strings.Cut(rev, op); ok {
+			rev = to
+			break
+		}
+	}
+	if rev == "" || len(o.shas) > 0 {
+		return "HEAD"
+	}
+	return rev
+}
+
+// parseGitattributes returns the rules of a .gitattributes file naming
+// any of the skippedAttributes. An attribute is set when given bare or
+// with a value other than false, and unset when prefixed with '-' or '!'.
+// Blank lines, comments, and the negative patterns git rejects are
+// skipped, as are the patterns ending in a slash, which only match
+// directories, whose attributes don't apply to the files inside them.
+func parseGitattributes(r io.Reader) (gitattributes, error) {
+	var rules gitattributes
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		pattern, rest, err := cutPattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", attributesFile, err)
+		}
+		if strings.HasSuffix(pattern, "/") {
+			continue
+		}
+
+		attrs := make(map[string]bool)
+		for _, field := range strings.Fields(rest) {
+			name, value, hasValue := strings.Cut(field, "=")
+			set := true
+			switch {
+			case strings.HasPrefix(name, "-"), strings.HasPrefix(name, "!"):
+				name, set = name[1:], false
+			case hasValue:
+				set = value != "false"
+			}
+			for _, attr := range skippedAttributes {
+				if name == attr {
+					attrs[name] = set
+				}
+			}
+		}
+		if len(attrs) == 0 {
+			continue
+		}
+
+		rules = append(rules, attrRule{line, attrs, attributePattern(pattern)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", attributesFile, err)
+	}
+
+	return rules, nil
+}
+
+// cutPattern returns the pattern a .gitattributes line starts with, and
+// the attributes following it. A pattern holding whitespace is quoted
+// like a C string.
+func cutPattern(line string) (string, string, error) {
+	if !strings.HasPrefix(line, `"`) {
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			return line, "", nil
+		}
+		return line[:i], line[i:], nil
+	}
+
+	quoted, err := strconv.QuotedPrefix(line)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid quoted pattern in %q", line)
+	}
+	pattern, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid quoted pattern in %q", line)
+	}
+	return pattern, line[len(quoted):], nil
+}
+
+// attributePattern returns a regexp matching the paths matched by a
+// .gitattributes pattern, with the glob semantics of git. A pattern
+// without a slash matches the name of a file in any directory, while one
+// with a slash matches paths from the top-level directory, a leading
+// slash only anchoring it. In both, '*' matches any characters but a
+// slash, '?' any single one, and brackets a class of them. A leading
+// "**/" matches in any directory, a trailing "/**" anything inside the
+// directory, and "/**/" any number of directories.
+func attributePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteByte('^')
+	if !strings.Contains(pattern, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case pattern[i:] == "**" && i > 0 && pattern[i-1] == '/':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			class, n := bracketClass(pattern[i:])
+			if n == 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(class)
+			i += n - 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteByte('$')
+
+	return regexp.MustCompile(b.String())
+}
+
+// bracketClass returns the regexp class of the bracket expression the
+// glob starts with, and its length in the glob, being 0 when it isn't
+// closed. A leading '!' or '^' negates the class, which never matches a
+// slash.
+func bracketClass(glob string) (string, int) {
+	i := 1
+	negated := i < len(glob) && (glob[i] == '!' || glob[i] == '^')
+	if negated {
+		i++
+	}
+	start := i
+	if i < len(glob) && glob[i] == ']' {
+		i++ // a leading ']' is part of the class
+	}
+	for i < len(glob) && glob[i] != ']' {
+		i++
+	}
+	if i >= len(glob) {
+		return "", 0
+	}
+
+	var b strings.Builder
+	b.WriteByte('[')
+	if negated {
+		b.WriteString("^/")
+	}
+	for _, r := range glob[start:i] {
+		if r == '\\' || r == '[' || r == ']' || (r == '^' && b.Len() == 1) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte(']')
+
+	return b.String(), i + 1
+}
+
+// skipAttributed returns the commits without their changes of the files
+// marked generated or vendored in .gitattributes, along with the number
+// of file changes skipped, with --respect-gitattributes. The commits still
+// count as commits.
+func (o options) skipAttributed(commits []commit) ([]commit, int) {
+	if len(o.attributes) == 0 {
+		return commits, 0
+	}
+
+	var skipped int
+	for i, c := range commits {
+		var kept []fileChange
+		for _, f := range c.Files {
+			if o.attributes.skips(f.Path) {
+				skipped++
+				continue
+			}
+			kept = append(kept, f)
+		}
+		commits[i].Files = kept
+	}
+
+	return commits, skipped
+}
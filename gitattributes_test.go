@@ -0,0 +1,105 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_ParseGitattributes(t *testing.T) {
+	f, err := os.Open("testdata/gitattributes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	attributes, err := parseGitattributes(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attributes) != 8 {
+		t.Fatalf("Expected 8 rules, got %q", attributes.lines())
+	}
+
+	cases := map[string]bool{
+		"api.pb.go":                 true,
+		"api/v1/service.pb.go":      true,
+		"api/keep.pb.go":            false,
+		"api/openapi.json":          true,
+		"internal/api/openapi.json": false,
+		"vendor/lib/lib.go":         true,
+		"vendor/local/patch.go":     false,
+		"src/vendor/lib.go":         false,
+		"docs/app.min.js":           true,
+		"docs/js/deep/app.min.js":   true,
+		"gen/a1.go":                 true,
+		"gen/d1.go":                 false,
+		"gen/sub/a1.go":             false,
+		"assets/big file.js":        true,
+		"build/out.go":              false,
+		"main.go":                   false,
+		"scripts/install.sh":        false,
+	}
+	for path, exp := range cases {
+		if got := attributes.skips(path); got != exp {
+			t.Errorf("Expected %s skipped to be %v, got %v", path, exp, got)
+		}
+	}
+}
+
+func Test_RespectGitattributes(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "main.go", "package main\n")
+	r.commit("Author Two", "gen/api.pb.go", "package gen\n\nvar x = 1\n")
+	r.commit("Author Two", "other.go", "package main\n")
+	r.commit("Author One", ".gitattributes", "gen/*.pb.go linguist-generated\n")
+
+	o := r.options()
+	o.respectGitattributes = true
+	if err := o.resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error extracting line changes: %s", err)
+	}
+	exp := map[string]LineChanges{
+		"Author One": {Additions: 2},
+		"Author Two": {Additions: 1},
+	}
+	if !reflect.DeepEqual(lineChanges, exp) {
+		t.Errorf("Expected the lines of the generated file skipped, %v, got %v", exp, lineChanges)
+	}
+
+	commits, err := logCommits(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 4 || len(commits[2].Files) != 0 {
+		t.Errorf("Expected the commit kept without the generated file, got %+v", commits)
+	}
+
+	if err := os.WriteFile(filepath.Join(r.dir, ".gitattributes"), []byte("*.go linguist-generated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o = r.options()
+	o.respectGitattributes = true
+	if err := o.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := o.attributes.lines(); !reflect.DeepEqual(lines, []string{"gen/*.pb.go linguist-generated"}) {
+		t.Errorf("Expected the committed .gitattributes read, not the work tree, got %q", lines)
+	}
+
+	o = r.options()
+	o.rev = "HEAD~1"
+	o.respectGitattributes = true
+	if err := o.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if o.attributes != nil {
+		t.Errorf("Expected no .gitattributes before it was committed, got %q", o.attributes.lines())
+	}
+}
@@ -5,39 +5,154 @@ package gitcontrib
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-
-	Z "github.com/rwxrob/bonzai/z"
+	"time"
 )
 
 // AuthorCommits returns a map of author names with their respective
 // non-merge commit counts as values
-func AuthorCommits() map[string]int {
-	var out string
+func AuthorCommits() (map[string]int, error) {
+	authorMap, err := authorCommits(options{})
+	if err != nil {
+		return nil, fmt.Errorf("error extracting commit counts: %w", err)
+	}
 
-	out = Z.Out("git", "branch")
-	branch, err := extractCheckedOutBranch(out)
+	return authorMap, nil
+}
+
+// authorCommits returns the non-merge commit counts of each author in the
+// revision or range of o.
+func authorCommits(o options) (map[string]int, error) {
+	if o.rev == "" && len(o.shas) == 0 && len(o.tips) == 0 {
+		out, err := o.git("branch")
+		if err != nil {
+			return nil, err
+		}
+
+		o.rev, err = extractCheckedOutBranch(out)
+		if errors.Is(err, ErrDetachedHead) {
+			o.rev, err = headCommit(o)
+		}
+		if errors.Is(err, ErrNoBranch) && !hasCommits(o) {
+			return nil, ErrNoCommits
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error extracting branch: %w", err)
+		}
+	}
+
+	// the commits too large to count, or touching no code, are only known
+	// from their files, and the squash merges from their messages
+	if (o.maxCommitLines > 0 && o.excludeCommit) || o.codeOnly || o.squashes() {
+		commits, err := logCommits(o)
+		if err != nil {
+			return nil, err
+		}
+		authorMap := make(map[string]int)
+		for _, c := range commits {
+			if !c.isMerge() {
+				authorMap[c.Author]++
+			}
+		}
+		return authorMap, nil
+	}
+
+	// shortlog always maps the authors through the mailmap
+	if o.noMailmap {
+		out, err := o.walk("log", "--no-merges", "--format=%an")
+		if err != nil {
+			return nil, err
+		}
+		return o.bucketCounts(countAuthors(out)), nil
+	}
+
+	// shortlog reads stdin without a revision, so walk passes the one
+	// resolved above
+	out, err := o.walk("shortlog", "-sn", "--no-merges")
 	if err != nil {
-		log.Fatalf("Error extracting branch: %s", err)
+		return nil, err
 	}
 
-	// git branch has to be passed when invoking like this
-	// https://stackoverflow.com/questions/51966053/what-is-wrong-with-invoking-git-shortlog-from-go-exec
-	out = Z.Out("git", "shortlog", "-sn", "--no-merges", branch)
 	authorMap, err := mapAuthorCommits(out)
 	if err != nil {
-		log.Fatalf("Error extracting commit counts: %s", err)
+		return nil, err
 	}
+	return o.bucketCounts(authorMap), nil
+}
 
+// countAuthors returns the number of lines of each author in git log
+// output with one author name per line. An empty line is a commit with an
+// empty author name.
+func countAuthors(gitOutput string) map[string]int {
+	authorMap := make(map[string]int)
+	scanner := bufio.NewScanner(strings.NewReader(gitOutput))
+	for scanner.Scan() {
+		authorMap[scanner.Text()]++
+	}
 	return authorMap
 }
 
+// defaultUnknownAuthor is the label of the commits without an author
+// name, unless another is set by --unknown-author.
+const defaultUnknownAuthor = "(unknown)"
+
+// authorName returns the name of the author as reported, being the label
+// of --unknown-author for a name that is empty or only whitespace, like
+// those of some imported histories.
+func (o options) authorName(name string) string {
+	if strings.TrimSpace(name) != "" {
+		return name
+	}
+	if o.unknownAuthor == "" {
+		return defaultUnknownAuthor
+	}
+	return o.unknownAuthor
+}
+
+// bucketCounts returns the counts with those of the authors without a name
+// summed under the label of authorName.
+func (o options) bucketCounts(counts map[string]int) map[string]int {
+	for name, n := range counts {
+		if label := o.authorName(name); label != name {
+			delete(counts, name)
+			counts[label] += n
+		}
+	}
+	return counts
+}
+
+// bucketLineChanges returns the line changes with those of the authors
+// without a name merged under the label of authorName.
+func (o options) bucketLineChanges(lineChanges map[string]LineChanges) map[string]LineChanges {
+	for name, lc := range lineChanges {
+		if label := o.authorName(name); label != name {
+			delete(lineChanges, name)
+			lineChanges[label] = lineChanges[label].Merge(lc)
+		}
+	}
+	return lineChanges
+}
+
+// ErrNoBranch is returned when no branch is checked out and no ref was
+// given to analyse instead.
+var ErrNoBranch = errors.New("no branch checked out; specify a ref explicitly")
+
+// ErrDetachedHead is returned by extractCheckedOutBranch for a detached
+// HEAD, like in CI checkouts, in which case the commit of HEAD is analysed.
+var ErrDetachedHead = errors.New("HEAD is detached")
+
+// extractCheckedOutBranch returns the checked out branch marked by a star
+// in the output of git branch. ErrDetachedHead is returned when git lists
+// a detached HEAD, like "* (HEAD detached at 1a2b3c)", and ErrNoBranch
+// when no branch is marked at all.
 func extractCheckedOutBranch(gitBranchOutput string) (string, error) {
 
 	// for all lines in git branch output, find the active one
@@ -51,29 +166,258 @@ func extractCheckedOutBranch(gitBranchOutput string) (string, error) {
 		}
 
 		if match {
-			branch = strings.Fields(line)[1]
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				return "", ErrNoBranch
+			}
+			if strings.HasPrefix(fields[1], "(") {
+				return "", ErrDetachedHead
+			}
+			branch = fields[1]
 			break
 		}
 	}
 
+	if branch == "" {
+		return "", ErrNoBranch
+	}
+
 	return branch, nil
 }
 
-func getRepoDirName() (string, error) {
+// defaultBranch returns the default branch of the repo of o, being the
+// branch origin/HEAD points to, as set when cloning. Without one, the
+// checked out branch is returned.
+func defaultBranch(o options) (string, error) {
+	out, err := o.git("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	if err == nil && strings.TrimSpace(out) != "" {
+		return strings.TrimSpace(out), nil
+	}
+
+	out, err = o.git("branch")
+	if err != nil {
+		return "", err
+	}
+
+	branch, err := extractCheckedOutBranch(out)
+	if err != nil {
+		return "", errors.New("no default branch found; specify a ref explicitly")
+	}
+
+	return branch, nil
+}
+
+// ErrNoCommits is returned when the repository has no commits to analyse.
+var ErrNoCommits = errors.New("repository has no commits")
+
+// ErrParseDrift is returned when the output of git is parsed into line
+// changes that can't be right, as when a new version of git changed its
+// format.
+var ErrParseDrift = errors.New("git output parsed into bad line changes")
+
+// ErrNoContributions is returned by --fail-on-empty when no author is
+// reported.
+var ErrNoContributions = errors.New("no contributions in the analysed history")
+
+// ErrTooManyAuthors is returned by --max-authors when more authors are
+// reported, which usually tells of a misconfiguration.
+var ErrTooManyAuthors = errors.New("too many authors")
+
+// RepoDateSpan returns the dates of the first and last commits of the
+// current repo branch, by author date. ErrNoCommits is returned for a
+// repo without commits.
+func RepoDateSpan() (first, last time.Time, err error) {
+	return dateSpan(options{})
+}
+
+// headCommit returns the hash of the commit HEAD of the repo of o points
+// to, or ErrNoBranch if it can't be resolved.
+func headCommit(o options) (string, error) {
+	out, err := o.git("rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return "", ErrNoBranch
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// hasCommits reports whether HEAD of the repo of o points to a commit,
+// which it doesn't in a repo without commits.
+func hasCommits(o options) bool {
+	_, err := o.git("rev-parse", "--verify", "--quiet", "HEAD")
+	return err == nil
+}
+
+// isEmpty reports whether the repo of o has no commits yet, as right
+// after git init. A directory that isn't a repo is not empty, leaving it to
+// be reported by the git invocations of the reports.
+func isEmpty(o options) bool {
+	if hasCommits(o) {
+		return false
+	}
+	_, err := o.git("rev-parse", "--git-dir")
+	return err == nil
+}
+
+// dateSpan returns the first and last author dates of the commits in the
+// revision or range of o, using HEAD when none is given.
+func dateSpan(o options) (first, last time.Time, err error) {
+	if o.rev == "" && len(o.shas) == 0 && !hasCommits(o) {
+		return first, last, ErrNoCommits
+	}
+
+	out, err := o.walk("log", o.logFormat("--format=%aI"))
+	if err != nil || dryRun {
+		return first, last, err
+	}
+
+	first, last, err = parseDateSpan(out)
+	return o.inZone(first), o.inZone(last), err
+}
+
+// parseDateSpan returns the earliest and latest of the dates in the git
+// log output, given as one strict ISO 8601 date per line.
+func parseDateSpan(gitOutput string) (first, last time.Time, err error) {
+	scanner := bufio.NewScanner(strings.NewReader(gitOutput))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		date, err := time.Parse(time.RFC3339, line)
+		if err != nil {
+			return first, last, fmt.Errorf("error parsing date: %w", err)
+		}
+
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if last.IsZero() || date.After(last) {
+			last = date
+		}
+	}
+
+	if first.IsZero() {
+		return first, last, ErrNoCommits
+	}
+
+	return first, last, nil
+}
+
+// getRepoDirName returns the name of the directory of the repo of o. For
+// bare repos, which have no work tree, it is the name of the git directory
+// without any ".git" suffix. In a linked worktree, whose directory is
+// often named after its branch, it is the name of the repo the worktree
+// was added to. The name given with --repo-name is returned instead when
+// set.
+func getRepoDirName(o options) (string, error) {
+	if o.repoName != "" {
+		return o.repoName, nil
+	}
+
+	path, bare, err := getRepoPath(o)
+	if err != nil {
+		return "", err
+	}
 
-	output := Z.Out("git", "rev-parse", "--show-toplevel")
-	if output == "" {
-		return "", errors.New("error getting git repo directory path")
+	if !bare {
+		commonDir, linked, err := linkedWorktree(o)
+		if err != nil {
+			return "", err
+		}
+		if linked {
+			// the common directory is the .git directory of the main
+			// work tree, or the repo itself when it is bare
+			path, bare = commonDir, true
+			if filepath.Base(commonDir) == ".git" {
+				path, bare = filepath.Dir(commonDir), false
+			}
+		}
 	}
 
-	dirname := strings.TrimSpace(filepath.Base(output))
+	dirname := filepath.Base(path)
+	if bare {
+		dirname = strings.TrimSuffix(dirname, ".git")
+	}
 
 	return dirname, nil
 }
 
+// linkedWorktree reports whether the repo of o is checked out in a linked
+// worktree, added by git worktree add, whose git directory differs from
+// the directory shared by all the work trees of the repo, which is
+// returned.
+func linkedWorktree(o options) (commonDir string, linked bool, err error) {
+	out, err := o.git("rev-parse", "--is-inside-work-tree")
+	if err != nil || strings.TrimSpace(out) != "true" {
+		return "", false, nil
+	}
+
+	gitDir, err := o.git("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", false, fmt.Errorf("error getting git directory path: %w", err)
+	}
+	out, err = o.git("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", false, fmt.Errorf("error getting common git directory path: %w", err)
+	}
+
+	// the common directory is given relative to the directory git runs in,
+	// unless in a linked worktree
+	commonDir = filepath.FromSlash(strings.TrimSpace(out))
+	if !filepath.IsAbs(commonDir) {
+		dir, err := filepath.Abs(o.dir)
+		if err != nil {
+			return "", false, err
+		}
+		commonDir = filepath.Join(dir, commonDir)
+	}
+
+	return commonDir, !sameDir(commonDir, filepath.FromSlash(strings.TrimSpace(gitDir))), nil
+}
+
+// sameDir reports whether the paths are of the same directory, resolving
+// the symbolic links git resolves in the paths it reports.
+func sameDir(a, b string) bool {
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// getRepoPath returns the absolute path of the work tree of the repo of o,
+// or of its git directory for bare repos.
+func getRepoPath(o options) (path string, bare bool, err error) {
+
+	out, err := o.git("rev-parse", "--is-bare-repository")
+	if err != nil {
+		return "", false, fmt.Errorf("error checking if repo is bare: %w", err)
+	}
+
+	if strings.TrimSpace(out) == "true" {
+		gitdir, err := o.git("rev-parse", "--absolute-git-dir")
+		if err != nil {
+			return "", true, fmt.Errorf("error getting git directory path: %w", err)
+		}
+
+		return filepath.FromSlash(strings.TrimSpace(gitdir)), true, nil
+	}
+
+	toplevel, err := o.git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", false, fmt.Errorf("error getting git repo directory path: %w", err)
+	}
+
+	return filepath.FromSlash(strings.TrimSpace(toplevel)), false, nil
+}
+
+// LineChanges holds the number of added and deleted lines of an author.
 type LineChanges struct {
-	Additions int
-	Deletions int
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
 }
 
 func (lc *LineChanges) Add(n int) {
@@ -84,23 +428,59 @@ func (lc *LineChanges) Del(n int) {
 	lc.Deletions += n
 }
 
-func (lc *LineChanges) Sum() int {
+// Sum returns the total number of changed lines.
+func (lc LineChanges) Sum() int {
 	return lc.Additions + lc.Deletions
 }
 
+// Net returns the number of added lines minus the number of deleted ones.
+func (lc LineChanges) Net() int {
+	return lc.Additions - lc.Deletions
+}
+
+// Merge returns the line changes of both lc and other combined, like when
+// aggregating the changes of an author across several repos.
+func (lc LineChanges) Merge(other LineChanges) LineChanges {
+	return LineChanges{
+		Additions: lc.Additions + other.Additions,
+		Deletions: lc.Deletions + other.Deletions,
+	}
+}
+
+// String returns the changes in the style of git, like "+12 -3".
+func (lc LineChanges) String() string {
+	return fmt.Sprintf("+%d -%d", lc.Additions, lc.Deletions)
+}
+
+// MarshalJSON includes the sum of the changes along with the additions
+// and deletions.
+func (lc LineChanges) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+		Sum       int `json:"sum"`
+	}{lc.Additions, lc.Deletions, lc.Sum()})
+}
+
 func mapAuthorCommits(shortlogOutput string) (map[string]int, error) {
 
 	authorMap := make(map[string]int)
 	scanner := bufio.NewScanner(strings.NewReader(shortlogOutput))
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		fields := strings.Fields(line)
-		commits, err := strconv.Atoi(fields[0])
+		count, name, _ := strings.Cut(line, "\t")
+		if name == "" {
+			count, name, _ = strings.Cut(line, " ")
+		}
+		commits, err := strconv.Atoi(strings.TrimSpace(count))
 		if err != nil {
 			return nil, fmt.Errorf("error parsing commit number: %w", err)
 		}
 
-		authorMap[strings.Join(fields[1:], " ")] = commits
+		// names are kept as is, as variants only differing in whitespace
+		// are listed separately, but for names of only whitespace, which
+		// are all trimmed to the empty name
+		authorMap[strings.TrimLeft(name, " \t")] += commits
 
 	}
 
@@ -109,37 +489,144 @@ func mapAuthorCommits(shortlogOutput string) (map[string]int, error) {
 
 // MapLineChanges returns an author map containing the line changes of each
 // author in the current repo branch.
-func MapLineChanges() map[string]LineChanges {
+func MapLineChanges() (map[string]LineChanges, error) {
+	authorMap, err := mapLineChanges(options{})
+	if err != nil {
+		return nil, fmt.Errorf("error extracting line changes: %w", err)
+	}
+
+	return authorMap, nil
+}
+
+// mapLineChanges returns the line changes of each author in the revision
+// or range of o, using HEAD when none is given.
+func mapLineChanges(o options) (map[string]LineChanges, error) {
+	if o.maxCommitLines > 0 || o.capLinesPerFile > 0 || o.codeOnly || o.squashes() || o.excludeReverts || len(o.attributes) > 0 {
+		return mapCommitLineChanges(o)
+	}
 
-	out := Z.Out("git", "log", "--numstat", "--pretty='%aN'")
-	authorMap, err := parseLineChanges(out)
+	// the output is parsed as git writes it, as it can take gigabytes for
+	// the largest histories
+	var lineChanges map[string]LineChanges
+	err := o.streamDiffs(func(r io.Reader) error {
+		var err error
+		lineChanges, err = readLineChanges(r)
+		return err
+	}, "--numstat", o.logFormat(lineChangesFormat))
+	if err = o.drift(err); err != nil {
+		return nil, err
+	}
+	return o.bucketLineChanges(lineChanges), nil
+}
+
+// lineChangesFormat is the git log format parsed by parseLineChanges,
+// giving the author name of each commit on a line of its own, preceded by
+// a NUL byte, so that an empty name still tells the commits apart.
+const lineChangesFormat = "--pretty=format:%x00%aN"
+
+// mapCommitLineChanges returns the line changes of each author like
+// mapLineChanges, reading the history commit by commit to skip the
+// commits larger than --max-commit-lines, whose number is reported, and
+// those touching no code file with --code-only, to skip the line changes
+// of the reverts with --exclude-reverts, whose number is reported, and of
+// the generated and vendored files with --respect-gitattributes, to cap
+// the additions of each file with --cap-lines-per-file, reporting the
+// lines left out, and to credit the squash merges as chosen by
+// --squash-author.
+func mapCommitLineChanges(o options) (map[string]LineChanges, error) {
+	commits, err := o.readCommits(o.streamDiffs)
 	if err != nil {
-		log.Fatalf("Error extracting commit counts: %s", err)
+		return nil, err
 	}
 
-	return authorMap
+	commits, skipped := o.skipNonCode(commits)
+	if skipped > 0 {
+		debugf("skipped %d commits touching no code file", skipped)
+	}
+
+	commits, skipped = o.skipAttributed(commits)
+	if skipped > 0 {
+		debugf("skipped %d changes of generated or vendored files", skipped)
+	}
+
+	commits, capped := o.capFiles(commits)
+	if capped > 0 {
+		debugf("capped %d added lines of files adding more than %d lines", capped, o.capLinesPerFile)
+	}
+
+	commits, skipped = o.skipLarge(commits)
+	if skipped > 0 {
+		what, noun := "the line changes of ", "commits"
+		if o.excludeCommit {
+			what = ""
+		}
+		if skipped == 1 {
+			noun = "commit"
+		}
+		warnf("skipped %s%d %s changing more than %d lines", what, skipped, noun, o.maxCommitLines)
+	}
+
+	commits, reverts, pairs, err := o.skipReverts(commits)
+	if err != nil {
+		return nil, err
+	}
+	o.logReverts(reverts, pairs)
+
+	commits, err = o.attributeSquashes(commits)
+	if err != nil {
+		return nil, err
+	}
+
+	authorMap := make(map[string]LineChanges)
+	for _, c := range commits {
+		name := o.authorName(c.Author)
+		authorMap[name] = authorMap[name].Merge(c.LineChanges())
+	}
+
+	return authorMap, nil
 }
 
+// numstatPrefix matches the start of a numstat line, being the number of
+// added lines, or a dash for binary files, followed by a tab.
+var numstatPrefix = regexp.MustCompile(`^(\d+|-)\t`)
+
+// parseLineChanges parses the output of git log --numstat with the
+// author name as format into the line changes of each author, the name
+// being preceded by a NUL byte with lineChangesFormat. Numstat lines that
+// can't be parsed are skipped. The line changes are returned along with
+// an ErrParseDrift error when checkLineChanges finds them bad, or counting
+// the skipped lines.
 func parseLineChanges(gitOutput string) (map[string]LineChanges, error) {
+	return readLineChanges(strings.NewReader(gitOutput))
+}
+
+// readLineChanges parses the output of git log --numstat like
+// parseLineChanges, reading it line by line from r, so that only the line
+// changes of the authors are held in memory rather than the whole output.
+// Like gitOut does for the whole output, the lines that aren't valid
+// UTF-8 are decoded as Latin-1.
+func readLineChanges(r io.Reader) (map[string]LineChanges, error) {
 	authorMap := make(map[string]LineChanges)
 
-	scanner := bufio.NewScanner(strings.NewReader(gitOutput))
+	scanner := bufio.NewScanner(r)
 	currentAuthor := ""
+	var malformed int
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
+		line := toUTF8(scanner.Text())
+		if strings.TrimSpace(line) == "" {
 			continue
 		}
 
-		// check if line is author
-		match, err := regexp.MatchString("^[a-zA-Z']", line)
-		if match {
-			if strings.HasPrefix(line, "'") && strings.HasSuffix(line, "'") {
-				line = line[:len(line)-1]
-				line = line[1:]
-			}
-			currentAuthor = line
-			_, ok := authorMap[line]
+		// lines that aren't numstat lines are authors
+		f, err := parseNumstat(line)
+		if err != nil && numstatPrefix.MatchString(line) {
+			debugf("skipping %s", err)
+			malformed++
+			continue
+		}
+		if err != nil {
+			currentAuthor = strings.TrimSpace(strings.TrimPrefix(line, "\x00"))
+			_, ok := authorMap[currentAuthor]
 			if !ok { // new author
 				authorMap[currentAuthor] = LineChanges{0, 0}
 			}
@@ -147,29 +634,40 @@ func parseLineChanges(gitOutput string) (map[string]LineChanges, error) {
 		}
 
 		// if not new author, accumulate counts
-		var adds int
-		var dels int
-
-		fields := strings.Fields(line)
-		if fields[0] != "-" {
-			adds, err = strconv.Atoi(fields[0])
-			if err != nil {
-				return nil, fmt.Errorf("error parsing adds: %s", err)
-			}
-		}
-
-		if fields[1] != "-" {
-			dels, err = strconv.Atoi(fields[1])
-			if err != nil {
-				return nil, fmt.Errorf("error parsing dels: %s", err)
-			}
-		}
-
 		a := authorMap[currentAuthor]
-		a.Add(adds)
-		a.Del(dels)
+		a.Add(f.Additions)
+		a.Del(f.Deletions)
 		authorMap[currentAuthor] = a
 	}
 
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := checkLineChanges(authorMap); err != nil {
+		return authorMap, err
+	}
+	if malformed > 0 {
+		return authorMap, fmt.Errorf("%w: skipped %d malformed numstat lines", ErrParseDrift, malformed)
+	}
 	return authorMap, nil
 }
+
+// checkLineChanges returns an ErrParseDrift error when an author has
+// negative line changes.
+func checkLineChanges(lineChanges map[string]LineChanges) error {
+	authors := make([]string, 0, len(lineChanges))
+	for author := range lineChanges {
+		authors = append(authors, author)
+	}
+	sort.Strings(authors)
+
+	for _, author := range authors {
+		lc := lineChanges[author]
+		if lc.Additions < 0 || lc.Deletions < 0 {
+			return fmt.Errorf("%w: author %q has %d additions and %d deletions", ErrParseDrift, author, lc.Additions, lc.Deletions)
+		}
+	}
+
+	return nil
+}
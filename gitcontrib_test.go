@@ -1,8 +1,16 @@
 package gitcontrib
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func Test_ExtractCheckedOutBranch(t *testing.T) {
@@ -20,6 +28,44 @@ func Test_ExtractCheckedOutBranch(t *testing.T) {
 	}
 }
 
+func Test_ExtractCheckedOutBranchNone(t *testing.T) {
+	for _, gbOutput := range []string{
+		"",
+		"  main\n  feature\n",
+	} {
+		_, err := extractCheckedOutBranch(gbOutput)
+		if !errors.Is(err, ErrNoBranch) {
+			t.Errorf("Expected ErrNoBranch for %q, got: %v", gbOutput, err)
+		}
+	}
+}
+
+func Test_ExtractCheckedOutBranchDetached(t *testing.T) {
+	gbOutput := `* (HEAD detached at 1a2b3c)
+  main
+`
+	_, err := extractCheckedOutBranch(gbOutput)
+	if !errors.Is(err, ErrDetachedHead) {
+		t.Errorf("Expected ErrDetachedHead, got: %v", err)
+	}
+}
+
+func Test_AuthorCommitsDetachedHead(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.commit("Author Two", "two.txt", "1\n")
+	r.git("checkout", "--detach", "HEAD~1")
+
+	m, err := authorCommits(r.options())
+	if err != nil {
+		t.Fatalf("error getting author commits: %s", err)
+	}
+
+	if len(m) != 1 || m["Author One"] != 1 {
+		t.Errorf("Expected only the commit of HEAD's history, got: %v", m)
+	}
+}
+
 func Test_MapAuthorCommits(t *testing.T) {
 	gitOutput := `    42  Author One
      3  Author Two
@@ -70,3 +116,274 @@ func Test_MapLineChanges(t *testing.T) {
 		}
 	}
 }
+
+func Test_MapLineChangesAuthorNames(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Svein-Kåre Bjørnsen", "one.txt", "1\n2\n")
+	r.commit("Ørjan Ås", "two.txt", "1\n")
+
+	authorMap, err := mapLineChanges(r.options())
+	if err != nil {
+		t.Fatalf("error getting line changes: %s", err)
+	}
+
+	for k := range authorMap {
+		if strings.ContainsAny(k, `'"`) {
+			t.Errorf("Expected author without quotes, got: %q", k)
+		}
+	}
+
+	if authorMap["Svein-Kåre Bjørnsen"].Additions != 2 {
+		t.Errorf("unexpected line changes: %v", authorMap)
+	}
+	if authorMap["Ørjan Ås"].Additions != 1 {
+		t.Errorf("unexpected line changes: %v", authorMap)
+	}
+}
+
+func Test_LineChanges(t *testing.T) {
+	lc := LineChanges{Additions: 12, Deletions: 3}
+
+	merged := lc.Merge(LineChanges{Additions: 1, Deletions: 2})
+	if merged != (LineChanges{Additions: 13, Deletions: 5}) {
+		t.Errorf("unexpected merge result: %+v", merged)
+	}
+	if lc.Additions != 12 || lc.Deletions != 3 {
+		t.Errorf("Expected merge to leave the receiver untouched, got: %+v", lc)
+	}
+
+	if got := lc.Net(); got != 9 {
+		t.Errorf("Expected net 9, got: %d", got)
+	}
+	if got := lc.String(); got != "+12 -3" {
+		t.Errorf("Expected \"+12 -3\", got: %q", got)
+	}
+
+	buf, err := json.Marshal(lc)
+	if err != nil {
+		t.Fatalf("error marshalling line changes: %s", err)
+	}
+	exp := `{"additions":12,"deletions":3,"sum":15}`
+	if string(buf) != exp {
+		t.Errorf("Expected %s, got: %s", exp, buf)
+	}
+}
+
+func Test_ParseDateSpan(t *testing.T) {
+	gitOutput := `2023-03-01T10:00:00+01:00
+2023-05-01T10:00:00+02:00
+2021-01-01T10:00:00+00:00
+`
+	first, last, err := parseDateSpan(gitOutput)
+	if err != nil {
+		t.Fatalf("error parsing date span: %s", err)
+	}
+
+	if exp := "2021-01-01T10:00:00Z"; first.Format(time.RFC3339) != exp {
+		t.Errorf("Expected first date %s, got: %s", exp, first)
+	}
+	if exp := "2023-05-01T10:00:00+02:00"; last.Format(time.RFC3339) != exp {
+		t.Errorf("Expected last date %s, got: %s", exp, last)
+	}
+
+	_, _, err = parseDateSpan("")
+	if !errors.Is(err, ErrNoCommits) {
+		t.Errorf("Expected ErrNoCommits for empty output, got: %v", err)
+	}
+}
+
+func Test_ParseLineChangesMalformed(t *testing.T) {
+	buf, err := ioutil.ReadFile("testdata/numstat-renames")
+	if err != nil {
+		t.Fatalf("unable to read file: %s", err)
+	}
+
+	authorMap, err := parseLineChanges(string(buf))
+	if !errors.Is(err, ErrParseDrift) || !strings.Contains(err.Error(), "skipped 2 malformed numstat lines") {
+		t.Fatalf("Expected ErrParseDrift for the malformed lines, got: %v", err)
+	}
+
+	expected := map[string]LineChanges{
+		"Author One": {Additions: 5, Deletions: 1},
+		"Author Two": {Additions: 9, Deletions: 7},
+	}
+	if !reflect.DeepEqual(authorMap, expected) {
+		t.Errorf("Expected %v, got: %v", expected, authorMap)
+	}
+}
+
+func Test_ParseLineChangesDrift(t *testing.T) {
+	out := "Author One\n3\t1\ta.go\n\nAuthor Two\n-5\t2\tb.go\n"
+
+	lineChanges, err := parseLineChanges(out)
+	if !errors.Is(err, ErrParseDrift) || !strings.Contains(err.Error(), `"Author Two" has -5 additions and 2 deletions`) {
+		t.Fatalf("Expected ErrParseDrift for the negative additions, got: %v", err)
+	}
+	if errorCode(err) != CodeParseDrift {
+		t.Errorf("Expected code %s, got: %s", CodeParseDrift, errorCode(err))
+	}
+	if lineChanges["Author One"] != (LineChanges{3, 1}) {
+		t.Errorf("Expected the line changes to be kept, got: %v", lineChanges)
+	}
+
+	logs := captureLogs(t)
+	if err := (options{}).drift(err); err != nil {
+		t.Errorf("Expected only a warning without --strict, got: %v", err)
+	}
+	if !strings.Contains(logs.String(), "bad line changes") {
+		t.Errorf("Expected a warning, got: %q", logs.String())
+	}
+	if err := (options{failDrift: true}).drift(err); !errors.Is(err, ErrParseDrift) {
+		t.Errorf("Expected ErrParseDrift with --strict, got: %v", err)
+	}
+}
+
+func Test_UnknownAuthor(t *testing.T) {
+	shortlog, err := ioutil.ReadFile("testdata/shortlog-unknown")
+	if err != nil {
+		t.Fatalf("unable to read file: %s", err)
+	}
+	numstat, err := ioutil.ReadFile("testdata/numstat-unknown")
+	if err != nil {
+		t.Fatalf("unable to read file: %s", err)
+	}
+
+	counts, err := mapAuthorCommits(string(shortlog))
+	if err != nil {
+		t.Fatalf("error mapping author commits: %s", err)
+	}
+	lineChanges, err := parseLineChanges(string(numstat))
+	if err != nil {
+		t.Fatalf("unable to parse output: %s", err)
+	}
+
+	var o options
+	if got, exp := o.bucketCounts(counts), map[string]int{"Author One": 5, "(unknown)": 3}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got: %v", exp, got)
+	}
+	exp := map[string]LineChanges{"Author One": {4, 1}, "(unknown)": {3, 1}}
+	if got := o.bucketLineChanges(lineChanges); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got: %v", exp, got)
+	}
+
+	o.unknownAuthor = "Imported"
+	if got := o.authorName(" "); got != "Imported" {
+		t.Errorf("Expected the --unknown-author label, got: %q", got)
+	}
+}
+
+func Test_UnknownAuthorRepo(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+
+	// git refuses to commit without an author name, unlike some imports
+	r.write("one.txt", "1\n2\n3\n")
+	r.git("add", "one.txt")
+	tree := strings.TrimSpace(r.git("write-tree"))
+	parent := strings.TrimSpace(r.git("rev-parse", "HEAD"))
+	r.write(".git/empty-author", "tree "+tree+"\nparent "+parent+
+		"\nauthor  <nobody@example.com> 1700000000 +0000\ncommitter Test <test@example.com> 1700000000 +0000\n\nimport\n")
+	commit := strings.TrimSpace(r.git("hash-object", "-t", "commit", "-w", ".git/empty-author"))
+	r.git("update-ref", "HEAD", commit)
+	r.commit("Author One", "one.txt", "1\n2\n3\n4\n")
+
+	o := r.options()
+	for _, noMailmap := range []bool{false, true} {
+		o.noMailmap = noMailmap
+		counts, err := authorCommits(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lineChanges, err := mapLineChanges(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if counts["(unknown)"] != 1 || counts["Author One"] != 2 || len(counts) != 2 {
+			t.Errorf("Expected the commit without author to be counted as unknown with noMailmap %t, got: %v", noMailmap, counts)
+		}
+		if lineChanges["(unknown)"] != (LineChanges{2, 0}) || lineChanges["Author One"] != (LineChanges{2, 0}) {
+			t.Errorf("Expected the line changes without author to be unknown with noMailmap %t, got: %v", noMailmap, lineChanges)
+		}
+	}
+
+	o.noMailmap = false
+	o.maxCommitLines = 100
+	if lineChanges, err := mapLineChanges(o); err != nil || lineChanges["(unknown)"] != (LineChanges{2, 0}) {
+		t.Errorf("Expected the commit parser to agree, got: %v, %v", lineChanges, err)
+	}
+}
+
+// numstatReader reads a synthetic git log --numstat output of n commits,
+// generated as it is read rather than held in memory, sampling the heap
+// in use every 10000 commits.
+type numstatReader struct {
+	n, i int
+	line []byte
+	buf  []byte
+	peak uint64 // the most heap in use sampled, in bytes
+}
+
+func (r *numstatReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.i%10000 == 0 {
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+			if ms.HeapAlloc > r.peak {
+				r.peak = ms.HeapAlloc
+			}
+		}
+		if r.i == r.n {
+			return 0, io.EOF
+		}
+		r.line = fmt.Appendf(r.line[:0], "\x00Author %d\n3\t1\tdir/file%d.go\n1\t0\tREADME\n\n", r.i%7, r.i%100)
+		r.buf = r.line
+		r.i++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Benchmark_ReadLineChanges reports the peak heap in use parsing the
+// numstat output of increasingly large histories, which stays about the
+// same when streamed, bounded by the number of authors, and grows with
+// the output when it is read in full first, as it used to be.
+func Benchmark_ReadLineChanges(b *testing.B) {
+	parsers := []struct {
+		name  string
+		parse func(r io.Reader) (map[string]LineChanges, error)
+	}{
+		{"streamed", readLineChanges},
+		{"buffered", func(r io.Reader) (map[string]LineChanges, error) {
+			out, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			return parseLineChanges(string(out))
+		}},
+	}
+
+	for _, p := range parsers {
+		for _, n := range []int{10000, 1000000} {
+			b.Run(fmt.Sprintf("%s/commits=%d", p.name, n), func(b *testing.B) {
+				var peak uint64
+				for i := 0; i < b.N; i++ {
+					runtime.GC()
+					r := &numstatReader{n: n}
+					lineChanges, err := p.parse(r)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if len(lineChanges) != 7 {
+						b.Fatalf("Expected 7 authors, got %d", len(lineChanges))
+					}
+					if r.peak > peak {
+						peak = r.peak
+					}
+				}
+				b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+			})
+		}
+	}
+}
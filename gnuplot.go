@@ -0,0 +1,49 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// writeSummaryGnuplot writes the 'summary' report to w as a gnuplot data
+// file, headed by a comment naming the columns, followed by a row per
+// author of the author, commits, additions and deletions, separated by
+// spaces. The columns are fixed, so that plots can index them by number
+// with 'using', like 'using 2:xtic(1)'.
+func writeSummaryGnuplot(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	if _, err := fmt.Fprintln(w, "# author commits additions deletions"); err != nil {
+		return err
+	}
+
+	for _, s := range summaries {
+		_, err := fmt.Fprintf(w, "%s %d %d %d\n", gnuplotName(s.Author), s.Commits, s.Additions, s.Deletions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gnuplotName returns the author name as a single gnuplot data field, with
+// its spaces, and the '#' gnuplot takes for the start of a comment,
+// replaced by underscores.
+func gnuplotName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '#' {
+			return '_'
+		}
+		return r
+	}, name)
+}
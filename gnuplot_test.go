@@ -0,0 +1,26 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_WriteSummaryGnuplot(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author One", Commits: 12, Additions: 100, Deletions: 20, LineRatio: 0.75},
+		{Author: "Two #2", Commits: 3, Additions: 30, Deletions: 10, LineRatio: 0.25},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeSummaryGnuplot(buf, summaries, Totals{}, renderOptions{}); err != nil {
+		t.Fatalf("error rendering gnuplot: %s", err)
+	}
+
+	exp := `# author commits additions deletions
+Author_One 12 100 20
+Two__2 3 30 10
+`
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf)
+	}
+}
@@ -1,10 +1,11 @@
 module github.com/morngrar/gitcontrib
 
-go 1.20
+go 1.21
 
 require (
 	github.com/rwxrob/bonzai v0.20.10
 	github.com/rwxrob/help v0.7.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
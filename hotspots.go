@@ -0,0 +1,69 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "sort"
+
+// hotspot holds the churn of a file, being its added and deleted lines
+// over the history, along with its top author and their churn.
+type hotspot struct {
+	File   string
+	Churn  int
+	Author string
+	Lines  int
+}
+
+// Share returns the share of the churn of the file made by its top
+// author.
+func (h hotspot) Share() float64 {
+	if h.Churn == 0 {
+		return 0
+	}
+	return float64(h.Lines) / float64(h.Churn)
+}
+
+// fileAuthorLines returns the changed lines of each author in each file
+// changed by the commits, by path. The commits are expected newest first
+// like git log writes them, and with followRenames the paths a file had
+// before being renamed count as the same file.
+func fileAuthorLines(commits []commit, followRenames bool) map[string]map[string]int {
+	lines := make(map[string]map[string]int) // path to author to lines
+	eachFileChange(commits, followRenames, func(author string, f fileChange) {
+		if lines[f.Path] == nil {
+			lines[f.Path] = make(map[string]int)
+		}
+		lines[f.Path][author] += f.Sum()
+	})
+	return lines
+}
+
+// findHotspots returns the files changed by the commits with their churn
+// and top author, being the author with the most changed lines in them,
+// ties going to the first name alphabetically, following renames like
+// fileAuthorLines. Binary files have no changed lines, so they are left
+// out. The hotspots are sorted by descending churn, then by path.
+func findHotspots(commits []commit, followRenames bool) []hotspot {
+	var hotspots []hotspot
+	for path, authors := range fileAuthorLines(commits, followRenames) {
+		h := hotspot{File: path}
+		for author, n := range authors {
+			h.Churn += n
+			if n > h.Lines || n == h.Lines && (h.Author == "" || author < h.Author) {
+				h.Author, h.Lines = author, n
+			}
+		}
+		if h.Churn > 0 {
+			hotspots = append(hotspots, h)
+		}
+	}
+
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Churn != hotspots[j].Churn {
+			return hotspots[i].Churn > hotspots[j].Churn
+		}
+		return hotspots[i].File < hotspots[j].File
+	})
+
+	return hotspots
+}
@@ -0,0 +1,39 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_FindHotspots(t *testing.T) {
+	lc := func(a, d int) LineChanges { return LineChanges{a, d} }
+
+	// newest first, like git log
+	commits := []commit{
+		{Author: "One", Files: []fileChange{{Path: "new.go", OldPath: "old.go", LineChanges: lc(2, 1)}}},
+		{Author: "Two", Files: []fileChange{{Path: "shared.go", LineChanges: lc(4, 4)}, {Path: "logo.png"}}},
+		{Author: "One", Files: []fileChange{{Path: "shared.go", LineChanges: lc(10, 0)}}},
+		{Author: "Two", Files: []fileChange{{Path: "old.go", LineChanges: lc(5, 0)}}},
+	}
+
+	exp := []hotspot{
+		{"shared.go", 18, "One", 10},
+		{"old.go", 5, "Two", 5},
+		{"new.go", 3, "One", 3},
+	}
+	if got := findHotspots(commits, false); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	exp = []hotspot{
+		{"shared.go", 18, "One", 10},
+		{"new.go", 8, "Two", 5},
+	}
+	if got := findHotspots(commits, true); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v with --follow-renames, got %v", exp, got)
+	}
+
+	if share := exp[1].Share(); share != 0.625 {
+		t.Errorf("Expected a share of 0.625, got %v", share)
+	}
+}
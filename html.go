@@ -0,0 +1,152 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// htmlReport is a standalone HTML page with inline styling and a small
+// script making the table sortable by clicking the column headers.
+var htmlReport = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th, td { padding: 0.4em 0.8em; border-bottom: 1px solid #ddd; }
+th { cursor: pointer; background: #f4f4f4; text-align: left; user-select: none; }
+th:hover { background: #e8e8e8; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+tr:hover td { background: #fafafa; }
+p.totals { color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table id="summary">
+<thead>
+<tr>
+<th>Author</th>
+<th>Commits</th>
+<th>Additions</th>
+<th>Deletions</th>
+<th>Line ratio</th>
+<th>Commit ratio</th>
+<th>Granularity</th>
+{{- if .Decay}}
+<th>Weighted</th>
+{{- end}}
+{{- if .Files}}
+<th>Files</th>
+{{- end}}
+{{- if .Breadth}}
+<th>Files/commit</th>
+<th>Breadth</th>
+{{- end}}
+{{- if .Merges}}
+<th>Merges</th>
+{{- end}}
+{{- if .Surviving}}
+<th>Surviving</th>
+<th>Footprint</th>
+{{- end}}
+</tr>
+</thead>
+<tbody>
+{{- $decay := .Decay}}
+{{- $files := .Files}}
+{{- $breadth := .Breadth}}
+{{- $merges := .Merges}}
+{{- $surviving := .Surviving}}
+{{- range .Summaries}}
+<tr>
+<td>{{.Author}}</td>
+<td class="num">{{.Commits}}</td>
+<td class="num">{{.Additions}}</td>
+<td class="num">{{.Deletions}}</td>
+<td class="num">{{printf "%.3f" .LineRatio}}</td>
+<td class="num">{{printf "%.3f" .CommitRatio}}</td>
+<td class="num">{{printf "%.3f" .Granularity}}</td>
+{{- if $decay}}
+<td class="num">{{printf "%.1f" .Weighted}}</td>
+{{- end}}
+{{- if $files}}
+<td class="num">{{.FilesTouched}}</td>
+{{- end}}
+{{- if $breadth}}
+<td class="num">{{printf "%.1f" .FilesPerCommit}}</td>
+<td class="num">{{printf "%.1f" .Breadth}}</td>
+{{- end}}
+{{- if $merges}}
+<td class="num">{{.Merges}}</td>
+{{- end}}
+{{- if $surviving}}
+<td class="num">{{.Surviving}}</td>
+<td class="num">{{printf "%.3f" .Footprint}}</td>
+{{- end}}
+</tr>
+{{- end}}
+</tbody>
+</table>
+<p class="totals">
+{{.Totals.Commits}} commits, {{.Totals.Additions}} additions,
+{{.Totals.Deletions}} deletions. Overall repo commit granularity:
+{{printf "%.3f" .Totals.Granularity}}.
+Gini coefficient of line changes: {{printf "%.3f" .Totals.Gini}}
+{{- if not .Totals.First.IsZero}}
+<br>
+Commits from {{.Totals.First.Format "2006-01-02"}} to
+{{.Totals.Last.Format "2006-01-02"}} ({{.Totals.Days}} days)
+{{- end}}
+</p>
+<script>
+document.querySelectorAll("#summary th").forEach(function (th, col) {
+  var asc = false;
+  th.addEventListener("click", function () {
+    var tbody = document.querySelector("#summary tbody");
+    var rows = Array.prototype.slice.call(tbody.rows);
+    asc = !asc;
+    rows.sort(function (a, b) {
+      var x = a.cells[col].textContent, y = b.cells[col].textContent;
+      var d = col === 0 ? x.localeCompare(y) : parseFloat(x) - parseFloat(y);
+      return asc ? d : -d;
+    });
+    rows.forEach(function (row) { tbody.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// writeSummaryHTML writes the summaries to w as a self-contained HTML page
+// with a sortable table.
+func writeSummaryHTML(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	err := htmlReport.Execute(w, struct {
+		Title     string
+		Summaries []AuthorSummary
+		Totals    Totals
+		Decay     bool
+		Files     bool
+		Breadth   bool
+		Merges    bool
+		Surviving bool
+	}{"Contribution summary", summaries, totals, opts.decay, opts.files, opts.breadth, opts.merges, opts.survive})
+	if err != nil {
+		return fmt.Errorf("error rendering html: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_WriteSummaryHTML(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: `<script>alert("x")</script>`, Commits: 1},
+	}
+
+	buf := new(bytes.Buffer)
+	err := writeSummaryHTML(buf, summaries, Totals{Commits: 1}, renderOptions{})
+	if err != nil {
+		t.Fatalf("error rendering html: %s", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, `<script>alert("x")</script>`) {
+		t.Errorf("author name was not escaped:\n%s", out)
+	}
+	if !strings.Contains(out, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;") {
+		t.Errorf("escaped author name missing from output:\n%s", out)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunksFormat is the git log format of the patches parsed by parseHunks,
+// giving the author name of each commit on a line of its own, preceded by
+// a NUL byte.
+const hunksFormat = "--format=%x00%aN"
+
+// hunkHeader matches the header of a hunk of a patch, capturing the
+// numbers of deleted and added lines, which are left out when 1.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+// commitHunks holds the changed lines of a commit, along with the number
+// of hunks they are in, being the distinct blocks of the changes.
+type commitHunks struct {
+	Author string
+	Lines  int
+	Hunks  int
+}
+
+// Complexity returns the line changes of the commit weighted by how
+// scattered they are, as a rough proxy of the effort they took, being
+// the changed lines times one plus the base-2 logarithm of the hunks. A
+// block of 100 lines weighs 100, while 100 lines in 8 hunks weigh 400.
+func (c commitHunks) Complexity() float64 {
+	if c.Hunks == 0 {
+		return 0
+	}
+	return float64(c.Lines) * (1 + math.Log2(float64(c.Hunks)))
+}
+
+// authorHunks returns the hunks of each non-merge commit in the history
+// of o, read from its patches without context lines, with the authors of
+// the commits without a name labelled like by authorName.
+func authorHunks(o options) ([]commitHunks, error) {
+	out, err := o.walk("log", "--no-merges", "-p", "--unified=0",
+		"--no-color", "--no-ext-diff", "--no-textconv", o.logFormat(hunksFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := parseHunks(out)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range commits {
+		commits[i].Author = o.authorName(c.Author)
+	}
+
+	return commits, nil
+}
+
+// parseHunks parses the output of git log -p --unified=0 with hunksFormat
+// into the hunks of each commit, counting the changed lines from the hunk
+// headers rather than from the lines of the patch, among which those of
+// the file headers look like changed lines. Binary files have no hunks.
+func parseHunks(gitOutput string) ([]commitHunks, error) {
+	var commits []commitHunks
+	for _, line := range strings.Split(gitOutput, "\n") {
+		if strings.HasPrefix(line, "\x00") {
+			commits = append(commits, commitHunks{Author: line[1:]})
+			continue
+		}
+
+		m := hunkHeader.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if len(commits) == 0 {
+			return nil, fmt.Errorf("hunk before any commit: %q", line)
+		}
+
+		c := &commits[len(commits)-1]
+		c.Hunks++
+		for _, n := range m[1:] {
+			lines := 1
+			if n != "" {
+				lines, _ = strconv.Atoi(n)
+			}
+			c.Lines += lines
+		}
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,47 @@
+package gitcontrib
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_ParseHunks(t *testing.T) {
+	out, err := os.ReadFile("testdata/patch-example")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commits, err := parseHunks(string(out))
+	if err != nil {
+		t.Fatalf("error parsing hunks: %s", err)
+	}
+
+	// the lines "+-- a" and "+++ b" are changed lines, not file headers,
+	// and the binary logo.png has no hunks
+	exp := []commitHunks{
+		{"Author Two", 6, 4},
+		{"Author One", 10, 1},
+	}
+	if !reflect.DeepEqual(commits, exp) {
+		t.Errorf("Expected %v, got %v", exp, commits)
+	}
+
+	for _, c := range []struct {
+		hunks commitHunks
+		exp   float64
+	}{
+		{commits[0], 18},
+		{commits[1], 10},
+		{commitHunks{Lines: 100, Hunks: 8}, 400},
+		{commitHunks{}, 0},
+	} {
+		if got := c.hunks.Complexity(); got != c.exp {
+			t.Errorf("Expected complexity %v of %v, got %v", c.exp, c.hunks, got)
+		}
+	}
+
+	if _, err := parseHunks("@@ -1 +1 @@\n"); err == nil {
+		t.Error("Expected an error for a hunk before any commit")
+	}
+}
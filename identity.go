@@ -0,0 +1,81 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// identityMap maps the canonical names of people to the glob patterns of
+// the names and emails they committed with, as read from the YAML file of
+// the --identity-map flag, like:
+//
+//	Jane Doe:
+//	  - jane@example.com
+//	  - "*@jane.dev"
+//	  - jdoe
+type identityMap map[string][]string
+
+// readIdentityMap reads the identity map in the YAML file at path.
+func readIdentityMap(path string) (identityMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading identity map: %w", err)
+	}
+
+	return parseIdentityMap(data)
+}
+
+// parseIdentityMap parses an identity map from YAML. Every canonical name
+// needs at least one pattern.
+func parseIdentityMap(data []byte) (identityMap, error) {
+	var im identityMap
+	if err := yaml.Unmarshal(data, &im); err != nil {
+		return nil, fmt.Errorf("error parsing identity map: %w", err)
+	}
+
+	for name, globs := range im {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("identity map has an empty canonical name")
+		}
+		if len(globs) == 0 {
+			return nil, fmt.Errorf("identity %q has no name or email patterns", name)
+		}
+	}
+
+	return im, nil
+}
+
+// identities returns the authors matched by the patterns of the map, by
+// name or by any of the emails they committed with, given by
+// authorEmails, mapped to their canonical name, so that they can be merged
+// like teams. An author can only be matched by one canonical name.
+func (im identityMap) identities(emails map[string][]string) (teams, error) {
+	names := make([]string, 0, len(im))
+	for name := range im {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ids := make(teams)
+	for _, name := range names {
+		patterns := compileAuthorPatterns(im[name])
+		for author, e := range emails {
+			if author == name || !patterns.match(author, e) {
+				continue
+			}
+			if other, ok := ids[author]; ok {
+				return nil, fmt.Errorf("author %q matches both identity %q and %q", author, other, name)
+			}
+			ids[author] = name
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,68 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_IdentityMap(t *testing.T) {
+	if _, err := parseIdentityMap([]byte("Jane Doe: []\n")); err == nil {
+		t.Errorf("Expected error for identity without patterns")
+	}
+	if _, err := parseIdentityMap([]byte("- jane\n")); err == nil {
+		t.Errorf("Expected error for identity map not being a map")
+	}
+
+	r := newTestRepo(t)
+	for i, id := range [][2]string{
+		{"Jane Doe", "jane@work.example.com"},
+		{"jdoe", "jane@home.example.com"},
+		{"Bobby", "bob@example.com"},
+		{"Jane Doe", "jane@work.example.com"},
+	} {
+		name := string(rune('a'+i)) + ".txt"
+		r.write(name, "line\n")
+		r.git("add", name)
+		r.gitEnv(
+			[]string{"GIT_AUTHOR_NAME=" + id[0], "GIT_AUTHOR_EMAIL=" + id[1]},
+			"commit", "-q", "-m", "change "+name,
+		)
+	}
+
+	path := filepath.Join(t.TempDir(), "identities.yaml")
+	err := os.WriteFile(path, []byte("Jane:\n  - jane@*\n  - jdoe\nBob:\n  - BOB@example.com\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ff := filterFlags{identityFile: path}
+	summaries, totals, err := ff.collect(r.options())
+	if err != nil {
+		t.Fatalf("error collecting: %s", err)
+	}
+
+	if len(summaries) != 2 || totals.Commits != 4 {
+		t.Fatalf("Expected two rows of four commits, got %+v", summaries)
+	}
+	for _, s := range summaries {
+		switch s.Author {
+		case "Jane":
+			if s.Commits != 3 || s.Additions != 3 {
+				t.Errorf("unexpected row for Jane: %+v", s)
+			}
+		case "Bob":
+			if s.Commits != 1 || s.Additions != 1 {
+				t.Errorf("unexpected row for Bob: %+v", s)
+			}
+		default:
+			t.Errorf("unexpected author %q", s.Author)
+		}
+	}
+
+	im := identityMap{"Jane": {"j*"}, "Jim": {"jdoe"}}
+	_, err = im.identities(map[string][]string{"jdoe": {"jane@home.example.com"}})
+	if err == nil {
+		t.Errorf("Expected error for author matching two identities")
+	}
+}
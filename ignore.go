@@ -0,0 +1,153 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreFile is the name of the file in the top-level directory of a repo
+// listing the authors left out of all reports, like CI bots.
+const ignoreFile = ".gitcontribignore"
+
+// authorPatterns holds compiled author glob patterns, each matching either
+// the name or an email of an author.
+type authorPatterns []*regexp.Regexp
+
+// compileAuthorPatterns compiles the given glob patterns, in which '*'
+// matches any number of characters and '?' any single character. All other
+// characters match themselves, so that names like "dependabot[bot]" need
+// no escaping. Patterns match the whole name or email, ignoring case.
+func compileAuthorPatterns(globs []string) authorPatterns {
+	patterns := make(authorPatterns, 0, len(globs))
+	for _, g := range globs {
+		expr := regexp.QuoteMeta(g)
+		expr = strings.ReplaceAll(expr, `\*`, `.*`)
+		expr = strings.ReplaceAll(expr, `\?`, `.`)
+		patterns = append(patterns, regexp.MustCompile(`(?i)^`+expr+`$`))
+	}
+	return patterns
+}
+
+// match reports whether any of the patterns matches the name or any of the
+// emails.
+func (ps authorPatterns) match(name string, emails []string) bool {
+	for _, p := range ps {
+		if p.MatchString(name) {
+			return true
+		}
+		for _, e := range emails {
+			if p.MatchString(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readIgnoreFile returns the patterns of the ignore file in the top-level
+// directory of the repo of o, or none when there is no such file. Bare
+// repos have no work tree to hold the file.
+func readIgnoreFile(o options) ([]string, error) {
+	f, err := openTopLevel(o, ignoreFile)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseIgnoreFile(f)
+}
+
+// openTopLevel opens the named file in the top-level directory of the
+// repo of o, returning nil when there is no such file. Bare repos have no
+// work tree to hold the file.
+func openTopLevel(o options, name string) (*os.File, error) {
+	bare, err := o.git("rev-parse", "--is-bare-repository")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(bare) == "true" {
+		return nil, nil
+	}
+
+	top, err := o.git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(strings.TrimSpace(top), name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return f, err
+}
+
+// parseIgnoreFile returns the patterns of an ignore file, one per line.
+// Blank lines and lines starting with '#' are skipped.
+func parseIgnoreFile(r io.Reader) ([]string, error) {
+	var globs []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		globs = append(globs, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", ignoreFile, err)
+	}
+
+	return globs, nil
+}
+
+// ignoredAuthors returns the names of the authors matched by the
+// patterns, by name or by any of the emails they committed with, given
+// by authorEmails.
+func ignoredAuthors(emails map[string][]string, patterns authorPatterns) map[string]bool {
+	ignored := make(map[string]bool)
+	for name, e := range emails {
+		if patterns.match(name, e) {
+			ignored[name] = true
+		}
+	}
+
+	return ignored
+}
+
+// authorEmails returns the distinct emails each author committed with in
+// the revision or range of o, by name.
+func authorEmails(o options) (map[string][]string, error) {
+	out, err := o.walk("log", o.logFormat("--format=%aN%x00%aE"))
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make(map[string][]string)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if seen[line] {
+			continue
+		}
+		seen[line] = true
+
+		name, email, ok := strings.Cut(line, "\x00")
+		if ok {
+			emails[name] = append(emails[name], email)
+		}
+	}
+
+	return emails, nil
+}
@@ -0,0 +1,72 @@
+package gitcontrib
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_AuthorPatterns(t *testing.T) {
+	patterns := compileAuthorPatterns([]string{"*[bot]", "ci-?@example.com"})
+
+	tests := []struct {
+		name   string
+		emails []string
+		match  bool
+	}{
+		{"dependabot[bot]", nil, true},
+		{"GitHub-Actions[BOT]", nil, true},
+		{"dependabot", nil, false},
+		{"botb", nil, false},
+		{"Runner", []string{"me@example.com", "ci-1@example.com"}, true},
+		{"Runner", []string{"ci-12@example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := patterns.match(tt.name, tt.emails); got != tt.match {
+			t.Errorf("match(%q, %q) = %v, expected %v", tt.name, tt.emails, got, tt.match)
+		}
+	}
+}
+
+func Test_ParseIgnoreFile(t *testing.T) {
+	globs, err := parseIgnoreFile(strings.NewReader("# bots\n*[bot]\n\n  renovate  \n"))
+	if err != nil {
+		t.Fatalf("error parsing ignore file: %s", err)
+	}
+	if strings.Join(globs, ",") != "*[bot],renovate" {
+		t.Errorf("unexpected patterns: %q", globs)
+	}
+}
+
+func Test_IgnoreFile(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.commit("dependabot[bot]", "go.sum", "1\n2\n3\n")
+	r.commit("Author One", ignoreFile, "*[bot]\n")
+
+	var ff filterFlags
+	summaries, totals, err := ff.collect(r.options())
+	if err != nil {
+		t.Fatalf("error collecting summary: %s", err)
+	}
+
+	if len(summaries) != 1 || summaries[0].Author != "Author One" {
+		t.Errorf("Expected the bot to be ignored, got: %+v", summaries)
+	}
+	if totals.Commits != 2 || totals.Additions != 3 {
+		t.Errorf("Expected the bot to be left out of totals, got: %+v", totals)
+	}
+
+	ff.ignoredInTotals = true
+	summaries, totals, err = ff.collect(r.options())
+	if err != nil {
+		t.Fatalf("error collecting summary: %s", err)
+	}
+
+	if len(summaries) != 1 {
+		t.Errorf("Expected the bot to be ignored, got: %+v", summaries)
+	}
+	if totals.Commits != 3 || totals.Additions != 6 {
+		t.Errorf("Expected the bot in totals, got: %+v", totals)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// writeSummaryInflux writes the summaries and totals to w in the InfluxDB
+// line protocol, as a gitcontrib point per author tagged with the author
+// and the repo, followed by a gitcontrib_repo point of the totals. All
+// points share the timestamp of opts, or the current time when unset.
+func writeSummaryInflux(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	ts := opts.timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	stamp := strconv.FormatInt(ts.UnixNano(), 10)
+
+	repo := ",repo=" + influxEscape(opts.repo)
+	if opts.repo == "" {
+		repo = ""
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, s := range summaries {
+		tags := repo
+		if s.Author != "" {
+			tags = ",author=" + influxEscape(s.Author) + repo
+		}
+		fmt.Fprintf(bw,
+			"gitcontrib%s commits=%di,additions=%di,deletions=%di,line_ratio=%s,commit_ratio=%s,granularity=%s %s\n",
+			tags, s.Commits, s.Additions, s.Deletions,
+			influxFloat(s.LineRatio), influxFloat(s.CommitRatio), influxFloat(s.Granularity),
+			stamp,
+		)
+	}
+
+	fmt.Fprintf(bw,
+		"gitcontrib_repo%s authors=%di,commits=%di,additions=%di,deletions=%di,granularity=%s,gini=%s %s\n",
+		repo, len(summaries), totals.Commits, totals.Additions, totals.Deletions,
+		influxFloat(totals.Granularity), influxFloat(totals.Gini),
+		stamp,
+	)
+
+	return bw.Flush()
+}
+
+// parseTimestamp parses the time of --timestamp, given as an RFC 3339
+// date or as Unix seconds.
+func parseTimestamp(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --timestamp %q, must be an RFC 3339 date or Unix seconds", s)
+	}
+	return t, nil
+}
+
+// influxEscape escapes a tag value, in which commas, equals signs and
+// spaces must be escaped by a backslash. Line feeds can't be escaped, so
+// they are replaced by spaces. Backslashes are doubled, so that one ending
+// the value doesn't escape the comma or space following it.
+func influxEscape(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `).Replace(s)
+}
+
+// influxFloat formats a float field value.
+func influxFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
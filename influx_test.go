@@ -0,0 +1,49 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_WriteSummaryInflux(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Doe, Jane", Commits: 42, Additions: 100, Deletions: 5, LineRatio: 0.75, CommitRatio: 0.5, Granularity: 0.4},
+		{Author: "x=y", Commits: 42, Additions: 30, Deletions: 0, LineRatio: 0.25, CommitRatio: 0.5, Granularity: 1.4},
+		{Author: "Two\nLines\\", Commits: 1, Additions: 1, Deletions: 0, LineRatio: 0, CommitRatio: 0, Granularity: 1},
+	}
+	totals := Totals{Commits: 84, Additions: 130, Deletions: 5, Granularity: 0.6, Gini: 0.25}
+	opts := renderOptions{repo: "my repo", timestamp: time.Unix(1700000000, 0)}
+
+	buf := new(bytes.Buffer)
+	if err := writeSummaryInflux(buf, summaries, totals, opts); err != nil {
+		t.Fatalf("error rendering influx: %s", err)
+	}
+
+	exp := `gitcontrib,author=Doe\,\ Jane,repo=my\ repo commits=42i,additions=100i,deletions=5i,line_ratio=0.75,commit_ratio=0.5,granularity=0.4 1700000000000000000
+gitcontrib,author=x\=y,repo=my\ repo commits=42i,additions=30i,deletions=0i,line_ratio=0.25,commit_ratio=0.5,granularity=1.4 1700000000000000000
+gitcontrib,author=Two\ Lines\\,repo=my\ repo commits=1i,additions=1i,deletions=0i,line_ratio=0,commit_ratio=0,granularity=1 1700000000000000000
+gitcontrib_repo,repo=my\ repo authors=3i,commits=84i,additions=130i,deletions=5i,granularity=0.6,gini=0.25 1700000000000000000
+`
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf)
+	}
+}
+
+func Test_ParseTimestamp(t *testing.T) {
+	for s, exp := range map[string]int64{
+		"1700000000":           1700000000,
+		"2023-11-14T22:13:20Z": 1700000000,
+	} {
+		ts, err := parseTimestamp(s)
+		if err != nil {
+			t.Errorf("error parsing %q: %s", s, err)
+		} else if ts.Unix() != exp {
+			t.Errorf("Expected %q to be %d, got %d", s, exp, ts.Unix())
+		}
+	}
+
+	if _, err := parseTimestamp("yesterday"); err == nil {
+		t.Error("Expected error for an invalid timestamp")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// readReport reads the summaries and totals of a report previously
+// written with the json or ndjson formats. Records failing to decode, and
+// authors without a name, are reported as errors along with their number.
+func readReport(r io.Reader) ([]AuthorSummary, Totals, error) {
+	dec := json.NewDecoder(r)
+
+	var summaries []AuthorSummary
+	var totals *Totals
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, Totals{}, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		if totals != nil {
+			return nil, Totals{}, fmt.Errorf("record %d: unexpected record after the totals", n)
+		}
+
+		var probe struct {
+			Authors json.RawMessage `json:"authors"`
+			Total   bool            `json:"_total"`
+		}
+		if err := json.Unmarshal(raw, &probe); err != nil {
+			return nil, Totals{}, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		switch {
+
+		case probe.Authors != nil:
+			if n != 1 {
+				return nil, Totals{}, fmt.Errorf("record %d: unexpected json report in ndjson", n)
+			}
+			var rep report
+			if err := decodeStrict(raw, &rep); err != nil {
+				return nil, Totals{}, fmt.Errorf("record %d: %w", n, err)
+			}
+			summaries, totals = rep.Authors, &rep.Totals
+
+		case probe.Total:
+			var t struct {
+				Total bool `json:"_total"`
+				Totals
+			}
+			if err := decodeStrict(raw, &t); err != nil {
+				return nil, Totals{}, fmt.Errorf("record %d: %w", n, err)
+			}
+			totals = &t.Totals
+
+		default:
+			var s AuthorSummary
+			if err := decodeStrict(raw, &s); err != nil {
+				return nil, Totals{}, fmt.Errorf("record %d: %w", n, err)
+			}
+			summaries = append(summaries, s)
+		}
+	}
+
+	if totals == nil {
+		return nil, Totals{}, errors.New("missing totals, the input is empty or truncated")
+	}
+
+	for i, s := range summaries {
+		if s.Author == "" {
+			return nil, Totals{}, fmt.Errorf("author %d has no name", i+1)
+		}
+	}
+
+	return summaries, *totals, nil
+}
+
+// decodeStrict decodes the JSON value into v, failing on unknown fields,
+// as they are likely from a different kind of document.
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
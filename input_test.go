@@ -0,0 +1,58 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_ReadReport(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author One", Commits: 3, Additions: 10, LineRatio: 0.75, CommitRatio: 0.6, Granularity: 0.3},
+		{Author: "Author Two", Commits: 2, Deletions: 5, LineRatio: 0.25, CommitRatio: 0.4, Granularity: 0.4, FilesTouched: 2},
+	}
+	totals := Totals{
+		Commits:     5,
+		Additions:   10,
+		Deletions:   5,
+		Granularity: 1.0 / 3,
+		First:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Last:        time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, format := range []string{"json", "ndjson"} {
+		var buf bytes.Buffer
+		err := renderers[format](&buf, summaries, totals, renderOptions{})
+		if err != nil {
+			t.Fatalf("error writing %s: %s", format, err)
+		}
+
+		gotSummaries, gotTotals, err := readReport(&buf)
+		if err != nil {
+			t.Fatalf("error reading %s: %s", format, err)
+		}
+		if !reflect.DeepEqual(gotSummaries, summaries) {
+			t.Errorf("%s: Expected %+v, got: %+v", format, summaries, gotSummaries)
+		}
+		if !reflect.DeepEqual(gotTotals, totals) {
+			t.Errorf("%s: Expected %+v, got: %+v", format, totals, gotTotals)
+		}
+	}
+
+	malformed := []string{
+		"",
+		`{"author":"Author One","commits":1}`,
+		`{"author":"Author One","commits":"many"}` + "\n" + `{"_total":true}`,
+		`{"author":"Author One","unknown":1}` + "\n" + `{"_total":true}`,
+		`{"commits":1}` + "\n" + `{"_total":true}`,
+		`{"_total":true}` + "\n" + `{"author":"Author One"}`,
+		`{"author":"Author One"`,
+	}
+	for _, input := range malformed {
+		if _, _, err := readReport(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error reading %q", input)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "sort"
+
+// currentLines returns the number of lines of the text files at the end
+// of the revision or range of o, HEAD when none is given, limited to its
+// paths and leaving out the files matching the exclude pathspecs. Binary
+// files don't count.
+func currentLines(o options, exclude []string) (int, error) {
+	files, err := textFileLines(o, blameRev(o.rev), exclude)
+	if err != nil {
+		return 0, err
+	}
+
+	var lines int
+	for _, f := range files {
+		lines += f.Additions
+	}
+
+	return lines, nil
+}
+
+// churnIntensity holds the changed lines of an author, or of all authors,
+// along with their number per thousand lines of the current code.
+type churnIntensity struct {
+	Author    string
+	Lines     int
+	Intensity float64
+}
+
+// churnIntensities returns the churn intensity of each author, sorted by
+// descending changed lines, then by author, and that of all authors. The
+// intensities are 0 when there are no current lines of code.
+func churnIntensities(lineChangesMap map[string]LineChanges, loc int) ([]churnIntensity, churnIntensity) {
+	intensity := func(lines int) float64 {
+		if loc == 0 {
+			return 0
+		}
+		return 1000 * float64(lines) / float64(loc)
+	}
+
+	authors := make([]churnIntensity, 0, len(lineChangesMap))
+	var total int
+	for author, lc := range lineChangesMap {
+		authors = append(authors, churnIntensity{author, lc.Sum(), intensity(lc.Sum())})
+		total += lc.Sum()
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Lines != authors[j].Lines {
+			return authors[i].Lines > authors[j].Lines
+		}
+		return authors[i].Author < authors[j].Author
+	})
+
+	return authors, churnIntensity{"", total, intensity(total)}
+}
@@ -0,0 +1,49 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_CurrentLines(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Alice", "main.go", "1\n2\n3\n4\n")
+	r.commit("Bob", "vendor/lib.go", "1\n2\n3\n4\n5\n6\n")
+	r.commit("Bob", "logo.png", "\x00\x01\x02\n")
+
+	o := r.options()
+	for _, tc := range []struct {
+		exclude []string
+		exp     int
+	}{
+		{nil, 10},
+		{[]string{"vendor"}, 4},
+	} {
+		loc, err := currentLines(o, tc.exclude)
+		if err != nil {
+			t.Fatalf("error counting lines: %s", err)
+		}
+		if loc != tc.exp {
+			t.Errorf("Expected %d lines excluding %v, got %d", tc.exp, tc.exclude, loc)
+		}
+	}
+}
+
+func Test_ChurnIntensities(t *testing.T) {
+	authors, total := churnIntensities(map[string]LineChanges{
+		"Alice": {Additions: 40, Deletions: 10},
+		"Bob":   {Additions: 150, Deletions: 0},
+	}, 200)
+
+	exp := []churnIntensity{{"Bob", 150, 750}, {"Alice", 50, 250}}
+	if !reflect.DeepEqual(authors, exp) {
+		t.Errorf("Expected %v, got %v", exp, authors)
+	}
+	if total.Lines != 200 || total.Intensity != 1000 {
+		t.Errorf("Expected overall intensity 1000 of 200 lines, got %v", total)
+	}
+
+	if _, total := churnIntensities(map[string]LineChanges{"Alice": {Additions: 1}}, 0); total.Intensity != 0 {
+		t.Errorf("Expected intensity 0 without code, got %v", total.Intensity)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Logger receives the diagnostic messages of the package, like the git
+// commands being run and their durations at slog.LevelDebug, and warnings
+// like commits being skipped at slog.LevelWarn. Programs embedding the
+// commands can replace it with a logger of their own. By default, the
+// messages are written to standard error to keep standard output free
+// for the reports themselves, at the level set by --log-level.
+var Logger = slog.New(newCLIHandler(os.Stderr, logLevel))
+
+// logLevel is the level of the messages written by the default Logger,
+// set by --log-level, or to slog.LevelDebug by --verbose.
+var logLevel = new(slog.LevelVar)
+
+// verbose enables the debug messages and the spinner, and is set by
+// --verbose.
+var verbose bool
+
+// explain writes every git command line to standard error before it is
+// run, and is set by --explain.
+var explain bool
+
+// dryRun writes the git command lines walking the history to standard
+// error instead of running them, and is set by --dry-run. The commands only looking up what to
+// walk, like the checked out branch, are logged and run as usual, and no
+// report is written.
+var dryRun bool
+
+// printCommand writes a command line of --explain or --dry-run to
+// standard error, on a line of its own that can be pasted into a shell.
+// Being the output asked for rather than a diagnostic, it bypasses the
+// Logger and --log-level.
+func printCommand(cmdline string) {
+	fmt.Fprintln(os.Stderr, cmdline)
+}
+
+// debugf logs a debug message to the Logger.
+func debugf(format string, args ...any) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// warnf logs a warning to the Logger.
+func warnf(format string, args ...any) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// logf logs a message at the given level to the Logger, only formatting
+// it when the level is enabled.
+func logf(level slog.Level, format string, args ...any) {
+	ctx := context.Background()
+	if Logger.Enabled(ctx, level) {
+		Logger.Log(ctx, level, fmt.Sprintf(format, args...))
+	}
+}
+
+// cliHandler is the slog.Handler of the default Logger, writing each
+// message on a line of its own prefixed by "gitcontrib: ", followed by its
+// attributes as key=value pairs, like the command line tools of git do.
+type cliHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Leveler
+	attrs  []slog.Attr
+	prefix string // of the keys, naming the groups
+}
+
+func newCLIHandler(w io.Writer, level slog.Leveler) *cliHandler {
+	return &cliHandler{mu: new(sync.Mutex), w: w, level: level}
+}
+
+func (h *cliHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *cliHandler) Handle(_ context.Context, r slog.Record) error {
+	line := "gitcontrib: " + r.Message
+	for _, a := range h.attrs {
+		line += fmt.Sprintf(" %s=%v", a.Key, a.Value)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		line += fmt.Sprintf(" %s%s=%v", h.prefix, a.Key, a.Value)
+		return true
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line+"\n")
+	return err
+}
+
+func (h *cliHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		c.attrs = append(c.attrs, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+	}
+	return &c
+}
+
+func (h *cliHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.prefix += name + "."
+	return &c
+}
+
+// spinning is set while a spinner is drawn, as concurrent git commands
+// would otherwise draw over each other.
+var spinning atomic.Bool
+
+// spin draws a spinner followed by msg on standard error until the
+// returned function is called. Nothing is drawn unless verbose is set and
+// standard error is a terminal, or when another spinner is already drawn.
+func spin(msg string) (stop func()) {
+	if !verbose || !isTerminal(os.Stderr) || !spinning.CompareAndSwap(false, true) {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		tick := time.NewTicker(100 * time.Millisecond)
+		defer tick.Stop()
+
+		frames := `|/-\`
+		for i := 0; ; i++ {
+			fmt.Fprintf(os.Stderr, "\r%c %s", frames[i%len(frames)], msg)
+			select {
+			case <-done:
+				fmt.Fprint(os.Stderr, "\r\033[K") // clear the line
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+		spinning.Store(false)
+	}
+}
+
+// isTerminal reports whether f is a character device like a terminal,
+// rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
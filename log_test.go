@@ -0,0 +1,82 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureLogs makes the Logger write all messages to the returned buffer
+// for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var logs bytes.Buffer
+	saved := Logger
+	Logger = slog.New(newCLIHandler(&logs, slog.LevelDebug))
+	t.Cleanup(func() { Logger = saved })
+	return &logs
+}
+
+func Test_CustomLogHandler(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n3\n")
+
+	var std, def bytes.Buffer
+	log.SetOutput(&std)
+	saved := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&def, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		slog.SetDefault(saved)
+	})
+
+	var custom bytes.Buffer
+	Logger = slog.New(slog.NewJSONHandler(&custom, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	t.Cleanup(func() { Logger = slog.New(newCLIHandler(os.Stderr, logLevel)) })
+
+	o := r.options()
+	o.maxCommitLines = 1
+	if _, err := mapLineChanges(o); err != nil {
+		t.Fatal(err)
+	}
+
+	if std.Len() > 0 || def.Len() > 0 {
+		t.Errorf("Expected no output to the default loggers, got %q and %q", std.String(), def.String())
+	}
+	for _, exp := range []string{`"level":"DEBUG"`, `"level":"WARN","msg":"skipped the line changes of 1 commit`} {
+		if !strings.Contains(custom.String(), exp) {
+			t.Errorf("Expected %s in the custom handler output:\n%s", exp, custom.String())
+		}
+	}
+}
+
+func Test_CLIHandler(t *testing.T) {
+	var buf bytes.Buffer
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+	l := slog.New(newCLIHandler(&buf, &level))
+
+	l.Info("hidden")
+	l.Warn("shown", "repo", "a")
+	l.WithGroup("git").With("cmd", "log").Error("failed", "code", 128)
+
+	exp := "gitcontrib: shown repo=a\ngitcontrib: failed git.cmd=log git.code=128\n"
+	if buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+}
+
+func Test_DryRunLogLevel(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+
+	code, stderr := runMain(t, "authorcommits", "--repo", r.dir, "--dry-run", "--log-level", "error")
+	if code != 0 || !strings.Contains(stderr, "git shortlog") {
+		t.Errorf("Expected the command lines whatever the log level, got %d:\n%s", code, stderr)
+	}
+	if strings.Contains(stderr, "gitcontrib: ") {
+		t.Errorf("Expected the command lines as is, got:\n%s", stderr)
+	}
+}
@@ -0,0 +1,15 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+// mergeCommits returns the number of merge commits each author made in
+// the revision or range of o, telling who integrates the work of others.
+func mergeCommits(o options) (map[string]int, error) {
+	out, err := o.walk("log", "--merges", o.logFormat("--format=%aN"))
+	if err != nil {
+		return nil, err
+	}
+
+	return o.bucketCounts(countAuthors(out)), nil
+}
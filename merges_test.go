@@ -0,0 +1,44 @@
+package gitcontrib
+
+import "testing"
+
+func Test_CountMergesSeparately(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+
+	for _, branch := range []string{"feature-a", "feature-b"} {
+		r.git("checkout", "-q", "-b", branch)
+		r.commit("Author Two", branch+".txt", "1\n")
+		r.git("checkout", "-q", "main")
+		r.gitEnv(
+			[]string{"GIT_AUTHOR_NAME=Maintainer"},
+			"merge", "-q", "--no-ff", "-m", "merge "+branch, branch,
+		)
+	}
+
+	sf := summaryFlags{options: r.options()}
+	sf.noCache = true
+	sf.merges = true
+
+	summaries, _, err := sf.analyse()
+	if err != nil {
+		t.Fatalf("error analysing: %s", err)
+	}
+
+	got := make(map[string]AuthorSummary)
+	for _, s := range summaries {
+		got[s.Author] = s
+	}
+
+	if s := got["Maintainer"]; s.Merges != 2 || s.Commits != 0 {
+		t.Errorf("Expected two merges and no commits of the maintainer, got %+v", s)
+	}
+	if s := got["Author Two"]; s.Merges != 0 || s.Commits != 2 {
+		t.Errorf("Expected two commits and no merges of the author, got %+v", s)
+	}
+
+	rows := summaryRows(summaries, renderOptions{merges: true})
+	if h := rows[0][len(rows[0])-1]; h != "Merges" {
+		t.Errorf("Expected a Merges column, got %q", h)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// monthAuthor holds the commits and line changes of an author in a month.
+type monthAuthor struct {
+	Author    string
+	Commits   int
+	Additions int
+	Deletions int
+}
+
+// monthReport holds the contributions of the authors in a calendar month.
+type monthReport struct {
+	Month   string // like 2023-01
+	Authors []monthAuthor
+}
+
+// monthlyReports returns the contributions of the authors in each calendar
+// month of the non-merge commits, oldest first, limited to the last months
+// of the history when months is positive. The history ends with the month
+// of its last commit rather than today. The authors of a month are sorted
+// by descending commits, then by name. Only the authors active in a month
+// are listed, and months without commits are left out, unless dense is
+// set, listing every author of the history in every month.
+func monthlyReports(commits []commit, months int, dense bool) []monthReport {
+	var first, last int
+	var found bool
+	for _, c := range commits {
+		if c.isMerge() {
+			continue
+		}
+		m := monthIndex(c.Date)
+		if !found || m < first {
+			first = m
+		}
+		if !found || m > last {
+			last = m
+		}
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	if months > 0 && last-first >= months {
+		first = last - months + 1
+	}
+
+	tallies := make([]map[string]*monthAuthor, last-first+1)
+	for i := range tallies {
+		tallies[i] = make(map[string]*monthAuthor)
+	}
+	authors := make(map[string]bool)
+	for _, c := range commits {
+		m := monthIndex(c.Date)
+		if c.isMerge() || m < first {
+			continue
+		}
+		authors[c.Author] = true
+
+		a, ok := tallies[m-first][c.Author]
+		if !ok {
+			a = &monthAuthor{Author: c.Author}
+			tallies[m-first][c.Author] = a
+		}
+		lines := c.LineChanges()
+		a.Commits++
+		a.Additions += lines.Additions
+		a.Deletions += lines.Deletions
+	}
+
+	var reports []monthReport
+	for i, tally := range tallies {
+		if len(tally) == 0 && !dense {
+			continue
+		}
+		if dense {
+			for author := range authors {
+				if _, ok := tally[author]; !ok {
+					tally[author] = &monthAuthor{Author: author}
+				}
+			}
+		}
+
+		r := monthReport{Month: monthName(first + i)}
+		for _, a := range tally {
+			r.Authors = append(r.Authors, *a)
+		}
+		sort.Slice(r.Authors, func(i, j int) bool {
+			a, b := r.Authors[i], r.Authors[j]
+			if a.Commits != b.Commits {
+				return a.Commits > b.Commits
+			}
+			return a.Author < b.Author
+		})
+		reports = append(reports, r)
+	}
+
+	return reports
+}
+
+// monthName returns the month of a monthIndex like 2023-01.
+func monthName(m int) string {
+	return fmt.Sprintf("%04d-%02d", m/12, m%12+1)
+}
+
+// writeMonthTables writes the human-readable 'monthly' report to w, a
+// table of the authors of each month headed by the month.
+func writeMonthTables(w io.Writer, reports []monthReport) error {
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, " %s\n\n", r.Month)
+
+		t := newTable(1, "Author", "Commits", "Additions", "Deletions")
+		for _, a := range r.Authors {
+			t.row(a.Author, strconv.Itoa(a.Commits), strconv.Itoa(a.Additions), strconv.Itoa(a.Deletions))
+		}
+		if err := t.write(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
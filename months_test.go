@@ -0,0 +1,60 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_MonthlyReports(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	change := func(add, del int) []fileChange {
+		return []fileChange{{Path: "a.txt", LineChanges: LineChanges{Additions: add, Deletions: del}}}
+	}
+
+	commits := []commit{
+		{Author: "Alice", Date: date("2023-04-02T10:00:00Z"), Files: change(5, 1)},
+		{Author: "Bob", Date: date("2023-04-01T10:00:00Z"), Files: change(2, 0)},
+		{Author: "Bob", Date: date("2023-04-01T09:00:00Z"), Files: change(3, 3)},
+		{Author: "Carol", Date: date("2023-04-01T08:00:00Z"), Parents: []string{"a", "b"}},
+		{Author: "Alice", Date: date("2023-01-15T10:00:00Z"), Files: change(10, 0)},
+	}
+
+	got := monthlyReports(commits, 0, false)
+	exp := []monthReport{
+		{Month: "2023-01", Authors: []monthAuthor{{"Alice", 1, 10, 0}}},
+		{Month: "2023-04", Authors: []monthAuthor{{"Bob", 2, 5, 3}, {"Alice", 1, 5, 1}}},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got: %v", exp, got)
+	}
+
+	got = monthlyReports(commits, 2, true)
+	exp = []monthReport{
+		{Month: "2023-03", Authors: []monthAuthor{{"Alice", 0, 0, 0}, {"Bob", 0, 0, 0}}},
+		{Month: "2023-04", Authors: []monthAuthor{{"Bob", 2, 5, 3}, {"Alice", 1, 5, 1}}},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected dense %v, got: %v", exp, got)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeMonthTables(buf, exp[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), " 2023-04\n\n") || !strings.Contains(buf.String(), "Bob") {
+		t.Errorf("unexpected tables:\n%s", buf)
+	}
+
+	if got := monthlyReports(nil, 0, true); len(got) != 0 {
+		t.Errorf("Expected no months without commits, got: %v", got)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "strings"
+
+// normalizeName returns the form of an author name its variants are
+// grouped by, trimmed, with internal whitespace collapsed to single spaces
+// and in lower case.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// nameVariants returns the author names of the given maps differing only
+// in case or whitespace from a more frequent variant, mapped to it, so
+// that they can be merged like teams. The most frequent variant is the one
+// with the most commits, then the most changed lines, then the first
+// alphabetically. Either map may be nil.
+func nameVariants(
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) teams {
+
+	groups := make(map[string][]string)
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			key := normalizeName(name)
+			groups[key] = append(groups[key], name)
+		}
+	}
+	for name := range commitMap {
+		add(name)
+	}
+	for name := range lineChangesMap {
+		add(name)
+	}
+
+	more := func(a, b string) bool {
+		if commitMap[a] != commitMap[b] {
+			return commitMap[a] > commitMap[b]
+		}
+		if la, lb := lineChangesMap[a].Sum(), lineChangesMap[b].Sum(); la != lb {
+			return la > lb
+		}
+		return a < b
+	}
+
+	variants := make(teams)
+	for _, names := range groups {
+		if len(names) == 1 {
+			continue
+		}
+
+		display := names[0]
+		for _, name := range names[1:] {
+			if more(name, display) {
+				display = name
+			}
+		}
+		for _, name := range names {
+			if name != display {
+				variants[name] = display
+			}
+		}
+	}
+
+	return variants
+}
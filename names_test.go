@@ -0,0 +1,68 @@
+package gitcontrib
+
+import "testing"
+
+func Test_NormalizeName(t *testing.T) {
+	for _, name := range []string{"John Smith", " john  smith", "JOHN\tSmith "} {
+		if got := normalizeName(name); got != "john smith" {
+			t.Errorf("Expected %q normalized to \"john smith\", got: %q", name, got)
+		}
+	}
+}
+
+func Test_NameVariants(t *testing.T) {
+	commitMap := map[string]int{
+		"john smith":  1,
+		"John Smith":  5,
+		"John  Smith": 2,
+		"Jane Doe":    3,
+	}
+	lineChangesMap := map[string]LineChanges{
+		"john smith":  {Additions: 10},
+		"John Smith":  {Additions: 50},
+		"John  Smith": {Additions: 20, Deletions: 5},
+		"Jane Doe":    {Additions: 30},
+	}
+
+	nameVariants(commitMap, lineChangesMap).merge(commitMap, lineChangesMap)
+
+	if len(commitMap) != 2 || commitMap["John Smith"] != 8 {
+		t.Errorf("Expected the variants merged into the most frequent one, got: %v", commitMap)
+	}
+	if lc := lineChangesMap["John Smith"]; len(lineChangesMap) != 2 || lc.Additions != 80 || lc.Deletions != 5 {
+		t.Errorf("Expected the line changes of the variants merged, got: %v", lineChangesMap)
+	}
+}
+
+func Test_NameVariantsTie(t *testing.T) {
+	lineChangesMap := map[string]LineChanges{
+		"jane doe": {Additions: 1},
+		"Jane Doe": {Additions: 1},
+	}
+
+	variants := nameVariants(nil, lineChangesMap)
+	if len(variants) != 1 || variants["jane doe"] != "Jane Doe" {
+		t.Errorf("Expected the first name alphabetically on a tie, got: %v", variants)
+	}
+}
+
+func Test_CollectNormalizeNames(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("John Smith", "a.txt", "1\n")
+	r.commit("john smith", "b.txt", "1\n")
+	r.commit("John  Smith", "c.txt", "1\n")
+	r.commit("John Smith", "d.txt", "1\n")
+
+	ff := filterFlags{normalize: true}
+	summaries, totals, err := ff.collect(r.options())
+	if err != nil {
+		t.Fatalf("error collecting: %s", err)
+	}
+
+	if len(summaries) != 1 || summaries[0].Author != "John Smith" || summaries[0].Commits != 4 {
+		t.Errorf("Expected a single row for John Smith, got: %+v", summaries)
+	}
+	if summaries[0].CommitRatio != 1 || totals.Commits != 4 {
+		t.Errorf("Expected the merged row to hold all commits, got: %+v", summaries[0])
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// coAuthorFormat is the git log format used for finding who co-authored
+// commits with whom. Each commit starts with a NUL byte followed by its
+// author on a line of its own, followed by its co-author trailers.
+var coAuthorFormat = "--format=%x00%aN%n" + trailersFormat("Co-authored-by")
+
+// coAuthorPair is an edge of the co-authorship network, between two
+// people in alphabetical order.
+type coAuthorPair struct {
+	A, B string
+}
+
+// coAuthorEdge is a pair of people along with the number of commits they
+// authored together.
+type coAuthorEdge struct {
+	coAuthorPair
+	Commits int
+}
+
+// coAuthorNetwork returns the co-authorship network of the revision or
+// range of o, using HEAD when none is given.
+func coAuthorNetwork(o options) ([]coAuthorEdge, error) {
+	out, err := o.walk("log", o.logFormat(coAuthorFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCoAuthors(out, o), nil
+}
+
+// parseCoAuthors returns the pairs of people authoring commits together in
+// the output of git log with coAuthorFormat, the author of a commit along
+// with each of its Co-authored-by trailers and the co-authors with each
+// other, by descending number of commits and then by name. The people
+// are named like the authors of o, the co-authors being mapped through the
+// .mailmap file too. A person is never paired with themself, even when
+// listed as their own co-author under another email.
+func parseCoAuthors(gitOutput string, o options) []coAuthorEdge {
+	counts := make(map[coAuthorPair]int)
+	coAuthorName := o.trailerNamer()
+
+	for _, record := range strings.Split(gitOutput, "\x00") {
+		if record == "" {
+			continue
+		}
+		author, lines, _ := strings.Cut(record, "\n")
+		author = o.authorName(author)
+
+		people := []string{author}
+		seen := map[string]bool{author: true}
+		for _, t := range parseTrailers(lines) {
+			if t.Value == "" || !strings.EqualFold(t.Key, "Co-authored-by") {
+				continue
+			}
+			name := coAuthorName(t.Value)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			people = append(people, name)
+		}
+
+		for i := range people {
+			for j := i + 1; j < len(people); j++ {
+				a, b := people[i], people[j]
+				if b < a {
+					a, b = b, a
+				}
+				counts[coAuthorPair{a, b}]++
+			}
+		}
+	}
+
+	edges := make([]coAuthorEdge, 0, len(counts))
+	for p, n := range counts {
+		edges = append(edges, coAuthorEdge{p, n})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Commits != edges[j].Commits {
+			return edges[i].Commits > edges[j].Commits
+		}
+		if edges[i].A != edges[j].A {
+			return edges[i].A < edges[j].A
+		}
+		return edges[i].B < edges[j].B
+	})
+
+	return edges
+}
+
+// writeNetworkDot writes the co-authorship network to w as an undirected
+// Graphviz DOT graph, with a node per person and an edge per pair, labelled
+// and weighted by their number of commits together.
+func writeNetworkDot(w io.Writer, edges []coAuthorEdge) error {
+	fmt.Fprintln(w, "graph coauthors {")
+	fmt.Fprintln(w, "\tnode [shape=box];")
+
+	seen := make(map[string]bool)
+	for _, e := range edges {
+		for _, name := range []string{e.A, e.B} {
+			if !seen[name] {
+				seen[name] = true
+				fmt.Fprintf(w, "\t%s;\n", dotID(name))
+			}
+		}
+	}
+
+	for _, e := range edges {
+		fmt.Fprintf(w, "\t%s -- %s [weight=%d, label=\"%d\"];\n",
+			dotID(e.A), dotID(e.B), e.Commits, e.Commits,
+		)
+	}
+
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// dotID returns name as a quoted DOT identifier, safe for any name.
+func dotID(name string) string {
+	name = strings.ReplaceAll(name, `\`, `\\`)
+	name = strings.ReplaceAll(name, `"`, `\"`)
+	return `"` + name + `"`
+}
@@ -0,0 +1,77 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ParseCoAuthors(t *testing.T) {
+	output := "\x00Alice\n" +
+		"Co-authored-by: Bob <bob@example.com>\n" +
+		"Co-authored-by: Carol <carol@example.com>\n" +
+		"\n" +
+		"\x00Bob\n" +
+		"Co-authored-by: Alice <alice@example.com>\n" +
+		"Co-authored-by: Bob <bob@example.com>\n" +
+		"\n" +
+		"\x00Carol\n" +
+		"\n"
+
+	edges := parseCoAuthors(output, options{noMailmap: true})
+
+	expected := []coAuthorEdge{
+		{coAuthorPair{"Alice", "Bob"}, 2},
+		{coAuthorPair{"Alice", "Carol"}, 1},
+		{coAuthorPair{"Bob", "Carol"}, 1},
+	}
+	if !reflect.DeepEqual(edges, expected) {
+		t.Errorf("Expected %v, got: %v", expected, edges)
+	}
+}
+
+func Test_WriteNetworkDot(t *testing.T) {
+	edges := []coAuthorEdge{
+		{coAuthorPair{`Jo "JJ" Smith`, `back\slash`}, 3},
+	}
+
+	var buf bytes.Buffer
+	if err := writeNetworkDot(&buf, edges); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, exp := range []string{
+		"graph coauthors {\n",
+		"\t\"Jo \\\"JJ\\\" Smith\";\n",
+		"\t\"Jo \\\"JJ\\\" Smith\" -- \"back\\\\slash\" [weight=3, label=\"3\"];\n",
+	} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("Expected %q in:\n%s", exp, buf.String())
+		}
+	}
+}
+
+func Test_CoAuthorMailmap(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Alice", ".mailmap", "Bob <bob@example.com> <bobby@old.example>\n")
+	r.write("two.txt", "1\n")
+	r.git("add", "two.txt")
+	r.gitEnv(
+		[]string{"GIT_AUTHOR_NAME=Alice"},
+		"commit", "-q", "-m", "Add two\n\nCo-authored-by: Bobby <bobby@old.example>\nCo-authored-by: <nobody@example.com>",
+	)
+
+	edges, err := coAuthorNetwork(r.options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []coAuthorEdge{
+		{coAuthorPair{"(unknown)", "Alice"}, 1},
+		{coAuthorPair{"(unknown)", "Bob"}, 1},
+		{coAuthorPair{"Alice", "Bob"}, 1},
+	}
+	if !reflect.DeepEqual(edges, expected) {
+		t.Errorf("Expected %v, got: %v", expected, edges)
+	}
+}
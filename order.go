@@ -0,0 +1,81 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sortOrders maps the keys of the --sort flag to the count the rows are
+// sorted by, in descending order. The "author" key sorts by name instead.
+var sortOrders = map[string]func(s AuthorSummary) int{
+	"author":    nil,
+	"commits":   func(s AuthorSummary) int { return s.Commits },
+	"additions": func(s AuthorSummary) int { return s.Additions },
+	"deletions": func(s AuthorSummary) int { return s.Deletions },
+	"lines":     func(s AuthorSummary) int { return s.Additions + s.Deletions },
+}
+
+// sortKeyNames returns the sorted keys of the --sort flag.
+func sortKeyNames() []string {
+	names := make([]string, 0, len(sortOrders))
+	for k := range sortOrders {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortSummaries sorts the summaries by the given --sort key, with ties
+// ordered by author name, so that the rows are always in the same order.
+// As the authors of a report have distinct names, this is a total order,
+// not depending on the order the summaries are given in, like that of the
+// maps they are made from. All sorts of the rows of reports follow the
+// same contract, breaking ties by name.
+func sortSummaries(summaries []AuthorSummary, key string) error {
+	count, ok := sortOrders[key]
+	if !ok {
+		return fmt.Errorf(
+			"invalid --sort %q, must be one of: %s",
+			key, strings.Join(sortKeyNames(), ", "),
+		)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if count != nil {
+			if a, b := count(summaries[i]), count(summaries[j]); a != b {
+				return a > b
+			}
+		}
+		return summaries[i].Author < summaries[j].Author
+	})
+
+	return nil
+}
+
+// commitSummaries returns the commit counts of the authors as summaries,
+// to be sorted like the rows of the 'summary' report.
+func commitSummaries(commitMap map[string]int) []AuthorSummary {
+	summaries := make([]AuthorSummary, 0, len(commitMap))
+	for k, v := range commitMap {
+		summaries = append(summaries, AuthorSummary{Author: k, Commits: v})
+	}
+	return summaries
+}
+
+// changeSummaries returns the line changes of the authors as summaries,
+// to be sorted like the rows of the 'summary' report.
+func changeSummaries(lineChangesMap map[string]LineChanges) []AuthorSummary {
+	summaries := make([]AuthorSummary, 0, len(lineChangesMap))
+	for k, v := range lineChangesMap {
+		summaries = append(summaries, AuthorSummary{
+			Author:    k,
+			Additions: v.Additions,
+			Deletions: v.Deletions,
+		})
+	}
+	return summaries
+}
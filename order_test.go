@@ -0,0 +1,64 @@
+package gitcontrib
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func Test_SortTies(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Dave", Commits: 2, Additions: 5, Deletions: 5},
+		{Author: "carol", Commits: 2, Additions: 5, Deletions: 5},
+		{Author: "Bob", Commits: 2, Additions: 5, Deletions: 5},
+		{Author: "Alice", Commits: 2, Additions: 5, Deletions: 5},
+		{Author: "Eve", Commits: 1, Additions: 20, Deletions: 0},
+	}
+
+	names := func(summaries []AuthorSummary) []string {
+		var names []string
+		for _, s := range summaries {
+			names = append(names, s.Author)
+		}
+		return names
+	}
+
+	for key, exp := range map[string][]string{
+		"author":    {"Alice", "Bob", "Dave", "Eve", "carol"},
+		"commits":   {"Alice", "Bob", "Dave", "carol", "Eve"},
+		"additions": {"Eve", "Alice", "Bob", "Dave", "carol"},
+		"deletions": {"Alice", "Bob", "Dave", "carol", "Eve"},
+		"lines":     {"Eve", "Alice", "Bob", "Dave", "carol"},
+	} {
+		for i := 0; i < 10; i++ {
+			shuffled := append([]AuthorSummary(nil), summaries...)
+			rand.Shuffle(len(shuffled), func(i, j int) {
+				shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			})
+
+			if err := sortSummaries(shuffled, key); err != nil {
+				t.Fatal(err)
+			}
+			if got := names(shuffled); !reflect.DeepEqual(got, exp) {
+				t.Fatalf("Expected order %v by %s, got %v", exp, key, got)
+			}
+		}
+	}
+
+	commitMap := make(map[string]int)
+	lineChangesMap := make(map[string]LineChanges)
+	for _, s := range summaries {
+		commitMap[s.Author] = s.Commits
+		lineChangesMap[s.Author] = LineChanges{s.Additions, s.Deletions}
+	}
+	for i := 0; i < 10; i++ {
+		got, _, _ := Summarize(commitMap, lineChangesMap)
+		if exp := []string{"Alice", "Bob", "Dave", "carol", "Eve"}; !reflect.DeepEqual(names(got), exp) {
+			t.Fatalf("Expected summaries in order %v, got %v", exp, names(got))
+		}
+	}
+
+	if err := sortSummaries(summaries, "email"); err == nil {
+		t.Error("Expected error for an unknown sort key")
+	}
+}
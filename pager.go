@@ -0,0 +1,59 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// defaultPager is the pager used when $PAGER is not set, chopping long
+// lines so wide tables can be scrolled horizontally.
+const defaultPager = "less -S"
+
+// page calls fn with a writer piping to the pager named by $PAGER, which
+// writes to standard output. Like git, less is told to quit right away
+// when the output fits on one screen, unless $LESS says otherwise. When
+// the pager can't be started, fn is called with standard output instead.
+func page(fn func(w io.Writer) error) error {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = strings.Fields(defaultPager)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return fn(os.Stdout)
+	}
+
+	if err := cmd.Start(); err != nil {
+		debugf("error starting pager %q: %s", args[0], err)
+		return fn(os.Stdout)
+	}
+
+	err = fn(in)
+	in.Close()
+	waitErr := cmd.Wait()
+
+	// quitting the pager before reading everything is no error
+	if errors.Is(err, syscall.EPIPE) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return waitErr
+}
@@ -0,0 +1,30 @@
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Page(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paged.txt")
+	t.Setenv("PAGER", "dd status=none of="+path)
+
+	err := page(func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, "paged")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error paging: %s", err)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "paged\n" {
+		t.Errorf("Expected the pager to get the output, got: %q", buf)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// pipeTable is the syntax of a plain text table with cells delimited by
+// pipes, like the tables of Org-mode and Markdown, which differ only in
+// how the header is separated from the rows and how a pipe in a cell is
+// escaped.
+type pipeTable struct {
+	junction string // joins the dashes of the separator line
+	pipe     string // replaces a pipe within a cell
+}
+
+// orgTable is the table syntax of Org-mode, in which a separator line
+// looks like |---+---|.
+var orgTable = pipeTable{junction: "+", pipe: `\vert{}`}
+
+// write writes rows to w as a pipe table, the first row being the header,
+// separated from the rest by a line of dashes. Like Org aligns them, the
+// cells of the leading text columns are left-aligned and those of the
+// following numeric columns right-aligned.
+func (p pipeTable) write(w io.Writer, text int, rows [][]string) error {
+	cells := make([][]string, len(rows))
+	var widths []int
+	for i, r := range rows {
+		cells[i] = make([]string, len(r))
+		for j, c := range r {
+			c = strings.ReplaceAll(c, "|", p.pipe)
+			cells[i][j] = c
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(c); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+
+	for i, r := range cells {
+		var b strings.Builder
+		for j, c := range r {
+			pad := strings.Repeat(" ", widths[j]-utf8.RuneCountInString(c))
+			if j < text || i == 0 {
+				c += pad
+			} else {
+				c = pad + c
+			}
+			b.WriteString("| " + c + " ")
+		}
+		if _, err := fmt.Fprintln(w, b.String()+"|"); err != nil {
+			return err
+		}
+
+		if i == 0 {
+			dashes := make([]string, len(widths))
+			for j, n := range widths {
+				dashes[j] = strings.Repeat("-", n+2)
+			}
+			_, err := fmt.Fprintln(w, "|"+strings.Join(dashes, p.junction)+"|")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeSummaryOrg writes the 'summary' table to w as an Org-mode table,
+// ready to be pasted into an Org document.
+func writeSummaryOrg(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	err := orgTable.write(w, opts.textColumns(), summaryRows(summaries, opts))
+	if err != nil {
+		return fmt.Errorf("error writing org table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_WriteSummaryOrg(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author | One", Commits: 12, Additions: 100, Deletions: 20, LineRatio: 0.75, CommitRatio: 0.8, Granularity: 0.1},
+		{Author: "Two", Commits: 3, Additions: 30, Deletions: 10, LineRatio: 0.25, CommitRatio: 0.2, Granularity: 0.075},
+	}
+
+	buf := new(bytes.Buffer)
+	err := writeSummaryOrg(buf, summaries, Totals{}, renderOptions{files: true})
+	if err != nil {
+		t.Fatalf("error rendering org: %s", err)
+	}
+
+	exp := `| Author             | Commits | Additions | Deletions | Line ratio | Commit ratio | Granularity | Files |
+|--------------------+---------+-----------+-----------+------------+--------------+-------------+-------|
+| Author \vert{} One |      12 |       100 |        20 |      0.750 |        0.800 |       0.100 |     0 |
+| Two                |       3 |        30 |        10 |      0.250 |        0.200 |       0.075 |     0 |
+`
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeSummaryPlain writes the 'summary' table to w without any alignment,
+// as a header of the column names followed by a row per author, the cells
+// joined by the delimiter of opts. Cells are neither padded nor quoted,
+// leaving the rows to be split by tools like awk and cut.
+func writeSummaryPlain(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	cols := opts.shownColumns()
+
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+	if _, err := fmt.Fprintln(w, strings.Join(names, opts.delimiter)); err != nil {
+		return err
+	}
+
+	for _, s := range summaries {
+		cells := make([]string, len(cols))
+		for i, c := range cols {
+			cells[i] = c.text(s)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, opts.delimiter)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_WriteSummaryPlain(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "One", Commits: 12, Additions: 100, Deletions: 20, LineRatio: 0.75, CommitRatio: 0.8, Granularity: 0.1},
+		{Author: "Two", Commits: 3, Additions: 30, Deletions: 10, LineRatio: 0.25, CommitRatio: 0.2, Granularity: 0.075},
+	}
+
+	for delimiter, exp := range map[string]string{
+		" ": `author commits additions deletions line_ratio commit_ratio granularity
+One 12 100 20 0.750 0.800 0.100
+Two 3 30 10 0.250 0.200 0.075
+`,
+		"\t": "author\tcommits\tadditions\tdeletions\tline_ratio\tcommit_ratio\tgranularity\n" +
+			"One\t12\t100\t20\t0.750\t0.800\t0.100\n" +
+			"Two\t3\t30\t10\t0.250\t0.200\t0.075\n",
+	} {
+		buf := new(bytes.Buffer)
+		err := writeSummaryPlain(buf, summaries, Totals{}, renderOptions{delimiter: delimiter})
+		if err != nil {
+			t.Fatalf("error rendering plain: %s", err)
+		}
+		if buf.String() != exp {
+			t.Errorf("Expected with delimiter %q:\n%s\ngot:\n%s", delimiter, exp, buf)
+		}
+	}
+
+	cols, err := parseColumns("author,net")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	err = writeSummaryPlain(buf, summaries, Totals{}, renderOptions{delimiter: ",", columns: cols})
+	if err != nil {
+		t.Fatalf("error rendering plain: %s", err)
+	}
+	if exp := "author,net\nOne,80\nTwo,20\n"; buf.String() != exp {
+		t.Errorf("Expected with columns:\n%s\ngot:\n%s", exp, buf)
+	}
+}
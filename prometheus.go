@@ -0,0 +1,99 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// promMetric is a gauge of the prometheus report, holding one value per
+// author or for the whole repo.
+type promMetric struct {
+	name   string
+	help   string
+	author func(AuthorSummary) float64
+	totals func(Totals) float64
+}
+
+// promMetrics are the metrics written by writeSummaryPrometheus, in order.
+var promMetrics = []promMetric{
+	{name: "gitcontrib_author_commits", help: "Non-merge commits of the author.",
+		author: func(s AuthorSummary) float64 { return float64(s.Commits) }},
+	{name: "gitcontrib_author_additions", help: "Lines added by the author.",
+		author: func(s AuthorSummary) float64 { return float64(s.Additions) }},
+	{name: "gitcontrib_author_deletions", help: "Lines deleted by the author.",
+		author: func(s AuthorSummary) float64 { return float64(s.Deletions) }},
+	{name: "gitcontrib_author_line_ratio", help: "Share of all line changes made by the author.",
+		author: func(s AuthorSummary) float64 { return s.LineRatio }},
+	{name: "gitcontrib_author_commit_ratio", help: "Share of all commits made by the author.",
+		author: func(s AuthorSummary) float64 { return s.CommitRatio }},
+	{name: "gitcontrib_author_granularity", help: "Commits per changed line of the author.",
+		author: func(s AuthorSummary) float64 { return s.Granularity }},
+	{name: "gitcontrib_commits", help: "Non-merge commits of the repo.",
+		totals: func(t Totals) float64 { return float64(t.Commits) }},
+	{name: "gitcontrib_additions", help: "Lines added in the repo.",
+		totals: func(t Totals) float64 { return float64(t.Additions) }},
+	{name: "gitcontrib_deletions", help: "Lines deleted in the repo.",
+		totals: func(t Totals) float64 { return float64(t.Deletions) }},
+	{name: "gitcontrib_granularity", help: "Commits per changed line of the repo.",
+		totals: func(t Totals) float64 { return t.Granularity }},
+	{name: "gitcontrib_gini", help: "Gini coefficient of the line changes of the authors.",
+		totals: func(t Totals) float64 { return t.Gini }},
+}
+
+// writeSummaryPrometheus writes the summaries and totals to w in the
+// Prometheus text exposition format, as gauges labelled with the author
+// and the repo.
+func writeSummaryPrometheus(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	repo := `repo="` + promEscape(opts.repo) + `"`
+
+	bw := bufio.NewWriter(w)
+	for _, m := range promMetrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", m.name)
+
+		if m.totals != nil {
+			fmt.Fprintf(bw, "%s{%s} %s\n", m.name, repo, promValue(m.totals(totals)))
+			continue
+		}
+
+		for _, s := range summaries {
+			fmt.Fprintf(bw, "%s{author=\"%s\",%s} %s\n",
+				m.name, promEscape(s.Author), repo, promValue(m.author(s)))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// promEscape escapes a label value, in which backslashes, double quotes
+// and line feeds must be escaped.
+func promEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// promValue formats a sample value, writing infinities and NaN the way
+// Prometheus expects them.
+func promValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
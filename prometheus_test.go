@@ -0,0 +1,46 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func Test_WriteSummaryPrometheus(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: `Quote "Q" Back\slash`, Commits: 3, Granularity: math.Inf(1)},
+		{Author: "Line\nFeed", Commits: 1, LineRatio: 0.25},
+	}
+
+	var buf bytes.Buffer
+	err := writeSummaryPrometheus(&buf, summaries, Totals{Commits: 4}, renderOptions{repo: "my repo"})
+	if err != nil {
+		t.Fatalf("error writing metrics: %s", err)
+	}
+
+	comment := regexp.MustCompile(`^# (HELP|TYPE) gitcontrib_[a-z_]+ .+$`)
+	sample := regexp.MustCompile(
+		`^gitcontrib_[a-z_]+\{(author="(?:[^"\\\n]|\\.)*",)?repo="(?:[^"\\\n]|\\.)*"\} (\+Inf|-Inf|NaN|[-0-9.e+]+)$`,
+	)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for _, line := range lines {
+		if !comment.MatchString(line) && !sample.MatchString(line) {
+			t.Errorf("malformed line: %q", line)
+		}
+	}
+
+	out := buf.String()
+	for _, exp := range []string{
+		`gitcontrib_author_commits{author="Quote \"Q\" Back\\slash",repo="my repo"} 3`,
+		`gitcontrib_author_line_ratio{author="Line\nFeed",repo="my repo"} 0.25`,
+		`gitcontrib_author_granularity{author="Quote \"Q\" Back\\slash",repo="my repo"} +Inf`,
+		`gitcontrib_commits{repo="my repo"} 4`,
+	} {
+		if !strings.Contains(out, exp+"\n") {
+			t.Errorf("Expected line %q, got:\n%s", exp, out)
+		}
+	}
+}
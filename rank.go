@@ -0,0 +1,48 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+// RankAuthors returns the rank of each of the summaries by the metric, one
+// of the keys of --sort, with standard competition ranking: authors tied
+// on the metric share a rank, and the next author is ranked as if they
+// weren't, like 1, 2, 2, 4. The highest counts rank first, and the author
+// key ranks by name, without ties. The ranks are in the order of the
+// summaries, whichever it is. An unknown metric gives nil.
+func RankAuthors(summaries []AuthorSummary, metric string) []int {
+	count, ok := sortOrders[metric]
+	if !ok {
+		return nil
+	}
+
+	ahead := func(a, b AuthorSummary) bool {
+		if count == nil {
+			return a.Author < b.Author
+		}
+		return count(a) > count(b)
+	}
+
+	ranks := make([]int, len(summaries))
+	for i, s := range summaries {
+		ranks[i] = 1
+		for _, other := range summaries {
+			if ahead(other, s) {
+				ranks[i]++
+			}
+		}
+	}
+
+	return ranks
+}
+
+// setRanks sorts the summaries by the metric like sortSummaries, and sets
+// their ranks by it.
+func setRanks(summaries []AuthorSummary, metric string) error {
+	if err := sortSummaries(summaries, metric); err != nil {
+		return err
+	}
+	for i, r := range RankAuthors(summaries, metric) {
+		summaries[i].Rank = r
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_RankAuthors(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Dave", Commits: 1, Additions: 9},
+		{Author: "Alice", Commits: 7, Additions: 1},
+		{Author: "Carol", Commits: 4, Additions: 5},
+		{Author: "Bob", Commits: 4, Additions: 5},
+	}
+
+	if got, exp := RankAuthors(summaries, "commits"), []int{4, 1, 2, 2}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected ranks %v by commits, got %v", exp, got)
+	}
+	if got, exp := RankAuthors(summaries, "author"), []int{4, 1, 3, 2}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected ranks %v by author, got %v", exp, got)
+	}
+	if got := RankAuthors(summaries, "score"); got != nil {
+		t.Errorf("Expected no ranks for an unknown metric, got %v", got)
+	}
+
+	if err := setRanks(summaries, "additions"); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	var ranks []int
+	for _, s := range summaries {
+		names = append(names, s.Author)
+		ranks = append(ranks, s.Rank)
+	}
+	if exp := []string{"Dave", "Bob", "Carol", "Alice"}; !reflect.DeepEqual(names, exp) {
+		t.Errorf("Expected the authors sorted %v, got %v", exp, names)
+	}
+	if exp := []int{1, 2, 2, 4}; !reflect.DeepEqual(ranks, exp) {
+		t.Errorf("Expected ranks %v, got %v", exp, ranks)
+	}
+
+	shown := renderOptions{rank: true, score: true}.shownColumns()
+	if shown[0].name != "rank" || shown[len(shown)-1].name != "score" {
+		t.Errorf("Expected the rank column first, got %v", shown)
+	}
+	if n := (renderOptions{rank: true}).textColumns(); n != 2 {
+		t.Errorf("Expected the rank and author columns left-aligned, got %d", n)
+	}
+}
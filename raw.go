@@ -0,0 +1,61 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// saveRaw is the directory the raw output of every git invocation is
+// saved to for archival, set by --save-raw.
+var saveRaw string
+
+// rawIndex is the file of the --save-raw directory listing the saved
+// outputs along with the command lines giving them.
+const rawIndex = "commands.txt"
+
+// rawSaved counts the outputs saved to the --save-raw directory, numbering
+// them in the order the invocations end, which may differ from the order
+// they start in when they run at the same time.
+var rawSaved struct {
+	sync.Mutex
+	n int
+}
+
+// saveRawOutput saves the standard output of the git invocation with the
+// given arguments, run in dir, to the --save-raw directory, as a file
+// named by its number and the git command, like "001-log.txt", before it
+// is decoded or parsed in any way. The file is listed in rawIndex along
+// with the command line, run with -C so that it can be pasted into a shell
+// to give the output again.
+func saveRawOutput(dir string, args []string, out []byte) error {
+	rawSaved.Lock()
+	defer rawSaved.Unlock()
+
+	if err := os.MkdirAll(saveRaw, 0o755); err != nil {
+		return fmt.Errorf("error saving raw git output: %w", err)
+	}
+
+	rawSaved.n++
+	name := fmt.Sprintf("%03d-%s.txt", rawSaved.n, args[0])
+	if err := os.WriteFile(filepath.Join(saveRaw, name), out, 0o644); err != nil {
+		return fmt.Errorf("error saving raw git output: %w", err)
+	}
+
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	f, err := os.OpenFile(filepath.Join(saveRaw, rawIndex), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error saving raw git output: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%s\t%s\n", name, commandLine(args)); err != nil {
+		f.Close()
+		return fmt.Errorf("error saving raw git output: %w", err)
+	}
+	return f.Close()
+}
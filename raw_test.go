@@ -0,0 +1,54 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_SaveRaw(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n3\n")
+	r.commit("Author Two", "two.txt", "1\n")
+
+	saveRaw = filepath.Join(t.TempDir(), "raw")
+	t.Cleanup(func() { saveRaw = "" })
+
+	o := r.options()
+	lineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error extracting line changes: %s", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(saveRaw, rawIndex))
+	if err != nil {
+		t.Fatalf("error reading the index: %s", err)
+	}
+	var logFile string
+	for _, line := range strings.Split(strings.TrimSpace(string(index)), "\n") {
+		name, cmdline, ok := strings.Cut(line, "\t")
+		if !ok || !strings.HasPrefix(cmdline, "git -C "+r.dir+" ") {
+			t.Errorf("Expected a file and the command line giving it, got %q", line)
+		}
+		if strings.HasSuffix(name, "-log.txt") {
+			logFile = name
+		}
+	}
+	if logFile == "" {
+		t.Fatalf("Expected the output of git log to be saved, got:\n%s", index)
+	}
+
+	buf, err := os.ReadFile(filepath.Join(saveRaw, logFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parseLineChanges(string(buf))
+	if err != nil {
+		t.Fatalf("error parsing the saved output: %s", err)
+	}
+	if got := o.bucketLineChanges(parsed); !reflect.DeepEqual(got, lineChanges) {
+		t.Errorf("Expected the saved output to give %v again, got %v", lineChanges, got)
+	}
+}
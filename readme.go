@@ -0,0 +1,59 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// markdownSpecial replaces the characters having a meaning in Markdown
+// text with their escaped forms.
+var markdownSpecial = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`,
+)
+
+// writeSummaryReadme writes the summaries to w as a ranked Markdown list
+// for the contributors section of a README, like:
+//
+//  1. **Author One** — 120 commits (45%)
+//
+// The authors keep the order of the summaries, most commits first.
+func writeSummaryReadme(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	var b strings.Builder
+	for i, s := range summaries {
+		noun := "commits"
+		if s.Commits == 1 {
+			noun = "commit"
+		}
+		fmt.Fprintf(&b, "%d. **%s** — %d %s (%s)\n",
+			i+1, markdownSpecial.Replace(s.Author), s.Commits, noun, percent(s.CommitRatio))
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("error writing markdown: %w", err)
+	}
+
+	return nil
+}
+
+// percent returns the ratio as a whole percentage, like "45%". Ratios
+// rounding to 0 while not being 0 are given as "<1%", so that no listed
+// contributor appears to have contributed nothing.
+func percent(ratio float64) string {
+	p := math.Round(ratio * 100)
+	if p == 0 && ratio > 0 {
+		return "<1%"
+	}
+	return fmt.Sprintf("%.0f%%", p)
+}
@@ -0,0 +1,27 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_WriteSummaryReadme(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author_One", Commits: 120, CommitRatio: 0.4523},
+		{Author: "Two", Commits: 1, CommitRatio: 0.004},
+		{Author: "Three", Commits: 0},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeSummaryReadme(buf, summaries, Totals{}, renderOptions{}); err != nil {
+		t.Fatalf("error rendering readme: %s", err)
+	}
+
+	exp := `1. **Author\_One** — 120 commits (45%)
+2. **Two** — 1 commit (<1%)
+3. **Three** — 0 commits (0%)
+`
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf)
+	}
+}
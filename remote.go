@@ -0,0 +1,178 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// githubAPI is the base URL of the GitHub REST API.
+var githubAPI = "https://api.github.com"
+
+// githubRetries is the number of times the contributor statistics are
+// asked for again while GitHub is still computing them, waiting
+// githubBackoff before the first retry and twice as long before each
+// following one.
+var (
+	githubRetries = 5
+	githubBackoff = 2 * time.Second
+)
+
+// githubClient queries the GitHub API, giving up on a request after 30
+// seconds rather than hanging on a stalled connection.
+var githubClient = &http.Client{Timeout: 30 * time.Second}
+
+// githubContributor is an entry of the contributor statistics of the
+// GitHub API, with the additions, deletions and commits of each week.
+type githubContributor struct {
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Total int `json:"total"`
+	Weeks []struct {
+		Start     int64 `json:"w"`
+		Additions int   `json:"a"`
+		Deletions int   `json:"d"`
+		Commits   int   `json:"c"`
+	} `json:"weeks"`
+}
+
+// parseGitHubRemote returns the owner and name of the GitHub repository a
+// remote URL refers to, given like https://github.com/OWNER/REPO, as an
+// SSH remote like git@github.com:OWNER/REPO.git, or without a scheme.
+func parseGitHubRemote(remote string) (owner, repo string, err error) {
+	host, path := "", ""
+	switch {
+	case strings.Contains(remote, "://"):
+		u, err := url.Parse(remote)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid remote URL: %w", err)
+		}
+		host, path = u.Hostname(), u.Path
+	case strings.Contains(remote, ":"):
+		host, path, _ = strings.Cut(remote, ":")
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	default:
+		host, path, _ = strings.Cut(remote, "/")
+	}
+
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	if host != "github.com" {
+		return "", "", fmt.Errorf("only GitHub remotes are supported, got host %q", host)
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid GitHub remote %q, expected github.com/OWNER/REPO", remote)
+	}
+
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+// githubContributors returns the contributor statistics of the GitHub
+// repository, authenticating with the GITHUB_TOKEN environment variable
+// when set. As GitHub answers 202 Accepted while it computes statistics
+// missing from its cache, it is asked again with backoff until they are
+// ready.
+func githubContributors(owner, repo string) ([]githubContributor, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/stats/contributors",
+		githubAPI, url.PathEscape(owner), url.PathEscape(repo))
+
+	wait := githubBackoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		debugf("GET %s", endpoint)
+		resp, err := githubClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error querying the GitHub API: %w", err)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var contributors []githubContributor
+			err := json.NewDecoder(resp.Body).Decode(&contributors)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("error decoding the GitHub contributor statistics: %w", err)
+			}
+			return contributors, nil
+
+		case http.StatusAccepted:
+			resp.Body.Close()
+			if attempt == githubRetries {
+				return nil, errors.New("GitHub is still computing the contributor statistics, try again later")
+			}
+			debugf("GitHub is computing the statistics, retrying in %s", wait)
+			time.Sleep(wait)
+			wait *= 2
+
+		case http.StatusNotFound:
+			resp.Body.Close()
+			return nil, &Error{CodeBadRef, fmt.Errorf("no GitHub repository %s/%s, or no access to it", owner, repo)}
+
+		default:
+			resp.Body.Close()
+			return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
+		}
+	}
+}
+
+// githubSummaries returns the summaries and totals of the contributor
+// statistics, like those of a local repository. The first and last dates
+// are the starts of the first and last weeks with commits.
+func githubSummaries(contributors []githubContributor) ([]AuthorSummary, Totals, error) {
+	commitMap := make(map[string]int)
+	lineChangesMap := make(map[string]LineChanges)
+	var first, last int64
+	for _, c := range contributors {
+		author := "unknown"
+		if c.Author != nil && c.Author.Login != "" {
+			author = c.Author.Login
+		}
+
+		commitMap[author] += c.Total
+		lc := lineChangesMap[author]
+		for _, w := range c.Weeks {
+			lc.Additions += w.Additions
+			lc.Deletions += w.Deletions
+			if w.Commits > 0 {
+				if first == 0 || w.Start < first {
+					first = w.Start
+				}
+				if w.Start > last {
+					last = w.Start
+				}
+			}
+		}
+		lineChangesMap[author] = lc
+	}
+
+	summaries, totals, err := Summarize(commitMap, lineChangesMap)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+	if first != 0 {
+		totals.First = time.Unix(first, 0).UTC()
+		totals.Last = time.Unix(last, 0).UTC()
+	}
+
+	return summaries, totals, nil
+}
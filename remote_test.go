@@ -0,0 +1,102 @@
+package gitcontrib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_ParseGitHubRemote(t *testing.T) {
+	for _, remote := range []string{
+		"https://github.com/owner/repo",
+		"https://www.github.com/owner/repo.git/",
+		"ssh://git@github.com/owner/repo.git",
+		"git@github.com:owner/repo.git",
+		"github.com/owner/repo",
+	} {
+		owner, repo, err := parseGitHubRemote(remote)
+		if err != nil {
+			t.Errorf("error parsing %q: %s", remote, err)
+			continue
+		}
+		if owner != "owner" || repo != "repo" {
+			t.Errorf("Expected owner/repo for %q, got %s/%s", remote, owner, repo)
+		}
+	}
+
+	for _, remote := range []string{
+		"https://gitlab.com/owner/repo",
+		"https://github.com/owner",
+		"github.com/owner/repo/tree/main",
+	} {
+		if _, _, err := parseGitHubRemote(remote); err == nil {
+			t.Errorf("Expected error for %q", remote)
+		}
+	}
+}
+
+func Test_GitHubContributors(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/repos/owner/repo/stats/contributors" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Expected the token to be sent, got: %q", r.Header.Get("Authorization"))
+		}
+		if requests == 1 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Write([]byte(`[
+			{"author": {"login": "bob"}, "total": 1, "weeks": [
+				{"w": 1672531200, "a": 10, "d": 0, "c": 1}
+			]},
+			{"author": {"login": "alice"}, "total": 3, "weeks": [
+				{"w": 1672531200, "a": 0, "d": 0, "c": 0},
+				{"w": 1673136000, "a": 20, "d": 10, "c": 2},
+				{"w": 1673740800, "a": 5, "d": 5, "c": 1}
+			]}
+		]`))
+	}))
+	defer srv.Close()
+
+	defer func(api string, backoff time.Duration) {
+		githubAPI, githubBackoff = api, backoff
+	}(githubAPI, githubBackoff)
+	githubAPI, githubBackoff = srv.URL, time.Millisecond
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	contributors, err := githubContributors("owner", "repo")
+	if err != nil {
+		t.Fatalf("error getting contributors: %s", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected a retry after 202 Accepted, got %d requests", requests)
+	}
+
+	summaries, totals, err := githubSummaries(contributors)
+	if err != nil {
+		t.Fatalf("error summarizing: %s", err)
+	}
+	if len(summaries) != 2 || summaries[0].Author != "alice" || summaries[0].Commits != 3 ||
+		summaries[0].Additions != 25 || summaries[0].Deletions != 15 {
+		t.Errorf("unexpected summaries: %+v", summaries)
+	}
+	if summaries[0].LineRatio != 0.8 || totals.Commits != 4 {
+		t.Errorf("unexpected ratios or totals: %+v, %+v", summaries[0], totals)
+	}
+	if exp := time.Unix(1672531200, 0).UTC(); !totals.First.Equal(exp) {
+		t.Errorf("Expected first week %s, got %s", exp, totals.First)
+	}
+	if exp := time.Unix(1673740800, 0).UTC(); !totals.Last.Equal(exp) {
+		t.Errorf("Expected last week %s, got %s", exp, totals.Last)
+	}
+
+	if _, err := githubContributors("owner", "missing"); errorCode(err) != CodeBadRef {
+		t.Errorf("Expected a bad ref error for a missing repo, got: %v", err)
+	}
+}
@@ -0,0 +1,398 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// renderer writes the author summaries and repo-wide totals to w in one
+// output format.
+type renderer func(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error
+
+// renderOptions holds the settings affecting how the reports are rendered.
+// Renderers ignore the settings not applying to their format.
+type renderOptions struct {
+	noFooter bool // omit the lines following the human-readable table
+	box      bool // draw borders around the human-readable table
+	ascii    bool // draw the borders with ASCII characters
+	grouped  bool // group the digits of the integers of the table by thousands
+	decay    bool // add the recency-weighted line changes column
+	files    bool // add the files touched column
+	breadth  bool // add the files per commit and breadth score columns
+	merges   bool // add the merge commits column
+	survive  bool // add the surviving lines and footprint columns
+	hunks    bool // add the hunks and complexity columns
+	extra    bool // add the deletions to additions ratio column
+	score    bool // add the column of the line ratios as scores
+	rank     bool // add the leading column of the ranks of the authors
+
+	selfRatios bool // add the columns of the own addition and deletion shares
+
+	columns []column // columns selected by --columns, replacing the above
+
+	delimiter string // separates the cells of the plain format
+
+	repo string // name of the repo, labelling the prometheus metrics
+
+	timestamp time.Time // of the influx points, the current time when zero
+}
+
+// renderers maps the names accepted by the --format flag to the renderer
+// of that format.
+var renderers = map[string]renderer{
+	"table":      writeSummaryTable,
+	"html":       writeSummaryHTML,
+	"influx":     writeSummaryInflux,
+	"json":       writeSummaryJSON,
+	"ndjson":     writeSummaryNDJSON,
+	"yaml":       writeSummaryYAML,
+	"svg":        writeSummarySVG,
+	"prometheus": writeSummaryPrometheus,
+	"org":        writeSummaryOrg,
+	"plain":      writeSummaryPlain,
+	"readme":     writeSummaryReadme,
+	"xlsx":       writeSummaryXLSX,
+	"gnuplot":    writeSummaryGnuplot,
+
+	"pretty-json":      writeSummaryPrettyJSON,
+	"markdown-details": writeSummaryDetails,
+}
+
+// report is the document written by the machine-readable formats.
+type report struct {
+	Authors []AuthorSummary `json:"authors" yaml:"authors"`
+	Totals  Totals          `json:"totals" yaml:"totals"`
+}
+
+// selectedReport is the document written by the machine-readable formats
+// with --columns.
+type selectedReport struct {
+	Authors []selectedSummary `json:"authors" yaml:"authors"`
+	Totals  Totals            `json:"totals" yaml:"totals"`
+}
+
+// formatNames returns the sorted names of all output formats.
+func formatNames() []string {
+	names := make([]string, 0, len(renderers))
+	for k := range renderers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeSummaryTable writes the human-readable 'summary' table to w,
+// followed by the overall repo commit granularity.
+func writeSummaryTable(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	rows := summaryRows(summaries, opts)
+	if opts.grouped {
+		groupIntegers(rows, summaries, opts.shownColumns())
+	}
+	if opts.box || opts.ascii {
+		style := unicodeBox
+		if opts.ascii {
+			style = asciiBox
+		}
+		if err := style.write(w, opts.textColumns(), rows); err != nil {
+			return err
+		}
+	} else {
+		t := newTable(opts.textColumns(), rows[0]...)
+		for _, row := range rows[1:] {
+			t.row(row...)
+		}
+		if err := t.write(w); err != nil {
+			return err
+		}
+	}
+
+	if opts.noFooter {
+		return nil
+	}
+
+	fmt.Fprintf(w,
+		"\n Overall repo commit granularity: %.3f\n",
+		totals.Granularity,
+	)
+	fmt.Fprintf(w,
+		" Gini coefficient of line changes: %.3f\n",
+		totals.Gini,
+	)
+
+	if !totals.First.IsZero() {
+		fmt.Fprintf(w,
+			" Commits from %s to %s (%d days)\n",
+			totals.First.Format("2006-01-02"),
+			totals.Last.Format("2006-01-02"),
+			totals.Days(),
+		)
+	}
+
+	return nil
+}
+
+// summaryRows returns the cells of the 'summary' table, headed by the
+// column names, with the columns of opts.
+func summaryRows(summaries []AuthorSummary, opts renderOptions) [][]string {
+	cols := opts.shownColumns()
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.header
+	}
+
+	rows := [][]string{header}
+	for _, s := range summaries {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = c.text(s)
+			if c.name == "author" && s.uncommitted {
+				row[i] += " *"
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// groupIntegers groups the digits of the cells of the integer columns of
+// the rows of the summaries by thousands, like 3,897,123, leaving out the
+// header row.
+func groupIntegers(rows [][]string, summaries []AuthorSummary, cols []column) {
+	for i, c := range cols {
+		if c.cell != nil || len(summaries) == 0 {
+			continue
+		}
+		if _, ok := c.value(summaries[0]).(int); !ok {
+			continue
+		}
+		for _, row := range rows[1:] {
+			row[i] = groupDigits(row[i])
+		}
+	}
+}
+
+// groupDigits returns the integer n, as written by strconv.Itoa, with its
+// digits grouped by thousands separated by commas.
+func groupDigits(n string) string {
+	sign, digits := "", n
+	if strings.HasPrefix(n, "-") {
+		sign, digits = "-", n[1:]
+	}
+	if len(digits) <= 3 {
+		return n
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+	b.WriteString(digits[:head])
+	for i := head; i < len(digits); i += 3 {
+		b.WriteByte(',')
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// deletionsPerAdditionCell returns the deleted lines per added line of s
+// as shown in the table, being "∞" for authors only deleting lines and
+// blank for authors without any line changes.
+func deletionsPerAdditionCell(s AuthorSummary) string {
+	switch {
+	case s.Additions > 0:
+		return fmt.Sprintf("%.3f", s.DeletionsPerAddition())
+	case s.Deletions > 0:
+		return "∞"
+	default:
+		return ""
+	}
+}
+
+// writeSummaryJSON writes the summaries and totals to w as a single JSON
+// object.
+func writeSummaryJSON(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	var err error
+	if len(opts.columns) > 0 {
+		err = json.NewEncoder(w).Encode(selectedReport{selectSummaries(summaries, opts.columns), totals})
+	} else {
+		err = json.NewEncoder(w).Encode(report{summaries, totals})
+	}
+	if err != nil {
+		return fmt.Errorf("error encoding json: %w", err)
+	}
+
+	return nil
+}
+
+// writeSummaryPrettyJSON writes the summaries and totals to w as a single
+// JSON object like writeSummaryJSON, indented by two spaces and with the
+// authors sorted by name, whatever the order of the summaries, so that
+// the reports of two runs only differ in the numbers that changed. The
+// fields are written in a fixed order, that of the struct fields or of
+// --columns.
+func writeSummaryPrettyJSON(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	sorted := make([]AuthorSummary, len(summaries))
+	copy(sorted, summaries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Author < sorted[j].Author })
+
+	var v interface{} = report{sorted, totals}
+	if len(opts.columns) > 0 {
+		v = selectedReport{selectSummaries(sorted, opts.columns), totals}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("error encoding json: %w", err)
+	}
+
+	return nil
+}
+
+// writeSummaryNDJSON writes each summary to w as a JSON object on a line
+// of its own, as soon as it is encoded. The totals follow on the last line,
+// marked by a "_total" field set to true.
+func writeSummaryNDJSON(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	enc := json.NewEncoder(w)
+	for _, s := range summaries {
+		var v interface{} = s
+		if len(opts.columns) > 0 {
+			v = selectedSummary{opts.columns, s}
+		}
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("error encoding json: %w", err)
+		}
+	}
+
+	err := enc.Encode(struct {
+		Total bool `json:"_total"`
+		Totals
+	}{true, totals})
+	if err != nil {
+		return fmt.Errorf("error encoding json: %w", err)
+	}
+
+	return nil
+}
+
+// writeSummaryYAML writes the summaries and totals to w as a YAML document.
+func writeSummaryYAML(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	var err error
+	if len(opts.columns) > 0 {
+		err = enc.Encode(selectedReport{selectSummaries(summaries, opts.columns), totals})
+	} else {
+		err = enc.Encode(report{summaries, totals})
+	}
+	if err != nil {
+		return fmt.Errorf("error encoding yaml: %w", err)
+	}
+
+	return enc.Close()
+}
+
+// writeBranchTable writes the human-readable 'allbranches' table to w, the
+// summary of each branch following the other with a leading branch column.
+func writeBranchTable(w io.Writer, branches []branchSummary) error {
+
+	t := newTable(2, "Branch", "Author", "Commits", "Additions", "Deletions", "Line ratio", "Commit ratio", "Granularity")
+	for _, b := range branches {
+		for _, s := range b.Summaries {
+			t.row(append([]string{b.Branch, s.Author}, summaryCells(s)...)...)
+		}
+	}
+
+	return t.write(w)
+}
+
+// writeReleaseTables writes the human-readable 'tags' report to w, a
+// table of the summary of each release headed by its name and range.
+func writeReleaseTables(w io.Writer, releases []releaseSummary) error {
+	for i, r := range releases {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, " %s (%s)", r.Name, r.Range)
+		if !r.Totals.First.IsZero() {
+			fmt.Fprintf(w, ", %s to %s",
+				r.Totals.First.Format("2006-01-02"),
+				r.Totals.Last.Format("2006-01-02"),
+			)
+		}
+		fmt.Fprint(w, "\n\n")
+
+		t := newTable(1, "Author", "Commits", "Additions", "Deletions", "Line ratio", "Commit ratio", "Granularity")
+		for _, s := range r.Summaries {
+			t.row(append([]string{s.Author}, summaryCells(s)...)...)
+		}
+		if err := t.write(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// summaryCells returns the table cells of the numeric columns of a
+// summary.
+func summaryCells(s AuthorSummary) []string {
+	return []string{
+		strconv.Itoa(s.Commits),
+		strconv.Itoa(s.Additions),
+		strconv.Itoa(s.Deletions),
+		fmt.Sprintf("%.3f", s.LineRatio),
+		fmt.Sprintf("%.3f", s.CommitRatio),
+		fmt.Sprintf("%.3f", s.Granularity),
+	}
+}
@@ -0,0 +1,224 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_WriteSummaryYAML(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Svein-Kåre Bjørnsen", Commits: 7, LineRatio: 0.5},
+	}
+
+	buf := new(bytes.Buffer)
+	err := writeSummaryYAML(buf, summaries, Totals{Commits: 7}, renderOptions{})
+	if err != nil {
+		t.Fatalf("error rendering yaml: %s", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "!!binary") {
+		t.Errorf("unicode author name was binary encoded:\n%s", out)
+	}
+	for _, exp := range []string{
+		"author: Svein-Kåre Bjørnsen",
+		"line_ratio: 0.5",
+		"totals:",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in output:\n%s", exp, out)
+		}
+	}
+}
+
+func Test_WriteSummaryNDJSON(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author One", Commits: 3},
+		{Author: "Author Two", Commits: 1},
+	}
+
+	buf := new(bytes.Buffer)
+	err := writeSummaryNDJSON(buf, summaries, Totals{Commits: 4}, renderOptions{})
+	if err != nil {
+		t.Fatalf("error rendering ndjson: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got:\n%s", buf)
+	}
+	if !strings.HasPrefix(lines[0], `{"author":"Author One","commits":3,`) {
+		t.Errorf("unexpected first line: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[2], `{"_total":true,"commits":4,`) {
+		t.Errorf("unexpected totals line: %s", lines[2])
+	}
+}
+
+func Test_DeletionsPerAddition(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Writer", Commits: 2, Additions: 40, Deletions: 10},
+		{Author: "Remover", Commits: 1, Deletions: 25},
+		{Author: "Empty", Commits: 1},
+	}
+
+	if r := summaries[0].DeletionsPerAddition(); r != 0.25 {
+		t.Errorf("Expected ratio 0.25, got %v", r)
+	}
+	if r := summaries[1].DeletionsPerAddition(); !math.IsInf(r, 1) {
+		t.Errorf("Expected infinite ratio for author without additions, got %v", r)
+	}
+	if r := summaries[2].DeletionsPerAddition(); r != 0 {
+		t.Errorf("Expected ratio 0 for author without line changes, got %v", r)
+	}
+
+	rows := summaryRows(summaries, renderOptions{extra: true})
+	var cells []string
+	for _, row := range rows {
+		cells = append(cells, row[len(row)-1])
+	}
+	exp := []string{"Del/add ratio", "0.250", "∞", ""}
+	if !reflect.DeepEqual(cells, exp) {
+		t.Errorf("Expected column %q, got %q", exp, cells)
+	}
+}
+
+func Test_SelfRatios(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Builder", Commits: 2, Additions: 80, Deletions: 20},
+		{Author: "Remover", Commits: 1, Deletions: 25},
+		{Author: "Empty", Commits: 1},
+	}
+
+	if r := summaries[0].AdditionShare(); r != 0.8 {
+		t.Errorf("Expected addition share 0.8, got %v", r)
+	}
+	if r := summaries[0].DeletionShare(); r != 0.2 {
+		t.Errorf("Expected deletion share 0.2, got %v", r)
+	}
+	if r := summaries[2].AdditionShare(); r != 0 {
+		t.Errorf("Expected share 0 for author without line changes, got %v", r)
+	}
+
+	rows := summaryRows(summaries, renderOptions{selfRatios: true})
+	var cells [][]string
+	for _, row := range rows {
+		cells = append(cells, row[len(row)-2:])
+	}
+	exp := [][]string{{"Add %", "Del %"}, {"80.0%", "20.0%"}, {"0.0%", "100.0%"}, {"", ""}}
+	if !reflect.DeepEqual(cells, exp) {
+		t.Errorf("Expected columns %q, got %q", exp, cells)
+	}
+
+	cols, err := parseColumns("author,addition_share")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := writeSummaryNDJSON(buf, summaries[1:], Totals{}, renderOptions{columns: cols}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Split(buf.String(), "\n")[:2]; !reflect.DeepEqual(got, []string{
+		`{"author":"Remover","addition_share":0}`,
+		`{"author":"Empty","addition_share":null}`,
+	}) {
+		t.Errorf("unexpected json shares: %q", got)
+	}
+}
+
+func Test_WriteSummaryJSONTotals(t *testing.T) {
+	summaries := []AuthorSummary{{Author: "Author One", Commits: 3, Additions: 10}}
+
+	buf := new(bytes.Buffer)
+	if err := writeSummaryJSON(buf, summaries, Totals{Commits: 3, Additions: 10}, renderOptions{}); err != nil {
+		t.Fatalf("error rendering json: %s", err)
+	}
+
+	var doc struct {
+		Authors []map[string]interface{}
+		Totals  map[string]interface{}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("error decoding json: %s", err)
+	}
+	if len(doc.Authors) != 1 || doc.Totals["commits"] != 3.0 || doc.Totals["additions"] != 10.0 {
+		t.Errorf("Expected the authors followed by the totals object, got: %s", buf)
+	}
+}
+
+func Test_WriteSummaryPrettyJSON(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Bravo", Commits: 2, Additions: 5},
+		{Author: "Alpha", Commits: 3, Additions: 10},
+		{Author: "Charlie", Commits: 2, Additions: 5},
+	}
+	totals := Totals{Commits: 7, Additions: 20}
+
+	render := func(summaries []AuthorSummary) string {
+		buf := new(bytes.Buffer)
+		if err := writeSummaryPrettyJSON(buf, summaries, totals, renderOptions{}); err != nil {
+			t.Fatalf("error rendering json: %s", err)
+		}
+		return buf.String()
+	}
+
+	first := render(summaries)
+	reordered := []AuthorSummary{summaries[2], summaries[0], summaries[1]}
+	if second := render(reordered); second != first {
+		t.Errorf("Expected byte-identical json of two runs, got:\n%s\nand:\n%s", first, second)
+	}
+
+	if !strings.HasPrefix(first, "{\n  \"authors\": [\n    {\n      \"author\": \"Alpha\",\n      \"commits\": 3,") {
+		t.Errorf("Expected indented json with the authors sorted by name, got:\n%s", first)
+	}
+	if a, b, c := strings.Index(first, "Alpha"), strings.Index(first, "Bravo"), strings.Index(first, "Charlie"); !(a < b && b < c) {
+		t.Errorf("Expected the authors sorted by name, got:\n%s", first)
+	}
+	if summaries[0].Author != "Bravo" {
+		t.Errorf("Expected the summaries left unsorted, got %v", summaries)
+	}
+
+	got, _, err := readReport(strings.NewReader(first))
+	if err != nil || len(got) != 3 {
+		t.Errorf("Expected the json read back, got %v, %v", got, err)
+	}
+}
+
+func Test_Thousands(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Alpha", Commits: 1234, Additions: 3897123, Deletions: 999, LineRatio: 0.9},
+		{Author: "Bravo", Commits: 5, Additions: 1000, Deletions: 12345, LineRatio: 0.1},
+	}
+	totals := Totals{Commits: 1239, Additions: 3898123, Deletions: 13344}
+	opts := renderOptions{grouped: true, noFooter: true}
+
+	var table bytes.Buffer
+	if err := writeSummaryTable(&table, summaries, totals, opts); err != nil {
+		t.Fatalf("error rendering table: %s", err)
+	}
+	for _, exp := range []string{"1,234", "3,897,123", " 999 ", "1,000", "12,345", "0.900"} {
+		if !strings.Contains(table.String(), exp) {
+			t.Errorf("Expected %q in the table, got:\n%s", exp, table.String())
+		}
+	}
+
+	for _, format := range []string{"json", "plain"} {
+		var buf bytes.Buffer
+		if err := renderers[format](&buf, summaries, totals, opts); err != nil {
+			t.Fatalf("error rendering %s: %s", format, err)
+		}
+		if !strings.Contains(buf.String(), "3897123") || strings.Contains(buf.String(), "3,897,123") {
+			t.Errorf("Expected plain integers in the %s format, got:\n%s", format, buf.String())
+		}
+	}
+
+	for n, exp := range map[string]string{"0": "0", "999": "999", "1000": "1,000", "-123456": "-123,456", "1234567": "1,234,567"} {
+		if got := groupDigits(n); got != exp {
+			t.Errorf("Expected %s grouped as %s, got %s", n, exp, got)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"time"
+)
+
+// Repo is a git repository opened for analysis. The fields select the
+// history analysed by its methods, and can be changed between analyses.
+type Repo struct {
+	// Path is the top-level directory of the repo, or the git directory
+	// of a bare repo.
+	Path string
+
+	// Branch is the branch, or any other revision or range, to analyse.
+	// The checked out branch is analysed when empty.
+	Branch string
+
+	// Since and Until limit the analysis to the commits committed after
+	// and before them, unless zero.
+	Since time.Time
+	Until time.Time
+
+	// UseMailmap reports the authors as mapped by the .mailmap file of the
+	// repo, merging the identities of the same person.
+	UseMailmap bool
+
+	// FirstParent only follows the first parent of merge commits.
+	FirstParent bool
+
+	// CommitterDates dates the commits by when they were committed rather
+	// than authored, which differ for rebased and cherry-picked commits.
+	CommitterDates bool
+
+	// flags holds the options of the commands not selected by the fields.
+	flags options
+}
+
+// Open returns the repo containing the directory at path, using the
+// mailmap. It is an error if path is not in a git repository.
+func Open(path string) (*Repo, error) {
+	top, _, err := getRepoPath(options{dir: path})
+	if err != nil {
+		return nil, fmt.Errorf("error opening repo %s: %w", path, err)
+	}
+
+	return &Repo{Path: top, UseMailmap: true}, nil
+}
+
+// repoFor returns the repo analysed by the commands given the options o
+// from their flags. The dates of o are kept as given, for git to parse.
+func repoFor(o options) *Repo {
+	return &Repo{
+		Path:           o.dir,
+		Branch:         o.rev,
+		UseMailmap:     !o.noMailmap,
+		FirstParent:    o.firstParent,
+		CommitterDates: o.dateType == "committer",
+		flags:          o,
+	}
+}
+
+// options returns the options analysing the history selected by the
+// fields of r, along with the other flags of the commands.
+func (r *Repo) options() options {
+	o := r.flags
+	o.dir = r.Path
+	o.rev = r.Branch
+	o.firstParent = r.FirstParent
+	o.noMailmap = !r.UseMailmap
+	if r.CommitterDates {
+		o.dateType = "committer"
+	} else if o.dateType == "committer" {
+		o.dateType = "author"
+	}
+	if !r.Since.IsZero() {
+		o.since = r.Since.Format(time.RFC3339)
+	}
+	if !r.Until.IsZero() {
+		o.until = r.Until.Format(time.RFC3339)
+	}
+	return o
+}
+
+// collect returns the report of the commands, as collected by collect
+// from the history selected by r.
+func (r *Repo) collect(collect func(options) ([]AuthorSummary, Totals, error)) ([]AuthorSummary, Totals, error) {
+	return collect(r.options())
+}
+
+// AuthorCommits returns the number of non-merge commits of each author.
+func (r *Repo) AuthorCommits() (map[string]int, error) {
+	return authorCommits(r.options())
+}
+
+// MapLineChanges returns the line changes of each author, summed over the
+// commits walked by WalkCommits.
+func (r *Repo) MapLineChanges() (map[string]LineChanges, error) {
+	lineChangesMap := make(map[string]LineChanges)
+	err := r.WalkCommits(func(c Commit) error {
+		lineChangesMap[c.Author] = lineChangesMap[c.Author].Merge(c.LineChanges())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error extracting line changes: %w", err)
+	}
+	return lineChangesMap, nil
+}
+
+// DateSpan returns the dates of the first and last commits, by author
+// date unless CommitterDates is set. ErrNoCommits is returned when there
+// are none.
+func (r *Repo) DateSpan() (first, last time.Time, err error) {
+	return dateSpan(r.options())
+}
+
+// Summarize returns the summary of each author, sorted as by Summarize,
+// along with the repo-wide totals and the dates of the first and last
+// commits, all from a single walk of the commits by WalkCommits.
+func (r *Repo) Summarize() ([]AuthorSummary, Totals, error) {
+	commitMap := make(map[string]int)
+	lineChangesMap := make(map[string]LineChanges)
+	var first, last time.Time
+	err := r.WalkCommits(func(c Commit) error {
+		if first.IsZero() || c.Date.Before(first) {
+			first = c.Date
+		}
+		if last.IsZero() || c.Date.After(last) {
+			last = c.Date
+		}
+		if c.IsMerge() {
+			return nil
+		}
+		commitMap[c.Author]++
+		lineChangesMap[c.Author] = lineChangesMap[c.Author].Merge(c.LineChanges())
+		return nil
+	})
+	if err != nil {
+		return nil, Totals{}, fmt.Errorf("error walking commits: %w", err)
+	}
+	if first.IsZero() {
+		return nil, Totals{}, ErrNoCommits
+	}
+
+	summaries, totals, err := Summarize(commitMap, lineChangesMap)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+	totals.First, totals.Last = first, last
+
+	return summaries, totals, nil
+}
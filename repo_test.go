@@ -0,0 +1,70 @@
+package gitcontrib
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_Repo(t *testing.T) {
+	r := newTestRepo(t)
+	r.gitEnv([]string{"GIT_COMMITTER_DATE=2021-01-01T10:00:00Z", "GIT_AUTHOR_DATE=2021-01-01T10:00:00Z"},
+		"commit", "-q", "--allow-empty", "-m", "old")
+	r.commit("Old Name", "nested/one.txt", "1\n2\n")
+	r.commit("New Name", ".mailmap", "New Name <test@example.com> Old Name <test@example.com>\n")
+
+	repo, err := Open(filepath.Join(r.dir, "nested"))
+	if err != nil {
+		t.Fatalf("error opening repo: %s", err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(r.dir); repo.Path != resolved {
+		t.Errorf("Expected the top-level directory %s, got: %s", resolved, repo.Path)
+	}
+
+	commits, err := repo.AuthorCommits()
+	if err != nil {
+		t.Fatalf("error getting author commits: %s", err)
+	}
+	if commits["New Name"] != 2 || len(commits) != 2 {
+		t.Errorf("Expected the mailmapped name, got: %v", commits)
+	}
+
+	repo.UseMailmap = false
+	lineChanges, err := repo.MapLineChanges()
+	if err != nil {
+		t.Fatalf("error getting line changes: %s", err)
+	}
+	if lineChanges["Old Name"].Additions != 2 {
+		t.Errorf("Expected the name as committed, got: %v", lineChanges)
+	}
+
+	repo.Since = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	summaries, totals, err := repo.Summarize()
+	if err != nil {
+		t.Fatalf("error summarizing: %s", err)
+	}
+	if totals.Commits != 2 || len(summaries) != 2 {
+		t.Errorf("Expected the old commit to be left out, got: %+v, %+v", summaries, totals)
+	}
+
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Error("Expected error opening a directory outside any repo")
+	}
+}
+
+func Test_RepoFor(t *testing.T) {
+	o := options{dir: "dir", rev: "main", since: "2 weeks ago", dateType: "committer", maxCommitLines: 10}
+	r := repoFor(o)
+	if r.Path != "dir" || r.Branch != "main" || !r.UseMailmap || !r.CommitterDates {
+		t.Errorf("Expected the repo selected by the flags, got: %+v", r)
+	}
+	if got := r.options(); got.since != "2 weeks ago" || got.dateType != "committer" || got.maxCommitLines != 10 {
+		t.Errorf("Expected the flags kept, got: %+v", got)
+	}
+
+	r.Branch = "v1.0"
+	r.CommitterDates = false
+	if got := r.options(); got.rev != "v1.0" || got.dateType != "author" {
+		t.Errorf("Expected the fields to override the flags, got: %+v", got)
+	}
+}
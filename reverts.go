@@ -0,0 +1,131 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// revertFormat is the git log format used for finding revert commits.
+// Each commit starts with a NUL byte followed by its hash and subject on
+// lines of their own, followed by its body.
+const revertFormat = "--format=%x00%H%n%s%n%b"
+
+// revertLine matches the line git revert writes in the body of a revert
+// commit, naming the commit it reverts.
+var revertLine = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{7,40})`)
+
+// findReverts returns the revert commits in the revision or range of o,
+// using HEAD when none is given, by hash.
+func findReverts(o options) (map[string]string, error) {
+	out, err := o.walk("log", revertFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReverts(out), nil
+}
+
+// parseReverts returns the revert commits in the output of git log with
+// revertFormat, mapping the hash of each to the hash of the commit it
+// reverts, which is empty when its body doesn't name it. A commit is taken
+// for a revert when its subject starts with `Revert "`, as written by git
+// revert, or its body has a "This reverts commit" line.
+func parseReverts(gitOutput string) map[string]string {
+	reverts := make(map[string]string)
+
+	for _, record := range strings.Split(gitOutput, "\x00") {
+		lines := strings.SplitN(record, "\n", 3)
+		if len(lines) < 2 || lines[0] == "" {
+			continue
+		}
+
+		var body string
+		if len(lines) == 3 {
+			body = lines[2]
+		}
+
+		m := revertLine.FindStringSubmatch(body)
+		switch {
+		case m != nil:
+			reverts[lines[0]] = m[1]
+		case strings.HasPrefix(lines[1], `Revert "`):
+			reverts[lines[0]] = ""
+		}
+	}
+
+	return reverts
+}
+
+// excludeReverts returns the commits without the line changes of the
+// reverts, and with --exclude-reverted also of the commits they revert,
+// whose changes then cancel out, along with the number of reverts and of
+// those paired with the commit they revert. The commits themselves are
+// kept, still counting as commits. A revert names the commit it reverts
+// by its full hash, or by a prefix of it.
+func excludeReverts(commits []commit, reverts map[string]string, reverted bool) ([]commit, int, int) {
+	excluded := make(map[string]bool)
+	var n, pairs int
+	for _, c := range commits {
+		target, ok := reverts[c.Hash]
+		if !ok {
+			continue
+		}
+		excluded[c.Hash] = true
+		n++
+
+		if !reverted || target == "" {
+			continue
+		}
+		for _, r := range commits {
+			if strings.HasPrefix(r.Hash, target) {
+				excluded[r.Hash] = true
+				pairs++
+				break
+			}
+		}
+	}
+
+	for i, c := range commits {
+		if excluded[c.Hash] {
+			commits[i].Files = nil
+		}
+	}
+
+	return commits, n, pairs
+}
+
+// skipReverts returns the commits of the history of o without the line
+// changes of the reverts with --exclude-reverts, finding them in a second
+// pass over the history, along with the number of reverts and of pairs
+// excluded.
+func (o options) skipReverts(commits []commit) ([]commit, int, int, error) {
+	if !o.excludeReverts {
+		return commits, 0, 0, nil
+	}
+
+	reverts, err := findReverts(o)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	commits, n, pairs := excludeReverts(commits, reverts, o.excludeReverted)
+	return commits, n, pairs, nil
+}
+
+// logReverts reports the numbers of reverts and pairs excluded by
+// skipReverts.
+func (o options) logReverts(n, pairs int) {
+	switch {
+	case !o.excludeReverts:
+	case !o.excludeReverted:
+		logf(slog.LevelInfo, "excluded the line changes of %d reverts", n)
+	case n > pairs:
+		logf(slog.LevelInfo, "excluded the line changes of %d revert pairs, and of %d reverts of commits outside the history", pairs, n-pairs)
+	default:
+		logf(slog.LevelInfo, "excluded the line changes of %d revert pairs", pairs)
+	}
+}
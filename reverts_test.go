@@ -0,0 +1,84 @@
+package gitcontrib
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ParseReverts(t *testing.T) {
+	buf, err := os.ReadFile("testdata/revert-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string]string{
+		"c3c3c3": "a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1a1",
+		"b2b2b2": "",
+		"e5e5e5": "0123abc",
+	}
+	if got := parseReverts(string(buf)); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+}
+
+func Test_ExcludeReverts(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.commit("Author One", "two.txt", "1\n2\n3\n")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Author Two"}, "revert", "--no-edit", "HEAD")
+
+	lines := func(reverts, reverted bool) map[string]LineChanges {
+		t.Helper()
+		o := r.options()
+		o.excludeReverts, o.excludeReverted = reverts, reverted
+		lineChanges, err := mapLineChanges(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lineChanges
+	}
+
+	exp := map[string]LineChanges{"Author One": {4, 0}, "Author Two": {0, 3}}
+	if got := lines(false, false); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected the revert to count, got %v", got)
+	}
+
+	exp = map[string]LineChanges{"Author One": {4, 0}, "Author Two": {}}
+	if got := lines(true, false); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected the revert to be skipped, got %v", got)
+	}
+
+	// the reverted change cancels out, leaving only the first commit
+	exp = map[string]LineChanges{"Author One": {1, 0}, "Author Two": {}}
+	if got := lines(true, true); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected the revert pair to cancel out, got %v", got)
+	}
+
+	o := r.options()
+	o.excludeReverts, o.excludeReverted = true, true
+	counts, err := authorCommits(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["Author One"] != 2 || counts["Author Two"] != 1 {
+		t.Errorf("Expected the reverts to still count as commits, got %v", counts)
+	}
+
+	commits, err := logCommits(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total LineChanges
+	for _, c := range commits {
+		total = total.Merge(c.LineChanges())
+	}
+	if total != (LineChanges{1, 0}) {
+		t.Errorf("Expected the commits to agree, got %v", total)
+	}
+
+	if !strings.HasPrefix(commits[0].Subject, `Revert "`) {
+		t.Errorf("Expected the revert first, got %q", commits[0].Subject)
+	}
+}
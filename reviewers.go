@@ -0,0 +1,61 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"strings"
+)
+
+// reviewFormat is the git log format used for counting reviews. Each
+// commit starts with a NUL byte followed by its author on a line of its
+// own, followed by its review trailers.
+var reviewFormat = "--format=%x00%aN%n" + trailersFormat("Reviewed-by", "Signed-off-by")
+
+// reviewCounts returns the number of commits reviewed by each person in
+// the revision or range of o, using HEAD when none is given.
+func reviewCounts(o options) (map[string]int, error) {
+	out, err := o.walk("log", o.logFormat(reviewFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReviewers(out), nil
+}
+
+// parseReviewers counts the commits reviewed by each person in the output
+// of git log with reviewFormat. A Reviewed-by trailer always counts as a
+// review, while a Signed-off-by trailer only does when signed by someone
+// other than the author, like a maintainer applying the commit. Each
+// person is counted at most once per commit.
+func parseReviewers(gitOutput string) map[string]int {
+	counts := make(map[string]int)
+
+	for _, record := range strings.Split(gitOutput, "\x00") {
+		author, lines, _ := strings.Cut(record, "\n")
+		if author == "" {
+			continue
+		}
+
+		reviewed := make(map[string]bool)
+		for _, t := range parseTrailers(lines) {
+			name := trailerName(t.Value)
+			if name == "" {
+				continue
+			}
+
+			switch {
+			case strings.EqualFold(t.Key, "Reviewed-by"):
+				reviewed[name] = true
+			case strings.EqualFold(t.Key, "Signed-off-by") && name != author:
+				reviewed[name] = true
+			}
+		}
+
+		for name := range reviewed {
+			counts[name]++
+		}
+	}
+
+	return counts
+}
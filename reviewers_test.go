@@ -0,0 +1,34 @@
+package gitcontrib
+
+import "testing"
+
+func Test_ParseReviewers(t *testing.T) {
+	output := "\x00Author One\n" +
+		"Reviewed-by: Reviewer One <one@example.com>\n" +
+		"Reviewed-by: Reviewer Two <two@example.com>\n" +
+		"Signed-off-by: Author One <author@example.com>\n" +
+		"\n" +
+		"\x00Author Two\n" +
+		"\n" +
+		"\x00Author Two\n" +
+		"Reviewed-by: Reviewer One <one@example.com>\n" +
+		"Signed-off-by: Reviewer One <one@example.com>\n" +
+		"Signed-off-by: Maintainer\n" +
+		"\n"
+
+	counts := parseReviewers(output)
+
+	expected := map[string]int{
+		"Reviewer One": 2,
+		"Reviewer Two": 1,
+		"Maintainer":   1,
+	}
+	if len(counts) != len(expected) {
+		t.Errorf("Expected %v, got: %v", expected, counts)
+	}
+	for k, v := range expected {
+		if counts[k] != v {
+			t.Errorf("Expected %d reviews for %s, got: %d", v, k, counts[k])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// findRepos returns the paths of the git repos in and below dir, sorted.
+// Repos with a work tree are found by their .git entry, and bare repos by
+// their HEAD file next to an objects directory. The directories of a repo
+// are not searched further, so nested repos and submodules are left out.
+func findRepos(dir string) ([]string, error) {
+	var repos []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if isRepo(path) {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(repos)
+	return repos, nil
+}
+
+// isRepo reports whether dir is the top-level directory of a repo, or a
+// bare repo.
+func isRepo(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+
+	head, err := os.Stat(filepath.Join(dir, "HEAD"))
+	if err != nil || head.IsDir() {
+		return false
+	}
+	objects, err := os.Stat(filepath.Join(dir, "objects"))
+	return err == nil && objects.IsDir()
+}
+
+// summarizeAll returns the 'summary' report of each repo in dirs, with
+// the other options of o, like summarize does for a single repo. The repos
+// are analysed at the same time, as many as jobs, and the error analysing
+// a repo is kept in its report rather than stopping the others.
+func (cf *csvFlags) summarizeAll(o options, ff *filterFlags, dirs []string, jobs int) []repoSummary {
+	repos := make([]repoSummary, len(dirs))
+	parallel(len(dirs), jobs, func(i int) error {
+		ro := o
+		ro.dir = dirs[i]
+		repos[i], repos[i].err = cf.summarize(ro, ff)
+		return nil
+	})
+
+	return repos
+}
+
+// failedRepos logs the errors of the repos that couldn't be analysed and
+// returns their number. Repos without commits are skipped rather than
+// failing.
+func failedRepos(repos []repoSummary) int {
+	var failed int
+	for _, r := range repos {
+		if r.err == nil {
+			continue
+		}
+		if errors.Is(r.err, ErrNoCommits) {
+			debugf("skipping %s: %s", r.dir, r.err)
+			continue
+		}
+		Logger.Error(fmt.Sprintf("error analysing %s: %s", r.dir, r.err))
+		failed++
+	}
+
+	return failed
+}
@@ -0,0 +1,67 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_RecursiveCsvSummary(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"zeta", "alpha", "group/mid"} {
+		r := &testRepo{t, filepath.Join(root, name)}
+		if err := os.MkdirAll(r.dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		r.git("init", "-q", "-b", "main")
+		r.commit("Author "+name, "file.txt", "1\n")
+		r.commit("Author "+name, "nested/file.txt", "1\n")
+	}
+	empty := &testRepo{t, filepath.Join(root, "empty")}
+	if err := os.MkdirAll(empty.dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	empty.git("init", "-q")
+	if err := os.MkdirAll(filepath.Join(root, "broken"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "broken", ".git"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := findRepos(root)
+	if err != nil {
+		t.Fatalf("error finding repos: %s", err)
+	}
+	var rel []string
+	for _, r := range repos {
+		p, _ := filepath.Rel(root, r)
+		rel = append(rel, filepath.ToSlash(p))
+	}
+	if exp := []string{"alpha", "broken", "empty", "group/mid", "zeta"}; !reflect.DeepEqual(rel, exp) {
+		t.Errorf("Expected repos %v, got: %v", exp, rel)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.csv")
+	err = CsvContributionSummaryCmd.Call(CsvContributionSummaryCmd,
+		"--recursive", "--jobs", "2", "--repo", root, "--root", root, "--output", out)
+	if err == nil || !strings.Contains(err.Error(), "1 of 5 repos failed") {
+		t.Errorf("Expected the broken repo to fail the scan, got: %v", err)
+	}
+
+	buf, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	exp := []string{
+		`"alpha","Author alpha",2,2,0,1.000,1.000,1.000`,
+		`"group/mid","Author group/mid",2,2,0,1.000,1.000,1.000`,
+		`"zeta","Author zeta",2,2,0,1.000,1.000,1.000`,
+	}
+	if !reflect.DeepEqual(lines, exp) {
+		t.Errorf("Expected %q, got: %q", exp, lines)
+	}
+}
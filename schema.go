@@ -0,0 +1,147 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaDraft is the JSON schema dialect of the schemas written by
+// reportSchema.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// fieldNotes describes the fields of the machine-readable reports, by type
+// and JSON name, including which flags they are only present with.
+var fieldNotes = map[string]string{
+	"report.authors": "one summary per reported author",
+	"report.totals":  "repo-wide metrics the summaries are relative to",
+
+	"AuthorSummary.author":           "author name",
+	"AuthorSummary.commits":          "non-merge commits",
+	"AuthorSummary.additions":        "added lines",
+	"AuthorSummary.deletions":        "deleted lines",
+	"AuthorSummary.line_ratio":       "share of all line changes in the repo",
+	"AuthorSummary.commit_ratio":     "share of all commits in the repo",
+	"AuthorSummary.granularity":      "commits per changed line",
+	"AuthorSummary.additions_ratio":  "share of all added lines in the repo",
+	"AuthorSummary.deletions_ratio":  "share of all deleted lines in the repo",
+	"AuthorSummary.weighted":         "recency-weighted line changes, only present with --decay",
+	"AuthorSummary.files_touched":    "distinct files touched, only present with --files",
+	"AuthorSummary.files_per_commit": "mean files changed per commit, only present with --breadth",
+	"AuthorSummary.breadth":          "breadth score from 0 to 100 of the commits, files touched and line churn, only present with --breadth",
+	"AuthorSummary.merges":           "merge commits, only present with --count-merges-separately",
+	"AuthorSummary.surviving":        "lines surviving in the code at the end of the history, only present with --surviving",
+	"AuthorSummary.footprint":        "share of all lines surviving in the code, only present with --surviving",
+	"AuthorSummary.hunks":            "distinct blocks of changed lines of the commits, only present with --complexity",
+	"AuthorSummary.complexity":       "line changes weighted by the hunks of each commit, only present with --complexity",
+	"AuthorSummary.activity":         "commits per calendar month from the month of the first commit, only present with the svg format",
+	"AuthorSummary.score":            "line ratio scaled to a score from 0 to 100, only present with --score",
+	"AuthorSummary.rank":             "rank of the author by the metric of --rank-by, tied authors sharing one, only present with --rank",
+
+	"Totals.commits":      "non-merge commits of all authors",
+	"Totals.additions":    "added lines of all authors",
+	"Totals.deletions":    "deleted lines of all authors",
+	"Totals.granularity":  "overall repo commit granularity",
+	"Totals.gini":         "Gini coefficient of the line changes of the authors",
+	"Totals.first_commit": "date of the first commit",
+	"Totals.last_commit":  "date of the last commit",
+}
+
+// reportDescription tells which formats write the report described by
+// the schema, and how.
+const reportDescription = "The report written by the json and yaml formats. " +
+	"The ndjson format writes each author as an AuthorSummary object on a " +
+	"line of its own, followed by a line with the Totals object and a " +
+	`"_total" field set to true.`
+
+// reportSchema returns the JSON schema of the report written by the
+// machine-readable formats, generated from the Go types, so that it
+// always matches them.
+func reportSchema() map[string]any {
+	defs := make(map[string]any)
+	schema := structSchema(reflect.TypeOf(report{}), defs)
+	schema["$schema"] = schemaDraft
+	schema["title"] = "gitcontrib summary report"
+	schema["description"] = reportDescription
+	schema["$defs"] = defs
+	return schema
+}
+
+// typeSchema returns the schema of values of type t. Named structs are
+// added to defs and referenced.
+func typeSchema(t reflect.Type, defs map[string]any) map[string]any {
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // guards against recursive types
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case t.Kind() == reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+// structSchema returns the schema of the JSON object of struct type t. The
+// fields without omitempty are always present, so they are required.
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	props := make(map[string]any)
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		s := typeSchema(f.Type, defs)
+		if note, ok := fieldNotes[t.Name()+"."+name]; ok {
+			s["description"] = note
+		}
+		props[name] = s
+
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// writeSchema writes the JSON schema of the report to w, indented.
+func writeSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(reportSchema()); err != nil {
+		return fmt.Errorf("error encoding schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func Test_ReportSchema(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := writeSchema(buf); err != nil {
+		t.Fatalf("error writing schema: %s", err)
+	}
+
+	var schema struct {
+		Properties map[string]any `json:"properties"`
+		Required   []string       `json:"required"`
+		Defs       map[string]struct {
+			Properties map[string]struct {
+				Type        string `json:"type"`
+				Description string `json:"description"`
+			} `json:"properties"`
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+		t.Fatalf("error parsing schema: %s", err)
+	}
+
+	if len(schema.Required) != 2 || schema.Properties["authors"] == nil {
+		t.Errorf("unexpected report schema: %s", buf)
+	}
+
+	summary := schema.Defs["AuthorSummary"]
+	if summary.Properties["commits"].Type != "integer" || summary.Properties["line_ratio"].Type != "number" {
+		t.Errorf("unexpected types: %+v", summary.Properties)
+	}
+	for _, name := range summary.Required {
+		if name == "weighted" || name == "files_touched" {
+			t.Errorf("Expected optional field %q not to be required", name)
+		}
+	}
+
+	// every field of the encoded report is described by the schema
+	out := new(bytes.Buffer)
+	s := AuthorSummary{Author: "A", Weighted: 1, FilesTouched: 1, Activity: []int{1}}
+	if err := writeSummaryJSON(out, []AuthorSummary{s}, Totals{}, renderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var r struct {
+		Authors []map[string]any `json:"authors"`
+		Totals  map[string]any   `json:"totals"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+		t.Fatal(err)
+	}
+	for name := range r.Authors[0] {
+		if _, ok := summary.Properties[name]; !ok {
+			t.Errorf("field %q of the authors missing from the schema", name)
+		}
+	}
+	for name := range r.Totals {
+		if _, ok := schema.Defs["Totals"].Properties[name]; !ok {
+			t.Errorf("field %q of the totals missing from the schema", name)
+		}
+	}
+
+	// the notes only describe existing fields
+	for key := range fieldNotes {
+		typ, name, _ := strings.Cut(key, ".")
+		if typ == "report" {
+			continue
+		}
+		if schema.Defs[typ].Properties[name].Description == "" {
+			t.Errorf("note %q describes no field", key)
+		}
+	}
+}
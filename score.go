@@ -0,0 +1,45 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "fmt"
+
+// The modes of --score-mode.
+const (
+	scoreRelative = "relative" // the top author scores 100
+	scoreShare    = "share"    // the scores sum to 100
+)
+
+// checkScoreMode returns an error for an unknown --score-mode.
+func checkScoreMode(mode string) error {
+	switch mode {
+	case scoreRelative, scoreShare:
+		return nil
+	}
+	return fmt.Errorf("invalid --score-mode %q, must be %s or %s", mode, scoreRelative, scoreShare)
+}
+
+// setScores sets the scores of the summaries, scaling their line ratios
+// to the range from 0 to 100. In relative mode, the author with the
+// highest line ratio scores 100 and the others in proportion. In share
+// mode, the scores are the shares of the summaries in their line ratios,
+// summing to 100, even when other authors were left out of the report.
+// Without any line changes, all scores are 0.
+func setScores(summaries []AuthorSummary, mode string) {
+	var scale float64
+	for _, s := range summaries {
+		if mode == scoreShare {
+			scale += s.LineRatio
+		} else if s.LineRatio > scale {
+			scale = s.LineRatio
+		}
+	}
+
+	for i, s := range summaries {
+		summaries[i].Score = 0
+		if scale > 0 {
+			summaries[i].Score = 100 * s.LineRatio / scale
+		}
+	}
+}
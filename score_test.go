@@ -0,0 +1,47 @@
+package gitcontrib
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_SetScores(t *testing.T) {
+	tests := []struct {
+		mode string
+		exp  []float64
+	}{
+		{scoreRelative, []float64{100, 50, 25, 0}},
+		{scoreShare, []float64{400.0 / 7, 200.0 / 7, 100.0 / 7, 0}},
+	}
+
+	for _, tt := range tests {
+		summaries := []AuthorSummary{
+			{Author: "One", LineRatio: 0.4},
+			{Author: "Two", LineRatio: 0.2},
+			{Author: "Three", LineRatio: 0.1},
+			{Author: "Four"},
+		}
+		setScores(summaries, tt.mode)
+
+		var sum float64
+		for i, s := range summaries {
+			sum += s.Score
+			if math.Abs(s.Score-tt.exp[i]) > 1e-9 {
+				t.Errorf("%s: expected %s to score %.3f, got %.3f", tt.mode, s.Author, tt.exp[i], s.Score)
+			}
+		}
+		if tt.mode == scoreShare && math.Abs(sum-100) > 1e-9 {
+			t.Errorf("Expected the shares to sum to 100, got %.3f", sum)
+		}
+	}
+
+	empty := []AuthorSummary{{Author: "One"}}
+	setScores(empty, scoreRelative)
+	if empty[0].Score != 0 {
+		t.Errorf("Expected no score without line changes, got %.3f", empty[0].Score)
+	}
+
+	if err := checkScoreMode("top"); err == nil {
+		t.Error("Expected an unknown mode to be rejected")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// selfCheckRepo is the repo field of the CSV rows written by roundTrip.
+const selfCheckRepo = "selfcheck"
+
+// roundTrip renders the summaries and totals to the csv summary rows and
+// to the json and ndjson formats, reads each back, with encoding/csv and
+// readReport, and returns an error listing the differences when the
+// records read back don't match those rendered. The CSV rows only hold
+// the default fields, and are written at full precision, as with
+// --precision -1.
+func roundTrip(summaries []AuthorSummary, totals Totals) error {
+	var errs []error
+
+	cf := csvFlags{precision: -1}
+	var buf bytes.Buffer
+	for _, s := range summaries {
+		fmt.Fprintln(&buf, cf.row(selfCheckRepo, s))
+	}
+	read, err := readCSVRows(&buf)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("csv: %w", err))
+	} else {
+		want := make([]AuthorSummary, len(summaries))
+		for i, s := range summaries {
+			want[i] = csvSummary(s)
+		}
+		if err := diffSummaries(want, read); err != nil {
+			errs = append(errs, fmt.Errorf("csv: %w", err))
+		}
+	}
+
+	for _, format := range []string{"json", "ndjson"} {
+		buf.Reset()
+		if err := renderers[format](&buf, summaries, totals, renderOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", format, err))
+			continue
+		}
+		read, readTotals, err := readReport(&buf)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", format, err))
+			continue
+		}
+		if err := diffSummaries(summaries, read); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", format, err))
+		}
+		if diff := diffFields(totals, readTotals); len(diff) > 0 {
+			errs = append(errs, fmt.Errorf("%s: totals: %s", format, strings.Join(diff, ", ")))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// csvSummary returns the summary holding only the fields of the default
+// csv summary rows.
+func csvSummary(s AuthorSummary) AuthorSummary {
+	return AuthorSummary{
+		Author:      s.Author,
+		Commits:     s.Commits,
+		Additions:   s.Additions,
+		Deletions:   s.Deletions,
+		LineRatio:   s.LineRatio,
+		CommitRatio: s.CommitRatio,
+		Granularity: s.Granularity,
+	}
+}
+
+// readCSVRows reads the default csv summary rows of a repo back into
+// summaries, following the repo field.
+func readCSVRows(r io.Reader) ([]AuthorSummary, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 8
+
+	var summaries []AuthorSummary
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return summaries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s := AuthorSummary{Author: record[1]}
+		ints := []*int{&s.Commits, &s.Additions, &s.Deletions}
+		for i, p := range ints {
+			if *p, err = strconv.Atoi(record[2+i]); err != nil {
+				return nil, fmt.Errorf("row %d: %w", n, err)
+			}
+		}
+		floats := []*float64{&s.LineRatio, &s.CommitRatio, &s.Granularity}
+		for i, p := range floats {
+			if *p, err = strconv.ParseFloat(record[5+i], 64); err != nil {
+				return nil, fmt.Errorf("row %d: %w", n, err)
+			}
+		}
+		summaries = append(summaries, s)
+	}
+}
+
+// diffSummaries returns an error listing the fields of the summaries read
+// back that differ from those rendered, or nil when they all match.
+func diffSummaries(want, got []AuthorSummary) error {
+	if len(want) != len(got) {
+		return fmt.Errorf("rendered %d authors, read back %d", len(want), len(got))
+	}
+
+	var diffs []string
+	for i := range want {
+		if diff := diffFields(want[i], got[i]); len(diff) > 0 {
+			diffs = append(diffs, fmt.Sprintf("author %q: %s", want[i].Author, strings.Join(diff, ", ")))
+		}
+	}
+	if len(diffs) > 0 {
+		return errors.New(strings.Join(diffs, "; "))
+	}
+	return nil
+}
+
+// diffFields returns the differences of the fields of two structs of the
+// same type, as "Field: rendered 1, read back 2". Times are compared as
+// instants, as their location isn't kept when written. Unexported fields
+// aren't written, so they are skipped.
+func diffFields(want, got interface{}) []string {
+	w, g := reflect.ValueOf(want), reflect.ValueOf(got)
+
+	var diffs []string
+	for i := 0; i < w.NumField(); i++ {
+		if !w.Type().Field(i).IsExported() {
+			continue
+		}
+		x, y := w.Field(i).Interface(), g.Field(i).Interface()
+		if t, ok := x.(time.Time); ok {
+			if t.Equal(y.(time.Time)) {
+				continue
+			}
+		} else if reflect.DeepEqual(x, y) {
+			continue
+		}
+		diffs = append(diffs, fmt.Sprintf("%s: rendered %v, read back %v", w.Type().Field(i).Name, x, y))
+	}
+
+	return diffs
+}
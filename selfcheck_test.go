@@ -0,0 +1,43 @@
+package gitcontrib
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_RoundTrip(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(`Author "Quoted" One`, "a.txt", "1\n2\n3\n")
+	r.commit("Two, Author", "b.txt", "1\n")
+	r.commit("Jörg Three", "c.txt", "1\n2\n")
+	r.commit("Jörg Three", "a.txt", "1\n")
+
+	var ff filterFlags
+	summaries, totals, err := ff.collect(r.options())
+	if err != nil {
+		t.Fatalf("error summarizing: %s", err)
+	}
+
+	if err := roundTrip(summaries, totals); err != nil {
+		t.Errorf("Expected the reports to read back as rendered, got: %s", err)
+	}
+}
+
+func Test_RoundTripMismatch(t *testing.T) {
+	want := []AuthorSummary{{Author: "One", Commits: 2, LineRatio: 1.0 / 3}}
+	got := []AuthorSummary{{Author: "One", Commits: 2, LineRatio: 0.333}}
+
+	err := diffSummaries(want, got)
+	if err == nil || !strings.Contains(err.Error(), `author "One": LineRatio: rendered 0.3333333333333333, read back 0.333`) {
+		t.Errorf("Expected the precision loss to be reported, got: %v", err)
+	}
+
+	if err := diffSummaries(want, nil); err == nil {
+		t.Error("Expected the missing authors to be reported")
+	}
+
+	// unescaped quotes, as the csv rows used to be written
+	if _, err := readCSVRows(strings.NewReader(`"repo","Author "Quoted"",1,1,0,1,1,1` + "\n")); err == nil {
+		t.Error("Expected the unescaped quotes to fail parsing")
+	}
+}
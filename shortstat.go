@@ -0,0 +1,149 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultShortstatThreshold is the share of the line changes by which the
+// totals of numstat and shortstat may differ with --shortstat, unless
+// another is given with --shortstat-threshold. Both read the same diffs,
+// so any difference tells a parser bug.
+const defaultShortstatThreshold = 0
+
+// statTotals holds the number of changed files and the line changes of a
+// history, summed over its commits.
+type statTotals struct {
+	Files int
+	LineChanges
+}
+
+// shortstatPart matches the parts of a line of git log --shortstat, like
+// "3 files changed", "1 insertion(+)" or "2 deletions(-)". The parts
+// without changes may be left out.
+var shortstatPart = regexp.MustCompile(`(\d+) (files? changed|insertions?\(\+\)|deletions?\(-\))`)
+
+// parseShortstat sums the lines of git log --shortstat with an empty
+// format, one per commit changing any file, like
+// " 3 files changed, 10 insertions(+), 2 deletions(-)". It is an error if
+// a line isn't one of them.
+func parseShortstat(gitOutput string) (statTotals, error) {
+	var t statTotals
+	for _, line := range strings.Split(gitOutput, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := shortstatPart.FindAllStringSubmatch(line, -1)
+		if len(parts) == 0 {
+			return statTotals{}, fmt.Errorf("malformed shortstat line: %q", line)
+		}
+		for _, p := range parts {
+			n, _ := strconv.Atoi(p[1])
+			switch p[2][0] {
+			case 'f':
+				t.Files += n
+			case 'i':
+				t.Additions += n
+			case 'd':
+				t.Deletions += n
+			}
+		}
+	}
+	return t, nil
+}
+
+// parseNumstatTotals sums the lines of git log --numstat with an empty
+// format, like parseShortstat, binary files counting as changed files
+// without line changes. It is an error if a line can't be parsed.
+func parseNumstatTotals(gitOutput string) (statTotals, error) {
+	var t statTotals
+	for _, line := range strings.Split(gitOutput, "\n") {
+		if line == "" {
+			continue
+		}
+
+		f, err := parseNumstat(line)
+		if err != nil {
+			return statTotals{}, err
+		}
+		t.Files++
+		t.LineChanges = t.LineChanges.Merge(f.LineChanges)
+	}
+	return t, nil
+}
+
+// agree reports whether the line changes a and b differ by no more than
+// the threshold, a share of those of a.
+func agree(a, b LineChanges, threshold float64) bool {
+	abs := func(n int) int {
+		if n < 0 {
+			return -n
+		}
+		return n
+	}
+	diff := abs(a.Additions-b.Additions) + abs(a.Deletions-b.Deletions)
+
+	return float64(diff) <= threshold*float64(a.Sum())
+}
+
+// reconcileStats returns an ErrParseDrift error when the line changes of
+// the numstat and shortstat totals differ by more than the threshold, a
+// share of the numstat line changes.
+func reconcileStats(numstat, shortstat statTotals, threshold float64) error {
+	if agree(numstat.LineChanges, shortstat.LineChanges, threshold) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: numstat gives +%d -%d in %d files, but shortstat +%d -%d in %d files",
+		ErrParseDrift,
+		numstat.Additions, numstat.Deletions, numstat.Files,
+		shortstat.Additions, shortstat.Deletions, shortstat.Files,
+	)
+}
+
+// verifyShortstat reads the history of o with both git log --numstat and
+// --shortstat, and checks that the totals of the line changes agree
+// within the threshold. The reported line changes, the totals of the
+// report, are checked against those of shortstat as well when given, so
+// that the parsing and summing of the report itself is checked rather than
+// only a second walk. A difference is only warned about, unless o fails on
+// parse drift.
+func verifyShortstat(o options, threshold float64, reported *LineChanges) error {
+	out, err := o.walkDiffs("--numstat", "--format=")
+	if err != nil {
+		return err
+	}
+	numstat, err := parseNumstatTotals(out)
+	if err != nil {
+		return err
+	}
+
+	out, err = o.walkDiffs("--shortstat", "--format=")
+	if err != nil {
+		return err
+	}
+	shortstat, err := parseShortstat(out)
+	if err != nil {
+		return err
+	}
+
+	if err := reconcileStats(numstat, shortstat, threshold); err != nil {
+		return o.drift(err)
+	}
+	debugf("numstat and shortstat agree on +%d -%d in %d files", numstat.Additions, numstat.Deletions, numstat.Files)
+
+	if reported != nil && !agree(*reported, shortstat.LineChanges, threshold) {
+		return o.drift(fmt.Errorf("%w: the report totals +%d -%d, but shortstat +%d -%d",
+			ErrParseDrift, reported.Additions, reported.Deletions, shortstat.Additions, shortstat.Deletions))
+	}
+
+	return nil
+}
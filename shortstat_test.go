@@ -0,0 +1,77 @@
+package gitcontrib
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_ReconcileShortstat(t *testing.T) {
+	buf, err := os.ReadFile("testdata/numstat-example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the author lines are left out by an empty format
+	var numstatLines []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		if strings.Contains(line, "\t") {
+			numstatLines = append(numstatLines, line)
+		}
+	}
+	numstat, err := parseNumstatTotals(strings.Join(numstatLines, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err = os.ReadFile("testdata/shortstat-example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shortstat, err := parseShortstat(string(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if numstat != shortstat {
+		t.Errorf("Expected the formats to agree, got %+v and %+v", numstat, shortstat)
+	}
+	if err := reconcileStats(numstat, shortstat, 0); err != nil {
+		t.Errorf("Expected the totals to reconcile, got: %s", err)
+	}
+
+	off := shortstat
+	off.Deletions += 10
+	if err := reconcileStats(numstat, off, 0); !errors.Is(err, ErrParseDrift) {
+		t.Errorf("Expected %v for diverging totals, got: %v", ErrParseDrift, err)
+	}
+	if err := reconcileStats(numstat, off, 0.01); err != nil {
+		t.Errorf("Expected the difference to be within 1%%, got: %s", err)
+	}
+
+	if _, err := parseShortstat(" 2 files changed\nsomething else\n"); err == nil {
+		t.Error("Expected an error for a malformed shortstat line")
+	}
+}
+
+func Test_VerifyShortstat(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.git("mv", "one.txt", "two.txt")
+	r.git("commit", "-q", "-m", "rename")
+	r.commit("Author Two", "logo.bin", "\x00\x01")
+	r.commit("Author Two", "two.txt", "1\n3\n")
+
+	if err := verifyShortstat(r.options(), 0, nil); err != nil {
+		t.Errorf("Expected numstat and shortstat to agree, got: %s", err)
+	}
+
+	if err := verifyShortstat(r.options(), 0, &LineChanges{Additions: 4, Deletions: 1}); err != nil {
+		t.Errorf("Expected the report totals to agree with shortstat, got: %s", err)
+	}
+	o := r.options()
+	o.failDrift = true
+	if err := verifyShortstat(o, 0, &LineChanges{Additions: 5, Deletions: 1}); !errors.Is(err, ErrParseDrift) {
+		t.Errorf("Expected %v for report totals off shortstat, got: %v", ErrParseDrift, err)
+	}
+}
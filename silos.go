@@ -0,0 +1,83 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"sort"
+	"strings"
+)
+
+// silo holds the files only ever changed by a single author.
+type silo struct {
+	Author string
+	Files  []string
+}
+
+// findSilos returns the files of the commits changed by a single author,
+// grouped by that author, leaving out the files missing from current,
+// like deleted ones, unless current is nil. The commits are expected
+// newest first like git log writes them, and with followRenames the
+// paths a file had before being renamed count as the same file. The
+// authors are sorted by descending number of files, then by name, and
+// the files of each by path.
+func findSilos(commits []commit, current map[string]bool, followRenames bool) []silo {
+	authors := make(map[string]map[string]bool) // path to authors
+	eachFile(commits, followRenames, func(author, path string) {
+		if current != nil && !current[path] {
+			return
+		}
+		if authors[path] == nil {
+			authors[path] = make(map[string]bool)
+		}
+		authors[path][author] = true
+	})
+
+	files := make(map[string][]string)
+	for path, as := range authors {
+		if len(as) != 1 {
+			continue
+		}
+		for author := range as {
+			files[author] = append(files[author], path)
+		}
+	}
+
+	silos := make([]silo, 0, len(files))
+	for author, paths := range files {
+		sort.Strings(paths)
+		silos = append(silos, silo{author, paths})
+	}
+	sort.Slice(silos, func(i, j int) bool {
+		if len(silos[i].Files) != len(silos[j].Files) {
+			return len(silos[i].Files) > len(silos[j].Files)
+		}
+		return silos[i].Author < silos[j].Author
+	})
+
+	return silos
+}
+
+// currentFiles returns the paths of the files at rev, relative to the top
+// of the repo like those of git log, limited to the paths of o.
+func currentFiles(o options, rev string) (map[string]bool, error) {
+	args := []string{"ls-tree", "-r", "-z", "--name-only", "--full-name"}
+	if len(o.paths) > 0 {
+		args = append(append(args, rev, "--"), o.paths...)
+	} else {
+		args = append(args, "--full-tree", rev)
+	}
+	out, err := o.git(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]bool)
+	for _, path := range strings.Split(out, "\x00") {
+		if path != "" {
+			files[path] = true
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,50 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_FindSilos(t *testing.T) {
+	// newest first, like git log
+	commits := []commit{
+		{Author: "One", Files: []fileChange{{Path: "new.go", OldPath: "old.go"}}},
+		{Author: "Two", Files: []fileChange{{Path: "shared.go"}, {Path: "two.go"}}},
+		{Author: "One", Files: []fileChange{{Path: "shared.go"}, {Path: "one.go"}, {Path: "deleted.go"}}},
+		{Author: "Two", Files: []fileChange{{Path: "old.go"}}},
+	}
+	current := map[string]bool{"new.go": true, "shared.go": true, "two.go": true, "one.go": true}
+
+	exp := []silo{
+		{"One", []string{"new.go", "one.go"}},
+		{"Two", []string{"two.go"}},
+	}
+	if got := findSilos(commits, current, false); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	// following the rename, new.go was changed by both
+	exp = []silo{
+		{"One", []string{"one.go"}},
+		{"Two", []string{"two.go"}},
+	}
+	if got := findSilos(commits, current, true); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v with --follow-renames, got %v", exp, got)
+	}
+}
+
+func Test_CurrentFiles(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "dir/one.txt", "1\n")
+	r.commit("Author One", "two.txt", "1\n")
+	r.git("rm", "-q", "two.txt")
+	r.git("commit", "-q", "-m", "remove two.txt")
+
+	files, err := currentFiles(r.options(), "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, map[string]bool{"dir/one.txt": true}) {
+		t.Errorf("Expected only the remaining file, got %v", files)
+	}
+}
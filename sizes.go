@@ -0,0 +1,63 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultBuckets are the upper bounds of the commit size buckets when no
+// others are given.
+const defaultBuckets = "10,100,1000"
+
+// parseBuckets parses a comma-separated list of ascending, positive
+// commit size bucket upper bounds.
+func parseBuckets(list string) ([]int, error) {
+	var bounds []int
+	for _, f := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket bound %q", f)
+		}
+		if n < 1 || len(bounds) > 0 && n <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("bucket bounds must be positive and ascending: %s", list)
+		}
+		bounds = append(bounds, n)
+	}
+	return bounds, nil
+}
+
+// bucketLabels returns the column headers of the buckets with the given
+// upper bounds, the last bucket holding everything larger.
+func bucketLabels(bounds []int) []string {
+	labels := make([]string, 0, len(bounds)+1)
+	low := 1
+	for _, b := range bounds {
+		labels = append(labels, fmt.Sprintf("%d-%d", low, b))
+		low = b + 1
+	}
+	return append(labels, fmt.Sprintf("%d+", low))
+}
+
+// commitSizes counts the commits of each author in each size bucket, the
+// size being the number of changed lines. Commits without line changes,
+// like merges and commits only changing binary files, are not counted.
+func commitSizes(commits []commit, bounds []int) map[string][]int {
+	sizes := make(map[string][]int)
+	for _, c := range commits {
+		lines := c.LineChanges().Sum()
+		if lines == 0 {
+			continue
+		}
+
+		if sizes[c.Author] == nil {
+			sizes[c.Author] = make([]int, len(bounds)+1)
+		}
+		sizes[c.Author][sort.SearchInts(bounds, lines)]++
+	}
+	return sizes
+}
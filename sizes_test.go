@@ -0,0 +1,45 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_CommitSizes(t *testing.T) {
+	bounds, err := parseBuckets(defaultBuckets)
+	if err != nil {
+		t.Fatalf("error parsing buckets: %s", err)
+	}
+
+	labels := bucketLabels(bounds)
+	if !reflect.DeepEqual(labels, []string{"1-10", "11-100", "101-1000", "1001+"}) {
+		t.Errorf("unexpected labels: %q", labels)
+	}
+
+	lines := func(n int) []fileChange {
+		return []fileChange{{Path: "file", LineChanges: LineChanges{Additions: n}}}
+	}
+	commits := []commit{
+		{Author: "Author One", Files: lines(1)},
+		{Author: "Author One", Files: lines(10)},
+		{Author: "Author One", Files: lines(11)},
+		{Author: "Author One", Files: lines(5000)},
+		{Author: "Author Two", Files: lines(1000)},
+		{Author: "Author Two"},
+	}
+
+	sizes := commitSizes(commits, bounds)
+	expected := map[string][]int{
+		"Author One": {2, 1, 0, 1},
+		"Author Two": {0, 0, 1, 0},
+	}
+	if !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("Expected %v, got: %v", expected, sizes)
+	}
+
+	for _, invalid := range []string{"", "10,x", "0,10", "100,10", "10,10"} {
+		if _, err := parseBuckets(invalid); err == nil {
+			t.Errorf("Expected error parsing %q", invalid)
+		}
+	}
+}
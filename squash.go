@@ -0,0 +1,145 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"regexp"
+	"strings"
+)
+
+// squashFormat is the git log format used for finding squash merges. Each
+// commit starts with a NUL byte followed by its hash, committer and
+// subject on lines of their own, followed by its co-author trailers.
+var squashFormat = "--format=%x00%H%n%cN%n%s%n" + trailersFormat("Co-authored-by")
+
+// squashSubject matches the subjects of squash merges, ending in the
+// number of the pull request, like "Add feature (#123)".
+var squashSubject = regexp.MustCompile(`\(#\d+\)\s*$`)
+
+// squash is a squash merge, along with who merged it and the authors its
+// message credits.
+type squash struct {
+	Committer string
+	Authors   []string
+}
+
+// squashes reports whether --squash-author credits the squash merges to
+// someone other than their author.
+func (o options) squashes() bool {
+	return o.squashAuthor == "committer" || o.squashAuthor == "body"
+}
+
+// findSquashes returns the squash merges in the revision or range of o,
+// using HEAD when none is given, by hash.
+func findSquashes(o options) (map[string]squash, error) {
+	out, err := o.walk("log", o.logFormat(squashFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSquashes(out, o), nil
+}
+
+// parseSquashes returns the squash merges in the output of git log with
+// squashFormat, by hash. A commit is taken for a squash merge when its
+// subject ends in the number of a pull request. Its authors are those of
+// its Co-authored-by trailers, each listed once, named like the authors of
+// o by mapping them through the .mailmap file too.
+func parseSquashes(gitOutput string, o options) map[string]squash {
+	squashes := make(map[string]squash)
+	coAuthorName := o.trailerNamer()
+
+	for _, record := range strings.Split(gitOutput, "\x00") {
+		lines := strings.SplitN(record, "\n", 4)
+		if len(lines) < 3 || lines[0] == "" || !squashSubject.MatchString(lines[2]) {
+			continue
+		}
+
+		s := squash{Committer: o.authorName(lines[1])}
+		if len(lines) == 4 {
+			seen := make(map[string]bool)
+			for _, t := range parseTrailers(lines[3]) {
+				if t.Value == "" || !strings.EqualFold(t.Key, "Co-authored-by") {
+					continue
+				}
+				name := coAuthorName(t.Value)
+				if seen[name] {
+					continue
+				}
+				seen[name] = true
+				s.Authors = append(s.Authors, name)
+			}
+		}
+		squashes[lines[0]] = s
+	}
+
+	return squashes
+}
+
+// creditSquashes returns the commits with the squash merges credited as
+// chosen by --squash-author. With committer, a squash merge is credited to
+// whoever merged it. With body, it is credited to the authors credited by
+// its message instead, as a commit of each of them, with its line changes
+// split evenly between them, so that the totals of the line changes are
+// unchanged. The first authors get the lines left over by the split. A
+// squash merge crediting no one keeps its author.
+func creditSquashes(commits []commit, squashes map[string]squash, mode string) []commit {
+	credited := make([]commit, 0, len(commits))
+	for _, c := range commits {
+		s, ok := squashes[c.Hash]
+		switch {
+		case !ok:
+			credited = append(credited, c)
+		case mode == "committer":
+			c.Author = s.Committer
+			credited = append(credited, c)
+		case len(s.Authors) == 0:
+			credited = append(credited, c)
+		default:
+			for i, name := range s.Authors {
+				share := c
+				share.Author = name
+				share.Files = splitFiles(c.Files, i, len(s.Authors))
+				credited = append(credited, share)
+			}
+		}
+	}
+	return credited
+}
+
+// splitFiles returns the i-th of n even shares of the line changes of the
+// files, the first shares getting the lines left over.
+func splitFiles(files []fileChange, i, n int) []fileChange {
+	split := func(lines int) int {
+		share := lines / n
+		if i < lines%n {
+			share++
+		}
+		return share
+	}
+
+	shares := make([]fileChange, len(files))
+	for j, f := range files {
+		shares[j] = f
+		shares[j].Additions = split(f.Additions)
+		shares[j].Deletions = split(f.Deletions)
+	}
+	return shares
+}
+
+// attributeSquashes returns the commits of the history of o with the
+// squash merges credited as chosen by --squash-author, finding them in a
+// second pass over the history.
+func (o options) attributeSquashes(commits []commit) ([]commit, error) {
+	if !o.squashes() {
+		return commits, nil
+	}
+
+	squashes, err := findSquashes(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return creditSquashes(commits, squashes, o.squashAuthor), nil
+}
@@ -0,0 +1,76 @@
+package gitcontrib
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_ParseSquashes(t *testing.T) {
+	buf, err := os.ReadFile("testdata/squash-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string]squash{
+		"aaa111": {Committer: "Maintainer", Authors: []string{"Alice", "Bob"}},
+		"bbb222": {Committer: "Maintainer"},
+	}
+	if got := parseSquashes(string(buf), options{noMailmap: true}); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+}
+
+func Test_SquashAuthor(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Alice", "one.txt", "1\n")
+	r.write("two.txt", "1\n2\n3\n4\n5\n")
+	r.git("add", "two.txt")
+	r.gitEnv(
+		[]string{"GIT_AUTHOR_NAME=Maintainer", "GIT_COMMITTER_NAME=Merger"},
+		"commit", "-q", "-m", "Add two (#7)\n\nCo-authored-by: Alice <alice@example.com>\nCo-authored-by: Bob <bob@example.com>",
+	)
+
+	tests := []struct {
+		mode    string
+		commits map[string]int
+		lines   map[string]LineChanges
+	}{
+		{
+			"author",
+			map[string]int{"Alice": 1, "Maintainer": 1},
+			map[string]LineChanges{"Alice": {Additions: 1}, "Maintainer": {Additions: 5}},
+		},
+		{
+			"committer",
+			map[string]int{"Alice": 1, "Merger": 1},
+			map[string]LineChanges{"Alice": {Additions: 1}, "Merger": {Additions: 5}},
+		},
+		{
+			"body",
+			map[string]int{"Alice": 2, "Bob": 1},
+			map[string]LineChanges{"Alice": {Additions: 4}, "Bob": {Additions: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		o := r.options()
+		o.squashAuthor = tt.mode
+
+		commits, err := authorCommits(o)
+		if err != nil {
+			t.Fatalf("error extracting commit counts: %s", err)
+		}
+		if !reflect.DeepEqual(commits, tt.commits) {
+			t.Errorf("--squash-author %s: expected commits %v, got %v", tt.mode, tt.commits, commits)
+		}
+
+		lines, err := mapLineChanges(o)
+		if err != nil {
+			t.Fatalf("error extracting line changes: %s", err)
+		}
+		if !reflect.DeepEqual(lines, tt.lines) {
+			t.Errorf("--squash-author %s: expected line changes %v, got %v", tt.mode, tt.lines, lines)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"strings"
+)
+
+// listSubmodules returns the paths of the initialized submodules of the
+// repo of o, including nested ones, relative to the directory of o, which
+// must be the top-level directory of the repo.
+func listSubmodules(o options) ([]string, error) {
+	out, err := o.git("submodule", "status", "--recursive")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSubmoduleStatus(out), nil
+}
+
+// parseSubmoduleStatus returns the paths of the submodules listed in the
+// output of git submodule status. Each line starts with a status
+// character and the hash of the submodule commit, followed by the path
+// and, for checked out submodules, the described commit in parentheses.
+// Uninitialized submodules, marked by a '-', are left out, as there is
+// nothing to analyse.
+func parseSubmoduleStatus(gitOutput string) []string {
+	var paths []string
+
+	scanner := bufio.NewScanner(strings.NewReader(gitOutput))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '-' {
+			continue
+		}
+
+		_, path, ok := strings.Cut(line[1:], " ")
+		if !ok {
+			continue
+		}
+		if i := strings.LastIndex(path, " ("); i >= 0 && strings.HasSuffix(path, ")") {
+			path = path[:i]
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths
+}
@@ -0,0 +1,46 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ParseSubmoduleStatus(t *testing.T) {
+	gitOutput := ` 1a2b3c4d vendor/lib (v1.0)
++5e6f7a8b vendor/with space (heads/main)
+-9c0d1e2f vendor/uninitialized
+ 3a4b5c6d vendor/lib/nested
+`
+	got := parseSubmoduleStatus(gitOutput)
+	exp := []string{"vendor/lib", "vendor/with space", "vendor/lib/nested"}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %q, got: %q", exp, got)
+	}
+}
+
+func Test_CollectSubmodules(t *testing.T) {
+	sub := newTestRepo(t)
+	sub.commit("Sub Author", "lib.go", "1\n2\n3\n")
+
+	r := newTestRepo(t)
+	r.commit("Super Author", "main.go", "1\n")
+	r.git("-c", "protocol.file.allow=always", "submodule", "add", "-q", sub.dir, "vendor/lib")
+	r.git("commit", "-q", "-m", "add submodule")
+
+	var ff filterFlags
+	summaries, totals, err := ff.collectSubmodules(r.options())
+	if err != nil {
+		t.Fatalf("error collecting summary: %s", err)
+	}
+
+	authors := make(map[string]int)
+	for _, s := range summaries {
+		authors[s.Author] = s.Additions
+	}
+	if authors["vendor/lib: Sub Author"] != 3 || authors["Super Author"] != 1 {
+		t.Errorf("Expected labelled submodule rows, got: %v", authors)
+	}
+	if totals.Additions < 4 {
+		t.Errorf("Expected totals to cover the submodule, got: %+v", totals)
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AuthorSummary holds the commits, line changes and aggregated metrics of
+// a single author, as shown in one row of the 'summary' report.
+type AuthorSummary struct {
+	Author      string  `json:"author" yaml:"author"`
+	Commits     int     `json:"commits" yaml:"commits"`
+	Additions   int     `json:"additions" yaml:"additions"`
+	Deletions   int     `json:"deletions" yaml:"deletions"`
+	LineRatio   float64 `json:"line_ratio" yaml:"line_ratio"`
+	CommitRatio float64 `json:"commit_ratio" yaml:"commit_ratio"`
+	Granularity float64 `json:"granularity" yaml:"granularity"`
+
+	// AdditionsRatio and DeletionsRatio hold the shares of all added and
+	// of all deleted lines in the repo, telling authors mostly writing new
+	// code from those mostly removing it, which LineRatio blends.
+	AdditionsRatio float64 `json:"additions_ratio" yaml:"additions_ratio"`
+	DeletionsRatio float64 `json:"deletions_ratio" yaml:"deletions_ratio"`
+
+	// Weighted holds the recency-weighted line changes, only set when
+	// weighting by recency was asked for.
+	Weighted float64 `json:"weighted,omitempty" yaml:"weighted,omitempty"`
+
+	// FilesTouched holds the number of distinct files touched, only set
+	// when asked for.
+	FilesTouched int `json:"files_touched,omitempty" yaml:"files_touched,omitempty"`
+
+	// FilesPerCommit holds the mean number of files changed per commit,
+	// and Breadth the breadth score combining the commits, distinct files
+	// touched and line churn, only set when asked for.
+	FilesPerCommit float64 `json:"files_per_commit,omitempty" yaml:"files_per_commit,omitempty"`
+	Breadth        float64 `json:"breadth,omitempty" yaml:"breadth,omitempty"`
+
+	// Merges holds the number of merge commits, which Commits leaves out,
+	// only set when asked for.
+	Merges int `json:"merges,omitempty" yaml:"merges,omitempty"`
+
+	// Surviving holds the lines of the author surviving in the code today,
+	// as attributed by git blame, and Footprint their share of all lines,
+	// only set when asked for.
+	Surviving int     `json:"surviving,omitempty" yaml:"surviving,omitempty"`
+	Footprint float64 `json:"footprint,omitempty" yaml:"footprint,omitempty"`
+
+	// Hunks holds the number of distinct blocks of changed lines of the
+	// commits of the author, and Complexity their line changes weighted by
+	// the hunks of each commit, only set when asked for.
+	Hunks      int     `json:"hunks,omitempty" yaml:"hunks,omitempty"`
+	Complexity float64 `json:"complexity,omitempty" yaml:"complexity,omitempty"`
+
+	// Activity holds the number of commits per calendar month, from the
+	// month of the first commit of the report, only set when asked for.
+	Activity []int `json:"activity,omitempty" yaml:"activity,omitempty"`
+
+	// Score holds the line ratio scaled to a score from 0 to 100, only set
+	// when asked for.
+	Score float64 `json:"score,omitempty" yaml:"score,omitempty"`
+
+	// Rank holds the rank of the author by the metric of --rank-by, tied
+	// authors sharing one, only set when asked for.
+	Rank int `json:"rank,omitempty" yaml:"rank,omitempty"`
+
+	// uncommitted is set for the author credited with the uncommitted
+	// changes of --include-working-tree, marked with a * in the text
+	// tables only.
+	uncommitted bool
+}
+
+// Totals holds the repo-wide metrics the author summaries are relative to.
+type Totals struct {
+	Commits     int       `json:"commits" yaml:"commits"`
+	Additions   int       `json:"additions" yaml:"additions"`
+	Deletions   int       `json:"deletions" yaml:"deletions"`
+	Granularity float64   `json:"granularity" yaml:"granularity"`
+	Gini        float64   `json:"gini" yaml:"gini"`
+	First       time.Time `json:"first_commit" yaml:"first_commit"`
+	Last        time.Time `json:"last_commit" yaml:"last_commit"`
+}
+
+// DeletionsPerAddition returns the number of deleted lines per added line, high
+// for authors mostly cleaning up or refactoring code. It is infinite for
+// authors only deleting lines, and 0 for authors without line changes.
+func (s AuthorSummary) DeletionsPerAddition() float64 {
+	if s.Additions == 0 && s.Deletions > 0 {
+		return math.Inf(1)
+	}
+	return ratio(s.Deletions, s.Additions)
+}
+
+// AdditionShare returns the share of the own line changes of the author
+// that are added lines, high for authors mostly building new code. It is
+// 0 for authors without line changes.
+func (s AuthorSummary) AdditionShare() float64 {
+	return ratio(s.Additions, s.Additions+s.Deletions)
+}
+
+// DeletionShare returns the share of the own line changes of the author
+// that are deleted lines, high for authors mostly maintaining code. It is
+// 0 for authors without line changes.
+func (s AuthorSummary) DeletionShare() float64 {
+	return ratio(s.Deletions, s.Additions+s.Deletions)
+}
+
+// Days returns the number of whole days between the first and last
+// commits.
+func (t Totals) Days() int {
+	return int(t.Last.Sub(t.First).Hours() / 24)
+}
+
+// Summarize combines the author commit counts and line changes into one
+// summary per author, along with the repo-wide totals. The summaries are
+// sorted by descending commit count, then by author name, as sortSummaries
+// does for the commits key.
+func Summarize(
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) ([]AuthorSummary, Totals, error) {
+
+	var totals Totals
+	for _, v := range commitMap {
+		totals.Commits += v
+	}
+
+	lines := make([]int, 0, len(lineChangesMap))
+	for _, v := range lineChangesMap {
+		totals.Additions += v.Additions
+		totals.Deletions += v.Deletions
+		lines = append(lines, v.Sum())
+	}
+	totals.Gini = Gini(lines)
+
+	totals.Granularity = granularity(
+		totals.Commits, totals.Additions+totals.Deletions,
+	)
+
+	// authors of only merge commits have line changes but no commits,
+	// and those of only empty commits the other way around
+	summaries := make([]AuthorSummary, 0, len(lineChangesMap))
+	for k, v := range lineChangesMap {
+		summaries = append(summaries, AuthorSummary{
+			Author:    k,
+			Commits:   commitMap[k],
+			Additions: v.Additions,
+			Deletions: v.Deletions,
+		})
+	}
+	for k, v := range commitMap {
+		if _, ok := lineChangesMap[k]; !ok {
+			summaries = append(summaries, AuthorSummary{Author: k, Commits: v})
+		}
+	}
+	setRatios(summaries, totals)
+	if err := sortSummaries(summaries, "commits"); err != nil {
+		return nil, Totals{}, err
+	}
+
+	return summaries, totals, nil
+}
+
+// totalAuthor is the author of the totals rows of --with-totals.
+const totalAuthor = "TOTAL"
+
+// totalSummary returns the totals as the summary of totalAuthor, for the
+// totals rows of the CSV output. Its ratios are those of the whole, being
+// 1 unless the totals are empty.
+func totalSummary(totals Totals) AuthorSummary {
+	s := []AuthorSummary{{
+		Author:    totalAuthor,
+		Commits:   totals.Commits,
+		Additions: totals.Additions,
+		Deletions: totals.Deletions,
+	}}
+	setRatios(s, totals)
+	return s[0]
+}
+
+// Relativize returns copies of the summaries with their ratios and
+// granularity recomputed to be relative to the given authors only, along
+// with the totals of those authors. The ratios of the returned summaries
+// sum to 1.
+func Relativize(summaries []AuthorSummary) ([]AuthorSummary, Totals) {
+	var totals Totals
+	lines := make([]int, 0, len(summaries))
+	for _, s := range summaries {
+		totals.Commits += s.Commits
+		totals.Additions += s.Additions
+		totals.Deletions += s.Deletions
+		lines = append(lines, s.Additions+s.Deletions)
+	}
+	totals.Gini = Gini(lines)
+
+	totals.Granularity = granularity(
+		totals.Commits, totals.Additions+totals.Deletions,
+	)
+
+	relative := make([]AuthorSummary, len(summaries))
+	copy(relative, summaries)
+	setRatios(relative, totals)
+
+	return relative, totals
+}
+
+// setRatios sets the line ratio, commit ratio, additions and deletions
+// ratios and granularity of each summary, relative to totals. A ratio to a
+// total of 0, like the deletions ratio of a history without deletions, is
+// 0.
+func setRatios(summaries []AuthorSummary, totals Totals) {
+	lineTotal := totals.Additions + totals.Deletions
+	for i, s := range summaries {
+		linesum := s.Additions + s.Deletions
+		summaries[i].LineRatio = ratio(linesum, lineTotal)
+		summaries[i].CommitRatio = ratio(s.Commits, totals.Commits)
+		summaries[i].AdditionsRatio = ratio(s.Additions, totals.Additions)
+		summaries[i].DeletionsRatio = ratio(s.Deletions, totals.Deletions)
+		summaries[i].Granularity = granularity(s.Commits, linesum)
+	}
+}
+
+// ratio returns n divided by total, or 0 for a total of 0.
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// granularity returns the number of commits per changed line, or 0 when
+// no lines were changed, like by an author of only empty commits.
+func granularity(commits, lines int) float64 {
+	if lines == 0 {
+		return 0
+	}
+	return 1.0 / (float64(lines) / float64(commits))
+}
+
+// checkAuthors returns an error listing the authors that are only in one
+// of the maps, or nil when both have the same authors.
+func checkAuthors(
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) error {
+
+	var onlyCommits, onlyLines []string
+	for k := range commitMap {
+		if _, ok := lineChangesMap[k]; !ok {
+			onlyCommits = append(onlyCommits, k)
+		}
+	}
+	for k := range lineChangesMap {
+		if _, ok := commitMap[k]; !ok {
+			onlyLines = append(onlyLines, k)
+		}
+	}
+	if len(onlyCommits) == 0 && len(onlyLines) == 0 {
+		return nil
+	}
+
+	sort.Strings(onlyCommits)
+	sort.Strings(onlyLines)
+
+	var parts []string
+	if len(onlyCommits) > 0 {
+		parts = append(parts, "only in commit counts: "+strings.Join(onlyCommits, ", "))
+	}
+	if len(onlyLines) > 0 {
+		parts = append(parts, "only in line changes: "+strings.Join(onlyLines, ", "))
+	}
+
+	return fmt.Errorf("authors of commit counts and line changes differ; %s", strings.Join(parts, "; "))
+}
+
+// ratioEpsilon is the deviation allowed by checkRatios for the ratios
+// of the ratios, which floating-point rounding never comes near.
+const ratioEpsilon = 1e-6
+
+// checkRatios returns an error when the line or commit ratio of an author
+// of a report read back, like with --input-json or from the cache, isn't
+// their share of the totals, as when the report was edited or mixes the
+// authors and totals of different reports. Ratios of a total of 0 are 0.
+func checkRatios(summaries []AuthorSummary, totals Totals) error {
+	for _, s := range summaries {
+		var parts []string
+		if r := ratio(s.Additions+s.Deletions, totals.Additions+totals.Deletions); math.Abs(s.LineRatio-r) > ratioEpsilon {
+			parts = append(parts, fmt.Sprintf("a line ratio of %g instead of %g", s.LineRatio, r))
+		}
+		if r := ratio(s.Commits, totals.Commits); math.Abs(s.CommitRatio-r) > ratioEpsilon {
+			parts = append(parts, fmt.Sprintf("a commit ratio of %g instead of %g", s.CommitRatio, r))
+		}
+		if len(parts) > 0 {
+			return fmt.Errorf("inconsistent ratios; %s has %s", s.Author, strings.Join(parts, " and "))
+		}
+	}
+	return nil
+}
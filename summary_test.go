@@ -0,0 +1,159 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"testing"
+)
+
+func Test_Summarize(t *testing.T) {
+	commitMap := map[string]int{
+		"Author One": 3,
+		"Author Two": 1,
+	}
+	lineChangesMap := map[string]LineChanges{
+		"Author One": {Additions: 50, Deletions: 10},
+		"Author Two": {Additions: 20, Deletions: 0},
+	}
+
+	summaries, totals, err := Summarize(commitMap, lineChangesMap)
+	if err != nil {
+		t.Fatalf("error summarizing: %s", err)
+	}
+
+	if totals.Commits != 4 || totals.Additions != 70 || totals.Deletions != 10 {
+		t.Errorf("unexpected totals: %+v", totals)
+	}
+	if totals.Granularity != 0.05 {
+		t.Errorf("Expected overall granularity 0.05, got: %f", totals.Granularity)
+	}
+
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 summaries, got: %d", len(summaries))
+	}
+
+	first := summaries[0]
+	if first.Author != "Author One" {
+		t.Errorf("Expected author one first, got: %q", first.Author)
+	}
+	if first.LineRatio != 0.75 {
+		t.Errorf("Expected line ratio 0.75, got: %f", first.LineRatio)
+	}
+	if first.CommitRatio != 0.75 {
+		t.Errorf("Expected commit ratio 0.75, got: %f", first.CommitRatio)
+	}
+	if first.Granularity != 0.05 {
+		t.Errorf("Expected granularity 0.05, got: %f", first.Granularity)
+	}
+}
+
+func Test_Relativize(t *testing.T) {
+	summaries, _, _ := Summarize(
+		map[string]int{"Author One": 3, "Author Two": 1, "Author Three": 4},
+		map[string]LineChanges{
+			"Author One":   {Additions: 30, Deletions: 10},
+			"Author Two":   {Additions: 20},
+			"Author Three": {Additions: 100},
+		},
+	)
+	summaries = filterAuthors(summaries, regexp.MustCompile("One|Two"))
+
+	relative, totals := Relativize(summaries)
+
+	if totals.Commits != 4 || totals.Additions != 50 || totals.Deletions != 10 {
+		t.Errorf("unexpected totals: %+v", totals)
+	}
+
+	var lineSum, commitSum float64
+	for _, s := range relative {
+		lineSum += s.LineRatio
+		commitSum += s.CommitRatio
+	}
+	if math.Abs(lineSum-1) > 1e-9 || math.Abs(commitSum-1) > 1e-9 {
+		t.Errorf("Expected ratios to sum to 1, got %f and %f", lineSum, commitSum)
+	}
+
+	if summaries[0].CommitRatio != 0.375 {
+		t.Errorf("Expected input summaries to be left untouched")
+	}
+}
+
+func Test_SummarizeMismatchedAuthors(t *testing.T) {
+	commitMap := map[string]int{"Author One": 2, "Empty Committer": 1}
+	lineChangesMap := map[string]LineChanges{
+		"Author One":  {Additions: 10},
+		"Merger Only": {},
+	}
+
+	summaries, totals, _ := Summarize(commitMap, lineChangesMap)
+	if len(summaries) != 3 {
+		t.Fatalf("Expected the authors of both maps, got: %+v", summaries)
+	}
+	for _, s := range summaries {
+		for _, f := range []float64{s.LineRatio, s.CommitRatio, s.Granularity} {
+			if math.IsInf(f, 0) || math.IsNaN(f) {
+				t.Errorf("Expected finite ratios, got: %+v", s)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeSummaryJSON(&buf, summaries, totals, renderOptions{}); err != nil {
+		t.Errorf("error encoding json: %s", err)
+	}
+
+	err := checkAuthors(commitMap, lineChangesMap)
+	exp := "authors of commit counts and line changes differ; only in commit counts: Empty Committer; only in line changes: Merger Only"
+	if err == nil || err.Error() != exp {
+		t.Errorf("Expected error %q, got: %v", exp, err)
+	}
+	if err := checkAuthors(map[string]int{"A": 1}, map[string]LineChanges{"A": {}}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func Test_SummarizeNoLines(t *testing.T) {
+	summaries, totals, _ := Summarize(map[string]int{"Author One": 1}, map[string]LineChanges{"Author One": {}})
+	if summaries[0].LineRatio != 0 || summaries[0].Granularity != 0 || totals.Granularity != 0 {
+		t.Errorf("Expected zero ratios without line changes, got: %+v, %+v", summaries[0], totals)
+	}
+}
+
+func Test_CheckRatios(t *testing.T) {
+	commitMap := map[string]int{"Author One": 3, "Author Two": 1}
+	lineChangesMap := map[string]LineChanges{"Author One": {Additions: 7}, "Author Two": {Deletions: 3}}
+
+	summaries, totals, _ := Summarize(commitMap, lineChangesMap)
+	if err := checkRatios(summaries, totals); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// the totals of another report, as if the report had been edited
+	_, skewed, _ := Summarize(map[string]int{"Author One": 3}, map[string]LineChanges{"Author One": {Additions: 7}})
+	err := checkRatios(summaries, skewed)
+	exp := "inconsistent ratios; Author One has a line ratio of 0.7 instead of 1 and a commit ratio of 0.75 instead of 1"
+	if err == nil || err.Error() != exp {
+		t.Errorf("Expected error %q, got: %v", exp, err)
+	}
+
+	if err := checkRatios(nil, Totals{}); err != nil {
+		t.Errorf("Expected no error without any changes, got: %s", err)
+	}
+}
+
+func Test_SeparateRatios(t *testing.T) {
+	commitMap := map[string]int{"Author One": 3, "Author Two": 1}
+	lineChangesMap := map[string]LineChanges{"Author One": {Additions: 6}, "Author Two": {Additions: 2}}
+
+	summaries, _, _ := Summarize(commitMap, lineChangesMap)
+	for _, s := range summaries {
+		exp := map[string]float64{"Author One": 0.75, "Author Two": 0.25}[s.Author]
+		if s.AdditionsRatio != exp {
+			t.Errorf("Expected additions ratio %v of %s, got %v", exp, s.Author, s.AdditionsRatio)
+		}
+		if s.DeletionsRatio != 0 {
+			t.Errorf("Expected deletions ratio 0 without deletions, got %v", s.DeletionsRatio)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Layout of the SVG sparkline report, in pixels.
+const (
+	svgNameWidth   = 200
+	svgSparkWidth  = 240
+	svgSparkHeight = 20
+	svgCountWidth  = 70
+	svgRowHeight   = 30
+	svgMargin      = 10
+)
+
+// writeSummarySVG writes a standalone SVG document to w with a sparkline
+// of the monthly commits of each author. All sparklines share the same
+// scale, so that they can be compared.
+func writeSummarySVG(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	var peak int
+	for _, s := range summaries {
+		for _, n := range s.Activity {
+			if n > peak {
+				peak = n
+			}
+		}
+	}
+
+	width := 2*svgMargin + svgNameWidth + svgSparkWidth + svgCountWidth
+	height := 2*svgMargin + svgRowHeight*(len(summaries)+1)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" `+
+			`viewBox="0 0 %d %d" font-family="sans-serif" font-size="13">`+"\n",
+		width, height, width, height,
+	)
+	fmt.Fprintf(bw, "<title>Commits per month</title>\n")
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="#fff"/>`+"\n")
+
+	title := "Commits per month"
+	if !totals.First.IsZero() {
+		title += fmt.Sprintf(", %s to %s",
+			totals.First.Format("2006-01"), totals.Last.Format("2006-01"))
+	}
+	fmt.Fprintf(bw, `<text x="%d" y="%d" font-weight="bold">%s</text>`+"\n",
+		svgMargin, svgMargin+svgRowHeight/2+5, html.EscapeString(title))
+
+	for i, s := range summaries {
+		y := svgMargin + svgRowHeight*(i+1)
+		text := y + svgRowHeight/2 + 5
+		fmt.Fprintf(bw, `<g class="author">`+"\n")
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n",
+			svgMargin, text, html.EscapeString(s.Author))
+		fmt.Fprintf(bw,
+			`<polyline fill="none" stroke="#2a6ebb" stroke-width="1.5" points="%s"/>`+"\n",
+			sparklinePoints(s.Activity, peak,
+				svgMargin+svgNameWidth, y+(svgRowHeight-svgSparkHeight)/2),
+		)
+		fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n",
+			width-svgMargin, text, s.Commits)
+		fmt.Fprintf(bw, "</g>\n")
+	}
+
+	fmt.Fprintf(bw, "</svg>\n")
+
+	return bw.Flush()
+}
+
+// sparklinePoints returns the points attribute of a polyline drawing the
+// counts in a box of svgSparkWidth by svgSparkHeight with its top left
+// corner at x, y. Counts equal to peak reach the top of the box. A single
+// count is drawn as a flat line across the box.
+func sparklinePoints(counts []int, peak, x, y int) string {
+	if len(counts) == 0 {
+		counts = []int{0}
+	}
+	if len(counts) == 1 {
+		counts = []int{counts[0], counts[0]}
+	}
+
+	var b strings.Builder
+	step := float64(svgSparkWidth) / float64(len(counts)-1)
+	for i, n := range counts {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		py := float64(y + svgSparkHeight)
+		if peak > 0 {
+			py -= float64(n) / float64(peak) * svgSparkHeight
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f", float64(x)+float64(i)*step, py)
+	}
+
+	return b.String()
+}
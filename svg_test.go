@@ -0,0 +1,61 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_WriteSummarySVG(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author <One>", Commits: 4, Activity: []int{1, 0, 3}},
+		{Author: "Author Two", Commits: 1, Activity: []int{0, 1, 0}},
+	}
+
+	var buf bytes.Buffer
+	err := writeSummarySVG(&buf, summaries, Totals{}, renderOptions{})
+	if err != nil {
+		t.Fatalf("error writing svg: %s", err)
+	}
+
+	var polylines []string
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("malformed svg: %s", err)
+		}
+		if el, ok := tok.(xml.StartElement); ok && el.Name.Local == "polyline" {
+			for _, a := range el.Attr {
+				if a.Name.Local == "points" {
+					polylines = append(polylines, a.Value)
+				}
+			}
+		}
+	}
+
+	if len(polylines) != 2 {
+		t.Fatalf("Expected a polyline per author, got: %v", polylines)
+	}
+	exp := "210.0,58.3 330.0,65.0 450.0,45.0"
+	if polylines[0] != exp {
+		t.Errorf("Expected points %q, got: %q", exp, polylines[0])
+	}
+}
+
+func Test_SparklinePoints(t *testing.T) {
+	got := sparklinePoints([]int{2}, 4, 0, 0)
+	if exp := "0.0,10.0 240.0,10.0"; got != exp {
+		t.Errorf("Expected %q, got: %q", exp, got)
+	}
+
+	got = sparklinePoints(nil, 0, 0, 0)
+	if strings.Count(got, ",") != 2 {
+		t.Errorf("Expected a flat line without counts, got: %q", got)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// tableLayout holds the layout of all human-readable tables, set by the
+// --minwidth, --padding and --max-name-width flags. The minimum width
+// includes the padding. Cells of the text columns wider than the maximum
+// name width are truncated, unless it is 0.
+var tableLayout = struct {
+	minWidth     int
+	padding      int
+	maxNameWidth int
+}{0, 2, 0}
+
+// table is a human-readable table with a header, written with its leading
+// text columns left-aligned and the following numeric columns
+// right-aligned.
+type table struct {
+	text int // number of leading text columns
+	rows [][]string
+}
+
+// newTable returns a table with the given header, underlined by dashes,
+// of which the first text columns are left-aligned.
+func newTable(text int, header ...string) *table {
+	t := &table{text: text}
+	t.row(header...)
+
+	dashes := make([]string, len(header))
+	for i, h := range header {
+		dashes[i] = strings.Repeat("-", utf8.RuneCountInString(h))
+	}
+	t.row(dashes...)
+
+	return t
+}
+
+// row adds a row of cells to the table.
+func (t *table) row(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// write writes the table to w. As the tabwriter right-aligns every cell,
+// the cells of the text columns are first truncated to the maximum name
+// width and padded to their widest cell.
+func (t *table) write(w io.Writer) error {
+	for _, r := range t.rows {
+		for i := 0; i < t.text && i < len(r); i++ {
+			r[i] = truncate(r[i], tableLayout.maxNameWidth)
+		}
+	}
+
+	widths := make([]int, t.text)
+	for _, r := range t.rows {
+		for i := 0; i < t.text && i < len(r); i++ {
+			if n := utf8.RuneCountInString(r[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(
+		w, tableLayout.minWidth, 8, tableLayout.padding, ' ', tabwriter.AlignRight,
+	)
+
+	for _, r := range t.rows {
+		for i, c := range r {
+			if i < t.text {
+				c += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c))
+			}
+			fmt.Fprint(tw, c, "\t")
+		}
+		fmt.Fprintln(tw)
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush output buffer: %w", err)
+	}
+
+	return nil
+}
+
+// truncate returns s cut to at most max runes, ending in an ellipsis when
+// cut. A max of 0 or less leaves s untouched.
+func truncate(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
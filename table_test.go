@@ -0,0 +1,82 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func Test_Table(t *testing.T) {
+	tbl := newTable(1, "Author", "Commits")
+	tbl.row("Svein-Kåre", "2")
+	tbl.row("Bo", "10")
+
+	var buf bytes.Buffer
+	if err := tbl.write(&buf); err != nil {
+		t.Fatalf("error writing table: %s", err)
+	}
+
+	exp := "  Author      Commits\n" +
+		"  ------      -------\n" +
+		"  Svein-Kåre        2\n" +
+		"  Bo               10\n"
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+}
+
+func Test_TableMaxNameWidth(t *testing.T) {
+	defer func(n int) { tableLayout.maxNameWidth = n }(tableLayout.maxNameWidth)
+	tableLayout.maxNameWidth = 12
+
+	long := strings.Repeat("å", 90) + "@example.c"
+	summaries := []AuthorSummary{
+		{Author: long, Commits: 2},
+		{Author: "Bo", Commits: 10},
+	}
+
+	var buf bytes.Buffer
+	err := writeSummaryTable(&buf, summaries, Totals{}, renderOptions{noFooter: true})
+	if err != nil {
+		t.Fatalf("error writing table: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for _, l := range lines[1:] {
+		if n, exp := utf8.RuneCountInString(l), utf8.RuneCountInString(lines[0]); n != exp {
+			t.Errorf("Expected aligned lines of %d characters, got %d: %q", exp, n, l)
+		}
+	}
+	if !strings.HasPrefix(lines[2], "  "+strings.Repeat("å", 11)+"…  ") {
+		t.Errorf("Expected name cut to 12 characters, got: %q", lines[2])
+	}
+
+	buf.Reset()
+	if err := writeSummaryJSON(&buf, summaries, Totals{}, renderOptions{}); err != nil {
+		t.Fatalf("error writing json: %s", err)
+	}
+	if !strings.Contains(buf.String(), `"author":"`+long+`"`) {
+		t.Errorf("Expected the full name in json, got: %s", buf.String())
+	}
+	if summaries[0].Author != long {
+		t.Errorf("Expected the summary to keep the full name, got: %q", summaries[0].Author)
+	}
+}
+
+func Test_Truncate(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		max int
+		exp string
+	}{
+		{"Svein-Kåre", 0, "Svein-Kåre"},
+		{"Svein-Kåre", 10, "Svein-Kåre"},
+		{"Svein-Kåre", 9, "Svein-Kå…"},
+		{"Ørjan", 1, "…"},
+	} {
+		if got := truncate(tt.s, tt.max); got != tt.exp {
+			t.Errorf("truncate(%q, %d): Expected %q, got: %q", tt.s, tt.max, tt.exp, got)
+		}
+	}
+}
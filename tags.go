@@ -0,0 +1,72 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"bufio"
+	"strings"
+)
+
+// unreleased is the name of the release holding the commits after the
+// last tag.
+const unreleased = "unreleased"
+
+// release is the range of commits that landed in a tagged release, being
+// those reachable from its tag but not from the tag before.
+type release struct {
+	Name  string
+	Range string
+}
+
+// releaseSummary holds the 'summary' report of a single release.
+type releaseSummary struct {
+	release
+	Summaries []AuthorSummary
+	Totals    Totals
+}
+
+// listTags returns the names of the tags of the repo of o, oldest first by
+// the date they were created.
+func listTags(o options) ([]string, error) {
+	out, err := o.git("tag", "--sort=creatordate")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if tag := strings.TrimSpace(scanner.Text()); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+// releases returns the release of each of the tags, oldest first, followed
+// by the unreleased commits from the last tag up to head. The first
+// release holds all history up to its tag.
+func releases(tags []string, head string) []release {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	rs := []release{{tags[0], tags[0]}}
+	for i := 1; i < len(tags); i++ {
+		rs = append(rs, release{tags[i], tags[i-1] + ".." + tags[i]})
+	}
+
+	return append(rs, release{unreleased, tags[len(tags)-1] + ".." + head})
+}
+
+// hasRangeCommits reports whether the revision or range rev of the repo of
+// o has any commits.
+func hasRangeCommits(o options, rev string) (bool, error) {
+	out, err := o.git("rev-list", "--count", rev)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "0", nil
+}
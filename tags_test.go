@@ -0,0 +1,107 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Releases(t *testing.T) {
+	got := releases([]string{"v1.0", "v1.1", "v2.0"}, "HEAD")
+	exp := []release{
+		{"v1.0", "v1.0"},
+		{"v1.1", "v1.0..v1.1"},
+		{"v2.0", "v1.1..v2.0"},
+		{unreleased, "v2.0..HEAD"},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got: %v", exp, got)
+	}
+
+	if got := releases(nil, "HEAD"); got != nil {
+		t.Errorf("Expected no releases without tags, got: %v", got)
+	}
+}
+
+func Test_ListTags(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+	r.git("tag", "v1.0")
+	r.commit("Author Two", "two.txt", "1\n")
+
+	tags, err := listTags(r.options())
+	if err != nil {
+		t.Fatalf("error listing tags: %s", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"v1.0"}) {
+		t.Errorf("Expected [v1.0], got: %v", tags)
+	}
+
+	ok, err := hasRangeCommits(r.options(), "v1.0..HEAD")
+	if err != nil || !ok {
+		t.Errorf("Expected commits after v1.0, got: %v, %v", ok, err)
+	}
+
+	ok, err = hasRangeCommits(r.options(), "HEAD..v1.0")
+	if err != nil || ok {
+		t.Errorf("Expected no commits in HEAD..v1.0, got: %v, %v", ok, err)
+	}
+}
+
+func Test_SinceTag(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "a.txt", "1\n")
+	r.git("tag", "v1.0")
+	r.commit("Author Two", "b.txt", "1\n")
+	r.git("tag", "v1.1")
+	r.commit("Author Three", "c.txt", "1\n")
+
+	o := r.options()
+	if err := o.sinceTag.Set("true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.resolve(); err != nil {
+		t.Fatalf("error resolving: %s", err)
+	}
+	if o.ref != "v1.1..HEAD" {
+		t.Errorf("Expected the range since the latest tag, got: %s", o.ref)
+	}
+
+	commits, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	if len(commits) != 1 || commits["Author Three"] != 1 {
+		t.Errorf("Expected only the commit after v1.1, got: %v", commits)
+	}
+
+	o = r.options()
+	o.sinceTag.Set("v1.0")
+	if err := o.resolve(); err != nil {
+		t.Fatalf("error resolving: %s", err)
+	}
+	commits, err = authorCommits(o)
+	if err != nil {
+		t.Fatalf("error extracting commit counts: %s", err)
+	}
+	if len(commits) != 2 || commits["Author One"] != 0 {
+		t.Errorf("Expected the commits after v1.0, got: %v", commits)
+	}
+
+	o = r.options()
+	o.sinceTag.Set("v2.0")
+	if err := o.resolve(); errorCode(err) != CodeBadRef {
+		t.Errorf("Expected error for an unknown tag, got: %v", err)
+	}
+}
+
+func Test_SinceTagNoTags(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "a.txt", "1\n")
+
+	o := r.options()
+	o.sinceTag.Set("true")
+	if err := o.resolve(); err == nil || !strings.Contains(err.Error(), "--since-tag=TAG") {
+		t.Errorf("Expected error naming --since-tag=TAG without tags, got: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// teams maps author names to the name of the team they are reported as,
+// set by the repeatable --team flag.
+type teams map[string]string
+
+func (tm *teams) String() string {
+	var defs []string
+	for author, team := range *tm {
+		defs = append(defs, team+"="+author)
+	}
+	sort.Strings(defs)
+	return strings.Join(defs, " ")
+}
+
+// Set adds the team of a --team flag, given as NAME=AUTHOR,AUTHOR...
+func (tm *teams) Set(def string) error {
+	name, list, ok := strings.Cut(def, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.TrimSpace(list) == "" {
+		return fmt.Errorf("invalid team %q, expected NAME=AUTHOR,AUTHOR", def)
+	}
+
+	if *tm == nil {
+		*tm = make(teams)
+	}
+
+	for _, author := range strings.Split(list, ",") {
+		author = strings.TrimSpace(author)
+		if team, ok := (*tm)[author]; ok && team != name {
+			return fmt.Errorf("author %q is in both team %q and %q", author, team, name)
+		}
+		(*tm)[author] = name
+	}
+
+	return nil
+}
+
+// name returns the name of the team of the author, or the name of the
+// author when not in any team.
+func (tm teams) name(author string) string {
+	if team, ok := tm[author]; ok {
+		return team
+	}
+	return author
+}
+
+// has reports whether name is the name of a team.
+func (tm teams) has(name string) bool {
+	for _, team := range tm {
+		if team == name {
+			return true
+		}
+	}
+	return false
+}
+
+// merge replaces the authors in teams by a single entry per team in the
+// given maps, summing their commits and line changes. Either map may be
+// nil.
+func (tm teams) merge(
+	commitMap map[string]int,
+	lineChangesMap map[string]LineChanges,
+) {
+	for author, team := range tm {
+		if v, ok := commitMap[author]; ok {
+			delete(commitMap, author)
+			commitMap[team] += v
+		}
+		if v, ok := lineChangesMap[author]; ok {
+			delete(lineChangesMap, author)
+			lineChangesMap[team] = lineChangesMap[team].Merge(v)
+		}
+	}
+}
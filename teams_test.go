@@ -0,0 +1,56 @@
+package gitcontrib
+
+import "testing"
+
+func Test_Teams(t *testing.T) {
+	var tm teams
+	if err := tm.Set("Core=Author One, Author Two"); err != nil {
+		t.Fatalf("error setting team: %s", err)
+	}
+	if err := tm.Set("Docs=Author One"); err == nil {
+		t.Errorf("Expected error for author in two teams")
+	}
+	for _, invalid := range []string{"Core", "=Author One", "Core="} {
+		if err := tm.Set(invalid); err == nil {
+			t.Errorf("Expected error for team %q", invalid)
+		}
+	}
+
+	commitMap := map[string]int{
+		"Author One":   2,
+		"Author Two":   3,
+		"Author Three": 1,
+	}
+	lineChangesMap := map[string]LineChanges{
+		"Author One":   {Additions: 5, Deletions: 1},
+		"Author Two":   {Additions: 10},
+		"Author Three": {Additions: 1},
+	}
+
+	tm.merge(commitMap, lineChangesMap)
+
+	if len(commitMap) != 2 || commitMap["Core"] != 5 || commitMap["Author Three"] != 1 {
+		t.Errorf("unexpected commits: %v", commitMap)
+	}
+	if lineChangesMap["Core"] != (LineChanges{Additions: 15, Deletions: 1}) {
+		t.Errorf("unexpected line changes: %v", lineChangesMap)
+	}
+	if _, ok := lineChangesMap["Author One"]; ok {
+		t.Errorf("Expected team members to be merged: %v", lineChangesMap)
+	}
+
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n")
+
+	ff := filterFlags{teams: tm, teamsOnly: true}
+	summaries, _, err := ff.summarize(r.options(), map[string]int{"Author One": 1, "Author Three": 1}, map[string]LineChanges{
+		"Author One":   {Additions: 1},
+		"Author Three": {Additions: 1},
+	})
+	if err != nil {
+		t.Fatalf("error summarizing: %s", err)
+	}
+	if len(summaries) != 1 || summaries[0].Author != "Core" || summaries[0].CommitRatio != 0.5 {
+		t.Errorf("Expected only the team row, relative to all authors: %+v", summaries)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// templateRecord is the data a --template is executed with for each
+// author. The AuthorSummary fields are available directly, and the
+// repo-wide metrics through .Totals.
+type templateRecord struct {
+	AuthorSummary
+	Totals Totals
+}
+
+// templatePresets are the templates named by --preset, for the common
+// reports not worth writing a template for.
+var templatePresets = map[string]string{
+	"leaderboard": `{{printf "%3d. %-24s %5d commits  +%d -%d" .Rank .Author .Commits .Additions .Deletions}}`,
+	"compact":     `{{.Author}} {{.Commits}}c +{{.Additions}} -{{.Deletions}}`,
+	"detailed": `{{.Author}}
+  commits:      {{.Commits}} of {{.Totals.Commits}}
+  additions:    {{.Additions}}
+  deletions:    {{.Deletions}}
+  line ratio:   {{printf "%.3f" .LineRatio}}
+  commit ratio: {{printf "%.3f" .CommitRatio}}
+  granularity:  {{printf "%.3f" .Granularity}}`,
+}
+
+// presetNames returns the sorted names of the template presets.
+func presetNames() []string {
+	names := make([]string, 0, len(templatePresets))
+	for k := range templatePresets {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupPreset returns the template of the named preset.
+func lookupPreset(name string) (string, error) {
+	text, ok := templatePresets[name]
+	if !ok {
+		return "", fmt.Errorf(
+			"unknown preset %q, must be one of: %s",
+			name, strings.Join(presetNames(), ", "),
+		)
+	}
+	return text, nil
+}
+
+// writePresets writes the names of the template presets to w, each
+// followed by its template, indented, so that it can be copied into a
+// --template of one's own.
+func writePresets(w io.Writer) error {
+	for _, name := range presetNames() {
+		fmt.Fprintln(w, name)
+		for _, line := range strings.Split(templatePresets[name], "\n") {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+	return nil
+}
+
+// parseTemplate parses the argument of a --template flag. Arguments
+// starting with '@' name a file to read the template from.
+func parseTemplate(arg string) (*template.Template, error) {
+	text := arg
+	if strings.HasPrefix(arg, "@") {
+		buf, err := os.ReadFile(arg[1:])
+		if err != nil {
+			return nil, fmt.Errorf("error reading template file: %w", err)
+		}
+		text = strings.TrimSuffix(string(buf), "\n")
+	}
+
+	tmpl, err := template.New("record").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// executeTemplate writes one line per author to w by executing tmpl with
+// each of the summaries. Unless ranked by --rank, the rank of an author
+// is its position in the report.
+func executeTemplate(
+	w io.Writer,
+	tmpl *template.Template,
+	summaries []AuthorSummary,
+	totals Totals,
+) error {
+	for i, s := range summaries {
+		if s.Rank == 0 {
+			s.Rank = i + 1
+		}
+		err := tmpl.Execute(w, templateRecord{s, totals})
+		if err != nil {
+			return fmt.Errorf("error executing template: %w", err)
+		}
+		fmt.Fprintln(w)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_ExecuteTemplate(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author One", Commits: 3},
+		{Author: "Author Two", Commits: 1},
+	}
+	totals := Totals{Commits: 4}
+
+	tmpl, err := parseTemplate("{{.Author}}: {{.Commits}}/{{.Totals.Commits}}")
+	if err != nil {
+		t.Fatalf("error parsing template: %s", err)
+	}
+
+	buf := new(bytes.Buffer)
+	err = executeTemplate(buf, tmpl, summaries, totals)
+	if err != nil {
+		t.Fatalf("error executing template: %s", err)
+	}
+
+	exp := "Author One: 3/4\nAuthor Two: 1/4\n"
+	if buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+}
+
+func Test_TemplatePresets(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author One", Commits: 3, Additions: 10, Deletions: 2},
+		{Author: "Author Two", Commits: 1, Additions: 1},
+	}
+
+	for _, name := range presetNames() {
+		text, err := lookupPreset(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := parseTemplate(text)
+		if err != nil {
+			t.Fatalf("error parsing preset %s: %s", name, err)
+		}
+		buf := new(bytes.Buffer)
+		if err := executeTemplate(buf, tmpl, summaries, Totals{Commits: 4}); err != nil {
+			t.Errorf("error executing preset %s: %s", name, err)
+		}
+		if !strings.Contains(buf.String(), "Author Two") {
+			t.Errorf("Expected preset %s to list the authors, got: %s", name, buf)
+		}
+	}
+
+	text, _ := lookupPreset("leaderboard")
+	tmpl, _ := parseTemplate(text)
+	buf := new(bytes.Buffer)
+	if err := executeTemplate(buf, tmpl, summaries, Totals{}); err != nil {
+		t.Fatal(err)
+	}
+	exp := "  1. Author One                   3 commits  +10 -2\n  2. Author Two                   1 commits  +1 -0\n"
+	if buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+
+	if _, err := lookupPreset("fancy"); err == nil || !strings.Contains(err.Error(), "compact, detailed, leaderboard") {
+		t.Errorf("Expected an unknown preset to list the presets, got: %v", err)
+	}
+
+	sf := summaryFlags{preset: "compact", template: "{{.Author}}"}
+	if err := sf.resolvePreset(); err == nil {
+		t.Error("Expected --preset and --template to conflict")
+	}
+
+	buf.Reset()
+	if err := writePresets(buf); err != nil || !strings.HasPrefix(buf.String(), "compact\n    {{.Author}}") {
+		t.Errorf("Expected the presets to be listed, got: %q, %v", buf, err)
+	}
+}
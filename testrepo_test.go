@@ -0,0 +1,119 @@
+package gitcontrib
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo is a git repository created from scratch for a test, to run
+// the git invocations of the package against.
+type testRepo struct {
+	t   testing.TB
+	dir string
+}
+
+// newTestRepo initializes an empty repository with a main branch in a
+// temporary directory removed after the test.
+func newTestRepo(t testing.TB) *testRepo {
+	t.Helper()
+	r := &testRepo{t, t.TempDir()}
+	r.git("init", "-q", "-b", "main")
+	return r
+}
+
+// git runs git in the repository, failing the test on error. Commits are
+// authored and committed by "Test" unless the environment overrides it.
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	return r.gitEnv(nil, args...)
+}
+
+// gitEnv runs git like git, with additional environment variables.
+func (r *testRepo) gitEnv(env []string, args ...string) string {
+	r.t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_AUTHOR_NAME=Test",
+		"GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test",
+		"GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	cmd.Env = append(cmd.Env, env...)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, out)
+	}
+
+	return string(out)
+}
+
+// write writes content to the named file in the work tree, creating any
+// parent directories.
+func (r *testRepo) write(name, content string) {
+	r.t.Helper()
+
+	path := filepath.Join(r.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// commit writes content to the named file and commits it as author.
+func (r *testRepo) commit(author, name, content string) {
+	r.t.Helper()
+
+	r.write(name, content)
+	r.git("add", name)
+	r.gitEnv(
+		[]string{"GIT_AUTHOR_NAME=" + author},
+		"commit", "-q", "-m", "change "+name,
+	)
+}
+
+// options returns the options running git in the repository.
+func (r *testRepo) options() options {
+	return options{dir: r.dir}
+}
+
+// mainArgs is the environment variable holding the arguments, one per
+// line, with which the test binary runs the program instead of the tests,
+// for runMain.
+const mainArgs = "GITCONTRIB_MAIN_ARGS"
+
+func TestMain(m *testing.M) {
+	if args, ok := os.LookupEnv(mainArgs); ok {
+		os.Args = append([]string{"gitcontrib"}, strings.Split(args, "\n")...)
+		Run()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the program with the given arguments in a process of its
+// own, and returns its status code and what it wrote to standard error.
+func runMain(t testing.TB, args ...string) (int, string) {
+	t.Helper()
+
+	var stderr strings.Builder
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), mainArgs+"="+strings.Join(args, "\n"))
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("error running %v: %s", args, err)
+	}
+	return cmd.ProcessState.ExitCode(), stderr.String()
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "time"
+
+// monthlyCommits returns the number of commits of each author per calendar
+// month, by author date in the time zone of the dates, from the month of
+// the first commit to that of the last. Every author gets the same months,
+// so the counts line up.
+func monthlyCommits(commits []commit) map[string][]int {
+	return monthly(commits, func(commit) int { return 1 })
+}
+
+// monthlyLineChanges returns the changed lines of each author per calendar
+// month, over the same months as monthlyCommits.
+func monthlyLineChanges(commits []commit) map[string][]int {
+	return monthly(commits, func(c commit) int { return c.LineChanges().Sum() })
+}
+
+// monthly returns the sum of the given measure of the commits of each
+// author per calendar month, from the month of the first commit to that of
+// the last.
+func monthly(commits []commit, measure func(commit) int) map[string][]int {
+	activity := make(map[string][]int)
+	if len(commits) == 0 {
+		return activity
+	}
+
+	first, last := monthIndex(commits[0].Date), monthIndex(commits[0].Date)
+	for _, c := range commits {
+		m := monthIndex(c.Date)
+		if m < first {
+			first = m
+		}
+		if m > last {
+			last = m
+		}
+	}
+
+	months := last - first + 1
+	for _, c := range commits {
+		counts, ok := activity[c.Author]
+		if !ok {
+			counts = make([]int, months)
+			activity[c.Author] = counts
+		}
+		counts[monthIndex(c.Date)-first] += measure(c)
+	}
+
+	return activity
+}
+
+// monthIndex returns the number of months from year 0 to the month of t in
+// its time zone, so that consecutive months have consecutive indices.
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
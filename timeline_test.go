@@ -0,0 +1,39 @@
+package gitcontrib
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_MonthlyCommits(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	commits := []commit{
+		{Author: "Author One", Date: date("2023-03-31T23:30:00-02:00")},
+		{Author: "Author Two", Date: date("2023-02-10T10:00:00Z")},
+		{Author: "Author One", Date: date("2023-01-31T12:00:00Z")},
+		{Author: "Author One", Date: date("2022-12-01T00:00:00Z")},
+	}
+
+	// the first commit is made in March in its own time zone, though in
+	// April in UTC
+	got := monthlyCommits(commits)
+	exp := map[string][]int{
+		"Author One": {1, 1, 0, 1},
+		"Author Two": {0, 0, 1, 0},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got: %v", exp, got)
+	}
+
+	if got := monthlyCommits(nil); len(got) != 0 {
+		t.Errorf("Expected no activity without commits, got: %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"strings"
+)
+
+// trailer is a single "Key: value" trailer of a commit message, like
+// "Reviewed-by: Jane Doe <jane@example.com>".
+type trailer struct {
+	Key   string
+	Value string
+}
+
+// trailersFormat returns the git log format placeholder expanding to the
+// trailers with the given keys, one per line and unfolded.
+func trailersFormat(keys ...string) string {
+	opts := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		opts = append(opts, "key="+k)
+	}
+	opts = append(opts, "unfold")
+	return "%(trailers:" + strings.Join(opts, ",") + ")"
+}
+
+// parseTrailers parses the trailer lines of a single commit, as written
+// by trailersFormat. Lines without a key are skipped.
+func parseTrailers(lines string) []trailer {
+	var trailers []trailer
+	for _, line := range strings.Split(lines, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		trailers = append(trailers, trailer{
+			Key:   strings.TrimSpace(key),
+			Value: strings.TrimSpace(value),
+		})
+	}
+	return trailers
+}
+
+// trailerName returns the name part of a trailer value identifying a
+// person, leaving out the email in angle brackets.
+func trailerName(value string) string {
+	if i := strings.Index(value, "<"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// mailmapName returns the name of the person of a contact like
+// "Name <email>", mapped through the .mailmap file like the authors
+// unless --no-mailmap is given. A contact without an email, or that git
+// can't parse, keeps its name.
+func (o options) mailmapName(contact string) string {
+	name := trailerName(contact)
+	if o.noMailmap || !strings.Contains(contact, "<") {
+		return name
+	}
+
+	out, err := o.git("check-mailmap", contact)
+	if err != nil {
+		return name
+	}
+	if i := strings.LastIndex(out, " <"); i > 0 {
+		return out[:i]
+	}
+	return name
+}
+
+// trailerNamer returns a function naming the person of a trailer value
+// the way the authors are named, through mailmapName and authorName, so
+// that a co-author is credited under the same name as their own commits.
+// Each value is only looked up once.
+func (o options) trailerNamer() func(value string) string {
+	names := make(map[string]string)
+	return func(value string) string {
+		name, ok := names[value]
+		if !ok {
+			name = o.authorName(o.mailmapName(value))
+			names[value] = name
+		}
+		return name
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"math"
+	"sort"
+)
+
+// recentMonths is the number of months at the end of the history summed
+// up as the recent line changes of an author.
+const recentMonths = 3
+
+// flatSlope is the slope, in changed lines per month, below which a trend
+// is considered flat.
+const flatSlope = 1.0
+
+// authorTrend holds whether the monthly line changes of an author are
+// growing or shrinking.
+type authorTrend struct {
+	Author string
+	Recent int     // changed lines in the last recentMonths months
+	Slope  float64 // change of the monthly changed lines per month
+}
+
+// Direction returns an arrow pointing up for a growing trend, down for a
+// shrinking one and sideways for a flat one.
+func (t authorTrend) Direction() string {
+	switch {
+	case t.Slope >= flatSlope:
+		return "↑"
+	case t.Slope <= -flatSlope:
+		return "↓"
+	default:
+		return "→"
+	}
+}
+
+// authorTrends returns the trend of the monthly line changes of each
+// author of the commits, sorted by descending slope, then by author. The
+// slope is fitted from the first month an author was active in to the end
+// of the history, so that months before they joined don't count as
+// growth. Authors active in a single month only have a flat trend.
+func authorTrends(commits []commit) []authorTrend {
+	var trends []authorTrend
+	for author, months := range monthlyLineChanges(commits) {
+		t := authorTrend{Author: author}
+
+		for i := len(months) - recentMonths; i < len(months); i++ {
+			if i >= 0 {
+				t.Recent += months[i]
+			}
+		}
+
+		start, active := -1, 0
+		for i, n := range months {
+			if n > 0 {
+				active++
+				if start < 0 {
+					start = i
+				}
+			}
+		}
+		if active > 1 {
+			t.Slope = slope(months[start:])
+		}
+
+		trends = append(trends, t)
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Slope != trends[j].Slope {
+			return trends[i].Slope > trends[j].Slope
+		}
+		return trends[i].Author < trends[j].Author
+	})
+
+	return trends
+}
+
+// slope returns the slope of the least-squares line fitted through the
+// values, placed one unit apart, or 0 for fewer than two values.
+func slope(ys []int) float64 {
+	n := float64(len(ys))
+	if n < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range ys {
+		x := float64(i)
+		sumX += x
+		sumY += float64(y)
+		sumXY += x * float64(y)
+		sumXX += x * x
+	}
+
+	d := n*sumXX - sumX*sumX
+	if d == 0 {
+		return 0
+	}
+
+	s := (n*sumXY - sumX*sumY) / d
+	if math.Abs(s) < 1e-9 {
+		return 0 // avoids printing -0.0
+	}
+	return s
+}
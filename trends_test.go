@@ -0,0 +1,52 @@
+package gitcontrib
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_AuthorTrends(t *testing.T) {
+	month := func(m int, author string, lines int) commit {
+		return commit{
+			Author: author,
+			Date:   time.Date(2023, time.Month(m), 15, 12, 0, 0, 0, time.UTC),
+			Files:  []fileChange{{Path: "a.txt", LineChanges: LineChanges{Additions: lines}}},
+		}
+	}
+
+	commits := []commit{
+		month(1, "Grower", 10), month(2, "Grower", 20), month(3, "Grower", 30), month(4, "Grower", 40),
+		month(1, "Shrinker", 40), month(2, "Shrinker", 20), month(3, "Shrinker", 10),
+		month(3, "Once", 500),
+		month(2, "Steady", 5), month(4, "Steady", 5), month(3, "Steady", 5),
+	}
+
+	trends := authorTrends(commits)
+	got := make(map[string]authorTrend)
+	var order []string
+	for _, tr := range trends {
+		got[tr.Author] = tr
+		order = append(order, tr.Author)
+	}
+
+	if tr := got["Grower"]; tr.Slope != 10 || tr.Direction() != "↑" || tr.Recent != 90 {
+		t.Errorf("unexpected trend of growing author: %+v", tr)
+	}
+	if tr := got["Shrinker"]; math.Abs(tr.Slope+13) > 1e-9 || tr.Direction() != "↓" {
+		t.Errorf("unexpected trend of shrinking author: %+v", tr)
+	}
+	if tr := got["Once"]; tr.Slope != 0 || tr.Direction() != "→" || tr.Recent != 500 {
+		t.Errorf("Expected a flat trend for a single active month: %+v", tr)
+	}
+	if tr := got["Steady"]; tr.Slope != 0 || tr.Direction() != "→" || tr.Recent != 15 {
+		t.Errorf("Expected a flat trend for steady author, not counting the months before: %+v", tr)
+	}
+	if order[0] != "Grower" || order[len(order)-1] != "Shrinker" {
+		t.Errorf("Expected authors by descending slope, got %v", order)
+	}
+
+	if s := slope([]int{7}); s != 0 {
+		t.Errorf("Expected slope 0 for a single value, got %v", s)
+	}
+}
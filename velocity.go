@@ -0,0 +1,81 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import "time"
+
+// velocity holds the rate of change of a repo, over the days and weeks of
+// its history.
+type velocity struct {
+	Commits int
+	Lines   int
+	Days    int
+	Weeks   float64
+}
+
+// LinesPerDay returns the average number of changed lines per day.
+func (v velocity) LinesPerDay() float64 {
+	if v.Days == 0 {
+		return 0
+	}
+	return float64(v.Lines) / float64(v.Days)
+}
+
+// CommitsPerWeek returns the average number of commits per week.
+func (v velocity) CommitsPerWeek() float64 {
+	if v.Weeks == 0 {
+		return 0
+	}
+	return float64(v.Commits) / v.Weeks
+}
+
+// repoVelocity returns the velocity of the non-merge commits. The days
+// span the history from the day of the first commit to that of the last,
+// both included, and the weeks are the days divided by seven. With
+// activeOnly, only the days with commits are counted, and the weeks are
+// the distinct ISO weeks with commits, so that dormant periods don't water
+// down the rates.
+func repoVelocity(commits []commit, activeOnly bool) velocity {
+	var v velocity
+	days := make(map[string]bool)
+	weeks := make(map[[2]int]bool)
+	var first, last string
+
+	for _, c := range commits {
+		if c.isMerge() {
+			continue
+		}
+
+		v.Commits++
+		v.Lines += c.LineChanges().Sum()
+
+		day := c.Date.Format("2006-01-02")
+		days[day] = true
+		year, week := c.Date.ISOWeek()
+		weeks[[2]int{year, week}] = true
+		if first == "" || day < first {
+			first = day
+		}
+		if day > last {
+			last = day
+		}
+	}
+
+	if v.Commits == 0 {
+		return v
+	}
+
+	if activeOnly {
+		v.Days = len(days)
+		v.Weeks = float64(len(weeks))
+		return v
+	}
+
+	from, _ := time.Parse("2006-01-02", first)
+	to, _ := time.Parse("2006-01-02", last)
+	v.Days = int(to.Sub(from).Hours()/24) + 1
+	v.Weeks = float64(v.Days) / 7
+
+	return v
+}
@@ -0,0 +1,48 @@
+package gitcontrib
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_RepoVelocity(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	lines := func(n int) []fileChange {
+		return []fileChange{{Path: "a", LineChanges: LineChanges{Additions: n}}}
+	}
+
+	// two busy weeks a year apart
+	commits := []commit{
+		{Date: day("2023-01-02"), Files: lines(10)},
+		{Date: day("2023-01-02"), Files: lines(20)},
+		{Date: day("2023-01-03"), Files: lines(30)},
+		{Date: day("2023-01-03"), Parents: []string{"a", "b"}},
+		{Date: day("2024-01-01"), Files: lines(40)},
+	}
+
+	v := repoVelocity(commits, false)
+	if v.Commits != 4 || v.Lines != 100 || v.Days != 365 {
+		t.Errorf("unexpected calendar velocity: %+v", v)
+	}
+	if got := v.CommitsPerWeek(); got < 0.076 || got > 0.077 {
+		t.Errorf("Expected about 0.077 commits per week, got %f", got)
+	}
+
+	v = repoVelocity(commits, true)
+	if v.Days != 3 || v.Weeks != 2 {
+		t.Errorf("Expected 3 active days in 2 weeks, got %+v", v)
+	}
+	if v.LinesPerDay() != 100.0/3 || v.CommitsPerWeek() != 2 {
+		t.Errorf("unexpected active rates: %f, %f", v.LinesPerDay(), v.CommitsPerWeek())
+	}
+
+	if v := repoVelocity(nil, true); v.LinesPerDay() != 0 || v.CommitsPerWeek() != 0 {
+		t.Errorf("Expected no velocity without commits, got %+v", v)
+	}
+}
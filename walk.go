@@ -0,0 +1,83 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"io"
+	"time"
+)
+
+// Commit is a commit of the history walked by WalkCommits, with the line
+// changes of each of its files.
+type Commit struct {
+	Hash    string
+	Parents []string
+	Author  string
+	Email   string
+	Date    time.Time
+	Subject string
+
+	// Files are the files changed by the commit, being none for merge
+	// commits.
+	Files []FileChange
+}
+
+// FileChange is the line changes of a file of a commit. Binary files have
+// none. A renamed file has the path it was renamed from as OldPath.
+type FileChange struct {
+	Path    string
+	OldPath string
+	LineChanges
+}
+
+// IsMerge reports whether the commit has more than one parent.
+func (c Commit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// LineChanges returns the sum of the line changes of all files of the
+// commit.
+func (c Commit) LineChanges() LineChanges {
+	var lc LineChanges
+	for _, f := range c.Files {
+		lc = lc.Merge(f.LineChanges)
+	}
+	return lc
+}
+
+// exported returns the commit as passed to the callback of WalkCommits.
+func (c commit) exported() Commit {
+	files := make([]FileChange, len(c.Files))
+	for i, f := range c.Files {
+		files[i] = FileChange{f.Path, f.OldPath, f.LineChanges}
+	}
+	return Commit{c.Hash, c.Parents, c.Author, c.Email, c.Date, c.Subject, files}
+}
+
+// WalkCommits calls fn with each commit of the history selected by the
+// fields of r, newest first, merge commits included, so that metrics of
+// any kind can be computed without parsing git output. Each commit is
+// passed on as soon as git log writes it, so that the history is never
+// held in memory as a whole. The walk stops at the first error returned
+// by fn, which is returned. ErrNoCommits is returned when there are no
+// commits.
+func (r *Repo) WalkCommits(fn func(Commit) error) error {
+	return walkCommits(r.options(), fn)
+}
+
+// walkCommits calls fn with each commit in the revision or range of o,
+// like WalkCommits. Unlike logCommits, it doesn't apply the flags of o
+// skipping commits or line changes, which no field of a Repo sets.
+func walkCommits(o options, fn func(Commit) error) error {
+	if o.rev == "" && len(o.shas) == 0 && !hasCommits(o) {
+		return ErrNoCommits
+	}
+
+	err := o.walkStream(func(r io.Reader) error {
+		return readCommits(r, func(c commit) error {
+			return fn(o.reported(c).exported())
+		})
+	}, "log", "--numstat", o.logFormat(commitFormat))
+	return o.drift(err)
+}
@@ -0,0 +1,78 @@
+package gitcontrib
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_WalkCommits(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n3\n")
+	r.git("checkout", "-q", "-b", "feature")
+	r.commit("Author Two", "two.txt", "1\n")
+	r.write("one.txt", "1\n")
+	r.git("commit", "-qam", "shorten")
+	r.git("checkout", "-q", "main")
+	r.gitEnv([]string{"GIT_AUTHOR_NAME=Author One"}, "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+
+	repo := &Repo{Path: r.dir, UseMailmap: true}
+	commitMap := make(map[string]int)
+	lineChangesMap := make(map[string]LineChanges)
+	var merges int
+	err := repo.WalkCommits(func(c Commit) error {
+		if c.IsMerge() {
+			merges++
+			return nil
+		}
+		commitMap[c.Author]++
+		for _, f := range c.Files {
+			lineChangesMap[c.Author] = lineChangesMap[c.Author].Merge(f.LineChanges)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error walking commits: %s", err)
+	}
+	if merges != 1 {
+		t.Errorf("Expected the merge commit to be walked, got %d", merges)
+	}
+
+	o := r.options()
+	expCommits, err := authorCommits(o)
+	if err != nil {
+		t.Fatalf("error getting author commits: %s", err)
+	}
+	expLineChanges, err := mapLineChanges(o)
+	if err != nil {
+		t.Fatalf("error getting line changes: %s", err)
+	}
+	exp, expTotals, _ := Summarize(expCommits, expLineChanges)
+
+	got, totals, _ := Summarize(commitMap, lineChangesMap)
+	if !reflect.DeepEqual(got, exp) || totals != expTotals {
+		t.Errorf("Expected %+v, %+v from the walk, got %+v, %+v", exp, expTotals, got, totals)
+	}
+
+	summaries, totals, err := repo.Summarize()
+	if err != nil {
+		t.Fatalf("error summarizing: %s", err)
+	}
+	if !reflect.DeepEqual(summaries, exp) || totals.Commits != expTotals.Commits || totals.First.IsZero() {
+		t.Errorf("Expected %+v, %+v from Summarize, got %+v, %+v", exp, expTotals, summaries, totals)
+	}
+
+	stop := errors.New("stop")
+	var walked int
+	err = repo.WalkCommits(func(c Commit) error {
+		walked++
+		return stop
+	})
+	if err != stop || walked != 1 {
+		t.Errorf("Expected the walk to stop at the first error, got %v after %d commits", err, walked)
+	}
+
+	if err := (&Repo{Path: newTestRepo(t).dir}).WalkCommits(func(Commit) error { return nil }); !errors.Is(err, ErrNoCommits) {
+		t.Errorf("Expected %v for a repo without commits, got %v", ErrNoCommits, err)
+	}
+}
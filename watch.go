@@ -0,0 +1,50 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// clearScreen moves the cursor of a terminal to the top left and clears
+// the screen.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watch calls render with w each time the commit of the revision of o
+// changes, as polled every interval, after clearing the screen. Errors of
+// render are logged rather than ending the watch, like when the history
+// is read halfway through a rebase. It returns when ctx is done.
+func watch(
+	ctx context.Context,
+	o options,
+	interval time.Duration,
+	w io.Writer,
+	render func(w io.Writer) error,
+) error {
+
+	var last string
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		rev, err := resolveRev(o)
+		if err != nil {
+			warnf("error resolving revision: %s", err)
+		} else if rev != last {
+			last = rev
+			io.WriteString(w, clearScreen)
+			if err := render(w); err != nil {
+				Logger.Error(err.Error())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
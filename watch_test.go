@@ -0,0 +1,41 @@
+package gitcontrib
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Watch(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "a.txt", "1\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var renders int
+	buf := new(bytes.Buffer)
+	err := watch(ctx, r.options(), 5*time.Millisecond, buf, func(w io.Writer) error {
+		renders++
+		switch renders {
+		case 1:
+			r.commit("Author Two", "b.txt", "1\n")
+		case 2:
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if renders != 2 {
+		t.Errorf("Expected a render per commit of HEAD, got: %d", renders)
+	}
+	if got := strings.Count(buf.String(), clearScreen); got != 2 {
+		t.Errorf("Expected the screen cleared before each render, got: %d", got)
+	}
+}
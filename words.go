@@ -0,0 +1,150 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// wordsFormat is the git log format used for counting the words of the
+// commit subjects, giving the author and subject of each commit on a line,
+// separated by a NUL byte.
+var wordsFormat = "--format=%aN%x00%s"
+
+// defaultStopwords are the common English words left out of the word
+// counts, unless replaced by --stopwords-file.
+var defaultStopwords = []string{
+	"a", "about", "after", "all", "also", "an", "and", "any", "are", "as",
+	"at", "be", "been", "before", "but", "by", "can", "do", "does", "for",
+	"from", "has", "have", "if", "in", "into", "is", "it", "its", "more",
+	"no", "not", "of", "on", "only", "or", "other", "out", "over", "so",
+	"some", "than", "that", "the", "their", "them", "then", "there",
+	"these", "they", "this", "to", "too", "up", "was", "we", "were", "when",
+	"which", "while", "will", "with", "without", "you", "your",
+}
+
+// wordCount is the number of commit subjects of an author using a word.
+type wordCount struct {
+	Author string
+	Word   string
+	Count  int
+}
+
+// stopwordSet returns the stopwords as a set, lowercased.
+func stopwordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[strings.ToLower(w)] = true
+	}
+	return set
+}
+
+// readStopwords returns the stopwords listed in the file, one or more per
+// line. Blank lines and lines starting with # are skipped.
+func readStopwords(path string) ([]string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading stopwords file: %w", err)
+	}
+
+	var words []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		words = append(words, fields...)
+	}
+
+	return words, nil
+}
+
+// subjectWords returns the non-merge commit subjects of each author in the
+// revision or range of o, using HEAD when none is given, counted by word.
+func subjectWords(o options, stopwords map[string]bool) (map[string]map[string]int, error) {
+	out, err := o.walk("log", "--no-merges", o.logFormat(wordsFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSubjectWords(out, stopwords), nil
+}
+
+// parseSubjectWords counts the words of the commit subjects of each author
+// in the output of git log with wordsFormat. The subjects are split into
+// lowercased words at every character that is neither a letter nor a
+// digit, and the stopwords and plain numbers, like those of pull
+// requests, are left out. A word used twice in a subject counts once.
+func parseSubjectWords(gitOutput string, stopwords map[string]bool) map[string]map[string]int {
+	counts := make(map[string]map[string]int)
+
+	for _, line := range strings.Split(gitOutput, "\n") {
+		author, subject, ok := strings.Cut(line, "\x00")
+		if !ok || author == "" {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(strings.ToLower(subject), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if seen[w] || stopwords[w] || isNumber(w) {
+				continue
+			}
+			seen[w] = true
+
+			if counts[author] == nil {
+				counts[author] = make(map[string]int)
+			}
+			counts[author][w]++
+		}
+	}
+
+	return counts
+}
+
+// isNumber reports whether the word only has digits.
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// topWords returns the n most frequent words of each author, by author
+// and then by descending count, ties ordered by word. Authors whose
+// subjects had no words left after the stopwords aren't listed.
+func topWords(counts map[string]map[string]int, n int) []wordCount {
+	authors := make([]string, 0, len(counts))
+	for k := range counts {
+		authors = append(authors, k)
+	}
+	sort.Strings(authors)
+
+	var top []wordCount
+	for _, author := range authors {
+		words := make([]wordCount, 0, len(counts[author]))
+		for w, c := range counts[author] {
+			words = append(words, wordCount{author, w, c})
+		}
+		sort.Slice(words, func(i, j int) bool {
+			if words[i].Count != words[j].Count {
+				return words[i].Count > words[j].Count
+			}
+			return words[i].Word < words[j].Word
+		})
+		if len(words) > n {
+			words = words[:n]
+		}
+		top = append(top, words...)
+	}
+
+	return top
+}
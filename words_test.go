@@ -0,0 +1,56 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_SubjectWords(t *testing.T) {
+	out := "Author One\x00Fix the parser (#12)\n" +
+		"Author One\x00Fix parser tests, fix CI\n" +
+		"Author One\x00Add the README\n" +
+		"Author Two\x00the and of\n" +
+		"Author Two\x00Tidy\n" +
+		"Author Three\x00\n"
+
+	counts := parseSubjectWords(out, stopwordSet(defaultStopwords))
+	exp := map[string]map[string]int{
+		"Author One": {"fix": 2, "parser": 2, "tests": 1, "ci": 1, "add": 1, "readme": 1},
+		"Author Two": {"tidy": 1},
+	}
+	if !reflect.DeepEqual(counts, exp) {
+		t.Errorf("Expected %v, got %v", exp, counts)
+	}
+
+	top := topWords(counts, 3)
+	expTop := []wordCount{
+		{"Author One", "fix", 2},
+		{"Author One", "parser", 2},
+		{"Author One", "add", 1},
+		{"Author Two", "tidy", 1},
+	}
+	if !reflect.DeepEqual(top, expTop) {
+		t.Errorf("Expected %v, got %v", expTop, top)
+	}
+}
+
+func Test_ReadStopwords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stopwords.txt")
+	content := "# project jargon\nfix  Add\n\nwip\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := readStopwords(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := []string{"fix", "Add", "wip"}; !reflect.DeepEqual(words, exp) {
+		t.Errorf("Expected %v, got %v", exp, words)
+	}
+	if set := stopwordSet(words); !set["add"] {
+		t.Errorf("Expected the stopwords lowercased, got %v", set)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// uncommitted is the uncommitted line changes of the working tree and the
+// index, credited to the current git user.
+type uncommitted struct {
+	Author string
+	LineChanges
+}
+
+// readUncommitted returns the line changes of the working tree and of the
+// index against HEAD, in the paths of o, credited to the git user of the
+// repo of o. Both are empty without any uncommitted changes.
+func readUncommitted(o options) (uncommitted, error) {
+	var outs []string
+	for _, args := range [][]string{
+		{"diff", "--numstat"},
+		{"diff", "--cached", "--numstat"},
+	} {
+		if len(o.paths) > 0 {
+			args = append(append(args, "--"), o.paths...)
+		}
+		out, err := o.git(args...)
+		if err != nil {
+			return uncommitted{}, fmt.Errorf("error reading uncommitted changes: %w", err)
+		}
+		outs = append(outs, out)
+	}
+
+	lc := parseUncommitted(outs...)
+	if lc.Sum() == 0 {
+		return uncommitted{}, nil
+	}
+
+	author, err := gitUser(o)
+	if err != nil {
+		return uncommitted{}, err
+	}
+
+	return uncommitted{author, lc}, nil
+}
+
+// parseUncommitted returns the sum of the line changes of the outputs of
+// git diff --numstat, like those of the working tree and of the index.
+func parseUncommitted(gitOutputs ...string) LineChanges {
+	var lc LineChanges
+	for _, out := range gitOutputs {
+		lc = lc.Merge(sumNumstat(out))
+	}
+	return lc
+}
+
+// gitUser returns the name of the git user of the repo of o, as set by
+// user.name, mapped through the .mailmap file like the authors unless
+// --no-mailmap is given.
+func gitUser(o options) (string, error) {
+	out, err := o.git("config", "user.name")
+	name := strings.TrimSpace(out)
+	if err != nil || name == "" {
+		return "", errors.New("no git user.name set to credit the uncommitted changes to")
+	}
+	if o.noMailmap {
+		return name, nil
+	}
+
+	email, _ := o.git("config", "user.email")
+	return o.mailmapName(name + " <" + strings.TrimSpace(email) + ">"), nil
+}
@@ -0,0 +1,73 @@
+package gitcontrib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_ParseUncommitted(t *testing.T) {
+	unstaged := "3\t1\tgit.go\n-\t-\tlogo.png\n"
+	staged := "10\t0\tworktree.go\n"
+
+	lc := parseUncommitted(unstaged, staged)
+	if exp := (LineChanges{Additions: 13, Deletions: 1}); lc != exp {
+		t.Errorf("Expected %v, got %v", exp, lc)
+	}
+
+	if lc := parseUncommitted("", ""); lc != (LineChanges{}) {
+		t.Errorf("Expected no line changes without a diff, got %v", lc)
+	}
+}
+
+func Test_ReadUncommitted(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.git("config", "user.name", "Author Two")
+
+	u, err := readUncommitted(r.options())
+	if err != nil || u != (uncommitted{}) {
+		t.Errorf("Expected nothing without uncommitted changes, got %+v, %v", u, err)
+	}
+
+	r.write("one.txt", "1\n3\n4\n")
+	r.write("two.txt", "1\n")
+	r.git("add", "two.txt")
+
+	u, err = readUncommitted(r.options())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := uncommitted{"Author Two", LineChanges{Additions: 3, Deletions: 1}}
+	if u != exp {
+		t.Errorf("Expected %+v, got %+v", exp, u)
+	}
+}
+
+func Test_UncommittedMarker(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit("Author One", "one.txt", "1\n2\n")
+	r.commit("Author Two", "two.txt", "1\n")
+	r.git("config", "user.name", "Author Two")
+	r.write("two.txt", "1\n2\n")
+
+	for format, exp := range map[string]string{
+		"table": "Author Two *",
+		"json":  `"author":"Author Two"`,
+	} {
+		out := filepath.Join(t.TempDir(), "out.txt")
+		err := ContributionSummaryCmd.Call(ContributionSummaryCmd,
+			"--repo", r.dir, "--include-working-tree", "--format", format, "--output", out)
+		if err != nil {
+			t.Fatalf("error running summary: %s", err)
+		}
+		buf, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(buf), exp) || format != "table" && strings.Contains(string(buf), "*") {
+			t.Errorf("Expected %s in the %s report, got:\n%s", exp, format, buf)
+		}
+	}
+}
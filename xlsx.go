@@ -0,0 +1,281 @@
+// Copyright 2023 gitcontrib Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcontrib
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The xlsx format is written with archive/zip and hand-written
+// SpreadsheetML rather than a spreadsheet library, keeping gitcontrib free
+// of the heavy dependencies such libraries pull in. The workbook only uses
+// inline strings and a handful of cell styles, which every spreadsheet
+// application reads.
+
+// xlsxStyle is the index of a cell style in the cellXfs of xlsxStyles.
+type xlsxStyle int
+
+const (
+	xlsxDefault xlsxStyle = iota
+	xlsxHeader            // bold on a grey fill, with a bottom border
+	xlsxRatio             // three decimals, like the table
+	xlsxWeight            // one decimal, like the Weighted column
+)
+
+// xlsxCell is a single cell of a sheet, either a string or a number.
+type xlsxCell struct {
+	text   string
+	number float64
+	isNum  bool
+	style  xlsxStyle
+}
+
+func xlsxText(s string) xlsxCell { return xlsxCell{text: s} }
+
+func xlsxInt(n int) xlsxCell { return xlsxCell{number: float64(n), isNum: true} }
+
+func xlsxFloat(f float64, style xlsxStyle) xlsxCell {
+	return xlsxCell{number: f, isNum: true, style: style}
+}
+
+// xlsxSheet is a named sheet of a workbook, its first row being a header
+// frozen in place when scrolling.
+type xlsxSheet struct {
+	name   string
+	widths []int // in characters, by column
+	rows   [][]xlsxCell
+}
+
+// writeSummaryXLSX writes the summaries to w as an Excel workbook, with
+// the summary table on the first sheet and the repo-wide totals on the
+// second.
+func writeSummaryXLSX(
+	w io.Writer,
+	summaries []AuthorSummary,
+	totals Totals,
+	opts renderOptions,
+) error {
+
+	summary := xlsxSheet{name: "Summary"}
+	header := summaryRows(nil, opts)[0]
+	summary.rows = append(summary.rows, xlsxHeaderRow(header))
+	for _, h := range header {
+		summary.widths = append(summary.widths, len(h)+2)
+	}
+
+	for _, s := range summaries {
+		row := []xlsxCell{
+			xlsxText(s.Author),
+			xlsxInt(s.Commits),
+			xlsxInt(s.Additions),
+			xlsxInt(s.Deletions),
+			xlsxFloat(s.LineRatio, xlsxRatio),
+			xlsxFloat(s.CommitRatio, xlsxRatio),
+			xlsxFloat(s.Granularity, xlsxRatio),
+		}
+		if opts.decay {
+			row = append(row, xlsxFloat(s.Weighted, xlsxWeight))
+		}
+		if opts.files {
+			row = append(row, xlsxInt(s.FilesTouched))
+		}
+		if opts.breadth {
+			row = append(row, xlsxFloat(s.FilesPerCommit, xlsxWeight), xlsxFloat(s.Breadth, xlsxWeight))
+		}
+		if opts.merges {
+			row = append(row, xlsxInt(s.Merges))
+		}
+		if opts.survive {
+			row = append(row, xlsxInt(s.Surviving), xlsxFloat(s.Footprint, xlsxRatio))
+		}
+		if opts.extra {
+			if s.Additions == 0 {
+				row = append(row, xlsxText(deletionsPerAdditionCell(s)))
+			} else {
+				row = append(row, xlsxFloat(s.DeletionsPerAddition(), xlsxRatio))
+			}
+		}
+		if opts.selfRatios {
+			if s.Additions+s.Deletions == 0 {
+				row = append(row, xlsxText(""), xlsxText(""))
+			} else {
+				row = append(row, xlsxFloat(s.AdditionShare(), xlsxRatio), xlsxFloat(s.DeletionShare(), xlsxRatio))
+			}
+		}
+		summary.rows = append(summary.rows, row)
+
+		if n := len(s.Author) + 2; n > summary.widths[0] {
+			summary.widths[0] = n
+		}
+	}
+
+	overall := xlsxSheet{
+		name:   "Totals",
+		widths: []int{34, 14},
+		rows: [][]xlsxCell{
+			xlsxHeaderRow([]string{"Metric", "Value"}),
+			{xlsxText("Commits"), xlsxInt(totals.Commits)},
+			{xlsxText("Additions"), xlsxInt(totals.Additions)},
+			{xlsxText("Deletions"), xlsxInt(totals.Deletions)},
+			{xlsxText("Overall repo commit granularity"), xlsxFloat(totals.Granularity, xlsxRatio)},
+			{xlsxText("Gini coefficient of line changes"), xlsxFloat(totals.Gini, xlsxRatio)},
+		},
+	}
+	if !totals.First.IsZero() {
+		overall.rows = append(overall.rows,
+			[]xlsxCell{xlsxText("First commit"), xlsxText(totals.First.Format("2006-01-02"))},
+			[]xlsxCell{xlsxText("Last commit"), xlsxText(totals.Last.Format("2006-01-02"))},
+			[]xlsxCell{xlsxText("Days"), xlsxInt(totals.Days())},
+		)
+	}
+
+	if err := writeWorkbook(w, []xlsxSheet{summary, overall}); err != nil {
+		return fmt.Errorf("error writing xlsx: %w", err)
+	}
+
+	return nil
+}
+
+// xlsxHeaderRow returns the header cells with the given names.
+func xlsxHeaderRow(names []string) []xlsxCell {
+	row := make([]xlsxCell, len(names))
+	for i, n := range names {
+		row[i] = xlsxCell{text: n, style: xlsxHeader}
+	}
+	return row
+}
+
+// writeWorkbook writes the sheets to w as the zipped parts of a workbook.
+func writeWorkbook(w io.Writer, sheets []xlsxSheet) error {
+	var types, sheetList, rels strings.Builder
+	for i, s := range sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&sheetList, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(s.name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", xml.Header +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header +
+			`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheetList.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, s := range sheets {
+		parts = append(parts, struct{ name, content string }{
+			fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml(),
+		})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// xlsxStyles holds the cell styles, indexed by xlsxStyle.
+const xlsxStyles = xml.Header +
+	`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="0.000"/><numFmt numFmtId="165" formatCode="0.0"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFE8E8E8"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="2"><border><left/><right/><top/><bottom/><diagonal/></border>` +
+	`<border><left/><right/><top/><bottom style="thin"><color auto="1"/></bottom><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// xml returns the worksheet part of the sheet, with the header row frozen.
+func (s xlsxSheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	b.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	b.WriteString(`</sheetView></sheetViews>`)
+
+	if len(s.widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, w := range s.widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, w)
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for i, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, c := range row {
+			ref := xlsxColumn(j) + strconv.Itoa(i+1)
+			style := ""
+			if c.style != xlsxDefault {
+				style = fmt.Sprintf(` s="%d"`, c.style)
+			}
+			if c.isNum {
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`,
+					ref, style, strconv.FormatFloat(c.number, 'g', -1, 64))
+			} else {
+				fmt.Fprintf(&b, `<c r="%s"%s t="inlineStr"><is><t>%s</t></is></c>`,
+					ref, style, xmlEscape(c.text))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+
+	return b.String()
+}
+
+// xlsxColumn returns the letters naming the column of index i, from "A"
+// for 0 through "Z", "AA" and on.
+func xlsxColumn(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// xmlEscape escapes s for use as XML text or attribute value.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
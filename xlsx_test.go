@@ -0,0 +1,88 @@
+package gitcontrib
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_WriteSummaryXLSX(t *testing.T) {
+	summaries := []AuthorSummary{
+		{Author: "Author <One> & Co", Commits: 12, Additions: 100, Deletions: 20, LineRatio: 0.75, CommitRatio: 0.8, Granularity: 0.1},
+		{Author: "Two", Commits: 3, Deletions: 10, LineRatio: 0.25, CommitRatio: 0.2, Granularity: 0.3},
+	}
+
+	buf := new(bytes.Buffer)
+	err := writeSummaryXLSX(buf, summaries, Totals{Commits: 15}, renderOptions{extra: true})
+	if err != nil {
+		t.Fatalf("error rendering xlsx: %s", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(b)
+
+		// every part is well-formed XML
+		dec := xml.NewDecoder(bytes.NewReader(b))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("malformed %s: %s", f.Name, err)
+			}
+		}
+	}
+
+	for _, name := range []string{
+		"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels", "xl/styles.xml",
+		"xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml",
+	} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook lacks part %s", name)
+		}
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, exp := range []string{
+		`state="frozen"`,
+		`<c r="A1" s="1" t="inlineStr"><is><t>Author</t></is></c>`,
+		`<c r="H1" s="1" t="inlineStr"><is><t>Del/add ratio</t></is></c>`,
+		`<t>Author &lt;One&gt; &amp; Co</t>`,
+		`<c r="B2"><v>12</v></c>`,
+		`<c r="E2" s="2"><v>0.75</v></c>`,
+		`<c r="H2" s="2"><v>0.2</v></c>`,
+		`<c r="H3" t="inlineStr"><is><t>∞</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, exp) {
+			t.Errorf("Expected summary sheet to contain %s, got:\n%s", exp, sheet)
+		}
+	}
+
+	if !strings.Contains(parts["xl/worksheets/sheet2.xml"], `<c r="B2"><v>15</v></c>`) {
+		t.Errorf("Expected totals sheet with the commits: %s", parts["xl/worksheets/sheet2.xml"])
+	}
+
+	for i, exp := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != exp {
+			t.Errorf("Expected column %d to be %s, got %s", i, exp, got)
+		}
+	}
+}